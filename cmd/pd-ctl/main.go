@@ -36,6 +36,7 @@ var (
 	caPath   string
 	certPath string
 	keyPath  string
+	token    string
 )
 
 func init() {
@@ -45,6 +46,7 @@ func init() {
 	flag.StringVar(&caPath, "cacert", "", "path of file that contains list of trusted SSL CAs.")
 	flag.StringVar(&certPath, "cert", "", "path of file that contains X509 certificate in PEM format.")
 	flag.StringVar(&keyPath, "key", "", "path of file that contains X509 key in PEM format.")
+	flag.StringVar(&token, "token", "", "bearer token of the pd HTTP API.")
 }
 
 func main() {
@@ -132,6 +134,9 @@ func loop() {
 		if caPath != "" && certPath != "" && keyPath != "" {
 			args = append(args, "--cacert", caPath, "--cert", certPath, "--key", keyPath)
 		}
+		if token != "" {
+			args = append(args, "--token", token)
+		}
 		pdctl.Start(args)
 	}
 }
@@ -84,7 +84,6 @@ func main() {
 
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc,
-		syscall.SIGHUP,
 		syscall.SIGINT,
 		syscall.SIGTERM,
 		syscall.SIGQUIT)
@@ -96,6 +95,18 @@ func main() {
 		cancel()
 	}()
 
+	// Reload the config file on SIGHUP.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			log.Info("got SIGHUP, reload the config file")
+			if _, err := svr.ReloadConfigFile(); err != nil {
+				log.Errorf("reload config file failed: %v", errors.ErrorStack(err))
+			}
+		}
+	}()
+
 	if err := svr.Run(ctx); err != nil {
 		log.Fatalf("run server failed: %v", errors.ErrorStack(err))
 	}
@@ -35,17 +35,25 @@ var (
 	caPath      = flag.String("cacert", "", "path of file that contains list of trusted SSL CAs.")
 	certPath    = flag.String("cert", "", "path of file that contains X509 certificate in PEM format..")
 	keyPath     = flag.String("key", "", "path of file that contains X509 key in PEM format.")
+	timeout     = flag.Duration("timeout", 3*time.Second, "timeout of the tso requests")
+	batchSize   = flag.Int("batch-size", 10000, "max count of the tso requests in a batch")
+	batchWait   = flag.Duration("batch-wait", 0, "time to wait for more tso requests before sending a batch")
 	wg          sync.WaitGroup
 )
 
 func main() {
 	flag.Parse()
 
+	opts := []pd.ClientOption{
+		pd.WithRequestTimeout(*timeout),
+		pd.WithMaxTSOBatchSize(*batchSize),
+		pd.WithTSOBatchWait(*batchWait),
+	}
 	pdCli, err := pd.NewClient([]string{*pdAddrs}, pd.SecurityOption{
 		CAPath:   *caPath,
 		CertPath: *certPath,
 		KeyPath:  *keyPath,
-	})
+	}, opts...)
 	if err != nil {
 		log.Fatal(err)
 	}
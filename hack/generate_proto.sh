@@ -0,0 +1,18 @@
+#!/usr/bin/env bash
+# Generates the Go code of the protos which are not in kvproto. It needs protoc
+# and protoc-gen-gofast of gogo/protobuf v1.0.0 in PATH, the imported kvproto
+# protos are taken from KVPROTO, which should be at the revision in Gopkg.lock.
+set -euo pipefail
+
+cd "$(dirname "$0")/.."
+KVPROTO=${KVPROTO:-${GOPATH%%:*}/src/github.com/pingcap/kvproto}
+
+MAPPINGS="Mmetapb.proto=github.com/pingcap/kvproto/pkg/metapb"
+MAPPINGS="${MAPPINGS},Mpdpb.proto=github.com/pingcap/kvproto/pkg/pdpb"
+MAPPINGS="${MAPPINGS},Meraftpb.proto=github.com/pingcap/kvproto/pkg/eraftpb"
+
+for dir in pkg/pdextpb pkg/pdinternalpb; do
+	protoc -I"${dir}" -I"${KVPROTO}/proto" -I"${KVPROTO}/include" \
+		--gofast_out=plugins=grpc,"${MAPPINGS}":"${dir}" "${dir}"/*.proto
+	gofmt -s -w "${dir}"/*.pb.go
+done
@@ -17,6 +17,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
@@ -26,10 +27,11 @@ import (
 	"github.com/opentracing/opentracing-go"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/certutil"
+	"github.com/pingcap/pd/pkg/pdextpb"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 )
 
 // Client is a PD (Placement Driver) client.
@@ -41,6 +43,16 @@ type Client interface {
 	GetTS(ctx context.Context) (int64, int64, error)
 	// GetTSAsync gets a timestamp from PD, without block the caller.
 	GetTSAsync(ctx context.Context) TSFuture
+	// GetMembers gets the members of the PD cluster and the leader.
+	GetMembers(ctx context.Context) ([]*pdpb.Member, *pdpb.Member, error)
+	// OnLeaderChange registers a callback which is called after the PD leader
+	// changes. The callbacks are called in order in a dedicated goroutine,
+	// they should not block for long.
+	OnLeaderChange(f LeaderChangeFunc)
+	// OnMembersChange registers a callback which is called after the members
+	// of the PD cluster change. The callbacks are called in the same
+	// goroutine as OnLeaderChange's.
+	OnMembersChange(f MembersChangeFunc)
 	// GetRegion gets a region and its leader Peer from PD by key.
 	// The region may expire after split. Caller is responsible for caching and
 	// taking care of region change.
@@ -49,18 +61,49 @@ type Client interface {
 	GetRegion(ctx context.Context, key []byte) (*metapb.Region, *metapb.Peer, error)
 	// GetRegionByID gets a region and its leader Peer from PD by id.
 	GetRegionByID(ctx context.Context, regionID uint64) (*metapb.Region, *metapb.Peer, error)
+	// GetPrevRegion gets the previous region and its leader Peer of the region
+	// where the key is located. It returns nil if there is a hole between
+	// them.
+	GetPrevRegion(ctx context.Context, key []byte) (*metapb.Region, *metapb.Peer, error)
+	// ScanRegions gets the regions which overlap with [key, endKey), starting
+	// from the region that contains key. An empty endKey means scanning to the
+	// end, limit limits the max count of regions returned.
+	// If a region has no leader, the corresponding leader is an empty Peer
+	// whose Id is 0.
+	ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*metapb.Region, []*metapb.Peer, error)
 	// GetStore gets a store from PD by store id.
 	// The store may expire later. Caller is responsible for caching and taking care
 	// of store change.
 	GetStore(ctx context.Context, storeID uint64) (*metapb.Store, error)
+	// GetAllStores gets all stores from PD, the tombstone stores are excluded.
+	GetAllStores(ctx context.Context) ([]*metapb.Store, error)
+	// ScatterRegion scatters the specified region. It should be used for a
+	// batch of regions, then the regions will be dispersed.
+	ScatterRegion(ctx context.Context, regionID uint64) error
+	// GetOperator gets the status of the pending operator of the specified
+	// region. It returns nil if the region has no pending operator.
+	GetOperator(ctx context.Context, regionID uint64) (*OperatorStatus, error)
 	// Update GC safe point. TiKV will check it and do GC themselves if necessary.
 	// If the given safePoint is less than the current one, it will not be updated.
 	// Returns the new safePoint after updating.
 	UpdateGCSafePoint(ctx context.Context, safePoint uint64) (uint64, error)
+	// UpdateServiceGCSafePoint updates the safe point of a service, the GC
+	// safe point will not exceed it before the ttl expires. The ttl is rounded
+	// up to seconds, a non-positive ttl removes the safe point of the service.
+	UpdateServiceGCSafePoint(ctx context.Context, serviceID string, ttl time.Duration, safePoint uint64) error
 	// Close closes the client.
 	Close()
 }
 
+// OperatorStatus is the status of the pending operator of a region.
+type OperatorStatus struct {
+	RegionID uint64 `json:"region_id"`
+	Desc     string `json:"desc"`
+	Kind     string `json:"kind"`
+	// Status is one of "running", "timeout" and "finished".
+	Status string `json:"status"`
+}
+
 type tsoRequest struct {
 	start    time.Time
 	ctx      context.Context
@@ -69,13 +112,6 @@ type tsoRequest struct {
 	logical  int64
 }
 
-const (
-	pdTimeout             = 3 * time.Second
-	updateLeaderTimeout   = time.Second // Use a shorter timeout to recover faster from network isolation.
-	maxMergeTSORequests   = 10000
-	maxInitClusterRetries = 100
-)
-
 var (
 	// errFailInitClusterID is returned when failed to load clusterID from all supplied PD addresses.
 	errFailInitClusterID = errors.New("[pd] failed to get cluster id")
@@ -99,11 +135,19 @@ type client struct {
 	tsDeadlineCh  chan deadline
 	checkLeaderCh chan struct{}
 
+	notifier *notifier
+
 	wg     sync.WaitGroup
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	security SecurityOption
+	// certReloader keeps the certificates up to date with the files, it is
+	// nil if TLS is not enabled.
+	certReloader *certutil.Reloader
+	option       *clientOptions
+	// httpClient is used for the requests which are only served by the HTTP
+	// API of the PD leader.
+	httpClient *http.Client
 }
 
 // SecurityOption records options about tls
@@ -113,18 +157,76 @@ type SecurityOption struct {
 	KeyPath  string
 }
 
+// ToTLSConfig returns the TLS config built from the security option, it
+// returns nil if TLS is not enabled.
+func (s SecurityOption) ToTLSConfig() (*tls.Config, error) {
+	if len(s.CAPath) == 0 {
+		return nil, nil
+	}
+
+	certificates := []tls.Certificate{}
+	if len(s.CertPath) != 0 && len(s.KeyPath) != 0 {
+		// Load the client certificates from disk
+		certificate, err := tls.LoadX509KeyPair(s.CertPath, s.KeyPath)
+		if err != nil {
+			return nil, errors.Errorf("could not load client key pair: %s", err)
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	// Create a certificate pool from the certificate authority
+	certPool := x509.NewCertPool()
+	ca, err := ioutil.ReadFile(s.CAPath)
+	if err != nil {
+		return nil, errors.Errorf("could not read ca certificate: %s", err)
+	}
+
+	// Append the certificates from the CA
+	if !certPool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("failed to append ca certs")
+	}
+
+	return &tls.Config{
+		Certificates: certificates,
+		RootCAs:      certPool,
+	}, nil
+}
+
+// NewCertReloader returns the Reloader which keeps the certificates up to
+// date with the files, it returns nil if TLS is not enabled.
+func (s SecurityOption) NewCertReloader() (*certutil.Reloader, error) {
+	if len(s.CAPath) == 0 {
+		return nil, nil
+	}
+	reloader, err := certutil.NewReloader(certutil.Paths{CAPath: s.CAPath, CertPath: s.CertPath, KeyPath: s.KeyPath})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return reloader, nil
+}
+
 // NewClient creates a PD client.
-func NewClient(pdAddrs []string, security SecurityOption) (Client, error) {
+func NewClient(pdAddrs []string, security SecurityOption, opts ...ClientOption) (Client, error) {
 	log.Infof("[pd] create pd client with endpoints %v", pdAddrs)
+	option := newClientOptions()
+	for _, opt := range opts {
+		opt(option)
+	}
+	certReloader, err := security.NewCertReloader()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	c := &client{
 		urls:          addrsToUrls(pdAddrs),
-		tsoRequests:   make(chan *tsoRequest, maxMergeTSORequests),
+		tsoRequests:   make(chan *tsoRequest, option.maxTSOBatchSize),
 		tsDeadlineCh:  make(chan deadline, 1),
 		checkLeaderCh: make(chan struct{}, 1),
+		notifier:      newNotifier(),
 		ctx:           ctx,
 		cancel:        cancel,
-		security:      security,
+		certReloader:  certReloader,
+		option:        option,
 	}
 	c.connMu.clientConns = make(map[string]*grpc.ClientConn)
 
@@ -134,12 +236,18 @@ func NewClient(pdAddrs []string, security SecurityOption) (Client, error) {
 	if err := c.updateLeader(); err != nil {
 		return nil, errors.Trace(err)
 	}
+	transport := &http.Transport{}
+	if certReloader != nil {
+		transport.DialTLS = certReloader.DialTLS
+	}
+	c.httpClient = &http.Client{Transport: transport}
 	log.Infof("[pd] init cluster id %v", c.clusterID)
 
-	c.wg.Add(3)
+	c.wg.Add(4)
 	go c.tsLoop()
 	go c.tsCancelLoop()
 	go c.leaderLoop()
+	go c.notifyLoop()
 
 	return c, nil
 }
@@ -153,11 +261,11 @@ func (c *client) updateURLs(members []*pdpb.Member) {
 }
 
 func (c *client) initClusterID() error {
-	ctx, cancel := context.WithCancel(c.ctx)
-	defer cancel()
-	for i := 0; i < maxInitClusterRetries; i++ {
+	for i := 0; i < c.option.backoff.MaxRetries; i++ {
 		for _, u := range c.urls {
+			ctx, cancel := context.WithTimeout(c.ctx, c.option.dialTimeout)
 			members, err := c.getMembers(ctx, u)
+			cancel()
 			if err != nil || members.GetHeader() == nil {
 				log.Errorf("[pd] failed to get cluster id: %v", err)
 				continue
@@ -166,34 +274,55 @@ func (c *client) initClusterID() error {
 			return nil
 		}
 
-		time.Sleep(time.Second)
+		if !c.backoff(i) {
+			break
+		}
 	}
 
 	return errors.Trace(errFailInitClusterID)
 }
 
+// updateLeader gets the leader from the members and switches to it, all the
+// members are retried with the backoff policy if none of them knows the
+// leader.
 func (c *client) updateLeader() error {
-	for _, u := range c.urls {
-		ctx, cancel := context.WithTimeout(c.ctx, updateLeaderTimeout)
-		members, err := c.getMembers(ctx, u)
-		cancel()
-		if err != nil || members.GetLeader() == nil || len(members.GetLeader().GetClientUrls()) == 0 {
-			select {
-			case <-c.ctx.Done():
-				return errors.Trace(err)
-			default:
+	for i := 0; i < c.option.backoff.MaxRetries; i++ {
+		for _, u := range c.urls {
+			ctx, cancel := context.WithTimeout(c.ctx, c.option.dialTimeout)
+			members, err := c.getMembers(ctx, u)
+			cancel()
+			if err != nil || members.GetLeader() == nil || len(members.GetLeader().GetClientUrls()) == 0 {
 				continue
 			}
+			c.updateURLs(members.GetMembers())
+			c.notifier.membersUpdated(members.GetMembers())
+			if err = c.switchLeader(members.GetLeader().GetClientUrls()); err != nil {
+				return errors.Trace(err)
+			}
+			return nil
 		}
-		c.updateURLs(members.GetMembers())
-		if err = c.switchLeader(members.GetLeader().GetClientUrls()); err != nil {
-			return errors.Trace(err)
+
+		if !c.backoff(i) {
+			break
 		}
-		return nil
 	}
 	return errors.Errorf("failed to get leader from %v", c.urls)
 }
 
+// backoff waits before the next retry, it returns false if the client is
+// closed or there is no retry left.
+func (c *client) backoff(retries int) bool {
+	if retries+1 >= c.option.backoff.MaxRetries {
+		return false
+	}
+	select {
+	case <-time.After(c.option.backoff.delay(retries)):
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
 func (c *client) getMembers(ctx context.Context, url string) (*pdpb.GetMembersResponse, error) {
 	cc, err := c.getOrCreateGRPCConn(url)
 	if err != nil {
@@ -224,8 +353,9 @@ func (c *client) switchLeader(addrs []string) error {
 	}
 
 	c.connMu.Lock()
-	defer c.connMu.Unlock()
 	c.connMu.leader = addr
+	c.connMu.Unlock()
+	c.notifier.leaderChanged(addr, oldLeader)
 	return nil
 }
 
@@ -238,42 +368,17 @@ func (c *client) getOrCreateGRPCConn(addr string) (*grpc.ClientConn, error) {
 	}
 
 	opt := grpc.WithInsecure()
-	if len(c.security.CAPath) != 0 {
-
-		certificates := []tls.Certificate{}
-		if len(c.security.CertPath) != 0 && len(c.security.KeyPath) != 0 {
-			// Load the client certificates from disk
-			certificate, err := tls.LoadX509KeyPair(c.security.CertPath, c.security.KeyPath)
-			if err != nil {
-				return nil, errors.Errorf("could not load client key pair: %s", err)
-			}
-			certificates = append(certificates, certificate)
-		}
-
-		// Create a certificate pool from the certificate authority
-		certPool := x509.NewCertPool()
-		ca, err := ioutil.ReadFile(c.security.CAPath)
-		if err != nil {
-			return nil, errors.Errorf("could not read ca certificate: %s", err)
-		}
-
-		// Append the certificates from the CA
-		if !certPool.AppendCertsFromPEM(ca) {
-			return nil, errors.New("failed to append ca certs")
-		}
-
-		creds := credentials.NewTLS(&tls.Config{
-			Certificates: certificates,
-			RootCAs:      certPool,
-		})
-
-		opt = grpc.WithTransportCredentials(creds)
+	if c.certReloader != nil {
+		opt = grpc.WithTransportCredentials(c.certReloader.TransportCredentials())
 	}
 	u, err := url.Parse(addr)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	cc, err := grpc.Dial(u.Host, opt)
+	ctx, cancel := context.WithTimeout(c.ctx, c.option.dialTimeout)
+	defer cancel()
+	cc, err := grpc.DialContext(ctx, u.Host, opt, grpc.WithBlock(),
+		grpc.WithBackoffMaxDelay(c.option.backoff.MaxDelay))
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -376,14 +481,10 @@ func (c *client) tsLoop() {
 
 		select {
 		case first := <-c.tsoRequests:
-			requests = append(requests, first)
-			pending := len(c.tsoRequests)
-			for i := 0; i < pending; i++ {
-				requests = append(requests, <-c.tsoRequests)
-			}
+			requests = c.collectTSORequests(append(requests, first))
 			done := make(chan struct{})
 			dl := deadline{
-				timer:  time.After(pdTimeout),
+				timer:  time.After(c.option.requestTimeout),
 				done:   done,
 				cancel: cancel,
 			}
@@ -415,6 +516,31 @@ func (c *client) tsLoop() {
 	}
 }
 
+// collectTSORequests appends the pending TSO requests to the batch, it waits
+// for more requests within the batch wait window if it's configured.
+func (c *client) collectTSORequests(requests []*tsoRequest) []*tsoRequest {
+	maxBatchSize := c.option.maxTSOBatchSize
+	pending := len(c.tsoRequests)
+	for i := 0; i < pending && len(requests) < maxBatchSize; i++ {
+		requests = append(requests, <-c.tsoRequests)
+	}
+	if c.option.tsoBatchWait <= 0 || len(requests) >= maxBatchSize {
+		return requests
+	}
+
+	timer := time.NewTimer(c.option.tsoBatchWait)
+	defer timer.Stop()
+	for len(requests) < maxBatchSize {
+		select {
+		case req := <-c.tsoRequests:
+			requests = append(requests, req)
+		case <-timer.C:
+			return requests
+		}
+	}
+	return requests
+}
+
 func extractSpanReference(requests []*tsoRequest, opts []opentracing.StartSpanOption) []opentracing.StartSpanOption {
 	for _, req := range requests {
 		if span := opentracing.SpanFromContext(req.ctx); span != nil {
@@ -431,6 +557,7 @@ func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoReq
 	}
 
 	start := time.Now()
+	tsoBatchSize.Observe(float64(len(requests)))
 	req := &pdpb.TsoRequest{
 		Header: c.requestHeader(),
 		Count:  uint32(len(requests)),
@@ -501,6 +628,15 @@ func (c *client) leaderClient() pdpb.PDClient {
 	return pdpb.NewPDClient(c.connMu.clientConns[c.connMu.leader])
 }
 
+// leaderExtClient returns the client of the PD methods which are not in
+// kvproto yet.
+func (c *client) leaderExtClient() pdextpb.PDClient {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+
+	return pdextpb.NewPDClient(c.connMu.clientConns[c.connMu.leader])
+}
+
 func (c *client) ScheduleCheckLeader() {
 	select {
 	case c.checkLeaderCh <- struct{}{}:
@@ -574,6 +710,33 @@ func (c *client) GetTS(ctx context.Context) (int64, int64, error) {
 	return resp.Wait()
 }
 
+func (c *client) GetMembers(ctx context.Context) ([]*pdpb.Member, *pdpb.Member, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.GetMembers", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDuration.WithLabelValues("get_members").Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
+	resp, err := c.leaderClient().GetMembers(ctx, &pdpb.GetMembersRequest{
+		Header: c.requestHeader(),
+	})
+	requestDuration.WithLabelValues("get_members").Observe(time.Since(start).Seconds())
+	cancel()
+
+	if err != nil {
+		cmdFailedDuration.WithLabelValues("get_members").Observe(time.Since(start).Seconds())
+		c.ScheduleCheckLeader()
+		return nil, nil, errors.Trace(err)
+	}
+	if err := headerError(resp.GetHeader()); err != nil {
+		cmdFailedDuration.WithLabelValues("get_members").Observe(time.Since(start).Seconds())
+		return nil, nil, errors.Trace(err)
+	}
+	return resp.GetMembers(), resp.GetLeader(), nil
+}
+
 func (c *client) GetRegion(ctx context.Context, key []byte) (*metapb.Region, *metapb.Peer, error) {
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		span = opentracing.StartSpan("pdclient.GetRegion", opentracing.ChildOf(span.Context()))
@@ -582,7 +745,7 @@ func (c *client) GetRegion(ctx context.Context, key []byte) (*metapb.Region, *me
 	start := time.Now()
 	defer func() { cmdDuration.WithLabelValues("get_region").Observe(time.Since(start).Seconds()) }()
 
-	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
 	resp, err := c.leaderClient().GetRegion(ctx, &pdpb.GetRegionRequest{
 		Header:    c.requestHeader(),
 		RegionKey: key,
@@ -606,7 +769,7 @@ func (c *client) GetRegionByID(ctx context.Context, regionID uint64) (*metapb.Re
 	start := time.Now()
 	defer func() { cmdDuration.WithLabelValues("get_region_byid").Observe(time.Since(start).Seconds()) }()
 
-	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
 	resp, err := c.leaderClient().GetRegionByID(ctx, &pdpb.GetRegionByIDRequest{
 		Header:   c.requestHeader(),
 		RegionId: regionID,
@@ -630,7 +793,7 @@ func (c *client) GetStore(ctx context.Context, storeID uint64) (*metapb.Store, e
 	start := time.Now()
 	defer func() { cmdDuration.WithLabelValues("get_store").Observe(time.Since(start).Seconds()) }()
 
-	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
 	resp, err := c.leaderClient().GetStore(ctx, &pdpb.GetStoreRequest{
 		Header:  c.requestHeader(),
 		StoreId: storeID,
@@ -653,6 +816,162 @@ func (c *client) GetStore(ctx context.Context, storeID uint64) (*metapb.Store, e
 	return store, nil
 }
 
+func (c *client) GetPrevRegion(ctx context.Context, key []byte) (*metapb.Region, *metapb.Peer, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.GetPrevRegion", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDuration.WithLabelValues("get_prev_region").Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
+	resp, err := c.leaderExtClient().GetPrevRegion(ctx, &pdpb.GetRegionRequest{
+		Header:    c.requestHeader(),
+		RegionKey: key,
+	})
+	requestDuration.WithLabelValues("get_prev_region").Observe(time.Since(start).Seconds())
+	cancel()
+
+	if err != nil {
+		cmdFailedDuration.WithLabelValues("get_prev_region").Observe(time.Since(start).Seconds())
+		c.ScheduleCheckLeader()
+		return nil, nil, errors.Trace(err)
+	}
+	if err := headerError(resp.GetHeader()); err != nil {
+		cmdFailedDuration.WithLabelValues("get_prev_region").Observe(time.Since(start).Seconds())
+		return nil, nil, errors.Trace(err)
+	}
+	return resp.GetRegion(), resp.GetLeader(), nil
+}
+
+func (c *client) ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*metapb.Region, []*metapb.Peer, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.ScanRegions", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDuration.WithLabelValues("scan_regions").Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
+	resp, err := c.leaderExtClient().ScanRegions(ctx, &pdextpb.ScanRegionsRequest{
+		Header:   c.requestHeader(),
+		StartKey: key,
+		EndKey:   endKey,
+		Limit:    int32(limit),
+	})
+	requestDuration.WithLabelValues("scan_regions").Observe(time.Since(start).Seconds())
+	cancel()
+
+	if err != nil {
+		cmdFailedDuration.WithLabelValues("scan_regions").Observe(time.Since(start).Seconds())
+		c.ScheduleCheckLeader()
+		return nil, nil, errors.Trace(err)
+	}
+	if err := headerError(resp.Header); err != nil {
+		cmdFailedDuration.WithLabelValues("scan_regions").Observe(time.Since(start).Seconds())
+		return nil, nil, errors.Trace(err)
+	}
+	scanRegionsCount.Observe(float64(len(resp.Regions)))
+	return resp.Regions, resp.Leaders, nil
+}
+
+func (c *client) GetAllStores(ctx context.Context) ([]*metapb.Store, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.GetAllStores", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDuration.WithLabelValues("get_all_stores").Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
+	resp, err := c.leaderClient().GetAllStores(ctx, &pdpb.GetAllStoresRequest{
+		Header: c.requestHeader(),
+	})
+	requestDuration.WithLabelValues("get_all_stores").Observe(time.Since(start).Seconds())
+	cancel()
+
+	if err != nil {
+		cmdFailedDuration.WithLabelValues("get_all_stores").Observe(time.Since(start).Seconds())
+		c.ScheduleCheckLeader()
+		return nil, errors.Trace(err)
+	}
+	if err := headerError(resp.GetHeader()); err != nil {
+		cmdFailedDuration.WithLabelValues("get_all_stores").Observe(time.Since(start).Seconds())
+		return nil, errors.Trace(err)
+	}
+	stores := make([]*metapb.Store, 0, len(resp.GetStores()))
+	for _, store := range resp.GetStores() {
+		if store.GetState() != metapb.StoreState_Tombstone {
+			stores = append(stores, store)
+		}
+	}
+	return stores, nil
+}
+
+func (c *client) ScatterRegion(ctx context.Context, regionID uint64) error {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.ScatterRegion", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDuration.WithLabelValues("scatter_region").Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
+	resp, err := c.leaderClient().ScatterRegion(ctx, &pdpb.ScatterRegionRequest{
+		Header:   c.requestHeader(),
+		RegionId: regionID,
+	})
+	requestDuration.WithLabelValues("scatter_region").Observe(time.Since(start).Seconds())
+	cancel()
+
+	if err != nil {
+		cmdFailedDuration.WithLabelValues("scatter_region").Observe(time.Since(start).Seconds())
+		c.ScheduleCheckLeader()
+		return errors.Trace(err)
+	}
+	if err := headerError(resp.GetHeader()); err != nil {
+		cmdFailedDuration.WithLabelValues("scatter_region").Observe(time.Since(start).Seconds())
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+func (c *client) GetOperator(ctx context.Context, regionID uint64) (*OperatorStatus, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.GetOperator", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDuration.WithLabelValues("get_operator").Observe(time.Since(start).Seconds()) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
+	resp, err := c.leaderExtClient().GetOperator(ctx, &pdextpb.GetOperatorRequest{
+		Header:   c.requestHeader(),
+		RegionId: regionID,
+	})
+	requestDuration.WithLabelValues("get_operator").Observe(time.Since(start).Seconds())
+	cancel()
+
+	if err != nil {
+		cmdFailedDuration.WithLabelValues("get_operator").Observe(time.Since(start).Seconds())
+		c.ScheduleCheckLeader()
+		return nil, errors.Trace(err)
+	}
+	if err := headerError(resp.Header); err != nil {
+		cmdFailedDuration.WithLabelValues("get_operator").Observe(time.Since(start).Seconds())
+		return nil, errors.Trace(err)
+	}
+	if resp.RegionId == 0 {
+		return nil, nil
+	}
+	return &OperatorStatus{
+		RegionID: resp.RegionId,
+		Desc:     resp.Desc,
+		Kind:     resp.Kind,
+		Status:   resp.Status,
+	}, nil
+}
+
 func (c *client) UpdateGCSafePoint(ctx context.Context, safePoint uint64) (uint64, error) {
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		span = opentracing.StartSpan("pdclient.UpdateGCSafePoint", opentracing.ChildOf(span.Context()))
@@ -661,7 +980,7 @@ func (c *client) UpdateGCSafePoint(ctx context.Context, safePoint uint64) (uint6
 	start := time.Now()
 	defer func() { cmdDuration.WithLabelValues("update_gc_safe_point").Observe(time.Since(start).Seconds()) }()
 
-	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
 	resp, err := c.leaderClient().UpdateGCSafePoint(ctx, &pdpb.UpdateGCSafePointRequest{
 		Header:    c.requestHeader(),
 		SafePoint: safePoint,
@@ -677,6 +996,43 @@ func (c *client) UpdateGCSafePoint(ctx context.Context, safePoint uint64) (uint6
 	return resp.GetNewSafePoint(), nil
 }
 
+func (c *client) UpdateServiceGCSafePoint(ctx context.Context, serviceID string, ttl time.Duration, safePoint uint64) error {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.UpdateServiceGCSafePoint", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() {
+		cmdDuration.WithLabelValues("update_service_gc_safe_point").Observe(time.Since(start).Seconds())
+	}()
+
+	// The ttl is in seconds on the server, round it up so that a positive ttl
+	// never becomes 0, which removes the safe point.
+	ttlSeconds := int64((ttl + time.Second - 1) / time.Second)
+	ctx, cancel := context.WithTimeout(ctx, c.option.requestTimeout)
+	err := c.leaderHTTPPost(ctx, "/api/v1/gc/safepoint/service/"+url.PathEscape(serviceID), map[string]interface{}{
+		"safe_point": safePoint,
+		"ttl":        ttlSeconds,
+	})
+	requestDuration.WithLabelValues("update_service_gc_safe_point").Observe(time.Since(start).Seconds())
+	cancel()
+
+	if err != nil {
+		cmdFailedDuration.WithLabelValues("update_service_gc_safe_point").Observe(time.Since(start).Seconds())
+		c.ScheduleCheckLeader()
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// headerError returns the error in the response header.
+func headerError(header *pdpb.ResponseHeader) error {
+	if err := header.GetError(); err != nil {
+		return errors.Errorf("[pd] %s: %s", err.GetType(), err.GetMessage())
+	}
+	return nil
+}
+
 func (c *client) requestHeader() *pdpb.RequestHeader {
 	return &pdpb.RequestHeader{
 		ClusterId: c.clusterID,
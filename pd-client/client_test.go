@@ -16,6 +16,7 @@ package pd
 import (
 	"context"
 	"math"
+	"os"
 	"strings"
 	"sync"
 	"testing"
@@ -25,6 +26,8 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/api"
+	"github.com/pingcap/pd/server/core"
 	"google.golang.org/grpc"
 )
 
@@ -158,6 +161,57 @@ func (s *testClientSuite) TestTSORace(c *C) {
 	wg.Wait()
 }
 
+func (s *testClientSuite) TestTSOBatch(c *C) {
+	cli, err := NewClient(s.srv.GetEndpoints(), SecurityOption{},
+		WithRequestTimeout(time.Second),
+		WithMaxTSOBatchSize(8),
+		WithTSOBatchWait(time.Millisecond))
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	tss := make(map[int64]struct{})
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				p, l, err := cli.GetTS(context.Background())
+				c.Assert(err, IsNil)
+				mu.Lock()
+				tss[p<<18+l] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	// The timestamps in the same batch are still unique.
+	c.Assert(tss, HasLen, 500)
+}
+
+func (s *testClientSuite) TestBackoff(c *C) {
+	backoff := BackoffPolicy{MaxRetries: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	c.Assert(backoff.delay(0), Equals, 100*time.Millisecond)
+	c.Assert(backoff.delay(2), Equals, 400*time.Millisecond)
+	c.Assert(backoff.delay(4), Equals, time.Second)
+	c.Assert(defaultBackoff.delay(10), Equals, time.Second)
+}
+
+func (s *testClientSuite) TestUnreachable(c *C) {
+	// Each of the 3 retries waits for the dial timeout, with the delay between them.
+	start := time.Now()
+	_, err := NewClient([]string{"127.0.0.1:1"}, SecurityOption{},
+		WithDialTimeout(100*time.Millisecond),
+		WithBackoff(BackoffPolicy{MaxRetries: 3, BaseDelay: 50 * time.Millisecond, MaxDelay: 50 * time.Millisecond}))
+	c.Assert(err, NotNil)
+	elapsed := time.Since(start)
+	c.Assert(elapsed, GreaterEqual, 400*time.Millisecond)
+	c.Assert(elapsed, Less, 2*time.Second)
+}
+
 func (s *testClientSuite) TestGetRegion(c *C) {
 	req := &pdpb.RegionHeartbeatRequest{
 		Header: newHeader(s.srv),
@@ -240,3 +294,216 @@ func (s *testClientSuite) TestUpdateGCSafePoint(c *C) {
 	c.Assert(err, IsNil)
 	s.checkGCSafePoint(c, math.MaxUint64)
 }
+
+var _ = Suite(&testServiceGCSafePointSuite{})
+
+type testServiceGCSafePointSuite struct {
+	cfg    *server.Config
+	srv    *server.Server
+	client Client
+}
+
+func (s *testServiceGCSafePointSuite) SetUpSuite(c *C) {
+	var err error
+	// The service safe points are updated through the HTTP API.
+	s.cfg = server.NewTestSingleConfig()
+	s.srv, err = server.CreateServer(s.cfg, api.NewHandler)
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.Run(context.Background()), IsNil)
+	mustWaitLeader(c, map[string]*server.Server{s.srv.GetAddr(): s.srv})
+	bootstrapServer(c, newHeader(s.srv), mustNewGrpcClient(c, s.srv.GetAddr()))
+
+	s.client, err = NewClient(s.srv.GetEndpoints(), SecurityOption{})
+	c.Assert(err, IsNil)
+}
+
+func (s *testServiceGCSafePointSuite) TearDownSuite(c *C) {
+	s.client.Close()
+	s.srv.Close()
+	os.RemoveAll(s.cfg.DataDir)
+}
+
+func (s *testServiceGCSafePointSuite) TestUpdateServiceGCSafePoint(c *C) {
+	ctx := context.Background()
+	c.Assert(s.client.UpdateServiceGCSafePoint(ctx, "br", time.Minute, 100), IsNil)
+	newSafePoint, err := s.client.UpdateGCSafePoint(ctx, 200)
+	c.Assert(err, IsNil)
+	c.Assert(newSafePoint, Equals, uint64(100))
+
+	// The service safe point can not be less than the GC safe point.
+	c.Assert(s.client.UpdateServiceGCSafePoint(ctx, "cdc", time.Minute, 50), NotNil)
+	c.Assert(s.client.UpdateServiceGCSafePoint(ctx, "invalid/id", time.Minute, 150), NotNil)
+
+	// Removes the service safe point.
+	c.Assert(s.client.UpdateServiceGCSafePoint(ctx, "br", 0, 0), IsNil)
+	newSafePoint, err = s.client.UpdateGCSafePoint(ctx, 200)
+	c.Assert(err, IsNil)
+	c.Assert(newSafePoint, Equals, uint64(200))
+
+	// A ttl less than a second still keeps the service safe point.
+	c.Assert(s.client.UpdateServiceGCSafePoint(ctx, "br", 500*time.Millisecond, 250), IsNil)
+	newSafePoint, err = s.client.UpdateGCSafePoint(ctx, 300)
+	c.Assert(err, IsNil)
+	c.Assert(newSafePoint, Equals, uint64(250))
+}
+
+var _ = Suite(&testRegionScanSuite{})
+
+type testRegionScanSuite struct {
+	cfg    *server.Config
+	srv    *server.Server
+	client Client
+}
+
+func (s *testRegionScanSuite) SetUpSuite(c *C) {
+	var err error
+	s.cfg = server.NewTestSingleConfig()
+	s.srv, err = server.CreateServer(s.cfg, api.NewHandler)
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.Run(context.Background()), IsNil)
+	mustWaitLeader(c, map[string]*server.Server{s.srv.GetAddr(): s.srv})
+	bootstrapServer(c, newHeader(s.srv), mustNewGrpcClient(c, s.srv.GetAddr()))
+
+	s.client, err = NewClient(s.srv.GetEndpoints(), SecurityOption{})
+	c.Assert(err, IsNil)
+}
+
+func (s *testRegionScanSuite) TearDownSuite(c *C) {
+	s.client.Close()
+	s.srv.Close()
+	os.RemoveAll(s.cfg.DataDir)
+}
+
+func (s *testRegionScanSuite) TestScanRegions(c *C) {
+	cluster := s.srv.GetRaftCluster()
+	c.Assert(cluster, NotNil)
+	keys := [][]byte{nil, []byte("a"), []byte("b"), []byte("c"), nil}
+	regions := make([]*metapb.Region, 0, len(keys)-1)
+	for i := 0; i < len(keys)-1; i++ {
+		r := &metapb.Region{
+			Id:          uint64(100 + i),
+			StartKey:    keys[i],
+			EndKey:      keys[i+1],
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 2},
+			Peers:       []*metapb.Peer{{Id: uint64(200 + i), StoreId: store.GetId()}},
+		}
+		var leader *metapb.Peer
+		// The last region has no leader.
+		if i < len(keys)-2 {
+			leader = r.Peers[0]
+		}
+		c.Assert(cluster.HandleRegionHeartbeat(core.NewRegionInfo(r, leader)), IsNil)
+		regions = append(regions, r)
+	}
+
+	ctx := context.Background()
+	scanned, leaders, err := s.client.ScanRegions(ctx, []byte("a1"), nil, 10)
+	c.Assert(err, IsNil)
+	c.Assert(scanned, DeepEquals, regions[1:])
+	c.Assert(leaders, HasLen, 3)
+	c.Assert(leaders[0], DeepEquals, regions[1].Peers[0])
+	c.Assert(leaders[2], DeepEquals, &metapb.Peer{})
+
+	scanned, _, err = s.client.ScanRegions(ctx, []byte(""), []byte("b"), 10)
+	c.Assert(err, IsNil)
+	c.Assert(scanned, DeepEquals, regions[:2])
+	scanned, _, err = s.client.ScanRegions(ctx, []byte(""), nil, 1)
+	c.Assert(err, IsNil)
+	c.Assert(scanned, DeepEquals, regions[:1])
+
+	prev, leader, err := s.client.GetPrevRegion(ctx, []byte("b1"))
+	c.Assert(err, IsNil)
+	c.Assert(prev, DeepEquals, regions[1])
+	c.Assert(leader, DeepEquals, regions[1].Peers[0])
+	prev, leader, err = s.client.GetPrevRegion(ctx, []byte("0"))
+	c.Assert(err, IsNil)
+	c.Assert(prev, IsNil)
+	c.Assert(leader, IsNil)
+}
+
+func (s *testRegionScanSuite) TestGetAllStores(c *C) {
+	cluster := s.srv.GetRaftCluster()
+	c.Assert(cluster, NotNil)
+	store2 := &metapb.Store{Id: 2, Address: "localhost:2", Version: server.MinSupportedVersion(server.Version2_0).String()}
+	_, err := s.srv.PutStore(context.Background(), &pdpb.PutStoreRequest{Header: newHeader(s.srv), Store: store2})
+	c.Assert(err, IsNil)
+
+	stores, err := s.client.GetAllStores(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(stores, HasLen, 2)
+
+	// The tombstone stores are excluded.
+	c.Assert(cluster.RemoveStore(store2.GetId()), IsNil)
+	c.Assert(cluster.BuryStore(store2.GetId(), true), IsNil)
+	stores, err = s.client.GetAllStores(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(stores, DeepEquals, []*metapb.Store{store})
+}
+
+func (s *testRegionScanSuite) TestScatterRegion(c *C) {
+	r := &metapb.Region{
+		Id:          300,
+		StartKey:    []byte("x"),
+		EndKey:      []byte("y"),
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		Peers:       []*metapb.Peer{{Id: 301, StoreId: store.GetId()}},
+	}
+	c.Assert(s.srv.GetRaftCluster().HandleRegionHeartbeat(core.NewRegionInfo(r, r.Peers[0])), IsNil)
+
+	ctx := context.Background()
+	op, err := s.client.GetOperator(ctx, r.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(op, IsNil)
+
+	// Scattering an unknown region fails.
+	c.Assert(s.client.ScatterRegion(ctx, 1000), NotNil)
+	// The region of a single-store cluster stays where it is, but the request
+	// succeeds.
+	c.Assert(s.client.ScatterRegion(ctx, r.GetId()), IsNil)
+}
+
+var _ = Suite(&testAuthSuite{})
+
+type testAuthSuite struct {
+	cfg *server.Config
+	srv *server.Server
+}
+
+const testRootToken = "root-token"
+
+func (s *testAuthSuite) SetUpSuite(c *C) {
+	var err error
+	s.cfg = server.NewTestSingleConfig()
+	s.cfg.Security.EnableAuth = true
+	s.cfg.Security.AuthRootToken = testRootToken
+	s.srv, err = server.CreateServer(s.cfg, api.NewHandler)
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.Run(context.Background()), IsNil)
+	mustWaitLeader(c, map[string]*server.Server{s.srv.GetAddr(): s.srv})
+	bootstrapServer(c, newHeader(s.srv), mustNewGrpcClient(c, s.srv.GetAddr()))
+}
+
+func (s *testAuthSuite) TearDownSuite(c *C) {
+	s.srv.Close()
+	os.RemoveAll(s.cfg.DataDir)
+}
+
+func (s *testAuthSuite) TestAuthToken(c *C) {
+	ctx := context.Background()
+	cli, err := NewClient(s.srv.GetEndpoints(), SecurityOption{}, WithAuthToken(testRootToken))
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	c.Assert(cli.UpdateServiceGCSafePoint(ctx, "br", time.Minute, 100), IsNil)
+	_, _, err = cli.ScanRegions(ctx, nil, nil, 10)
+	c.Assert(err, IsNil)
+	_, _, err = cli.GetPrevRegion(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+	_, err = cli.GetOperator(ctx, 1)
+	c.Assert(err, IsNil)
+
+	// The HTTP API rejects the client without the token.
+	cli, err = NewClient(s.srv.GetEndpoints(), SecurityOption{})
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	c.Assert(cli.UpdateServiceGCSafePoint(ctx, "br", time.Minute, 100), NotNil)
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/juju/errors"
+	"golang.org/x/net/context"
+)
+
+// httpAPIPrefix is the prefix of the HTTP API served on the client URLs of PD.
+const httpAPIPrefix = "/pd"
+
+// httpStatusError is returned when the HTTP API responds a status other than
+// 200.
+type httpStatusError struct {
+	api    string
+	status int
+	msg    string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("[pd] request %s failed: [%d] %s", e.api, e.status, e.msg)
+}
+
+// leaderHTTPPost posts the JSON encoded input to the HTTP API of the PD
+// leader. It is used for the requests which have no corresponding gRPC
+// methods.
+func (c *client) leaderHTTPPost(ctx context.Context, api string, input interface{}) error {
+	data, err := json.Marshal(input)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return c.leaderHTTPDo(ctx, http.MethodPost, api, bytes.NewBuffer(data))
+}
+
+func (c *client) leaderHTTPDo(ctx context.Context, method, api string, body io.Reader) error {
+	url := strings.TrimSuffix(c.GetLeaderAddr(), "/") + httpAPIPrefix + api
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.option.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.option.authToken)
+	}
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return errors.Trace(&httpStatusError{api: api, status: resp.StatusCode, msg: strings.TrimSpace(string(msg))})
+	}
+	return nil
+}
@@ -0,0 +1,694 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/coreos/go-semver/semver"
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	pd "github.com/pingcap/pd/pd-client"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/api"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/table"
+	"golang.org/x/net/context"
+)
+
+// Ping checks whether PD is available.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, http.MethodGet, "/pd/ping", nil)
+	return err
+}
+
+// GetHealth gets the health status of the members of PD.
+func (c *Client) GetHealth(ctx context.Context) ([]*api.Health, error) {
+	data, err := c.Do(ctx, http.MethodGet, "/pd/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	var healths []*api.Health
+	if err := json.Unmarshal(data, &healths); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return healths, nil
+}
+
+// GetCluster gets the meta of the cluster.
+func (c *Client) GetCluster(ctx context.Context) (*metapb.Cluster, error) {
+	var cluster metapb.Cluster
+	if err := c.get(ctx, "/cluster", &cluster); err != nil {
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+// SetLogLevel sets the log level of the PD server which serves the request.
+func (c *Client) SetLogLevel(ctx context.Context, level string) error {
+	return c.post(ctx, "/log", level)
+}
+
+// GetTSO allocates count consecutive timestamps, the first of them is
+// returned.
+func (c *Client) GetTSO(ctx context.Context, count uint32) (*api.TSOResponse, error) {
+	var ts api.TSOResponse
+	if err := c.get(ctx, fmt.Sprintf("/tso?count=%d", count), &ts); err != nil {
+		return nil, err
+	}
+	return &ts, nil
+}
+
+// GetGCSafePoints gets the gc safe point and the safe points of services.
+func (c *Client) GetGCSafePoints(ctx context.Context) (*api.GCSafePoints, error) {
+	var safePoints api.GCSafePoints
+	if err := c.get(ctx, "/gc/safepoint", &safePoints); err != nil {
+		return nil, err
+	}
+	return &safePoints, nil
+}
+
+// SetServiceGCSafePoint sets the safe point of a service, the gc safe point
+// will not exceed it in the ttl seconds.
+func (c *Client) SetServiceGCSafePoint(ctx context.Context, serviceID string, safePoint uint64, ttl int64) error {
+	return c.post(ctx, "/gc/safepoint/service/"+url.PathEscape(serviceID), map[string]interface{}{
+		"safe_point": safePoint,
+		"ttl":        ttl,
+	})
+}
+
+// DeleteServiceGCSafePoint deletes the safe point of a service.
+func (c *Client) DeleteServiceGCSafePoint(ctx context.Context, serviceID string) error {
+	return c.delete(ctx, "/gc/safepoint/service/"+url.PathEscape(serviceID))
+}
+
+// GetStores gets all the stores, including the tombstone ones.
+func (c *Client) GetStores(ctx context.Context) (*api.StoresInfo, error) {
+	var stores api.StoresInfo
+	if err := c.get(ctx, "/stores", &stores); err != nil {
+		return nil, err
+	}
+	return &stores, nil
+}
+
+// GetStore gets a store by id.
+func (c *Client) GetStore(ctx context.Context, storeID uint64) (*api.StoreInfo, error) {
+	var store api.StoreInfo
+	if err := c.get(ctx, fmt.Sprintf("/store/%d", storeID), &store); err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// DeleteStore makes a store offline.
+func (c *Client) DeleteStore(ctx context.Context, storeID uint64) error {
+	return c.delete(ctx, fmt.Sprintf("/store/%d", storeID))
+}
+
+// SetStoreState sets the state of a store.
+func (c *Client) SetStoreState(ctx context.Context, storeID uint64, state metapb.StoreState) error {
+	query := url.Values{"state": {state.String()}}
+	return c.post(ctx, fmt.Sprintf("/store/%d/state?%s", storeID, query.Encode()), nil)
+}
+
+// SetStoreLabels adds or updates the labels of a store.
+func (c *Client) SetStoreLabels(ctx context.Context, storeID uint64, labels map[string]string) error {
+	return c.post(ctx, fmt.Sprintf("/store/%d/label", storeID), labels)
+}
+
+// SetStoreWeight sets the leader weight and the region weight of a store.
+func (c *Client) SetStoreWeight(ctx context.Context, storeID uint64, leader, region float64) error {
+	return c.post(ctx, fmt.Sprintf("/store/%d/weight", storeID), map[string]interface{}{
+		"leader": leader,
+		"region": region,
+	})
+}
+
+// GetLabels gets all the distinct labels of the stores.
+func (c *Client) GetLabels(ctx context.Context) ([]*metapb.StoreLabel, error) {
+	var labels []*metapb.StoreLabel
+	if err := c.get(ctx, "/labels", &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// GetStoresByLabel gets the stores whose labels match the name and value
+// patterns.
+func (c *Client) GetStoresByLabel(ctx context.Context, name, value string) (*api.StoresInfo, error) {
+	query := url.Values{"name": {name}, "value": {value}}
+	var stores api.StoresInfo
+	if err := c.get(ctx, "/labels/stores?"+query.Encode(), &stores); err != nil {
+		return nil, err
+	}
+	return &stores, nil
+}
+
+// GetRegionByID gets a region by id.
+func (c *Client) GetRegionByID(ctx context.Context, regionID uint64) (*api.RegionInfo, error) {
+	var region *api.RegionInfo
+	if err := c.get(ctx, fmt.Sprintf("/region/id/%d", regionID), &region); err != nil {
+		return nil, err
+	}
+	return region, nil
+}
+
+// GetRegionByKey gets the region which contains the key. The key can't contain
+// '/' because the API routes the request by the unescaped path.
+func (c *Client) GetRegionByKey(ctx context.Context, key []byte) (*api.RegionInfo, error) {
+	var region *api.RegionInfo
+	if err := c.get(ctx, "/region/key/"+url.PathEscape(string(key)), &region); err != nil {
+		return nil, err
+	}
+	return region, nil
+}
+
+// GetRegions gets all the regions.
+func (c *Client) GetRegions(ctx context.Context) (*api.RegionsInfo, error) {
+	var regions api.RegionsInfo
+	if err := c.get(ctx, "/regions", &regions); err != nil {
+		return nil, err
+	}
+	return &regions, nil
+}
+
+// ListRegions lists the regions page by page, the params are the query
+// parameters of the API, such as start_key, end_key, limit, next, sort_by and
+// the filters like store_id.
+func (c *Client) ListRegions(ctx context.Context, params url.Values) (*api.RegionsInfo, error) {
+	var regions api.RegionsInfo
+	if err := c.get(ctx, "/regions?"+params.Encode(), &regions); err != nil {
+		return nil, err
+	}
+	return &regions, nil
+}
+
+// GetRegionHistory gets the recent changes of a region.
+func (c *Client) GetRegionHistory(ctx context.Context, regionID uint64) ([]*server.RegionChange, error) {
+	var history []*server.RegionChange
+	if err := c.get(ctx, fmt.Sprintf("/region/id/%d/history", regionID), &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetRegionSiblings gets the left and the right adjacent regions of a region,
+// they are nil if the region is the first or the last one.
+func (c *Client) GetRegionSiblings(ctx context.Context, regionID uint64) ([]*api.RegionInfo, error) {
+	var regions []*api.RegionInfo
+	if err := c.get(ctx, fmt.Sprintf("/regions/sibling/%d", regionID), &regions); err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+// GetCheckedRegions gets the regions in the abnormal state, the state is one
+// of miss-peer, extra-peer, pending-peer, down-peer, no-heartbeat and
+// incorrect-ns.
+func (c *Client) GetCheckedRegions(ctx context.Context, state string) ([]*core.RegionInfo, error) {
+	var regions []*core.RegionInfo
+	if err := c.get(ctx, "/regions/check/"+url.PathEscape(state), &regions); err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+// CheckRegionKeyRange checks whether the regions cover the whole key space
+// without overlaps.
+func (c *Client) CheckRegionKeyRange(ctx context.Context) (*api.KeyRangeCheck, error) {
+	var res api.KeyRangeCheck
+	if err := c.get(ctx, "/regions/check/key-range", &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetTopWriteFlowRegions gets at most limit regions with the highest write
+// flow.
+func (c *Client) GetTopWriteFlowRegions(ctx context.Context, limit int) (*api.RegionsInfo, error) {
+	var regions api.RegionsInfo
+	if err := c.get(ctx, fmt.Sprintf("/regions/writeflow?limit=%d", limit), &regions); err != nil {
+		return nil, err
+	}
+	return &regions, nil
+}
+
+// GetTopReadFlowRegions gets at most limit regions with the highest read
+// flow.
+func (c *Client) GetTopReadFlowRegions(ctx context.Context, limit int) (*api.RegionsInfo, error) {
+	var regions api.RegionsInfo
+	if err := c.get(ctx, fmt.Sprintf("/regions/readflow?limit=%d", limit), &regions); err != nil {
+		return nil, err
+	}
+	return &regions, nil
+}
+
+// GetHotWriteRegions gets the hot write regions grouped by store.
+func (c *Client) GetHotWriteRegions(ctx context.Context) (*core.StoreHotRegionInfos, error) {
+	var infos core.StoreHotRegionInfos
+	if err := c.get(ctx, "/hotspot/regions/write", &infos); err != nil {
+		return nil, err
+	}
+	return &infos, nil
+}
+
+// GetHotReadRegions gets the hot read regions grouped by store.
+func (c *Client) GetHotReadRegions(ctx context.Context) (*core.StoreHotRegionInfos, error) {
+	var infos core.StoreHotRegionInfos
+	if err := c.get(ctx, "/hotspot/regions/read", &infos); err != nil {
+		return nil, err
+	}
+	return &infos, nil
+}
+
+// GetHotStores gets the flow statistics of the hot stores.
+func (c *Client) GetHotStores(ctx context.Context) (*api.HotStoreStats, error) {
+	var stats api.HotStoreStats
+	if err := c.get(ctx, "/hotspot/stores", &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetOperators gets the descriptions of all the pending operators.
+func (c *Client) GetOperators(ctx context.Context) ([]string, error) {
+	var ops []string
+	if err := c.get(ctx, "/operators", &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// GetOperatorsByKind gets the descriptions of the pending operators of a
+// kind, the kind is one of admin, leader and region.
+func (c *Client) GetOperatorsByKind(ctx context.Context, kind string) ([]string, error) {
+	query := url.Values{"kind": {kind}}
+	var ops []string
+	if err := c.get(ctx, "/operators?"+query.Encode(), &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// GetOperator gets the description of the pending operator of a region.
+func (c *Client) GetOperator(ctx context.Context, regionID uint64) (string, error) {
+	var op string
+	if err := c.get(ctx, fmt.Sprintf("/operators/%d", regionID), &op); err != nil {
+		return "", err
+	}
+	return op, nil
+}
+
+// GetOperatorStatus gets the status of the pending operator of a region, it
+// returns nil if the region has no pending operator.
+func (c *Client) GetOperatorStatus(ctx context.Context, regionID uint64) (*pd.OperatorStatus, error) {
+	var status pd.OperatorStatus
+	err := c.get(ctx, fmt.Sprintf("/operators/%d/status", regionID), &status)
+	if IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// CreateOperator creates an operator, the input contains the name of the
+// operator and its arguments, such as
+// {"name": "transfer-leader", "region_id": 1, "to_store_id": 2}.
+func (c *Client) CreateOperator(ctx context.Context, input map[string]interface{}) error {
+	if _, ok := input["name"]; !ok {
+		return errors.New("[pd] missing operator name")
+	}
+	return c.post(ctx, "/operators", input)
+}
+
+// DeleteOperator cancels the pending operator of a region.
+func (c *Client) DeleteOperator(ctx context.Context, regionID uint64) error {
+	return c.delete(ctx, fmt.Sprintf("/operators/%d", regionID))
+}
+
+// SubmitOperatorBatch adds the operators all or nothing and returns the id of
+// the batch, each operator is described as the input of CreateOperator, with
+// an optional region_epoch which the region should still have.
+func (c *Client) SubmitOperatorBatch(ctx context.Context, ops []map[string]interface{}) (uint64, error) {
+	data, err := c.Do(ctx, http.MethodPost, apiPrefix+"/operators/batch", map[string]interface{}{"operators": ops})
+	if err != nil {
+		return 0, err
+	}
+	var res struct {
+		ID uint64 `json:"id"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return 0, errors.Trace(err)
+	}
+	return res.ID, nil
+}
+
+// GetOperatorBatch gets the progress of an operator batch.
+func (c *Client) GetOperatorBatch(ctx context.Context, batchID uint64) (*server.OperatorBatchStatus, error) {
+	var status server.OperatorBatchStatus
+	if err := c.get(ctx, fmt.Sprintf("/operators/batch/%d", batchID), &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// CancelOperatorBatch cancels the running operators of an operator batch.
+func (c *Client) CancelOperatorBatch(ctx context.Context, batchID uint64) error {
+	return c.delete(ctx, fmt.Sprintf("/operators/batch/%d", batchID))
+}
+
+// GetSchedulers gets the names of the running schedulers.
+func (c *Client) GetSchedulers(ctx context.Context) ([]string, error) {
+	var schedulers []string
+	if err := c.get(ctx, "/schedulers", &schedulers); err != nil {
+		return nil, err
+	}
+	return schedulers, nil
+}
+
+// AddScheduler adds a scheduler with the arguments, such as
+// AddScheduler(ctx, "evict-leader-scheduler", map[string]interface{}{"store_id": 1}).
+func (c *Client) AddScheduler(ctx context.Context, name string, args map[string]interface{}) error {
+	input := map[string]interface{}{"name": name}
+	for k, v := range args {
+		input[k] = v
+	}
+	return c.post(ctx, "/schedulers", input)
+}
+
+// RemoveScheduler removes a scheduler by name.
+func (c *Client) RemoveScheduler(ctx context.Context, name string) error {
+	return c.delete(ctx, "/schedulers/"+url.PathEscape(name))
+}
+
+// GetConfig gets the whole config of PD.
+func (c *Client) GetConfig(ctx context.Context) (*server.Config, error) {
+	var cfg server.Config
+	if err := c.get(ctx, "/config", &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// GetFileConfig gets the config loaded from the config file.
+func (c *Client) GetFileConfig(ctx context.Context) (*server.Config, error) {
+	var cfg server.Config
+	if err := c.get(ctx, "/config?source=file", &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// GetScheduleConfig gets the schedule config.
+func (c *Client) GetScheduleConfig(ctx context.Context) (*server.ScheduleConfig, error) {
+	var cfg server.ScheduleConfig
+	if err := c.get(ctx, "/config/schedule", &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// GetReplicationConfig gets the replication config.
+func (c *Client) GetReplicationConfig(ctx context.Context) (*server.ReplicationConfig, error) {
+	var cfg server.ReplicationConfig
+	if err := c.get(ctx, "/config/replicate", &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SetConfig updates the config items, the keys are the names of the items
+// in the schedule config or the replication config, such as
+// {"max-replicas": 5}.
+func (c *Client) SetConfig(ctx context.Context, items map[string]interface{}) error {
+	return c.post(ctx, "/config", items)
+}
+
+// GetNamespaceConfig gets the config of a namespace, the items not set are
+// filled with the global ones.
+func (c *Client) GetNamespaceConfig(ctx context.Context, name string) (*server.NamespaceConfig, error) {
+	var cfg server.NamespaceConfig
+	if err := c.get(ctx, "/config/namespace/"+url.PathEscape(name), &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SetNamespaceConfig updates the config items of a namespace, such as
+// {"max-replicas": 5}.
+func (c *Client) SetNamespaceConfig(ctx context.Context, name string, items map[string]interface{}) error {
+	return c.post(ctx, "/config/namespace/"+url.PathEscape(name), items)
+}
+
+// DeleteNamespaceConfig deletes the config of a namespace.
+func (c *Client) DeleteNamespaceConfig(ctx context.Context, name string) error {
+	return c.delete(ctx, "/config/namespace/"+url.PathEscape(name))
+}
+
+// GetLabelPropertyConfig gets the label property config.
+func (c *Client) GetLabelPropertyConfig(ctx context.Context) (server.LabelPropertyConfig, error) {
+	var cfg server.LabelPropertyConfig
+	if err := c.get(ctx, "/config/label-property", &cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// SetLabelProperty adds a label to a type of the label property config.
+func (c *Client) SetLabelProperty(ctx context.Context, typ, key, value string) error {
+	return c.postLabelProperty(ctx, "set", typ, key, value)
+}
+
+// DeleteLabelProperty removes a label from a type of the label property
+// config.
+func (c *Client) DeleteLabelProperty(ctx context.Context, typ, key, value string) error {
+	return c.postLabelProperty(ctx, "delete", typ, key, value)
+}
+
+func (c *Client) postLabelProperty(ctx context.Context, action, typ, key, value string) error {
+	return c.post(ctx, "/config/label-property", map[string]interface{}{
+		"type":        typ,
+		"action":      action,
+		"label-key":   key,
+		"label-value": value,
+	})
+}
+
+// GetConfigHistory gets at most limit latest revisions of the config with the
+// changes, a non-positive limit means all the revisions in the history.
+func (c *Client) GetConfigHistory(ctx context.Context, limit int) ([]*server.ConfigRevision, error) {
+	var history []*server.ConfigRevision
+	if err := c.get(ctx, fmt.Sprintf("/config/history?limit=%d", limit), &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetConfigRevision gets a revision of the config with the changes.
+func (c *Client) GetConfigRevision(ctx context.Context, revision uint64) (*server.ConfigRevision, error) {
+	var rev server.ConfigRevision
+	if err := c.get(ctx, fmt.Sprintf("/config/history/%d", revision), &rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// RollbackConfig restores the config to a revision in the history.
+func (c *Client) RollbackConfig(ctx context.Context, revision uint64) error {
+	return c.post(ctx, fmt.Sprintf("/config/rollback/%d", revision), nil)
+}
+
+// ValidateConfig checks the config items as the input of SetConfig without
+// applying them.
+func (c *Client) ValidateConfig(ctx context.Context, items map[string]interface{}) (*server.ConfigCheckResult, error) {
+	data, err := c.Do(ctx, http.MethodPost, apiPrefix+"/config/validate", items)
+	if err != nil {
+		return nil, err
+	}
+	var res server.ConfigCheckResult
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &res, nil
+}
+
+// ReloadConfig makes the PD server reload its config file like SIGHUP.
+func (c *Client) ReloadConfig(ctx context.Context) (*server.ConfigReloadResult, error) {
+	data, err := c.Do(ctx, http.MethodPost, apiPrefix+"/config/reload", nil)
+	if err != nil {
+		return nil, err
+	}
+	var res server.ConfigReloadResult
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &res, nil
+}
+
+// GetClusterVersion gets the cluster version.
+func (c *Client) GetClusterVersion(ctx context.Context) (*semver.Version, error) {
+	var version semver.Version
+	if err := c.get(ctx, "/config/cluster-version", &version); err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// SetClusterVersion sets the cluster version.
+func (c *Client) SetClusterVersion(ctx context.Context, version string) error {
+	return c.post(ctx, "/config/cluster-version", map[string]interface{}{"cluster-version": version})
+}
+
+// GetMembers gets the members of PD.
+func (c *Client) GetMembers(ctx context.Context) (*api.MembersInfo, error) {
+	var members api.MembersInfo
+	if err := c.get(ctx, "/members", &members); err != nil {
+		return nil, err
+	}
+	return &members, nil
+}
+
+// GetLeader gets the leader of PD.
+func (c *Client) GetLeader(ctx context.Context) (*pdpb.Member, error) {
+	var leader pdpb.Member
+	if err := c.get(ctx, "/leader", &leader); err != nil {
+		return nil, err
+	}
+	return &leader, nil
+}
+
+// DeleteMemberByName removes a member of PD by name.
+func (c *Client) DeleteMemberByName(ctx context.Context, name string) error {
+	return c.delete(ctx, "/members/name/"+url.PathEscape(name))
+}
+
+// DeleteMemberByID removes a member of PD by id.
+func (c *Client) DeleteMemberByID(ctx context.Context, memberID uint64) error {
+	return c.delete(ctx, fmt.Sprintf("/members/id/%d", memberID))
+}
+
+// SetMemberLeaderPriority sets the priority of a member to be elected as the
+// leader.
+func (c *Client) SetMemberLeaderPriority(ctx context.Context, name string, priority int) error {
+	return c.post(ctx, "/members/name/"+url.PathEscape(name), map[string]interface{}{"leader-priority": priority})
+}
+
+// ResignLeader makes the leader resign, another member will be elected.
+func (c *Client) ResignLeader(ctx context.Context) error {
+	_, err := c.Do(ctx, http.MethodPost, apiPrefix+"/leader/resign", nil)
+	return err
+}
+
+// TransferLeader transfers the leadership to a member by name.
+func (c *Client) TransferLeader(ctx context.Context, name string) error {
+	_, err := c.Do(ctx, http.MethodPost, apiPrefix+"/leader/transfer/"+url.PathEscape(name), nil)
+	return err
+}
+
+// GetAlerts gets the firing alerts found by the diagnose rules.
+func (c *Client) GetAlerts(ctx context.Context) ([]*server.AlertStatus, error) {
+	var alerts []*server.AlertStatus
+	if err := c.get(ctx, "/alerts", &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// GetAlertSilences gets the alert silences which are not expired.
+func (c *Client) GetAlertSilences(ctx context.Context) ([]*core.AlertSilence, error) {
+	var silences []*core.AlertSilence
+	if err := c.get(ctx, "/alerts/silences", &silences); err != nil {
+		return nil, err
+	}
+	return silences, nil
+}
+
+// AddAlertSilence adds an alert silence and returns its id.
+func (c *Client) AddAlertSilence(ctx context.Context, silence *core.AlertSilence) (uint64, error) {
+	data, err := c.Do(ctx, http.MethodPost, apiPrefix+"/alerts/silences", silence)
+	if err != nil {
+		return 0, err
+	}
+	var res core.AlertSilence
+	if err := json.Unmarshal(data, &res); err != nil {
+		return 0, errors.Trace(err)
+	}
+	return res.ID, nil
+}
+
+// DeleteAlertSilence deletes an alert silence.
+func (c *Client) DeleteAlertSilence(ctx context.Context, id uint64) error {
+	return c.delete(ctx, fmt.Sprintf("/alerts/silences/%d", id))
+}
+
+// GetTableNamespaces gets the namespaces of the table namespace classifier.
+func (c *Client) GetTableNamespaces(ctx context.Context) ([]*table.Namespace, error) {
+	var res struct {
+		Namespaces []*table.Namespace `json:"namespaces"`
+	}
+	if err := c.get(ctx, "/classifier/table/namespaces", &res); err != nil {
+		return nil, err
+	}
+	return res.Namespaces, nil
+}
+
+// CreateTableNamespace creates a namespace of the table namespace classifier.
+func (c *Client) CreateTableNamespace(ctx context.Context, name string) error {
+	return c.post(ctx, "/classifier/table/namespaces", map[string]string{"namespace": name})
+}
+
+// AddNamespaceTableID adds a table to a namespace.
+func (c *Client) AddNamespaceTableID(ctx context.Context, name string, tableID int64) error {
+	return c.postNamespaceTableID(ctx, "add", name, tableID)
+}
+
+// RemoveNamespaceTableID removes a table from a namespace.
+func (c *Client) RemoveNamespaceTableID(ctx context.Context, name string, tableID int64) error {
+	return c.postNamespaceTableID(ctx, "remove", name, tableID)
+}
+
+func (c *Client) postNamespaceTableID(ctx context.Context, action, name string, tableID int64) error {
+	return c.post(ctx, "/classifier/table/namespaces/table", map[string]string{
+		"namespace": name,
+		"table_id":  strconv.FormatInt(tableID, 10),
+		"action":    action,
+	})
+}
+
+// AddNamespaceMeta makes the meta regions belong to a namespace.
+func (c *Client) AddNamespaceMeta(ctx context.Context, name string) error {
+	return c.post(ctx, "/classifier/table/namespaces/meta", map[string]string{"namespace": name, "action": "add"})
+}
+
+// RemoveNamespaceMeta makes the meta regions not belong to a namespace.
+func (c *Client) RemoveNamespaceMeta(ctx context.Context, name string) error {
+	return c.post(ctx, "/classifier/table/namespaces/meta", map[string]string{"namespace": name, "action": "remove"})
+}
+
+// AddNamespaceStore adds a store to a namespace.
+func (c *Client) AddNamespaceStore(ctx context.Context, storeID uint64, name string) error {
+	return c.post(ctx, fmt.Sprintf("/classifier/table/store_ns/%d", storeID), map[string]string{"namespace": name, "action": "add"})
+}
+
+// RemoveNamespaceStore removes a store from a namespace.
+func (c *Client) RemoveNamespaceStore(ctx context.Context, storeID uint64, name string) error {
+	return c.post(ctx, fmt.Sprintf("/classifier/table/store_ns/%d", storeID), map[string]string{"namespace": name, "action": "remove"})
+}
@@ -0,0 +1,269 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package http provides a typed client of the PD HTTP API.
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	pd "github.com/pingcap/pd/pd-client"
+	"golang.org/x/net/context"
+)
+
+const (
+	apiPrefix = "/pd/api/v1"
+
+	// The errors responded by the PD which fails to redirect the request to
+	// the leader, the request should be retried after the leader is updated.
+	errRedirectFailed      = "redirect failed"
+	errRedirectToNotLeader = "redirect to not leader"
+)
+
+// StatusError is returned when the PD responds a status other than 200.
+type StatusError struct {
+	Code int
+	Msg  string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("[%d] %s", e.Code, e.Msg)
+}
+
+// IsNotFound returns true if the error is caused by a 404 response.
+func IsNotFound(err error) bool {
+	e, ok := errors.Cause(err).(*StatusError)
+	return ok && e.Code == http.StatusNotFound
+}
+
+// Client is a client of the PD HTTP API. The requests are sent to the PD
+// leader if it is known, otherwise to the first available address. It is
+// safe for concurrent use.
+type Client struct {
+	addrs      []string
+	httpClient *http.Client
+	token      string
+
+	mu     sync.RWMutex
+	leader string
+}
+
+// ClientOption configures the client.
+type ClientOption func(*Client)
+
+// WithToken sets the bearer token sent with the requests, it's required if
+// the auth of the PD HTTP API is enabled.
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// NewClient creates a client of the PD HTTP API served at the addresses. The
+// addresses without a scheme are prefixed with "http://", or "https://" if
+// TLS is enabled by the security option.
+func NewClient(addrs []string, security pd.SecurityOption, opts ...ClientOption) (*Client, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("[pd] no pd address")
+	}
+	certReloader, err := security.NewCertReloader()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	scheme := "http://"
+	transport := &http.Transport{}
+	if certReloader != nil {
+		scheme = "https://"
+		transport.DialTLS = certReloader.DialTLS
+	}
+	c := &Client{
+		httpClient: &http.Client{Transport: transport},
+	}
+	for _, addr := range addrs {
+		addr = strings.TrimSuffix(addr, "/")
+		if !strings.Contains(addr, "://") {
+			addr = scheme + addr
+		}
+		c.addrs = append(c.addrs, addr)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Do sends a request to the path of PD, the path includes the "/pd" prefix.
+// The input is encoded into JSON as the body if it is not nil. It returns the
+// raw body of the response. A GET request is retried on the other addresses if
+// it fails, the requests of the other methods are retried only if they are
+// surely not handled by PD.
+func (c *Client) Do(ctx context.Context, method, path string, input interface{}) ([]byte, error) {
+	var body []byte
+	if input != nil {
+		var err error
+		if body, err = json.Marshal(input); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	var lastErr error
+	for retried := false; ; retried = true {
+		for _, addr := range c.candidates() {
+			resp, err := c.doOnce(ctx, method, addr+path, body)
+			if err == nil {
+				return resp, nil
+			}
+			lastErr = err
+			if !retryable(method, err) {
+				return nil, err
+			}
+			if ctx.Err() != nil {
+				return nil, errors.Trace(ctx.Err())
+			}
+		}
+		// All the addresses fail, update the leader and try again once.
+		if retried || c.updateLeader(ctx) != nil {
+			return nil, lastErr
+		}
+	}
+}
+
+func (c *Client) doOnce(ctx context.Context, method, target string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// The error messages are usually encoded as JSON strings.
+		msg := strings.TrimSpace(string(data))
+		var s string
+		if json.Unmarshal(data, &s) == nil {
+			msg = s
+		}
+		return nil, errors.Trace(&StatusError{Code: resp.StatusCode, Msg: msg})
+	}
+	return data, nil
+}
+
+// candidates returns the addresses to try in order, the leader goes first.
+func (c *Client) candidates() []string {
+	c.mu.RLock()
+	leader := c.leader
+	c.mu.RUnlock()
+	if leader == "" {
+		return c.addrs
+	}
+	addrs := []string{leader}
+	for _, addr := range c.addrs {
+		if addr != leader {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// updateLeader asks the addresses for the current leader.
+func (c *Client) updateLeader(ctx context.Context) error {
+	var lastErr error
+	for _, addr := range c.addrs {
+		data, err := c.doOnce(ctx, http.MethodGet, addr+apiPrefix+"/leader", nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		var leader pdpb.Member
+		if err = json.Unmarshal(data, &leader); err != nil || len(leader.GetClientUrls()) == 0 {
+			lastErr = errors.Errorf("[pd] invalid leader %s", data)
+			continue
+		}
+		c.mu.Lock()
+		c.leader = strings.TrimSuffix(leader.GetClientUrls()[0], "/")
+		c.mu.Unlock()
+		return nil
+	}
+	return lastErr
+}
+
+// retryable returns true if the request can be sent again after the error.
+// The requests other than GET may be not idempotent, so they are retried only
+// if the connection can't be established, or a follower refuses to redirect
+// them again. A failed redirection is not retried as the leader may have
+// handled the request.
+func retryable(method string, err error) bool {
+	if e, ok := errors.Cause(err).(*StatusError); ok {
+		if e.Code != http.StatusInternalServerError {
+			return false
+		}
+		if method == http.MethodGet && strings.Contains(e.Msg, errRedirectFailed) {
+			return true
+		}
+		return strings.Contains(e.Msg, errRedirectToNotLeader)
+	}
+	return method == http.MethodGet || isDialError(err)
+}
+
+func isDialError(err error) bool {
+	if e, ok := errors.Cause(err).(*url.Error); ok {
+		err = e.Err
+	}
+	e, ok := errors.Cause(err).(*net.OpError)
+	return ok && e.Op == "dial"
+}
+
+func (c *Client) get(ctx context.Context, api string, output interface{}) error {
+	data, err := c.Do(ctx, http.MethodGet, apiPrefix+api, nil)
+	if err != nil {
+		return err
+	}
+	return errors.Trace(json.Unmarshal(data, output))
+}
+
+func (c *Client) post(ctx context.Context, api string, input interface{}) error {
+	_, err := c.Do(ctx, http.MethodPost, apiPrefix+api, input)
+	return err
+}
+
+func (c *Client) delete(ctx context.Context, api string) error {
+	_, err := c.Do(ctx, http.MethodDelete, apiPrefix+api, nil)
+	return err
+}
@@ -0,0 +1,292 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	pd "github.com/pingcap/pd/pd-client"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/api"
+	_ "github.com/pingcap/pd/server/schedulers"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+func TestHTTPClient(t *testing.T) {
+	server.EnableZap = true
+	TestingT(t)
+}
+
+var _ = Suite(&testHTTPClientSuite{})
+
+type testHTTPClientSuite struct {
+	cfg    *server.Config
+	srv    *server.Server
+	client *Client
+}
+
+var (
+	store = &metapb.Store{
+		Id:      1,
+		Address: "localhost",
+	}
+	region = &metapb.Region{
+		Id: 3,
+		RegionEpoch: &metapb.RegionEpoch{
+			ConfVer: 1,
+			Version: 1,
+		},
+		Peers: []*metapb.Peer{{Id: 2, StoreId: store.GetId()}},
+	}
+)
+
+func (s *testHTTPClientSuite) SetUpSuite(c *C) {
+	var err error
+	s.cfg = server.NewTestSingleConfig()
+	s.srv, err = server.CreateServer(s.cfg, api.NewHandler)
+	c.Assert(err, IsNil)
+	c.Assert(s.srv.Run(context.Background()), IsNil)
+	for i := 0; i < 500 && !s.srv.IsLeader(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(s.srv.IsLeader(), IsTrue)
+
+	conn, err := grpc.Dial(strings.TrimPrefix(s.srv.GetAddr(), "http://"), grpc.WithInsecure())
+	c.Assert(err, IsNil)
+	defer conn.Close()
+	resp, err := pdpb.NewPDClient(conn).Bootstrap(context.Background(), &pdpb.BootstrapRequest{
+		Header: &pdpb.RequestHeader{ClusterId: s.srv.ClusterID()},
+		Store:  store,
+		Region: region,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp.GetHeader().GetError(), IsNil)
+
+	// The unreachable address is skipped.
+	s.client, err = NewClient([]string{"127.0.0.1:1", s.srv.GetAddr()}, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+}
+
+func (s *testHTTPClientSuite) TearDownSuite(c *C) {
+	s.srv.Close()
+	os.RemoveAll(s.cfg.DataDir)
+}
+
+func (s *testHTTPClientSuite) TestStores(c *C) {
+	ctx := context.Background()
+	stores, err := s.client.GetStores(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(stores.Count, Equals, 1)
+	c.Assert(stores.Stores[0].Store.GetId(), Equals, store.GetId())
+
+	c.Assert(s.client.SetStoreLabels(ctx, store.GetId(), map[string]string{"zone": "z1"}), IsNil)
+	c.Assert(s.client.SetStoreWeight(ctx, store.GetId(), 2, 3), IsNil)
+	info, err := s.client.GetStore(ctx, store.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(info.Store.GetLabels(), DeepEquals, []*metapb.StoreLabel{{Key: "zone", Value: "z1"}})
+	c.Assert(info.Status.LeaderWeight, Equals, float64(2))
+	c.Assert(info.Status.RegionWeight, Equals, float64(3))
+
+	labels, err := s.client.GetLabels(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(labels, DeepEquals, []*metapb.StoreLabel{{Key: "zone", Value: "z1"}})
+	stores, err = s.client.GetStoresByLabel(ctx, "zone", "z1")
+	c.Assert(err, IsNil)
+	c.Assert(stores.Count, Equals, 1)
+
+	c.Assert(s.client.DeleteStore(ctx, 100), NotNil)
+}
+
+func (s *testHTTPClientSuite) TestRegions(c *C) {
+	ctx := context.Background()
+	info, err := s.client.GetRegionByID(ctx, region.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(info.ID, Equals, region.GetId())
+	info, err = s.client.GetRegionByKey(ctx, []byte("a b"))
+	c.Assert(err, IsNil)
+	c.Assert(info.ID, Equals, region.GetId())
+	regions, err := s.client.GetRegions(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(regions.Count, Equals, 1)
+	regions, err = s.client.ListRegions(ctx, url.Values{"limit": {"1"}})
+	c.Assert(err, IsNil)
+	c.Assert(regions.Count, Equals, 1)
+	siblings, err := s.client.GetRegionSiblings(ctx, region.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(siblings, HasLen, 2)
+	keyRangeCheck, err := s.client.CheckRegionKeyRange(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(keyRangeCheck.Holes, HasLen, 0)
+	_, err = s.client.GetCheckedRegions(ctx, "pending-peer")
+	c.Assert(err, IsNil)
+	_, err = s.client.GetTopWriteFlowRegions(ctx, 10)
+	c.Assert(err, IsNil)
+	_, err = s.client.GetHotWriteRegions(ctx)
+	c.Assert(err, IsNil)
+	_, err = s.client.GetHotStores(ctx)
+	c.Assert(err, IsNil)
+
+	status, err := s.client.GetOperatorStatus(ctx, region.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(status, IsNil)
+	c.Assert(s.client.CreateOperator(ctx, map[string]interface{}{"region_id": 1}), NotNil)
+
+	batchID, err := s.client.SubmitOperatorBatch(ctx, []map[string]interface{}{
+		{"name": "split-region", "region_id": region.GetId(), "policy": "approximate"},
+	})
+	c.Assert(err, IsNil)
+	batch, err := s.client.GetOperatorBatch(ctx, batchID)
+	c.Assert(err, IsNil)
+	c.Assert(batch.Total, Equals, 1)
+	c.Assert(s.client.CancelOperatorBatch(ctx, batchID), IsNil)
+	_, err = s.client.GetOperatorBatch(ctx, batchID+1)
+	c.Assert(IsNotFound(err), IsTrue)
+}
+
+func (s *testHTTPClientSuite) TestSchedulersAndConfig(c *C) {
+	ctx := context.Background()
+	c.Assert(s.client.AddScheduler(ctx, "evict-leader-scheduler", map[string]interface{}{"store_id": store.GetId()}), IsNil)
+	schedulers, err := s.client.GetSchedulers(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(hasScheduler(schedulers, "evict-leader-scheduler-1"), IsTrue)
+	c.Assert(s.client.RemoveScheduler(ctx, "evict-leader-scheduler-1"), IsNil)
+	schedulers, err = s.client.GetSchedulers(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(hasScheduler(schedulers, "evict-leader-scheduler-1"), IsFalse)
+
+	c.Assert(s.client.SetConfig(ctx, map[string]interface{}{"max-replicas": 5}), IsNil)
+	replication, err := s.client.GetReplicationConfig(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(replication.MaxReplicas, Equals, uint64(5))
+	cfg, err := s.client.GetConfig(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(cfg.Replication.MaxReplicas, Equals, uint64(5))
+	history, err := s.client.GetConfigHistory(ctx, 1)
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 1)
+	c.Assert(history[0].Revision, Equals, cfg.ConfigRevision)
+
+	res, err := s.client.ValidateConfig(ctx, map[string]interface{}{"high-space-ratio": 0.9})
+	c.Assert(err, IsNil)
+	c.Assert(res.Errors, HasLen, 1)
+	c.Assert(res.Errors[0].Field, Equals, "schedule.low-space-ratio")
+}
+
+func (s *testHTTPClientSuite) TestMembers(c *C) {
+	ctx := context.Background()
+	members, err := s.client.GetMembers(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(members.Members, HasLen, 1)
+	leader, err := s.client.GetLeader(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(leader.GetName(), Equals, s.cfg.Name)
+	c.Assert(s.client.SetMemberLeaderPriority(ctx, s.cfg.Name, 10), IsNil)
+
+	_, err = s.client.Do(ctx, "GET", apiPrefix+"/not-exist", nil)
+	c.Assert(IsNotFound(err), IsTrue)
+}
+
+func (s *testHTTPClientSuite) TestClusterAndGC(c *C) {
+	ctx := context.Background()
+	c.Assert(s.client.Ping(ctx), IsNil)
+	healths, err := s.client.GetHealth(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(healths, HasLen, 1)
+	c.Assert(healths[0].Health, IsTrue)
+	cluster, err := s.client.GetCluster(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(cluster.GetId(), Equals, s.srv.ClusterID())
+	ts, err := s.client.GetTSO(ctx, 3)
+	c.Assert(err, IsNil)
+	c.Assert(ts.Count, Equals, uint32(3))
+
+	c.Assert(s.client.SetServiceGCSafePoint(ctx, "svc", 10, 100), IsNil)
+	safePoints, err := s.client.GetGCSafePoints(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(safePoints.ServiceGCSafePoints, HasLen, 1)
+	c.Assert(safePoints.ServiceGCSafePoints[0].ServiceID, Equals, "svc")
+	c.Assert(s.client.DeleteServiceGCSafePoint(ctx, "svc"), IsNil)
+	safePoints, err = s.client.GetGCSafePoints(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(safePoints.ServiceGCSafePoints, HasLen, 0)
+
+	c.Assert(s.client.SetLabelProperty(ctx, "reject-leader", "zone", "z2"), IsNil)
+	labelProperty, err := s.client.GetLabelPropertyConfig(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(labelProperty["reject-leader"], HasLen, 1)
+	c.Assert(s.client.DeleteLabelProperty(ctx, "reject-leader", "zone", "z2"), IsNil)
+	_, err = s.client.GetClusterVersion(ctx)
+	c.Assert(err, IsNil)
+}
+
+func hasScheduler(schedulers []string, name string) bool {
+	for _, s := range schedulers {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+var _ = Suite(&testRetrySuite{})
+
+type testRetrySuite struct{}
+
+func (s *testRetrySuite) TestRetry(c *C) {
+	var handled int32
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&handled, 1)
+		w.Write([]byte(`"ok"`))
+	}))
+	defer ok.Close()
+	// The connection is closed after the request is read.
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		c.Assert(err, IsNil)
+		conn.Close()
+	}))
+	defer broken.Close()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	closed := "http://" + l.Addr().String()
+	l.Close()
+
+	ctx := context.Background()
+	client, err := NewClient([]string{broken.URL, ok.URL}, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	_, err = client.Do(ctx, http.MethodGet, apiPrefix+"/schedulers", nil)
+	c.Assert(err, IsNil)
+	c.Assert(atomic.LoadInt32(&handled), Equals, int32(1))
+	_, err = client.Do(ctx, http.MethodPost, apiPrefix+"/schedulers", nil)
+	c.Assert(err, NotNil)
+	c.Assert(atomic.LoadInt32(&handled), Equals, int32(1))
+
+	client, err = NewClient([]string{closed, ok.URL}, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	_, err = client.Do(ctx, http.MethodPost, apiPrefix+"/schedulers", nil)
+	c.Assert(err, IsNil)
+	c.Assert(atomic.LoadInt32(&handled), Equals, int32(2))
+}
@@ -42,10 +42,39 @@ var (
 			Help:      "Bucketed histogram of processing time (s) of handled requests.",
 			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 13),
 		}, []string{"type"})
+
+	tsoBatchSize = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "tso_batch_size",
+			Help:      "Bucketed histogram of the batch size of handled tso requests.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 13),
+		})
+
+	scanRegionsCount = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "scan_regions_count",
+			Help:      "Bucketed histogram of the number of regions returned by scan regions requests.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 13),
+		})
+
+	regionCacheCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd_client",
+			Subsystem: "region_cache",
+			Name:      "operations_total",
+			Help:      "Counter of the region cache operations.",
+		}, []string{"type"})
 )
 
 func init() {
 	prometheus.MustRegister(cmdDuration)
 	prometheus.MustRegister(cmdFailedDuration)
 	prometheus.MustRegister(requestDuration)
+	prometheus.MustRegister(tsoBatchSize)
+	prometheus.MustRegister(scanRegionsCount)
+	prometheus.MustRegister(regionCacheCounter)
 }
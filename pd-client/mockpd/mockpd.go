@@ -0,0 +1,678 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mockpd provides an in-memory implementation of pd.Client, it is
+// used by the unit tests which need a PD but not a real PD server.
+package mockpd
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	pd "github.com/pingcap/pd/pd-client"
+	"golang.org/x/net/context"
+)
+
+// The names of the methods which can be injected with faults.
+const (
+	MethodGetTS                    = "GetTS"
+	MethodGetMembers               = "GetMembers"
+	MethodGetRegion                = "GetRegion"
+	MethodGetRegionByID            = "GetRegionByID"
+	MethodGetPrevRegion            = "GetPrevRegion"
+	MethodScanRegions              = "ScanRegions"
+	MethodGetStore                 = "GetStore"
+	MethodGetAllStores             = "GetAllStores"
+	MethodScatterRegion            = "ScatterRegion"
+	MethodGetOperator              = "GetOperator"
+	MethodUpdateGCSafePoint        = "UpdateGCSafePoint"
+	MethodUpdateServiceGCSafePoint = "UpdateServiceGCSafePoint"
+)
+
+var (
+	// ErrClosed is returned when the client is used after it is closed.
+	ErrClosed = errors.New("mockpd: client is closed")
+	// ErrRegionNotFound is returned when the region to operate does not exist.
+	ErrRegionNotFound = errors.New("mockpd: region not found")
+	// ErrStoreNotFound is returned when the store does not exist.
+	ErrStoreNotFound = errors.New("mockpd: store not found")
+)
+
+type region struct {
+	meta   *metapb.Region
+	leader *metapb.Peer
+}
+
+type fault struct {
+	err     error
+	latency time.Duration
+}
+
+type serviceSafePoint struct {
+	safePoint uint64
+	expiredAt time.Time
+}
+
+// Client is an in-memory PD which implements pd.Client. The regions and
+// stores are set up by its helper methods, and the faults can be injected
+// into the methods of pd.Client to simulate errors and slow responses.
+type Client struct {
+	sync.RWMutex
+	clusterID uint64
+	closed    bool
+
+	physical int64
+	logical  int64
+	id       uint64
+
+	stores map[uint64]*metapb.Store
+	// regions are sorted by start key and never overlap with each other.
+	regions   []*region
+	operators map[uint64]*pd.OperatorStatus
+
+	gcSafePoint       uint64
+	serviceSafePoints map[string]serviceSafePoint
+
+	faults map[string]fault
+
+	members          []*pdpb.Member
+	leader           *pdpb.Member
+	leaderCallbacks  []pd.LeaderChangeFunc
+	membersCallbacks []pd.MembersChangeFunc
+}
+
+var _ pd.Client = &Client{}
+
+// NewClient creates an in-memory PD with no region or store. It has a
+// single member which is the leader.
+func NewClient(clusterID uint64) *Client {
+	leader := &pdpb.Member{Name: "mockpd", MemberId: 1, ClientUrls: []string{"http://mockpd"}}
+	return &Client{
+		clusterID:         clusterID,
+		stores:            make(map[uint64]*metapb.Store),
+		operators:         make(map[uint64]*pd.OperatorStatus),
+		serviceSafePoints: make(map[string]serviceSafePoint),
+		faults:            make(map[string]fault),
+		members:           []*pdpb.Member{leader},
+		leader:            leader,
+	}
+}
+
+// SetMembers sets the members and the leader of the PD cluster. The
+// registered callbacks are called synchronously if the leader or the members
+// change.
+func (c *Client) SetMembers(members []*pdpb.Member, leader *pdpb.Member) {
+	c.Lock()
+	oldLeader, newLeader := memberURL(c.leader), memberURL(leader)
+	membersChanged := !sameMembers(c.members, members)
+	c.members, c.leader = members, leader
+	leaderCallbacks, membersCallbacks := c.leaderCallbacks, c.membersCallbacks
+	c.Unlock()
+
+	if membersChanged {
+		for _, f := range membersCallbacks {
+			f(members)
+		}
+	}
+	if newLeader != oldLeader {
+		for _, f := range leaderCallbacks {
+			f(newLeader, oldLeader)
+		}
+	}
+}
+
+// Bootstrap puts the stores and a region covering the whole key space whose
+// peers are on the stores, the existing regions are removed. The first peer
+// is the leader. It returns the region.
+func (c *Client) Bootstrap(stores ...*metapb.Store) *metapb.Region {
+	c.Lock()
+	defer c.Unlock()
+	meta := &metapb.Region{
+		Id:          c.allocIDLocked(),
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+	}
+	for _, store := range stores {
+		c.stores[store.GetId()] = proto.Clone(store).(*metapb.Store)
+		meta.Peers = append(meta.Peers, &metapb.Peer{Id: c.allocIDLocked(), StoreId: store.GetId()})
+	}
+	var leader *metapb.Peer
+	if len(meta.Peers) > 0 {
+		leader = meta.Peers[0]
+	}
+	c.regions = nil
+	c.putRegionLocked(meta, leader)
+	return proto.Clone(meta).(*metapb.Region)
+}
+
+// AllocID allocates a unique id, it never returns the ids of the regions or
+// peers put into the client by the caller if they are less than 1000.
+func (c *Client) AllocID() uint64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.allocIDLocked()
+}
+
+func (c *Client) allocIDLocked() uint64 {
+	if c.id < 1000 {
+		c.id = 1000
+	}
+	c.id++
+	return c.id
+}
+
+// PutStore adds or updates a store.
+func (c *Client) PutStore(store *metapb.Store) {
+	c.Lock()
+	defer c.Unlock()
+	c.stores[store.GetId()] = proto.Clone(store).(*metapb.Store)
+}
+
+// SetStoreState sets the state of a store.
+func (c *Client) SetStoreState(storeID uint64, state metapb.StoreState) error {
+	c.Lock()
+	defer c.Unlock()
+	store, ok := c.stores[storeID]
+	if !ok {
+		return errors.Trace(ErrStoreNotFound)
+	}
+	store.State = state
+	return nil
+}
+
+// PutRegion adds or updates a region, the regions which overlap with it are
+// removed. The leader can be nil.
+func (c *Client) PutRegion(meta *metapb.Region, leader *metapb.Peer) {
+	c.Lock()
+	defer c.Unlock()
+	c.putRegionLocked(proto.Clone(meta).(*metapb.Region), leader)
+}
+
+func (c *Client) putRegionLocked(meta *metapb.Region, leader *metapb.Peer) {
+	if leader != nil {
+		leader = proto.Clone(leader).(*metapb.Peer)
+	}
+	regions := c.regions[:0:0]
+	for _, r := range c.regions {
+		if r.meta.GetId() != meta.GetId() && !overlaps(r.meta, meta) {
+			regions = append(regions, r)
+		}
+	}
+	regions = append(regions, &region{meta: meta, leader: leader})
+	sort.Slice(regions, func(i, j int) bool {
+		return bytes.Compare(regions[i].meta.GetStartKey(), regions[j].meta.GetStartKey()) < 0
+	})
+	c.regions = regions
+}
+
+// RemoveRegion removes a region, which leaves a hole in the key space.
+func (c *Client) RemoveRegion(regionID uint64) {
+	c.Lock()
+	defer c.Unlock()
+	for i, r := range c.regions {
+		if r.meta.GetId() == regionID {
+			c.regions = append(c.regions[:i:i], c.regions[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetLeader transfers the leader of a region to its peer on the store.
+func (c *Client) SetLeader(regionID, storeID uint64) error {
+	c.Lock()
+	defer c.Unlock()
+	r := c.getRegionLocked(regionID)
+	if r == nil {
+		return errors.Trace(ErrRegionNotFound)
+	}
+	for _, peer := range r.meta.GetPeers() {
+		if peer.GetStoreId() == storeID {
+			r.leader = peer
+			return nil
+		}
+	}
+	return errors.Errorf("mockpd: region %d has no peer on store %d", regionID, storeID)
+}
+
+// Split splits a region at the key, the left part gets a new region id and
+// new peer ids, and the versions of both regions are increased. It returns
+// the new left region.
+func (c *Client) Split(regionID uint64, splitKey []byte) (*metapb.Region, error) {
+	c.Lock()
+	defer c.Unlock()
+	r := c.getRegionLocked(regionID)
+	if r == nil {
+		return nil, errors.Trace(ErrRegionNotFound)
+	}
+	if bytes.Compare(splitKey, r.meta.GetStartKey()) <= 0 ||
+		(len(r.meta.GetEndKey()) > 0 && bytes.Compare(splitKey, r.meta.GetEndKey()) >= 0) {
+		return nil, errors.Errorf("mockpd: split key %q is not in region %d", splitKey, regionID)
+	}
+
+	right := proto.Clone(r.meta).(*metapb.Region)
+	right.StartKey = splitKey
+	right.RegionEpoch.Version++
+	left := proto.Clone(right).(*metapb.Region)
+	left.Id = c.allocIDLocked()
+	left.StartKey, left.EndKey = r.meta.GetStartKey(), splitKey
+	var leftLeader, rightLeader *metapb.Peer
+	for i, peer := range left.GetPeers() {
+		peer.Id = c.allocIDLocked()
+		if r.leader != nil && peer.GetStoreId() == r.leader.GetStoreId() {
+			leftLeader, rightLeader = peer, right.GetPeers()[i]
+		}
+	}
+	r.meta, r.leader = right, rightLeader
+	c.putRegionLocked(left, leftLeader)
+	return proto.Clone(left).(*metapb.Region), nil
+}
+
+// Merge merges the source region into the adjacent target region, the
+// source region is removed and the version of the target region is
+// increased.
+func (c *Client) Merge(sourceID, targetID uint64) error {
+	c.Lock()
+	defer c.Unlock()
+	source, target := c.getRegionLocked(sourceID), c.getRegionLocked(targetID)
+	if source == nil || target == nil {
+		return errors.Trace(ErrRegionNotFound)
+	}
+	meta := proto.Clone(target.meta).(*metapb.Region)
+	switch {
+	case bytes.Equal(source.meta.GetEndKey(), target.meta.GetStartKey()) && len(target.meta.GetStartKey()) > 0:
+		meta.StartKey = source.meta.GetStartKey()
+	case bytes.Equal(target.meta.GetEndKey(), source.meta.GetStartKey()) && len(source.meta.GetStartKey()) > 0:
+		meta.EndKey = source.meta.GetEndKey()
+	default:
+		return errors.Errorf("mockpd: region %d and %d are not adjacent", sourceID, targetID)
+	}
+	meta.RegionEpoch.Version = maxUint64(source.meta.GetRegionEpoch().GetVersion(), target.meta.GetRegionEpoch().GetVersion()) + 1
+	c.putRegionLocked(meta, target.leader)
+	return nil
+}
+
+// SetOperator sets the pending operator of a region, a nil status removes it.
+func (c *Client) SetOperator(regionID uint64, status *pd.OperatorStatus) {
+	c.Lock()
+	defer c.Unlock()
+	if status == nil {
+		delete(c.operators, regionID)
+		return
+	}
+	c.operators[regionID] = status
+}
+
+// InjectError makes the method return err, a nil err removes the injected
+// error.
+func (c *Client) InjectError(method string, err error) {
+	c.Lock()
+	defer c.Unlock()
+	f := c.faults[method]
+	f.err = err
+	c.faults[method] = f
+}
+
+// InjectLatency delays the responses of the method, the method returns the
+// context error if the context is done before the latency elapses.
+func (c *Client) InjectLatency(method string, latency time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	f := c.faults[method]
+	f.latency = latency
+	c.faults[method] = f
+}
+
+func (c *Client) enter(ctx context.Context, method string) error {
+	c.RLock()
+	closed, f := c.closed, c.faults[method]
+	c.RUnlock()
+	if closed {
+		return errors.Trace(ErrClosed)
+	}
+	if f.latency > 0 {
+		select {
+		case <-time.After(f.latency):
+		case <-ctx.Done():
+			return errors.Trace(ctx.Err())
+		}
+	}
+	return errors.Trace(f.err)
+}
+
+// GetClusterID implements pd.Client.
+func (c *Client) GetClusterID(ctx context.Context) uint64 {
+	return c.clusterID
+}
+
+// GetTS implements pd.Client. The timestamps are strictly increasing.
+func (c *Client) GetTS(ctx context.Context) (int64, int64, error) {
+	if err := c.enter(ctx, MethodGetTS); err != nil {
+		return 0, 0, err
+	}
+	c.Lock()
+	defer c.Unlock()
+	physical := time.Now().UnixNano() / int64(time.Millisecond)
+	if physical > c.physical {
+		c.physical, c.logical = physical, 0
+	} else {
+		c.logical++
+	}
+	return c.physical, c.logical, nil
+}
+
+// GetMembers implements pd.Client.
+func (c *Client) GetMembers(ctx context.Context) ([]*pdpb.Member, *pdpb.Member, error) {
+	if err := c.enter(ctx, MethodGetMembers); err != nil {
+		return nil, nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	members := make([]*pdpb.Member, 0, len(c.members))
+	for _, m := range c.members {
+		members = append(members, proto.Clone(m).(*pdpb.Member))
+	}
+	var leader *pdpb.Member
+	if c.leader != nil {
+		leader = proto.Clone(c.leader).(*pdpb.Member)
+	}
+	return members, leader, nil
+}
+
+// OnLeaderChange implements pd.Client.
+func (c *Client) OnLeaderChange(f pd.LeaderChangeFunc) {
+	c.Lock()
+	defer c.Unlock()
+	c.leaderCallbacks = append(c.leaderCallbacks, f)
+}
+
+// OnMembersChange implements pd.Client.
+func (c *Client) OnMembersChange(f pd.MembersChangeFunc) {
+	c.Lock()
+	defer c.Unlock()
+	c.membersCallbacks = append(c.membersCallbacks, f)
+}
+
+type tsFuture struct {
+	physical, logical int64
+	err               error
+}
+
+func (f *tsFuture) Wait() (int64, int64, error) {
+	return f.physical, f.logical, f.err
+}
+
+// GetTSAsync implements pd.Client.
+func (c *Client) GetTSAsync(ctx context.Context) pd.TSFuture {
+	physical, logical, err := c.GetTS(ctx)
+	return &tsFuture{physical: physical, logical: logical, err: err}
+}
+
+// GetRegion implements pd.Client.
+func (c *Client) GetRegion(ctx context.Context, key []byte) (*metapb.Region, *metapb.Peer, error) {
+	if err := c.enter(ctx, MethodGetRegion); err != nil {
+		return nil, nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	i := c.searchLocked(key)
+	if i < 0 {
+		return nil, nil, nil
+	}
+	return c.regions[i].clone()
+}
+
+// GetRegionByID implements pd.Client.
+func (c *Client) GetRegionByID(ctx context.Context, regionID uint64) (*metapb.Region, *metapb.Peer, error) {
+	if err := c.enter(ctx, MethodGetRegionByID); err != nil {
+		return nil, nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	r := c.getRegionLocked(regionID)
+	if r == nil {
+		return nil, nil, nil
+	}
+	return r.clone()
+}
+
+// GetPrevRegion implements pd.Client.
+func (c *Client) GetPrevRegion(ctx context.Context, key []byte) (*metapb.Region, *metapb.Peer, error) {
+	if err := c.enter(ctx, MethodGetPrevRegion); err != nil {
+		return nil, nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	i := c.searchLocked(key)
+	if i <= 0 || !bytes.Equal(c.regions[i-1].meta.GetEndKey(), c.regions[i].meta.GetStartKey()) {
+		return nil, nil, nil
+	}
+	return c.regions[i-1].clone()
+}
+
+// ScanRegions implements pd.Client.
+func (c *Client) ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*metapb.Region, []*metapb.Peer, error) {
+	if err := c.enter(ctx, MethodScanRegions); err != nil {
+		return nil, nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	var (
+		regions []*metapb.Region
+		leaders []*metapb.Peer
+	)
+	for _, r := range c.regions {
+		if len(r.meta.GetEndKey()) > 0 && bytes.Compare(r.meta.GetEndKey(), key) <= 0 {
+			continue
+		}
+		if len(endKey) > 0 && bytes.Compare(r.meta.GetStartKey(), endKey) >= 0 {
+			break
+		}
+		meta, leader, _ := r.clone()
+		if leader == nil {
+			leader = &metapb.Peer{}
+		}
+		regions, leaders = append(regions, meta), append(leaders, leader)
+		if limit > 0 && len(regions) >= limit {
+			break
+		}
+	}
+	return regions, leaders, nil
+}
+
+// GetStore implements pd.Client.
+func (c *Client) GetStore(ctx context.Context, storeID uint64) (*metapb.Store, error) {
+	if err := c.enter(ctx, MethodGetStore); err != nil {
+		return nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	store, ok := c.stores[storeID]
+	if !ok {
+		return nil, errors.Trace(ErrStoreNotFound)
+	}
+	return proto.Clone(store).(*metapb.Store), nil
+}
+
+// GetAllStores implements pd.Client.
+func (c *Client) GetAllStores(ctx context.Context) ([]*metapb.Store, error) {
+	if err := c.enter(ctx, MethodGetAllStores); err != nil {
+		return nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	stores := make([]*metapb.Store, 0, len(c.stores))
+	for _, store := range c.stores {
+		if store.GetState() != metapb.StoreState_Tombstone {
+			stores = append(stores, proto.Clone(store).(*metapb.Store))
+		}
+	}
+	sort.Slice(stores, func(i, j int) bool { return stores[i].GetId() < stores[j].GetId() })
+	return stores, nil
+}
+
+// ScatterRegion implements pd.Client. It sets a running scatter operator on
+// the region.
+func (c *Client) ScatterRegion(ctx context.Context, regionID uint64) error {
+	if err := c.enter(ctx, MethodScatterRegion); err != nil {
+		return err
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.getRegionLocked(regionID) == nil {
+		return errors.Trace(ErrRegionNotFound)
+	}
+	c.operators[regionID] = &pd.OperatorStatus{
+		RegionID: regionID,
+		Desc:     "scatter-region",
+		Kind:     "region",
+		Status:   "running",
+	}
+	return nil
+}
+
+// GetOperator implements pd.Client.
+func (c *Client) GetOperator(ctx context.Context, regionID uint64) (*pd.OperatorStatus, error) {
+	if err := c.enter(ctx, MethodGetOperator); err != nil {
+		return nil, err
+	}
+	c.RLock()
+	defer c.RUnlock()
+	status, ok := c.operators[regionID]
+	if !ok {
+		return nil, nil
+	}
+	s := *status
+	return &s, nil
+}
+
+// UpdateGCSafePoint implements pd.Client. Like PD, the safe point is limited
+// by the service safe points and never goes back.
+func (c *Client) UpdateGCSafePoint(ctx context.Context, safePoint uint64) (uint64, error) {
+	if err := c.enter(ctx, MethodUpdateGCSafePoint); err != nil {
+		return 0, err
+	}
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	for serviceID, ssp := range c.serviceSafePoints {
+		if now.After(ssp.expiredAt) {
+			delete(c.serviceSafePoints, serviceID)
+			continue
+		}
+		if ssp.safePoint < safePoint {
+			safePoint = ssp.safePoint
+		}
+	}
+	if safePoint > c.gcSafePoint {
+		c.gcSafePoint = safePoint
+	}
+	return c.gcSafePoint, nil
+}
+
+// UpdateServiceGCSafePoint implements pd.Client.
+func (c *Client) UpdateServiceGCSafePoint(ctx context.Context, serviceID string, ttl time.Duration, safePoint uint64) error {
+	if err := c.enter(ctx, MethodUpdateServiceGCSafePoint); err != nil {
+		return err
+	}
+	c.Lock()
+	defer c.Unlock()
+	if ttl <= 0 {
+		delete(c.serviceSafePoints, serviceID)
+		return nil
+	}
+	if safePoint < c.gcSafePoint {
+		return errors.Errorf("mockpd: service %s safe point %d is less than gc safe point %d", serviceID, safePoint, c.gcSafePoint)
+	}
+	c.serviceSafePoints[serviceID] = serviceSafePoint{safePoint: safePoint, expiredAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Close implements pd.Client.
+func (c *Client) Close() {
+	c.Lock()
+	defer c.Unlock()
+	c.closed = true
+}
+
+// searchLocked returns the index of the region which contains the key, or -1
+// if there is no such region.
+func (c *Client) searchLocked(key []byte) int {
+	i := sort.Search(len(c.regions), func(i int) bool {
+		return bytes.Compare(c.regions[i].meta.GetStartKey(), key) > 0
+	}) - 1
+	if i < 0 {
+		return -1
+	}
+	endKey := c.regions[i].meta.GetEndKey()
+	if len(endKey) > 0 && bytes.Compare(key, endKey) >= 0 {
+		return -1
+	}
+	return i
+}
+
+func (c *Client) getRegionLocked(regionID uint64) *region {
+	for _, r := range c.regions {
+		if r.meta.GetId() == regionID {
+			return r
+		}
+	}
+	return nil
+}
+
+func (r *region) clone() (*metapb.Region, *metapb.Peer, error) {
+	meta := proto.Clone(r.meta).(*metapb.Region)
+	if r.leader == nil {
+		return meta, nil, nil
+	}
+	return meta, proto.Clone(r.leader).(*metapb.Peer), nil
+}
+
+func overlaps(a, b *metapb.Region) bool {
+	return (len(b.GetEndKey()) == 0 || bytes.Compare(a.GetStartKey(), b.GetEndKey()) < 0) &&
+		(len(a.GetEndKey()) == 0 || bytes.Compare(b.GetStartKey(), a.GetEndKey()) < 0)
+}
+
+func memberURL(m *pdpb.Member) string {
+	if len(m.GetClientUrls()) == 0 {
+		return ""
+	}
+	return m.GetClientUrls()[0]
+}
+
+func sameMembers(a, b []*pdpb.Member) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := make(map[uint64]struct{}, len(a))
+	for _, m := range a {
+		ids[m.GetMemberId()] = struct{}{}
+	}
+	for _, m := range b {
+		if _, ok := ids[m.GetMemberId()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func maxUint64(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
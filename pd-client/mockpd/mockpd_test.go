@@ -0,0 +1,177 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mockpd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"golang.org/x/net/context"
+)
+
+func TestMockPD(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testMockPDSuite{})
+
+type testMockPDSuite struct{}
+
+func newTestStores(n int) []*metapb.Store {
+	stores := make([]*metapb.Store, 0, n)
+	for i := 1; i <= n; i++ {
+		stores = append(stores, &metapb.Store{Id: uint64(i), Address: "mock"})
+	}
+	return stores
+}
+
+func (s *testMockPDSuite) TestTSO(c *C) {
+	client := NewClient(1)
+	ctx := context.Background()
+	var lastPhysical, lastLogical int64
+	for i := 0; i < 1000; i++ {
+		physical, logical, err := client.GetTSAsync(ctx).Wait()
+		c.Assert(err, IsNil)
+		c.Assert(physical > lastPhysical || (physical == lastPhysical && logical > lastLogical), IsTrue)
+		lastPhysical, lastLogical = physical, logical
+	}
+}
+
+func (s *testMockPDSuite) TestSplitMerge(c *C) {
+	client := NewClient(1)
+	ctx := context.Background()
+	first := client.Bootstrap(newTestStores(3)...)
+
+	left, err := client.Split(first.GetId(), []byte("m"))
+	c.Assert(err, IsNil)
+	_, err = client.Split(first.GetId(), []byte("a"))
+	c.Assert(err, NotNil)
+
+	region, leader, err := client.GetRegion(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(region, DeepEquals, left)
+	c.Assert(leader.GetStoreId(), Equals, uint64(1))
+	region, _, err = client.GetRegion(ctx, []byte("z"))
+	c.Assert(err, IsNil)
+	c.Assert(region.GetId(), Equals, first.GetId())
+	c.Assert(region.GetRegionEpoch().GetVersion(), Equals, uint64(2))
+
+	prev, _, err := client.GetPrevRegion(ctx, []byte("z"))
+	c.Assert(err, IsNil)
+	c.Assert(prev, DeepEquals, left)
+	regions, leaders, err := client.ScanRegions(ctx, []byte(""), nil, 0)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 2)
+	c.Assert(leaders, HasLen, 2)
+
+	c.Assert(client.SetLeader(first.GetId(), 2), IsNil)
+	_, leader, err = client.GetRegionByID(ctx, first.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(leader.GetStoreId(), Equals, uint64(2))
+
+	c.Assert(client.Merge(left.GetId(), first.GetId()), IsNil)
+	region, _, err = client.GetRegion(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(region.GetId(), Equals, first.GetId())
+	c.Assert(region.GetStartKey(), HasLen, 0)
+	c.Assert(region.GetEndKey(), HasLen, 0)
+	c.Assert(region.GetRegionEpoch().GetVersion(), Equals, uint64(3))
+	region, _, err = client.GetRegionByID(ctx, left.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(region, IsNil)
+}
+
+func (s *testMockPDSuite) TestStoresAndOperators(c *C) {
+	client := NewClient(1)
+	ctx := context.Background()
+	region := client.Bootstrap(newTestStores(3)...)
+
+	c.Assert(client.SetStoreState(3, metapb.StoreState_Tombstone), IsNil)
+	stores, err := client.GetAllStores(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(stores, HasLen, 2)
+	_, err = client.GetStore(ctx, 4)
+	c.Assert(errors.Cause(err), Equals, ErrStoreNotFound)
+
+	op, err := client.GetOperator(ctx, region.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(op, IsNil)
+	c.Assert(client.ScatterRegion(ctx, region.GetId()), IsNil)
+	op, err = client.GetOperator(ctx, region.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(op.Desc, Equals, "scatter-region")
+}
+
+func (s *testMockPDSuite) TestGCSafePoint(c *C) {
+	client := NewClient(1)
+	ctx := context.Background()
+	c.Assert(client.UpdateServiceGCSafePoint(ctx, "br", time.Hour, 100), IsNil)
+	safePoint, err := client.UpdateGCSafePoint(ctx, 200)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(100))
+	c.Assert(client.UpdateServiceGCSafePoint(ctx, "br", 0, 0), IsNil)
+	safePoint, err = client.UpdateGCSafePoint(ctx, 200)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(200))
+	safePoint, err = client.UpdateGCSafePoint(ctx, 150)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(200))
+}
+
+func (s *testMockPDSuite) TestFaults(c *C) {
+	client := NewClient(1)
+	client.Bootstrap(newTestStores(1)...)
+
+	errInjected := errors.New("injected")
+	client.InjectError(MethodGetRegion, errInjected)
+	_, _, err := client.GetRegion(context.Background(), []byte("a"))
+	c.Assert(errors.Cause(err), Equals, errInjected)
+	client.InjectError(MethodGetRegion, nil)
+	_, _, err = client.GetRegion(context.Background(), []byte("a"))
+	c.Assert(err, IsNil)
+
+	client.InjectLatency(MethodGetTS, time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = client.GetTS(ctx)
+	c.Assert(errors.Cause(err), Equals, context.DeadlineExceeded)
+
+	client.Close()
+	_, err = client.GetAllStores(context.Background())
+	c.Assert(errors.Cause(err), Equals, ErrClosed)
+}
+
+func (s *testMockPDSuite) TestMembers(c *C) {
+	client := NewClient(1)
+	members, leader, err := client.GetMembers(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(members, HasLen, 1)
+	c.Assert(leader.GetMemberId(), Equals, uint64(1))
+
+	var leaders []string
+	var membersChanges int
+	client.OnLeaderChange(func(newLeader, oldLeader string) { leaders = append(leaders, newLeader) })
+	client.OnMembersChange(func([]*pdpb.Member) { membersChanges++ })
+	m2 := &pdpb.Member{Name: "pd2", MemberId: 2, ClientUrls: []string{"http://pd2"}}
+	client.SetMembers([]*pdpb.Member{members[0], m2}, m2)
+	c.Assert(leaders, DeepEquals, []string{"http://pd2"})
+	c.Assert(membersChanges, Equals, 1)
+	client.SetMembers([]*pdpb.Member{members[0], m2}, members[0])
+	c.Assert(leaders, DeepEquals, []string{"http://pd2", "http://mockpd"})
+	c.Assert(membersChanges, Equals, 1)
+}
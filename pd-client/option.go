@@ -0,0 +1,130 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import "time"
+
+const (
+	defaultRequestTimeout = 3 * time.Second
+	// Use a shorter timeout to recover faster from network isolation.
+	defaultDialTimeout     = time.Second
+	defaultMaxTSOBatchSize = 10000
+)
+
+// defaultBackoff retries every second for 100 times.
+var defaultBackoff = BackoffPolicy{
+	MaxRetries: 100,
+	BaseDelay:  time.Second,
+	MaxDelay:   time.Second,
+}
+
+// BackoffPolicy is the policy of retrying to get the cluster id and the
+// leader from the PD members. The delay starts from BaseDelay and doubles
+// after each retry until it reaches MaxDelay, which also bounds the delay of
+// reconnecting to a PD member.
+type BackoffPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// delay returns the delay before the next retry.
+func (b BackoffPolicy) delay(retries int) time.Duration {
+	d := b.BaseDelay
+	for i := 0; i < retries && d < b.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > b.MaxDelay {
+		d = b.MaxDelay
+	}
+	return d
+}
+
+type clientOptions struct {
+	dialTimeout     time.Duration
+	requestTimeout  time.Duration
+	backoff         BackoffPolicy
+	maxTSOBatchSize int
+	tsoBatchWait    time.Duration
+	authToken       string
+}
+
+func newClientOptions() *clientOptions {
+	return &clientOptions{
+		dialTimeout:     defaultDialTimeout,
+		requestTimeout:  defaultRequestTimeout,
+		backoff:         defaultBackoff,
+		maxTSOBatchSize: defaultMaxTSOBatchSize,
+	}
+}
+
+// ClientOption configures the PD client.
+type ClientOption func(*clientOptions)
+
+// WithDialTimeout sets the timeout of connecting to a PD member and getting
+// the cluster id and the leader from it.
+func WithDialTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		if timeout > 0 {
+			o.dialTimeout = timeout
+		}
+	}
+}
+
+// WithRequestTimeout sets the timeout of the requests, including the TSO
+// requests.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		if timeout > 0 {
+			o.requestTimeout = timeout
+		}
+	}
+}
+
+// WithBackoff sets the policy of retrying to get the cluster id and the
+// leader from the PD members.
+func WithBackoff(backoff BackoffPolicy) ClientOption {
+	return func(o *clientOptions) {
+		if backoff.MaxRetries > 0 {
+			o.backoff = backoff
+		}
+	}
+}
+
+// WithMaxTSOBatchSize sets the max count of the TSO requests which are sent
+// to PD in a batch.
+func WithMaxTSOBatchSize(size int) ClientOption {
+	return func(o *clientOptions) {
+		if size > 0 {
+			o.maxTSOBatchSize = size
+		}
+	}
+}
+
+// WithTSOBatchWait sets the time to wait for more TSO requests before
+// sending a batch. It trades the latency for fewer requests to PD, and the
+// batch is sent without waiting by default.
+func WithTSOBatchWait(wait time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.tsoBatchWait = wait
+	}
+}
+
+// WithAuthToken sets the token which authenticates the client to the HTTP API
+// of PD, it is required if the authentication is enabled on PD.
+func WithAuthToken(token string) ClientOption {
+	return func(o *clientOptions) {
+		o.authToken = token
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/google/btree"
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"golang.org/x/net/context"
+)
+
+const regionCacheBtreeDegree = 32
+
+// CachedRegion is a region and its leader cached by RegionCache. It should be
+// treated as read-only, RegionCache replaces it as a whole when it changes.
+type CachedRegion struct {
+	Meta   *metapb.Region
+	Leader *metapb.Peer
+}
+
+// Contains returns true if the key is in the range of the region.
+func (r *CachedRegion) Contains(key []byte) bool {
+	return bytes.Compare(r.Meta.GetStartKey(), key) <= 0 &&
+		(len(r.Meta.GetEndKey()) == 0 || bytes.Compare(key, r.Meta.GetEndKey()) < 0)
+}
+
+type regionCacheItem struct {
+	region *CachedRegion
+}
+
+// Less returns true if the start key of the region is less than the other.
+func (item *regionCacheItem) Less(other btree.Item) bool {
+	return bytes.Compare(item.region.Meta.GetStartKey(), other.(*regionCacheItem).region.Meta.GetStartKey()) < 0
+}
+
+// RegionCache caches the regions fetched from PD by key range. The regions
+// are loaded on lookup misses or in batch by LoadRegions, and callers are
+// responsible for invalidating them when TiKV reports an epoch-not-match or
+// not-leader error.
+type RegionCache struct {
+	pdClient Client
+
+	mu struct {
+		sync.RWMutex
+		regions map[uint64]*CachedRegion
+		sorted  *btree.BTree
+	}
+}
+
+// NewRegionCache creates a RegionCache which loads regions from pdClient.
+func NewRegionCache(pdClient Client) *RegionCache {
+	c := &RegionCache{pdClient: pdClient}
+	c.mu.regions = make(map[uint64]*CachedRegion)
+	c.mu.sorted = btree.New(regionCacheBtreeDegree)
+	return c
+}
+
+// LocateKey returns the region which contains the key. It loads the region
+// from PD if it is not cached.
+func (c *RegionCache) LocateKey(ctx context.Context, key []byte) (*CachedRegion, error) {
+	c.mu.RLock()
+	region := c.searchCachedRegion(key)
+	c.mu.RUnlock()
+	if region != nil {
+		regionCacheCounter.WithLabelValues("hit").Inc()
+		return region, nil
+	}
+	regionCacheCounter.WithLabelValues("miss").Inc()
+
+	meta, leader, err := c.pdClient.GetRegion(ctx, key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if meta == nil {
+		return nil, errors.Errorf("[pd] region not found for key %q", key)
+	}
+	c.mu.Lock()
+	region = c.insertRegion(&CachedRegion{Meta: meta, Leader: leader})
+	c.mu.Unlock()
+	return region, nil
+}
+
+// LocateRegionByID returns the region with the id. It loads the region from
+// PD if it is not cached.
+func (c *RegionCache) LocateRegionByID(ctx context.Context, regionID uint64) (*CachedRegion, error) {
+	c.mu.RLock()
+	region := c.mu.regions[regionID]
+	c.mu.RUnlock()
+	if region != nil {
+		regionCacheCounter.WithLabelValues("hit").Inc()
+		return region, nil
+	}
+	regionCacheCounter.WithLabelValues("miss").Inc()
+
+	meta, leader, err := c.pdClient.GetRegionByID(ctx, regionID)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if meta == nil {
+		return nil, errors.Errorf("[pd] region %d not found", regionID)
+	}
+	c.mu.Lock()
+	region = c.insertRegion(&CachedRegion{Meta: meta, Leader: leader})
+	c.mu.Unlock()
+	return region, nil
+}
+
+// LoadRegions loads at most limit regions which overlap with
+// [startKey, endKey) from PD into the cache and returns them.
+func (c *RegionCache) LoadRegions(ctx context.Context, startKey, endKey []byte, limit int) ([]*CachedRegion, error) {
+	metas, leaders, err := c.pdClient.ScanRegions(ctx, startKey, endKey, limit)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	regions := make([]*CachedRegion, 0, len(metas))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, meta := range metas {
+		region := &CachedRegion{Meta: meta}
+		// ScanRegions responds an empty Peer for the regions without leader.
+		if leaders[i].GetId() != 0 {
+			region.Leader = leaders[i]
+		}
+		regions = append(regions, c.insertRegion(region))
+	}
+	return regions, nil
+}
+
+// InvalidateRegion removes the region from the cache, it will be reloaded
+// from PD by the next lookup.
+func (c *RegionCache) InvalidateRegion(regionID uint64) {
+	regionCacheCounter.WithLabelValues("invalidate").Inc()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeRegion(regionID)
+}
+
+// OnEpochNotMatch handles the epoch-not-match error reported for the region.
+// The current regions carried by the error replace the stale one, or the
+// region is just invalidated if there are none.
+func (c *RegionCache) OnEpochNotMatch(regionID uint64, currentRegions []*metapb.Region) {
+	regionCacheCounter.WithLabelValues("invalidate").Inc()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeRegion(regionID)
+	for _, meta := range currentRegions {
+		c.insertRegion(&CachedRegion{Meta: meta})
+	}
+}
+
+// OnNotLeader handles the not-leader error reported for the region. It
+// switches to the new leader if it is known, otherwise the region is
+// invalidated.
+func (c *RegionCache) OnNotLeader(regionID uint64, leader *metapb.Peer) {
+	if leader == nil || !c.UpdateLeader(regionID, leader.GetStoreId()) {
+		c.InvalidateRegion(regionID)
+	}
+}
+
+// UpdateLeader switches the leader of the cached region to its peer on the
+// store. It returns false if the region is not cached or has no peer on the
+// store.
+func (c *RegionCache) UpdateLeader(regionID, leaderStoreID uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	region, ok := c.mu.regions[regionID]
+	if !ok {
+		return false
+	}
+	for _, peer := range region.Meta.GetPeers() {
+		if peer.GetStoreId() == leaderStoreID {
+			c.insertRegion(&CachedRegion{Meta: region.Meta, Leader: peer})
+			return true
+		}
+	}
+	return false
+}
+
+// SwitchToNextPeer switches the leader of the cached region to the peer next
+// to the current leader, it is used to try the other peers when the leader is
+// unreachable. It returns false if the region is not cached.
+func (c *RegionCache) SwitchToNextPeer(regionID uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	region, ok := c.mu.regions[regionID]
+	if !ok || len(region.Meta.GetPeers()) == 0 {
+		return false
+	}
+	peers := region.Meta.GetPeers()
+	next := 0
+	for i, peer := range peers {
+		if peer.GetId() == region.Leader.GetId() {
+			next = (i + 1) % len(peers)
+			break
+		}
+	}
+	c.insertRegion(&CachedRegion{Meta: region.Meta, Leader: peers[next]})
+	return true
+}
+
+// Clear removes all the cached regions.
+func (c *RegionCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.regions = make(map[uint64]*CachedRegion)
+	c.mu.sorted.Clear(false)
+}
+
+func (c *RegionCache) searchCachedRegion(key []byte) *CachedRegion {
+	var region *CachedRegion
+	c.mu.sorted.DescendLessOrEqual(&regionCacheItem{region: newKeyRegion(key)}, func(item btree.Item) bool {
+		region = item.(*regionCacheItem).region
+		return false
+	})
+	if region == nil || !region.Contains(key) {
+		return nil
+	}
+	return region
+}
+
+// insertRegion puts the region into the cache, the cached regions which
+// overlap with it are removed. The region is not put if its epoch is older
+// than the cached one with the same id, which may be loaded by a concurrent
+// lookup. It returns the region in the cache.
+func (c *RegionCache) insertRegion(region *CachedRegion) *CachedRegion {
+	if origin, ok := c.mu.regions[region.Meta.GetId()]; ok {
+		r, o := region.Meta.GetRegionEpoch(), origin.Meta.GetRegionEpoch()
+		if r.GetVersion() < o.GetVersion() || r.GetConfVer() < o.GetConfVer() {
+			return origin
+		}
+	}
+	c.removeRegion(region.Meta.GetId())
+
+	var overlaps []*CachedRegion
+	startKey, endKey := region.Meta.GetStartKey(), region.Meta.GetEndKey()
+	if prev := c.searchCachedRegion(startKey); prev != nil {
+		overlaps = append(overlaps, prev)
+	}
+	c.mu.sorted.AscendGreaterOrEqual(&regionCacheItem{region: newKeyRegion(startKey)}, func(item btree.Item) bool {
+		r := item.(*regionCacheItem).region
+		if len(endKey) > 0 && bytes.Compare(r.Meta.GetStartKey(), endKey) >= 0 {
+			return false
+		}
+		overlaps = append(overlaps, r)
+		return true
+	})
+	for _, r := range overlaps {
+		c.removeRegion(r.Meta.GetId())
+	}
+
+	c.mu.regions[region.Meta.GetId()] = region
+	c.mu.sorted.ReplaceOrInsert(&regionCacheItem{region: region})
+	return region
+}
+
+func (c *RegionCache) removeRegion(regionID uint64) {
+	region, ok := c.mu.regions[regionID]
+	if !ok {
+		return
+	}
+	delete(c.mu.regions, regionID)
+	c.mu.sorted.Delete(&regionCacheItem{region: region})
+}
+
+// newKeyRegion returns a region which only has the start key, it is used to
+// search the tree.
+func newKeyRegion(key []byte) *CachedRegion {
+	return &CachedRegion{Meta: &metapb.Region{StartKey: key}}
+}
@@ -0,0 +1,227 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"bytes"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"golang.org/x/net/context"
+)
+
+var _ = Suite(&testRegionCacheSuite{})
+
+type testRegionCacheSuite struct{}
+
+// regionsClient serves the region requests from the regions in key order,
+// the other methods of Client are not implemented.
+type regionsClient struct {
+	Client
+	regions  []*metapb.Region
+	requests int
+}
+
+func (c *regionsClient) GetRegion(ctx context.Context, key []byte) (*metapb.Region, *metapb.Peer, error) {
+	c.requests++
+	for _, r := range c.regions {
+		if (&CachedRegion{Meta: r}).Contains(key) {
+			return r, r.GetPeers()[0], nil
+		}
+	}
+	return nil, nil, nil
+}
+
+func (c *regionsClient) GetRegionByID(ctx context.Context, regionID uint64) (*metapb.Region, *metapb.Peer, error) {
+	c.requests++
+	for _, r := range c.regions {
+		if r.GetId() == regionID {
+			return r, r.GetPeers()[0], nil
+		}
+	}
+	return nil, nil, nil
+}
+
+func (c *regionsClient) ScanRegions(ctx context.Context, key, endKey []byte, limit int) ([]*metapb.Region, []*metapb.Peer, error) {
+	c.requests++
+	var (
+		regions []*metapb.Region
+		leaders []*metapb.Peer
+	)
+	for _, r := range c.regions {
+		if len(r.GetEndKey()) > 0 && bytes.Compare(r.GetEndKey(), key) <= 0 {
+			continue
+		}
+		if len(endKey) > 0 && bytes.Compare(r.GetStartKey(), endKey) >= 0 {
+			break
+		}
+		regions = append(regions, r)
+		leaders = append(leaders, r.GetPeers()[0])
+		if len(regions) == limit {
+			break
+		}
+	}
+	return regions, leaders, nil
+}
+
+func newCacheTestRegion(id uint64, startKey, endKey string) *metapb.Region {
+	return &metapb.Region{
+		Id:          id,
+		StartKey:    []byte(startKey),
+		EndKey:      []byte(endKey),
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		Peers: []*metapb.Peer{
+			{Id: id*10 + 1, StoreId: 1},
+			{Id: id*10 + 2, StoreId: 2},
+			{Id: id*10 + 3, StoreId: 3},
+		},
+	}
+}
+
+func (s *testRegionCacheSuite) TestLocate(c *C) {
+	client := &regionsClient{regions: []*metapb.Region{
+		newCacheTestRegion(1, "", "b"),
+		newCacheTestRegion(2, "b", "d"),
+		newCacheTestRegion(3, "d", ""),
+	}}
+	cache := NewRegionCache(client)
+	ctx := context.Background()
+
+	region, err := cache.LocateKey(ctx, []byte("c"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Meta.GetId(), Equals, uint64(2))
+	c.Assert(client.requests, Equals, 1)
+	// The cached region is hit.
+	region, err = cache.LocateKey(ctx, []byte("b"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Meta.GetId(), Equals, uint64(2))
+	region, err = cache.LocateRegionByID(ctx, 2)
+	c.Assert(err, IsNil)
+	c.Assert(region.Meta.GetId(), Equals, uint64(2))
+	c.Assert(client.requests, Equals, 1)
+
+	region, err = cache.LocateKey(ctx, []byte("z"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Meta.GetId(), Equals, uint64(3))
+	c.Assert(client.requests, Equals, 2)
+
+	// Invalidated regions are reloaded.
+	cache.InvalidateRegion(2)
+	_, err = cache.LocateKey(ctx, []byte("c"))
+	c.Assert(err, IsNil)
+	c.Assert(client.requests, Equals, 3)
+
+	cache.Clear()
+	regions, err := cache.LoadRegions(ctx, []byte(""), nil, 10)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 3)
+	c.Assert(client.requests, Equals, 4)
+	for _, key := range []string{"", "a", "c", "e"} {
+		_, err = cache.LocateKey(ctx, []byte(key))
+		c.Assert(err, IsNil)
+	}
+	c.Assert(client.requests, Equals, 4)
+}
+
+func (s *testRegionCacheSuite) TestEpochNotMatch(c *C) {
+	client := &regionsClient{regions: []*metapb.Region{newCacheTestRegion(1, "", "")}}
+	cache := NewRegionCache(client)
+	ctx := context.Background()
+	_, err := cache.LocateKey(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+
+	// The region is split into 1 and 2.
+	left, right := newCacheTestRegion(1, "", "m"), newCacheTestRegion(2, "m", "")
+	left.RegionEpoch.Version, right.RegionEpoch.Version = 2, 2
+	cache.OnEpochNotMatch(1, []*metapb.Region{left, right})
+	region, err := cache.LocateKey(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Meta, DeepEquals, left)
+	region, err = cache.LocateKey(ctx, []byte("z"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Meta, DeepEquals, right)
+	c.Assert(client.requests, Equals, 1)
+
+	// The overlapped regions are replaced by the merged one.
+	merged := newCacheTestRegion(2, "", "")
+	merged.RegionEpoch.Version = 3
+	cache.OnEpochNotMatch(2, []*metapb.Region{merged})
+	region, err = cache.LocateKey(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Meta, DeepEquals, merged)
+	region, err = cache.LocateRegionByID(ctx, 1)
+	c.Assert(err, IsNil)
+	c.Assert(client.requests, Equals, 2)
+}
+
+func (s *testRegionCacheSuite) TestStaleRegion(c *C) {
+	client := &regionsClient{regions: []*metapb.Region{newCacheTestRegion(1, "", "")}}
+	cache := NewRegionCache(client)
+	ctx := context.Background()
+	left, right := newCacheTestRegion(1, "", "m"), newCacheTestRegion(2, "m", "")
+	left.RegionEpoch.Version, right.RegionEpoch.Version = 2, 2
+	cache.OnEpochNotMatch(1, []*metapb.Region{left, right})
+
+	// The region loaded from PD is older than the cached one, it replaces
+	// neither the cached one nor the overlapped ones.
+	regions, err := cache.LoadRegions(ctx, []byte(""), nil, 10)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+	c.Assert(regions[0].Meta, DeepEquals, left)
+	region, err := cache.LocateKey(ctx, []byte("z"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Meta, DeepEquals, right)
+
+	// A newer version with an older conf version is stale too.
+	stale := newCacheTestRegion(2, "m", "")
+	stale.RegionEpoch = &metapb.RegionEpoch{ConfVer: 0, Version: 3}
+	client.regions = []*metapb.Region{left, stale}
+	regions, err = cache.LoadRegions(ctx, []byte("m"), nil, 10)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 1)
+	c.Assert(regions[0].Meta, DeepEquals, right)
+
+	// The region with the same epoch replaces the cached one.
+	cache.UpdateLeader(2, 3)
+	region, err = cache.LocateKey(ctx, []byte("z"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Leader.GetStoreId(), Equals, uint64(3))
+}
+
+func (s *testRegionCacheSuite) TestSwitchLeader(c *C) {
+	client := &regionsClient{regions: []*metapb.Region{newCacheTestRegion(1, "", "")}}
+	cache := NewRegionCache(client)
+	ctx := context.Background()
+	region, err := cache.LocateKey(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Leader.GetStoreId(), Equals, uint64(1))
+
+	cache.OnNotLeader(1, &metapb.Peer{Id: 13, StoreId: 3})
+	region, err = cache.LocateKey(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Leader.GetStoreId(), Equals, uint64(3))
+	c.Assert(cache.SwitchToNextPeer(1), IsTrue)
+	region, err = cache.LocateKey(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(region.Leader.GetStoreId(), Equals, uint64(1))
+	c.Assert(client.requests, Equals, 1)
+
+	// The region is invalidated if the new leader is unknown.
+	c.Assert(cache.UpdateLeader(1, 4), IsFalse)
+	cache.OnNotLeader(1, nil)
+	c.Assert(cache.SwitchToNextPeer(1), IsFalse)
+	_, err = cache.LocateKey(ctx, []byte("a"))
+	c.Assert(err, IsNil)
+	c.Assert(client.requests, Equals, 2)
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pd
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+)
+
+// LeaderChangeFunc is called with the client URL of the new PD leader and the
+// previous one after the leader changes.
+type LeaderChangeFunc func(newLeader, oldLeader string)
+
+// MembersChangeFunc is called with the current members after the members of
+// the PD cluster change.
+type MembersChangeFunc func(members []*pdpb.Member)
+
+// notifier calls the callbacks of the leader and members changes in its own
+// goroutine, so that the slow callbacks don't block the leader loop and the
+// TSO loop.
+type notifier struct {
+	sync.Mutex
+	leaderCallbacks  []LeaderChangeFunc
+	membersCallbacks []MembersChangeFunc
+	// memberIDs are the sorted ids of the members seen last time.
+	memberIDs []uint64
+	pending   []func()
+
+	notifyCh chan struct{}
+}
+
+func newNotifier() *notifier {
+	return &notifier{notifyCh: make(chan struct{}, 1)}
+}
+
+func (n *notifier) addLeaderCallback(f LeaderChangeFunc) {
+	n.Lock()
+	defer n.Unlock()
+	n.leaderCallbacks = append(n.leaderCallbacks, f)
+}
+
+func (n *notifier) addMembersCallback(f MembersChangeFunc) {
+	n.Lock()
+	defer n.Unlock()
+	n.membersCallbacks = append(n.membersCallbacks, f)
+}
+
+func (n *notifier) leaderChanged(newLeader, oldLeader string) {
+	n.Lock()
+	defer n.Unlock()
+	for _, f := range n.leaderCallbacks {
+		f := f
+		n.pending = append(n.pending, func() { f(newLeader, oldLeader) })
+	}
+	n.notifyLocked()
+}
+
+// membersUpdated records the current members, the callbacks are called if
+// they are different from the members seen last time.
+func (n *notifier) membersUpdated(members []*pdpb.Member) {
+	ids := make([]uint64, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.GetMemberId())
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	n.Lock()
+	defer n.Unlock()
+	// The first seen members are not a change.
+	changed := n.memberIDs != nil && !equalIDs(ids, n.memberIDs)
+	n.memberIDs = ids
+	if !changed {
+		return
+	}
+	for _, f := range n.membersCallbacks {
+		f := f
+		n.pending = append(n.pending, func() { f(members) })
+	}
+	n.notifyLocked()
+}
+
+func (n *notifier) notifyLocked() {
+	if len(n.pending) == 0 {
+		return
+	}
+	select {
+	case n.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// takePending returns the pending callbacks in order and clears them.
+func (n *notifier) takePending() []func() {
+	n.Lock()
+	defer n.Unlock()
+	pending := n.pending
+	n.pending = nil
+	return pending
+}
+
+func (c *client) notifyLoop() {
+	defer c.wg.Done()
+
+	for {
+		select {
+		case <-c.notifier.notifyCh:
+		case <-c.ctx.Done():
+			return
+		}
+		for _, f := range c.notifier.takePending() {
+			f()
+		}
+	}
+}
+
+func (c *client) OnLeaderChange(f LeaderChangeFunc) {
+	c.notifier.addLeaderCallback(f)
+}
+
+func (c *client) OnMembersChange(f MembersChangeFunc) {
+	c.notifier.addMembersCallback(f)
+}
+
+func equalIDs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
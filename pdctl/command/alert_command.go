@@ -0,0 +1,146 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/pd/server/core"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+// NewAlertCommand return an alert subcommand of rootCmd
+func NewAlertCommand() *cobra.Command {
+	a := &cobra.Command{
+		Use:   "alert",
+		Short: "show the firing alerts found by the diagnose rules",
+		Run:   showAlertsCommandFunc,
+	}
+	a.AddCommand(NewAlertSilenceCommand())
+	return a
+}
+
+// NewAlertSilenceCommand return a silence subcommand of alertCmd
+func NewAlertSilenceCommand() *cobra.Command {
+	s := &cobra.Command{
+		Use:   "silence",
+		Short: "show the alert silences which are not expired",
+		Run:   showAlertSilencesCommandFunc,
+	}
+	s.AddCommand(NewAddAlertSilenceCommand())
+	s.AddCommand(NewDeleteAlertSilenceCommand())
+	return s
+}
+
+// NewAddAlertSilenceCommand return an add subcommand of alertSilenceCmd
+func NewAddAlertSilenceCommand() *cobra.Command {
+	a := &cobra.Command{
+		Use:   "add <label>=<value>... [--duration=<duration>] [--comment=<comment>]",
+		Short: "mute the alerts whose labels match all the matchers for the duration",
+		Run:   addAlertSilenceCommandFunc,
+	}
+	a.Flags().Duration("duration", time.Hour, "how long the alerts are muted")
+	a.Flags().String("comment", "", "why the alerts are muted")
+	return a
+}
+
+// NewDeleteAlertSilenceCommand return a delete subcommand of alertSilenceCmd
+func NewDeleteAlertSilenceCommand() *cobra.Command {
+	d := &cobra.Command{
+		Use:   "delete <silence_id>",
+		Short: "delete an alert silence",
+		Run:   deleteAlertSilenceCommandFunc,
+	}
+	return d
+}
+
+func showAlertsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	alerts, err := getClient(cmd).GetAlerts(context.Background())
+	if err != nil {
+		fmt.Printf("Failed to get alerts: %s\n", err)
+		return
+	}
+	printJSON(alerts)
+}
+
+func showAlertSilencesCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	silences, err := getClient(cmd).GetAlertSilences(context.Background())
+	if err != nil {
+		fmt.Printf("Failed to get alert silences: %s\n", err)
+		return
+	}
+	printJSON(silences)
+}
+
+func addAlertSilenceCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	matchers := make(map[string]string, len(args))
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			fmt.Printf("invalid matcher %s, it should be <label>=<value>\n", arg)
+			return
+		}
+		matchers[kv[0]] = kv[1]
+	}
+	duration, err := cmd.Flags().GetDuration("duration")
+	if err != nil || duration <= 0 {
+		fmt.Println("duration should be positive")
+		return
+	}
+	comment, _ := cmd.Flags().GetString("comment")
+	silence := &core.AlertSilence{
+		Matchers: matchers,
+		EndsAt:   time.Now().Add(duration),
+		Comment:  comment,
+	}
+	id, err := getClient(cmd).AddAlertSilence(context.Background(), silence)
+	if err != nil {
+		fmt.Printf("Failed to add alert silence: %s\n", err)
+		return
+	}
+	fmt.Printf("Success! The silence id is %d\n", id)
+}
+
+func deleteAlertSilenceCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Println("silence_id should be a number")
+		return
+	}
+	if err = getClient(cmd).DeleteAlertSilence(context.Background(), id); err != nil {
+		fmt.Printf("Failed to delete alert silence %s: %s\n", args[0], err)
+		return
+	}
+	fmt.Println("Success!")
+}
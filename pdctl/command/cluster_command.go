@@ -15,13 +15,11 @@ package command
 
 import (
 	"fmt"
-	"net/http"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
 )
 
-const clusterPrefix = "pd/api/v1/cluster"
-
 // NewClusterCommand return a cluster subcommand of rootCmd
 func NewClusterCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -33,10 +31,10 @@ func NewClusterCommand() *cobra.Command {
 }
 
 func showClusterCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, clusterPrefix, http.MethodGet)
+	cluster, err := getClient(cmd).GetCluster(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get the cluster information: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(cluster)
 }
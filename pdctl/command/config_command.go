@@ -14,23 +14,12 @@
 package command
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"net/http"
-	"path"
 	"strconv"
 
+	"github.com/pingcap/pd/server"
 	"github.com/spf13/cobra"
-)
-
-var (
-	configPrefix         = "pd/api/v1/config"
-	schedulePrefix       = "pd/api/v1/config/schedule"
-	replicationPrefix    = "pd/api/v1/config/replicate"
-	namespacePrefix      = "pd/api/v1/config/namespace"
-	labelPropertyPrefix  = "pd/api/v1/config/label-property"
-	clusterVersionPrefix = "pd/api/v1/config/cluster-version"
+	"golang.org/x/net/context"
 )
 
 // NewConfigCommand return a config subcommand of rootCmd
@@ -42,9 +31,43 @@ func NewConfigCommand() *cobra.Command {
 	conf.AddCommand(NewShowConfigCommand())
 	conf.AddCommand(NewSetConfigCommand())
 	conf.AddCommand(NewDeleteConfigCommand())
+	conf.AddCommand(NewConfigHistoryCommand())
+	conf.AddCommand(NewRollbackConfigCommand())
+	conf.AddCommand(NewReloadConfigCommand())
 	return conf
 }
 
+// NewConfigHistoryCommand returns a history subcommand of configCmd
+func NewConfigHistoryCommand() *cobra.Command {
+	sc := &cobra.Command{
+		Use:   "history [<revision>]",
+		Short: "show the latest revisions of the config with the changes, or a revision with the whole config",
+		Run:   showConfigHistoryCommandFunc,
+	}
+	sc.Flags().Int("limit", 16, "the max number of the revisions to show")
+	return sc
+}
+
+// NewRollbackConfigCommand returns a rollback subcommand of configCmd
+func NewRollbackConfigCommand() *cobra.Command {
+	sc := &cobra.Command{
+		Use:   "rollback <revision>",
+		Short: "restore the config to a revision in the history, except the schedulers and the cluster version",
+		Run:   rollbackConfigCommandFunc,
+	}
+	return sc
+}
+
+// NewReloadConfigCommand returns a reload subcommand of configCmd
+func NewReloadConfigCommand() *cobra.Command {
+	sc := &cobra.Command{
+		Use:   "reload",
+		Short: "make the PD server reload its config file, and show the applied and the ignored changes",
+		Run:   reloadConfigCommandFunc,
+	}
+	return sc
+}
+
 // NewShowConfigCommand return a show subcommand of configCmd
 func NewShowConfigCommand() *cobra.Command {
 	sc := &cobra.Command{
@@ -77,6 +100,7 @@ func NewShowAllConfigCommand() *cobra.Command {
 		Short: "show all config of PD",
 		Run:   showAllConfigCommandFunc,
 	}
+	sc.Flags().String("source", "effective", "effective for the running config, file for the config loaded from the config file")
 	return sc
 }
 
@@ -185,39 +209,53 @@ func NewDeleteLabelPropertyConfigCommand() *cobra.Command {
 }
 
 func showConfigCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, schedulePrefix, http.MethodGet)
+	cfg, err := getClient(cmd).GetScheduleConfig(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get config: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(cfg)
 }
 
 func showReplicationConfigCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, replicationPrefix, http.MethodGet)
+	cfg, err := getClient(cmd).GetReplicationConfig(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get config: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(cfg)
 }
 
 func showLabelPropertyConfigCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, labelPropertyPrefix, http.MethodGet)
+	cfg, err := getClient(cmd).GetLabelPropertyConfig(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get config: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(cfg)
 }
 
 func showAllConfigCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, configPrefix, http.MethodGet)
+	source, err := cmd.Flags().GetString("source")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var cfg *server.Config
+	switch source {
+	case "effective":
+		cfg, err = getClient(cmd).GetConfig(context.Background())
+	case "file":
+		cfg, err = getClient(cmd).GetFileConfig(context.Background())
+	default:
+		fmt.Printf("invalid source %s, it should be effective or file\n", source)
+		return
+	}
 	if err != nil {
 		fmt.Printf("Failed to get config: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(cfg)
 }
 
 func showNamespaceConfigCommandFunc(cmd *cobra.Command, args []string) {
@@ -225,45 +263,48 @@ func showNamespaceConfigCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println(cmd.UsageString())
 		return
 	}
-	prefix := path.Join(namespacePrefix, args[0])
-	r, err := doRequest(cmd, prefix, http.MethodGet)
+	cfg, err := getClient(cmd).GetNamespaceConfig(context.Background(), args[0])
 	if err != nil {
 		fmt.Printf("Failed to get config: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(cfg)
 }
 
 func showClusterVersionCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, clusterVersionPrefix, http.MethodGet)
+	version, err := getClient(cmd).GetClusterVersion(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get cluster version: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(version)
 }
 
-func postConfigDataWithPath(cmd *cobra.Command, key, value, path string) error {
+// configItem makes the input of setting a config item, the value is sent as
+// a number if it can be parsed as one.
+func configItem(key, value string) map[string]interface{} {
 	var val interface{}
-	data := make(map[string]interface{})
 	val, err := strconv.ParseFloat(value, 64)
 	if err != nil {
 		val = value
 	}
-	data[key] = val
-	reqData, err := json.Marshal(data)
+	return map[string]interface{}{key: val}
+}
+
+func setConfig(cmd *cobra.Command, key, value string) error {
+	client := getClient(cmd)
+	data := configItem(key, value)
+	res, err := client.ValidateConfig(context.Background(), data)
 	if err != nil {
 		return err
 	}
-	req, err := getRequest(cmd, path, http.MethodPost, "application/json", bytes.NewBuffer(reqData))
-	if err != nil {
-		return err
+	for _, issue := range res.Warnings {
+		fmt.Printf("Warning: %s\n", issue)
 	}
-	_, err = dail(req)
-	if err != nil {
+	if err = res.Err(); err != nil {
 		return err
 	}
-	return nil
+	return client.SetConfig(context.Background(), data)
 }
 
 func setConfigCommandFunc(cmd *cobra.Command, args []string) {
@@ -272,7 +313,7 @@ func setConfigCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 	opt, val := args[0], args[1]
-	err := postConfigDataWithPath(cmd, opt, val, configPrefix)
+	err := setConfig(cmd, opt, val)
 	if err != nil {
 		fmt.Printf("Failed to set config: %s\n", err)
 		return
@@ -286,8 +327,7 @@ func setNamespaceConfigCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 	name, opt, val := args[0], args[1], args[2]
-	prefix := path.Join(namespacePrefix, name)
-	err := postConfigDataWithPath(cmd, opt, val, prefix)
+	err := getClient(cmd).SetNamespaceConfig(context.Background(), name, configItem(opt, val))
 	if err != nil {
 		fmt.Printf("Failed to set namespace:%s config: %s\n", name, err)
 		return
@@ -300,15 +340,15 @@ func deleteNamespaceConfigCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println(cmd.UsageString())
 		return
 	}
-	name, opt := args[0], args[1]
-	prefix := path.Join(namespacePrefix, name)
+	name, opt := args[0], ""
 
 	var err error
 	if len(args) == 2 {
 		// delete namespace config's option by setting the option with zero value
-		err = postConfigDataWithPath(cmd, opt, "0", prefix)
+		opt = args[1]
+		err = getClient(cmd).SetNamespaceConfig(context.Background(), name, configItem(opt, "0"))
 	} else {
-		_, err = doRequest(cmd, prefix, http.MethodDelete)
+		err = getClient(cmd).DeleteNamespaceConfig(context.Background(), name)
 	}
 
 	if err != nil {
@@ -319,26 +359,86 @@ func deleteNamespaceConfigCommandFunc(cmd *cobra.Command, args []string) {
 }
 
 func setLabelPropertyConfigCommandFunc(cmd *cobra.Command, args []string) {
-	postLabelProperty(cmd, "set", args)
+	if len(args) != 3 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	if err := getClient(cmd).SetLabelProperty(context.Background(), args[0], args[1], args[2]); err != nil {
+		fmt.Println(err)
+	}
 }
 
 func deleteLabelPropertyConfigCommandFunc(cmd *cobra.Command, args []string) {
-	postLabelProperty(cmd, "delete", args)
+	if len(args) != 3 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	if err := getClient(cmd).DeleteLabelProperty(context.Background(), args[0], args[1], args[2]); err != nil {
+		fmt.Println(err)
+	}
 }
 
-func postLabelProperty(cmd *cobra.Command, action string, args []string) {
-	if len(args) != 3 {
+func showConfigHistoryCommandFunc(cmd *cobra.Command, args []string) {
+	var (
+		res interface{}
+		err error
+	)
+	switch len(args) {
+	case 0:
+		limit, flagErr := cmd.Flags().GetInt("limit")
+		if flagErr != nil {
+			fmt.Println(flagErr)
+			return
+		}
+		res, err = getClient(cmd).GetConfigHistory(context.Background(), limit)
+	case 1:
+		revision, parseErr := strconv.ParseUint(args[0], 10, 64)
+		if parseErr != nil {
+			fmt.Println("revision should be a number")
+			return
+		}
+		res, err = getClient(cmd).GetConfigRevision(context.Background(), revision)
+	default:
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	if err != nil {
+		fmt.Printf("Failed to get config history: %s\n", err)
+		return
+	}
+	printJSON(res)
+}
+
+func rollbackConfigCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
 		fmt.Println(cmd.UsageString())
 		return
 	}
-	input := map[string]interface{}{
-		"type":        args[0],
-		"action":      action,
-		"label-key":   args[1],
-		"label-value": args[2],
+	revision, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Println("revision should be a number")
+		return
+	}
+	if err := getClient(cmd).RollbackConfig(context.Background(), revision); err != nil {
+		fmt.Printf("Failed to rollback config: %s\n", err)
+		return
 	}
-	prefix := path.Join(labelPropertyPrefix)
-	postJSON(cmd, prefix, input)
+	fmt.Println("Success!")
+}
+
+func reloadConfigCommandFunc(cmd *cobra.Command, args []string) {
+	res, err := getClient(cmd).ReloadConfig(context.Background())
+	if err != nil {
+		fmt.Printf("Failed to reload config: %s\n", err)
+		return
+	}
+	for _, change := range res.Applied {
+		fmt.Printf("Applied: %s: %v -> %v\n", change.Path, change.Old, change.New)
+	}
+	for _, issue := range res.Ignored {
+		fmt.Printf("Ignored: %s\n", issue)
+	}
+	fmt.Println("Success!")
 }
 
 func setClusterVersionCommandFunc(cmd *cobra.Command, args []string) {
@@ -346,8 +446,7 @@ func setClusterVersionCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println(cmd.UsageString())
 		return
 	}
-	input := map[string]interface{}{
-		"cluster-version": args[0],
+	if err := getClient(cmd).SetClusterVersion(context.Background(), args[0]); err != nil {
+		fmt.Println(err)
 	}
-	postJSON(cmd, clusterVersionPrefix, input)
 }
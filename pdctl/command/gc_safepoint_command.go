@@ -0,0 +1,101 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+// NewServiceGCSafePointCommand return a service gc safe point subcommand of rootCmd
+func NewServiceGCSafePointCommand() *cobra.Command {
+	l := &cobra.Command{
+		Use:   "service-gc-safepoint",
+		Short: "show the gc safe point and the safe points of services",
+		Run:   showServiceGCSafePointCommandFunc,
+	}
+	l.AddCommand(NewSetServiceGCSafePointCommand())
+	l.AddCommand(NewDeleteServiceGCSafePointCommand())
+	return l
+}
+
+// NewSetServiceGCSafePointCommand return a set subcommand of serviceGCSafePointCmd
+func NewSetServiceGCSafePointCommand() *cobra.Command {
+	l := &cobra.Command{
+		Use:   "set <service_id> <safe_point> <ttl_seconds>",
+		Short: "set the safe point of a service, the gc safe point will not exceed it in the ttl",
+		Run:   setServiceGCSafePointCommandFunc,
+	}
+	return l
+}
+
+// NewDeleteServiceGCSafePointCommand return a delete subcommand of serviceGCSafePointCmd
+func NewDeleteServiceGCSafePointCommand() *cobra.Command {
+	l := &cobra.Command{
+		Use:   "delete <service_id>",
+		Short: "delete the safe point of a service",
+		Run:   deleteServiceGCSafePointCommandFunc,
+	}
+	return l
+}
+
+func showServiceGCSafePointCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	safePoints, err := getClient(cmd).GetGCSafePoints(context.Background())
+	if err != nil {
+		fmt.Printf("Failed to get gc safe points: %s\n", err)
+		return
+	}
+	printJSON(safePoints)
+}
+
+func setServiceGCSafePointCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 3 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	safePoint, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		fmt.Println("safe_point should be a number")
+		return
+	}
+	ttl, err := strconv.ParseInt(args[2], 10, 64)
+	if err != nil {
+		fmt.Println("ttl_seconds should be a number")
+		return
+	}
+	if err = getClient(cmd).SetServiceGCSafePoint(context.Background(), args[0], safePoint, ttl); err != nil {
+		fmt.Printf("Failed to set the safe point of service %s: %s\n", args[0], err)
+		return
+	}
+	fmt.Println("Success!")
+}
+
+func deleteServiceGCSafePointCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	if err := getClient(cmd).DeleteServiceGCSafePoint(context.Background(), args[0]); err != nil {
+		fmt.Printf("Failed to delete the safe point of service %s: %s\n", args[0], err)
+		return
+	}
+	fmt.Println("Success!")
+}
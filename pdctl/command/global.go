@@ -14,131 +14,64 @@
 package command
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
-	"io/ioutil"
-	"net/http"
-	"net/url"
 	"os"
+	"strings"
 
-	"github.com/coreos/etcd/pkg/transport"
 	"github.com/juju/errors"
+	pd "github.com/pingcap/pd/pd-client"
+	pdhttp "github.com/pingcap/pd/pd-client/http"
 	"github.com/spf13/cobra"
 )
 
 var (
-	dialClient = &http.Client{}
-
-	pingPrefix = "pd/ping"
+	security  pd.SecurityOption
+	authToken string
 )
 
 // InitHTTPSClient creates https client with ca file
 func InitHTTPSClient(CAPath, CertPath, KeyPath string) error {
-	tlsInfo := transport.TLSInfo{
-		CertFile:      CertPath,
-		KeyFile:       KeyPath,
-		TrustedCAFile: CAPath,
-	}
-	tlsConfig, err := tlsInfo.ClientConfig()
-	if err != nil {
-		return errors.Trace(err)
-	}
-
-	dialClient = &http.Client{Transport: &http.Transport{
-		TLSClientConfig: tlsConfig,
-	}}
-
-	return nil
-}
-
-func getRequest(cmd *cobra.Command, prefix string, method string, bodyType string, body io.Reader) (*http.Request, error) {
-	if method == "" {
-		method = http.MethodGet
-	}
-	url := getAddressFromCmd(cmd, prefix)
-	req, err := http.NewRequest(method, url, body)
-	if err != nil {
-		return nil, err
+	security = pd.SecurityOption{
+		CAPath:   CAPath,
+		CertPath: CertPath,
+		KeyPath:  KeyPath,
 	}
-	req.Header.Set("Content-Type", bodyType)
-	return req, err
+	// Checks the certificates early.
+	_, err := security.ToTLSConfig()
+	return errors.Trace(err)
 }
 
-func dail(req *http.Request) (string, error) {
-	var res string
-	reps, err := dialClient.Do(req)
-	if err != nil {
-		return res, err
-	}
-	defer reps.Body.Close()
-	if reps.StatusCode != http.StatusOK {
-		return res, genResponseError(reps)
-	}
-
-	r, err := ioutil.ReadAll(reps.Body)
-	if err != nil {
-		return res, err
-	}
-	res = string(r)
-	return res, nil
+// InitAuthToken sets the bearer token sent with the requests.
+func InitAuthToken(token string) {
+	authToken = token
 }
 
-func doRequest(cmd *cobra.Command, prefix string, method string) (string, error) {
-	req, err := getRequest(cmd, prefix, method, "", nil)
-	if err != nil {
-		return "", err
-	}
-	return dail(req)
-}
-
-func genResponseError(r *http.Response) error {
-	res, _ := ioutil.ReadAll(r.Body)
-	return errors.Errorf("[%d] %s", r.StatusCode, res)
-}
-
-func getAddressFromCmd(cmd *cobra.Command, prefix string) string {
+// getClient creates a client of the PD HTTP API with the addresses set by
+// the flag "pd", the addresses are separated by commas.
+func getClient(cmd *cobra.Command) *pdhttp.Client {
 	p, err := cmd.Flags().GetString("pd")
 	if err != nil {
 		fmt.Println("Get pd address error,should set flag with '-u'")
 		os.Exit(1)
 	}
-
-	u, err := url.Parse(p)
-	if err != nil {
-		fmt.Println("address is wrong format,should like 'http://127.0.0.1:2379'")
-	}
-	if u.Scheme == "" {
-		u.Scheme = "http"
-	}
-	s := fmt.Sprintf("%s/%s", u, prefix)
-	return s
-}
-
-func printResponseError(r *http.Response) {
-	fmt.Printf("[%d]:", r.StatusCode)
-	io.Copy(os.Stdout, r.Body)
-}
-
-func postJSON(cmd *cobra.Command, prefix string, input map[string]interface{}) {
-	data, err := json.Marshal(input)
+	client, err := pdhttp.NewClient(strings.Split(p, ","), security, pdhttp.WithToken(authToken))
 	if err != nil {
 		fmt.Println(err)
-		return
+		os.Exit(1)
 	}
+	return client
+}
 
-	url := getAddressFromCmd(cmd, prefix)
-	r, err := dialClient.Post(url, "application/json", bytes.NewBuffer(data))
+// printJSON prints the value as indented JSON like the responses of the PD
+// HTTP API.
+func printJSON(v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	defer r.Body.Close()
-
-	if r.StatusCode != http.StatusOK {
-		printResponseError(r)
-	}
+	fmt.Println(string(data))
 }
 
 // UsageTemplate will used to generate a help information
@@ -15,13 +15,9 @@ package command
 
 import (
 	"fmt"
-	"net/http"
 
 	"github.com/spf13/cobra"
-)
-
-var (
-	healthPrefix = "pd/health"
+	"golang.org/x/net/context"
 )
 
 // NewHealthCommand return a health subcommand of rootCmd
@@ -35,10 +31,10 @@ func NewHealthCommand() *cobra.Command {
 }
 
 func showHealthCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, healthPrefix, http.MethodGet)
+	healths, err := getClient(cmd).GetHealth(context.Background())
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(healths)
 }
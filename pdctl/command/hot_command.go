@@ -15,15 +15,9 @@ package command
 
 import (
 	"fmt"
-	"net/http"
 
 	"github.com/spf13/cobra"
-)
-
-const (
-	hotReadRegionsPrefix  = "pd/api/v1/hotspot/regions/read"
-	hotWriteRegionsPrefix = "pd/api/v1/hotspot/regions/write"
-	hotStoresPrefix       = "pd/api/v1/hotspot/stores"
+	"golang.org/x/net/context"
 )
 
 // NewHotSpotCommand return a hot subcommand of rootCmd
@@ -49,12 +43,12 @@ func NewHotWriteRegionCommand() *cobra.Command {
 }
 
 func showHotWriteRegionsCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, hotWriteRegionsPrefix, http.MethodGet)
+	infos, err := getClient(cmd).GetHotWriteRegions(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get hotspot: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(infos)
 }
 
 // NewHotReadRegionCommand return a hot read regions subcommand of hotSpotCmd
@@ -68,12 +62,12 @@ func NewHotReadRegionCommand() *cobra.Command {
 }
 
 func showHotReadRegionsCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, hotReadRegionsPrefix, http.MethodGet)
+	infos, err := getClient(cmd).GetHotReadRegions(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get hotspot: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(infos)
 }
 
 // NewHotStoreCommand return a hot stores subcommand of hotSpotCmd
@@ -87,10 +81,10 @@ func NewHotStoreCommand() *cobra.Command {
 }
 
 func showHotStoresCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, hotStoresPrefix, http.MethodGet)
+	stats, err := getClient(cmd).GetHotStores(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get hotspot: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(stats)
 }
@@ -15,14 +15,9 @@ package command
 
 import (
 	"fmt"
-	"net/http"
 
 	"github.com/spf13/cobra"
-)
-
-var (
-	labelsPrefix      = "pd/api/v1/labels"
-	labelsStorePrefix = "pd/api/v1/labels/stores"
+	"golang.org/x/net/context"
 )
 
 // NewLabelCommand return a member subcommand of rootCmd
@@ -47,12 +42,12 @@ func NewLabelListStoresCommand() *cobra.Command {
 }
 
 func showLabelsCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, labelsPrefix, http.MethodGet)
+	labels, err := getClient(cmd).GetLabels(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get labels: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(labels)
 }
 
 func getValue(args []string, i int) string {
@@ -67,13 +62,10 @@ func showLabelListStoresCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: label store name [value]")
 		return
 	}
-	namePrefix := fmt.Sprintf("name=%s", getValue(args, 0))
-	valuePrefix := fmt.Sprintf("value=%s", getValue(args, 1))
-	prefix := fmt.Sprintf("%s?%s&%s", labelsStorePrefix, namePrefix, valuePrefix)
-	r, err := doRequest(cmd, prefix, http.MethodGet)
+	stores, err := getClient(cmd).GetStoresByLabel(context.Background(), getValue(args, 0), getValue(args, 1))
 	if err != nil {
 		fmt.Printf("Failed to get stores through label: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(stores)
 }
@@ -14,16 +14,10 @@
 package command
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"net/http"
 
 	"github.com/spf13/cobra"
-)
-
-var (
-	logPrefix = "pd/api/v1/log"
+	"golang.org/x/net/context"
 )
 
 // NewLogCommand New a log subcommand of the rootCmd
@@ -37,24 +31,12 @@ func NewLogCommand() *cobra.Command {
 }
 
 func logCommandFunc(cmd *cobra.Command, args []string) {
-	var err error
 	if len(args) != 1 {
 		fmt.Println(cmd.UsageString())
 		return
 	}
 
-	data, err := json.Marshal(args[0])
-	if err != nil {
-		fmt.Printf("Failed to set log level: %s\n", err)
-		return
-	}
-	req, err := getRequest(cmd, logPrefix, http.MethodPost, "application/json", bytes.NewBuffer(data))
-	if err != nil {
-		fmt.Printf("Failed to set log level: %s\n", err)
-		return
-	}
-	_, err = dail(req)
-	if err != nil {
+	if err := getClient(cmd).SetLogLevel(context.Background(), args[0]); err != nil {
 		fmt.Printf("Failed to set log level: %s\n", err)
 		return
 	}
@@ -14,18 +14,11 @@
 package command
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"strconv"
 
 	"github.com/spf13/cobra"
-)
-
-var (
-	membersPrefix      = "pd/api/v1/members"
-	leaderMemberPrefix = "pd/api/v1/leader"
+	"golang.org/x/net/context"
 )
 
 // NewMemberCommand return a member subcommand of rootCmd
@@ -90,12 +83,12 @@ func NewLeaderMemberCommand() *cobra.Command {
 }
 
 func showMemberCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, membersPrefix, http.MethodGet)
+	members, err := getClient(cmd).GetMembers(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get pd members: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(members)
 }
 
 func deleteMemberByNameCommandFunc(cmd *cobra.Command, args []string) {
@@ -103,8 +96,7 @@ func deleteMemberByNameCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: member delete <member_name>")
 		return
 	}
-	prefix := membersPrefix + "/name/" + args[0]
-	_, err := doRequest(cmd, prefix, http.MethodDelete)
+	err := getClient(cmd).DeleteMemberByName(context.Background(), args[0])
 	if err != nil {
 		fmt.Printf("Failed to delete member %s: %s\n", args[0], err)
 		return
@@ -117,8 +109,12 @@ func deleteMemberByIDCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: member delete id <member_id>")
 		return
 	}
-	prefix := membersPrefix + "/id/" + args[0]
-	_, err := doRequest(cmd, prefix, http.MethodDelete)
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Println("member_id should be a number")
+		return
+	}
+	err = getClient(cmd).DeleteMemberByID(context.Background(), id)
 	if err != nil {
 		fmt.Printf("Failed to delete member %s: %s\n", args[0], err)
 		return
@@ -127,17 +123,16 @@ func deleteMemberByIDCommandFunc(cmd *cobra.Command, args []string) {
 }
 
 func getLeaderMemberCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, leaderMemberPrefix, http.MethodGet)
+	leader, err := getClient(cmd).GetLeader(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get the leader of pd members: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(leader)
 }
 
 func resignLeaderCommandFunc(cmd *cobra.Command, args []string) {
-	prefix := leaderMemberPrefix + "/resign"
-	_, err := doRequest(cmd, prefix, http.MethodPost)
+	err := getClient(cmd).ResignLeader(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to resign: %s\n", err)
 		return
@@ -150,8 +145,7 @@ func transferPDLeaderCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: leader transfer <member_name>")
 		return
 	}
-	prefix := leaderMemberPrefix + "/transfer/" + args[0]
-	_, err := doRequest(cmd, prefix, http.MethodPost)
+	err := getClient(cmd).TransferLeader(context.Background(), args[0])
 	if err != nil {
 		fmt.Printf("Failed to trasfer leadership: %s\n", err)
 		return
@@ -164,20 +158,12 @@ func setLeaderPriorityFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: leader_priority <member_name> <priority>")
 		return
 	}
-	prefix := membersPrefix + "/name/" + args[0]
 	priority, err := strconv.ParseFloat(args[1], 64)
 	if err != nil {
 		fmt.Printf("failed to parse priority: %v\n", err)
 		return
 	}
-	data := map[string]interface{}{"leader-priority": priority}
-	reqData, _ := json.Marshal(data)
-	req, err := getRequest(cmd, prefix, http.MethodPost, "application/json", bytes.NewBuffer(reqData))
-	if err != nil {
-		fmt.Printf("failed to set leader priority: %v\n", err)
-		return
-	}
-	_, err = dail(req)
+	err = getClient(cmd).SetMemberLeaderPriority(context.Background(), args[0], int(priority))
 	if err != nil {
 		fmt.Printf("failed to set leader priority: %v\n", err)
 		return
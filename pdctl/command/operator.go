@@ -15,15 +15,11 @@ package command
 
 import (
 	"fmt"
-	"net/http"
 	"strconv"
 
 	"github.com/juju/errors"
 	"github.com/spf13/cobra"
-)
-
-var (
-	operatorsPrefix = "pd/api/v1/operators"
+	"golang.org/x/net/context"
 )
 
 // NewOperatorCommand returns a operator command.
@@ -49,22 +45,23 @@ func NewShowOperatorCommand() *cobra.Command {
 }
 
 func showOperatorCommandFunc(cmd *cobra.Command, args []string) {
-	var path string
+	var (
+		ops []string
+		err error
+	)
 	if len(args) == 0 {
-		path = operatorsPrefix
+		ops, err = getClient(cmd).GetOperators(context.Background())
 	} else if len(args) == 1 {
-		path = fmt.Sprintf("%s?kind=%s", operatorsPrefix, args[0])
+		ops, err = getClient(cmd).GetOperatorsByKind(context.Background(), args[0])
 	} else {
 		fmt.Println(cmd.UsageString())
 		return
 	}
-
-	r, err := doRequest(cmd, path, http.MethodGet)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(ops)
 }
 
 // NewAddOperatorCommand returns a command to add operators.
@@ -110,7 +107,7 @@ func transferLeaderCommandFunc(cmd *cobra.Command, args []string) {
 	input["name"] = cmd.Name()
 	input["region_id"] = ids[0]
 	input["to_store_id"] = ids[1]
-	postJSON(cmd, operatorsPrefix, input)
+	createOperator(cmd, input)
 }
 
 // NewTransferRegionCommand returns a command to transfer region.
@@ -139,7 +136,7 @@ func transferRegionCommandFunc(cmd *cobra.Command, args []string) {
 	input["name"] = cmd.Name()
 	input["region_id"] = ids[0]
 	input["to_store_ids"] = ids[1:]
-	postJSON(cmd, operatorsPrefix, input)
+	createOperator(cmd, input)
 }
 
 // NewTransferPeerCommand returns a command to transfer region.
@@ -169,7 +166,7 @@ func transferPeerCommandFunc(cmd *cobra.Command, args []string) {
 	input["region_id"] = ids[0]
 	input["from_store_id"] = ids[1]
 	input["to_store_id"] = ids[2]
-	postJSON(cmd, operatorsPrefix, input)
+	createOperator(cmd, input)
 }
 
 // NewAddPeerCommand returns a command to add region peer.
@@ -198,7 +195,7 @@ func addPeerCommandFunc(cmd *cobra.Command, args []string) {
 	input["name"] = cmd.Name()
 	input["region_id"] = ids[0]
 	input["store_id"] = ids[1]
-	postJSON(cmd, operatorsPrefix, input)
+	createOperator(cmd, input)
 }
 
 // NewMergeRegionCommand returns a command to merge two regions.
@@ -227,7 +224,7 @@ func mergeRegionCommandFunc(cmd *cobra.Command, args []string) {
 	input["name"] = cmd.Name()
 	input["source_region_id"] = ids[0]
 	input["target_region_id"] = ids[1]
-	postJSON(cmd, operatorsPrefix, input)
+	createOperator(cmd, input)
 }
 
 // NewRemovePeerCommand returns a command to add region peer.
@@ -256,7 +253,7 @@ func removePeerCommandFunc(cmd *cobra.Command, args []string) {
 	input["name"] = cmd.Name()
 	input["region_id"] = ids[0]
 	input["store_id"] = ids[1]
-	postJSON(cmd, operatorsPrefix, input)
+	createOperator(cmd, input)
 }
 
 // NewSplitRegionCommand returns a command to split a region.
@@ -295,7 +292,7 @@ func splitRegionCommandFunc(cmd *cobra.Command, args []string) {
 	input["name"] = cmd.Name()
 	input["region_id"] = ids[0]
 	input["policy"] = policy
-	postJSON(cmd, operatorsPrefix, input)
+	createOperator(cmd, input)
 }
 
 // NewScatterRegionCommand returns a command to scatter a region.
@@ -323,7 +320,7 @@ func scatterRegionCommandFunc(cmd *cobra.Command, args []string) {
 	input := make(map[string]interface{})
 	input["name"] = cmd.Name()
 	input["region_id"] = ids[0]
-	postJSON(cmd, operatorsPrefix, input)
+	createOperator(cmd, input)
 }
 
 // NewRemoveOperatorCommand returns a command to remove operators.
@@ -342,14 +339,24 @@ func removeOperatorCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	path := operatorsPrefix + "/" + args[0]
-	_, err := doRequest(cmd, path, http.MethodDelete)
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Println("region_id should be a number")
+		return
+	}
+	err = getClient(cmd).DeleteOperator(context.Background(), id)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 }
 
+func createOperator(cmd *cobra.Command, input map[string]interface{}) {
+	if err := getClient(cmd).CreateOperator(context.Background(), input); err != nil {
+		fmt.Println(err)
+	}
+}
+
 func parseUint64s(args []string) ([]uint64, error) {
 	results := make([]uint64, 0, len(args))
 	for _, arg := range args {
@@ -15,10 +15,10 @@ package command
 
 import (
 	"fmt"
-	"net/http"
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
 )
 
 // NewPingCommand return a ping subcommand of rootCmd
@@ -33,8 +33,7 @@ func NewPingCommand() *cobra.Command {
 
 func showPingCommandFunc(cmd *cobra.Command, args []string) {
 	start := time.Now()
-	_, err := doRequest(cmd, pingPrefix, http.MethodGet)
-	if err != nil {
+	if err := getClient(cmd).Ping(context.Background()); err != nil {
 		fmt.Println(err)
 		return
 	}
@@ -15,24 +15,19 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
+	"net/url"
 	"os/exec"
 	"strconv"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
 )
 
-var (
-	regionsPrefix          = "pd/api/v1/regions"
-	regionsCheckPrefix     = "pd/api/v1/regions/check"
-	regionsWriteflowPrefix = "pd/api/v1/regions/writeflow"
-	regionsReadflowPrefix  = "pd/api/v1/regions/readflow"
-	regionsSiblingPrefix   = "pd/api/v1/regions/sibling"
-	regionIDPrefix         = "pd/api/v1/region/id"
-	regionKeyPrefix        = "pd/api/v1/region/key"
-)
+// defaultRegionLimit is the number of the regions shown by default.
+const defaultRegionLimit = 16
 
 // NewRegionCommand return a region subcommand of rootCmd
 func NewRegionCommand() *cobra.Command {
@@ -44,6 +39,8 @@ func NewRegionCommand() *cobra.Command {
 	r.AddCommand(NewRegionWithKeyCommand())
 	r.AddCommand(NewRegionWithCheckCommand())
 	r.AddCommand(NewRegionWithSiblingCommand())
+	r.AddCommand(NewRegionHistoryCommand())
+	r.AddCommand(NewRegionScanCommand())
 
 	topRead := &cobra.Command{
 		Use:   "topread <limit>",
@@ -64,59 +61,68 @@ func NewRegionCommand() *cobra.Command {
 }
 
 func showRegionCommandFunc(cmd *cobra.Command, args []string) {
-	prefix := regionsPrefix
+	var (
+		res interface{}
+		err error
+	)
 	if len(args) == 1 {
-		if _, err := strconv.Atoi(args[0]); err != nil {
+		id, parseErr := strconv.ParseUint(args[0], 10, 64)
+		if parseErr != nil {
 			fmt.Println("region_id should be a number")
 			return
 		}
-		prefix = regionIDPrefix + "/" + args[0]
+		res, err = getClient(cmd).GetRegionByID(context.Background(), id)
+	} else {
+		res, err = getClient(cmd).GetRegions(context.Background())
 	}
-	r, err := doRequest(cmd, prefix, http.MethodGet)
 	if err != nil {
 		fmt.Printf("Failed to get region: %s\n", err)
 		return
 	}
 	if flag := cmd.Flag("jq"); flag != nil && flag.Value.String() != "" {
-		printWithJQFilter(r, flag.Value.String())
+		printWithJQFilter(res, flag.Value.String())
 		return
 	}
 
-	fmt.Println(r)
+	printJSON(res)
 }
 
 func showRegionTopWriteCommandFunc(cmd *cobra.Command, args []string) {
-	prefix := regionsWriteflowPrefix
-	if len(args) == 1 {
-		if _, err := strconv.Atoi(args[0]); err != nil {
-			fmt.Println("limit should be a number")
-			return
-		}
-		prefix += "?limit=" + args[0]
+	limit, ok := parseRegionLimit(args)
+	if !ok {
+		return
 	}
-	r, err := doRequest(cmd, prefix, http.MethodGet)
+	regions, err := getClient(cmd).GetTopWriteFlowRegions(context.Background(), limit)
 	if err != nil {
 		fmt.Printf("Failed to get regions: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(regions)
 }
 
 func showRegionTopReadCommandFunc(cmd *cobra.Command, args []string) {
-	prefix := regionsReadflowPrefix
-	if len(args) == 1 {
-		if _, err := strconv.Atoi(args[0]); err != nil {
-			fmt.Println("limit should be a number")
-			return
-		}
-		prefix += "?limit=" + args[0]
+	limit, ok := parseRegionLimit(args)
+	if !ok {
+		return
 	}
-	r, err := doRequest(cmd, prefix, http.MethodGet)
+	regions, err := getClient(cmd).GetTopReadFlowRegions(context.Background(), limit)
 	if err != nil {
 		fmt.Printf("Failed to get regions: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(regions)
+}
+
+func parseRegionLimit(args []string) (int, bool) {
+	if len(args) == 0 {
+		return defaultRegionLimit, true
+	}
+	limit, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Println("limit should be a number")
+		return 0, false
+	}
+	return limit, true
 }
 
 // NewRegionWithKeyCommand return a region with key subcommand of regionCmd
@@ -155,14 +161,12 @@ func showRegionWithTableCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Error: unknown format")
 		return
 	}
-	// TODO: Deal with path escaped
-	prefix := regionKeyPrefix + "/" + key
-	r, err := doRequest(cmd, prefix, http.MethodGet)
+	region, err := getClient(cmd).GetRegionByKey(context.Background(), []byte(key))
 	if err != nil {
 		fmt.Printf("Failed to get region: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(region)
 
 }
 
@@ -191,7 +195,7 @@ func decodeProtobufText(text string) (string, error) {
 // NewRegionWithCheckCommand return a region with check subcommand of regionCmd
 func NewRegionWithCheckCommand() *cobra.Command {
 	r := &cobra.Command{
-		Use:   "check [miss-peer|extra-peer|down-peer|pending-peer|incorrect-ns]",
+		Use:   "check [miss-peer|extra-peer|down-peer|pending-peer|incorrect-ns|no-heartbeat|key-range]",
 		Short: "show the region with check specific status",
 		Run:   showRegionWithCheckCommandFunc,
 	}
@@ -203,14 +207,20 @@ func showRegionWithCheckCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println(cmd.UsageString())
 		return
 	}
-	state := args[0]
-	prefix := regionsCheckPrefix + "/" + state
-	r, err := doRequest(cmd, prefix, http.MethodGet)
+	var (
+		res interface{}
+		err error
+	)
+	if state := args[0]; state == "key-range" {
+		res, err = getClient(cmd).CheckRegionKeyRange(context.Background())
+	} else {
+		res, err = getClient(cmd).GetCheckedRegions(context.Background(), state)
+	}
 	if err != nil {
 		fmt.Printf("Failed to get region: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(res)
 }
 
 // NewRegionWithSiblingCommand return a region with check subcommand of regionCmd
@@ -228,17 +238,126 @@ func showRegionWithSiblingCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println(cmd.UsageString())
 		return
 	}
-	regionID := args[0]
-	prefix := regionsSiblingPrefix + "/" + regionID
-	r, err := doRequest(cmd, prefix, http.MethodGet)
+	regionID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Println("region_id should be a number")
+		return
+	}
+	regions, err := getClient(cmd).GetRegionSiblings(context.Background(), regionID)
 	if err != nil {
 		fmt.Printf("Failed to get region sibling: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(regions)
+}
+
+// NewRegionHistoryCommand return a region history subcommand of regionCmd
+func NewRegionHistoryCommand() *cobra.Command {
+	r := &cobra.Command{
+		Use:   "history <region_id>",
+		Short: "show the recent changes of specific region",
+		Run:   showRegionHistoryCommandFunc,
+	}
+	return r
+}
+
+func showRegionHistoryCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	regionID, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Println("region_id should be a number")
+		return
+	}
+	history, err := getClient(cmd).GetRegionHistory(context.Background(), regionID)
+	if err != nil {
+		fmt.Printf("Failed to get region history: %s\n", err)
+		return
+	}
+	printJSON(history)
+}
+
+// regionScanFlags are the flags of the scan command and the query parameters
+// they are mapped to.
+var regionScanFlags = map[string]string{
+	"start-key":    "start_key",
+	"end-key":      "end_key",
+	"limit":        "limit",
+	"store":        "store_id",
+	"leader-store": "leader_store_id",
+	"peer-state":   "peer_state",
+	"min-size":     "min_size",
+	"max-size":     "max_size",
+	"min-keys":     "min_keys",
+	"max-keys":     "max_keys",
+	"sort-by":      "sort_by",
+}
+
+// NewRegionScanCommand return a region scan subcommand of regionCmd
+func NewRegionScanCommand() *cobra.Command {
+	r := &cobra.Command{
+		Use:   "scan [--start-key=<key>] [--end-key=<key>] [--limit=<limit>] [--store=<store_id>] [--leader-store=<store_id>] [--peer-state=pending|down|learner] [--min-size=<mb>] [--max-size=<mb>] [--min-keys=<keys>] [--max-keys=<keys>] [--sort-by=size|keys|read|write] [--all]",
+		Short: "scan the regions in the key range page by page",
+		Run:   showRegionScanCommandFunc,
+	}
+	r.Flags().String("start-key", "", "the start key of the range")
+	r.Flags().String("end-key", "", "the end key of the range, the range reaches the end if empty")
+	r.Flags().Int("limit", 16, "the max number of the regions in a page")
+	r.Flags().String("store", "", "only the regions with a peer in the store")
+	r.Flags().String("leader-store", "", "only the regions with the leader in the store")
+	r.Flags().String("peer-state", "", "only the regions with a peer in the state, the peer in the store if --store is specified")
+	r.Flags().String("min-size", "", "only the regions whose approximate size in MB is at least it")
+	r.Flags().String("max-size", "", "only the regions whose approximate size in MB is at most it")
+	r.Flags().String("min-keys", "", "only the regions whose approximate keys are at least it")
+	r.Flags().String("max-keys", "", "only the regions whose approximate keys are at most it")
+	r.Flags().String("sort-by", "", "show the top regions in descending order of the field instead of the key order, a page scans at most 102400 regions")
+	r.Flags().Bool("all", false, "show all the pages")
+	r.Flags().String("next", "", "the continuation token returned by the previous page")
+	return r
 }
 
-func printWithJQFilter(data, filter string) {
+func showRegionScanCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	query := url.Values{}
+	for flag, param := range regionScanFlags {
+		if f := cmd.Flags().Lookup(flag); f != nil && (f.Changed || flag == "limit") {
+			query.Set(param, f.Value.String())
+		}
+	}
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		fmt.Println("Error: ", err)
+		return
+	}
+	next := cmd.Flags().Lookup("next").Value.String()
+	for {
+		if next != "" {
+			query.Set("next", next)
+		}
+		page, err := getClient(cmd).ListRegions(context.Background(), query)
+		if err != nil {
+			fmt.Printf("Failed to scan regions: %s\n", err)
+			return
+		}
+		printJSON(page)
+		if !all || page.Next == "" {
+			return
+		}
+		next = page.Next
+	}
+}
+
+func printWithJQFilter(v interface{}, filter string) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	cmd := exec.Command("jq", "-c", filter)
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -248,7 +367,7 @@ func printWithJQFilter(data, filter string) {
 
 	go func() {
 		defer stdin.Close()
-		io.WriteString(stdin, data)
+		stdin.Write(data)
 	}()
 
 	out, err := cmd.CombinedOutput()
@@ -15,15 +15,11 @@ package command
 
 import (
 	"fmt"
-	"net/http"
 	"net/url"
 	"strconv"
 
 	"github.com/spf13/cobra"
-)
-
-var (
-	schedulersPrefix = "pd/api/v1/schedulers"
+	"golang.org/x/net/context"
 )
 
 // NewSchedulerCommand returns a scheduler command.
@@ -54,12 +50,12 @@ func showSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	r, err := doRequest(cmd, schedulersPrefix, http.MethodGet)
+	schedulers, err := getClient(cmd).GetSchedulers(context.Background())
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(schedulers)
 }
 
 // NewAddSchedulerCommand returns a command to add scheduler.
@@ -108,10 +104,7 @@ func addSchedulerForStoreCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	input := make(map[string]interface{})
-	input["name"] = cmd.Name()
-	input["store_id"] = storeID
-	postJSON(cmd, schedulersPrefix, input)
+	addScheduler(cmd, map[string]interface{}{"store_id": storeID})
 }
 
 // NewShuffleLeaderSchedulerCommand returns a command to add a shuffle-leader-scheduler.
@@ -140,9 +133,7 @@ func addSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	input := make(map[string]interface{})
-	input["name"] = cmd.Name()
-	postJSON(cmd, schedulersPrefix, input)
+	addScheduler(cmd, nil)
 }
 
 // NewScatterRangeSchedulerCommand returns a command to add a scatter-range-scheduler.
@@ -161,12 +152,17 @@ func addSchedulerForScatterRangeCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	input := make(map[string]interface{})
-	input["name"] = cmd.Name()
-	input["start_key"] = url.QueryEscape(args[0])
-	input["end_key"] = url.QueryEscape(args[1])
-	input["range_name"] = args[2]
-	postJSON(cmd, schedulersPrefix, input)
+	addScheduler(cmd, map[string]interface{}{
+		"start_key":  url.QueryEscape(args[0]),
+		"end_key":    url.QueryEscape(args[1]),
+		"range_name": args[2],
+	})
+}
+
+func addScheduler(cmd *cobra.Command, args map[string]interface{}) {
+	if err := getClient(cmd).AddScheduler(context.Background(), cmd.Name(), args); err != nil {
+		fmt.Println(err)
+	}
 }
 
 // NewRemoveSchedulerCommand returns a command to remove scheduler.
@@ -185,8 +181,7 @@ func removeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	path := schedulersPrefix + "/" + args[0]
-	_, err := doRequest(cmd, path, http.MethodDelete)
+	err := getClient(cmd).RemoveScheduler(context.Background(), args[0])
 	if err != nil {
 		fmt.Println(err)
 		return
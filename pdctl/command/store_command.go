@@ -15,16 +15,10 @@ package command
 
 import (
 	"fmt"
-	"net/http"
-	"path"
 	"strconv"
 
 	"github.com/spf13/cobra"
-)
-
-var (
-	storesPrefix = "pd/api/v1/stores"
-	storePrefix  = "pd/api/v1/store/%s"
+	"golang.org/x/net/context"
 )
 
 // NewStoreCommand return a store subcommand of rootCmd
@@ -71,24 +65,29 @@ func NewSetStoreWeightCommand() *cobra.Command {
 }
 
 func showStoreCommandFunc(cmd *cobra.Command, args []string) {
-	prefix := storesPrefix
+	var (
+		res interface{}
+		err error
+	)
 	if len(args) == 1 {
-		if _, err := strconv.Atoi(args[0]); err != nil {
+		id, parseErr := strconv.ParseUint(args[0], 10, 64)
+		if parseErr != nil {
 			fmt.Println("store_id should be a number")
 			return
 		}
-		prefix = fmt.Sprintf(storePrefix, args[0])
+		res, err = getClient(cmd).GetStore(context.Background(), id)
+	} else {
+		res, err = getClient(cmd).GetStores(context.Background())
 	}
-	r, err := doRequest(cmd, prefix, http.MethodGet)
 	if err != nil {
 		fmt.Printf("Failed to get store: %s\n", err)
 		return
 	}
 	if flag := cmd.Flag("jq"); flag != nil && flag.Value.String() != "" {
-		printWithJQFilter(r, flag.Value.String())
+		printWithJQFilter(res, flag.Value.String())
 		return
 	}
-	fmt.Println(r)
+	printJSON(res)
 }
 
 func deleteStoreCommandFunc(cmd *cobra.Command, args []string) {
@@ -96,12 +95,12 @@ func deleteStoreCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: store delete <store_id>")
 		return
 	}
-	if _, err := strconv.Atoi(args[0]); err != nil {
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
 		fmt.Println("store_id should be a number")
 		return
 	}
-	prefix := fmt.Sprintf(storePrefix, args[0])
-	_, err := doRequest(cmd, prefix, http.MethodDelete)
+	err = getClient(cmd).DeleteStore(context.Background(), id)
 	if err != nil {
 		fmt.Printf("Failed to delete store %s: %s\n", args[0], err)
 		return
@@ -114,12 +113,14 @@ func labelStoreCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: store label <store_id> <key> <value>")
 		return
 	}
-	if _, err := strconv.Atoi(args[0]); err != nil {
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
 		fmt.Println("store_id should be a number")
 		return
 	}
-	prefix := fmt.Sprintf(path.Join(storePrefix, "label"), args[0])
-	postJSON(cmd, prefix, map[string]interface{}{args[1]: args[2]})
+	if err = getClient(cmd).SetStoreLabels(context.Background(), id, map[string]string{args[1]: args[2]}); err != nil {
+		fmt.Println(err)
+	}
 }
 
 func setStoreWeightCommandFunc(cmd *cobra.Command, args []string) {
@@ -127,6 +128,11 @@ func setStoreWeightCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: store weight <store_id> <leader_weight> <region_weight>")
 		return
 	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		fmt.Println("store_id should be a number")
+		return
+	}
 	leader, err := strconv.ParseFloat(args[1], 64)
 	if err != nil || leader < 0 {
 		fmt.Println("leader_weight should be a number that >= 0.")
@@ -137,9 +143,7 @@ func setStoreWeightCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("region_weight should be a number that >= 0")
 		return
 	}
-	prefix := fmt.Sprintf(path.Join(storePrefix, "weight"), args[0])
-	postJSON(cmd, prefix, map[string]interface{}{
-		"leader": leader,
-		"region": region,
-	})
+	if err = getClient(cmd).SetStoreWeight(context.Background(), id, leader, region); err != nil {
+		fmt.Println(err)
+	}
 }
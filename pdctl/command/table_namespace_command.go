@@ -15,17 +15,10 @@ package command
 
 import (
 	"fmt"
-	"net/http"
 	"strconv"
 
 	"github.com/spf13/cobra"
-)
-
-const (
-	namespacesPrefix     = "pd/api/v1/classifier/table/namespaces"
-	namespaceTablePrefix = "pd/api/v1/classifier/table/namespaces/table"
-	namespaceMetaPrefix  = "pd/api/v1/classifier/table/namespaces/meta"
-	storeNsPrefix        = "pd/api/v1/classifier/table/store_ns/%s"
+	"golang.org/x/net/context"
 )
 
 // NewTableNamespaceCommand return a table namespace sub-command of rootCmd
@@ -76,12 +69,12 @@ func NewRemoveTableIDCommand() *cobra.Command {
 }
 
 func showNamespaceCommandFunc(cmd *cobra.Command, args []string) {
-	r, err := doRequest(cmd, namespacesPrefix, http.MethodGet)
+	namespaces, err := getClient(cmd).GetTableNamespaces(context.Background())
 	if err != nil {
 		fmt.Printf("Failed to get the namespace information: %s\n", err)
 		return
 	}
-	fmt.Println(r)
+	printJSON(namespaces)
 }
 
 func createNamespaceCommandFunc(cmd *cobra.Command, args []string) {
@@ -90,11 +83,9 @@ func createNamespaceCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	input := map[string]interface{}{
-		"namespace": args[0],
+	if err := getClient(cmd).CreateTableNamespace(context.Background(), args[0]); err != nil {
+		fmt.Println(err)
 	}
-
-	postJSON(cmd, namespacesPrefix, input)
 }
 
 func addTableCommandFunc(cmd *cobra.Command, args []string) {
@@ -102,18 +93,15 @@ func addTableCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: namespace add <name> <table_id>")
 		return
 	}
-	if _, err := strconv.Atoi(args[1]); err != nil {
+	tableID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
 		fmt.Println("table_id shoud be a number")
 		return
 	}
 
-	input := map[string]interface{}{
-		"namespace": args[0],
-		"table_id":  args[1],
-		"action":    "add",
+	if err = getClient(cmd).AddNamespaceTableID(context.Background(), args[0], tableID); err != nil {
+		fmt.Println(err)
 	}
-
-	postJSON(cmd, namespaceTablePrefix, input)
 }
 
 func removeTableCommandFunc(cmd *cobra.Command, args []string) {
@@ -121,18 +109,15 @@ func removeTableCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: namespace remove <name> <table_id>")
 		return
 	}
-	if _, err := strconv.Atoi(args[1]); err != nil {
+	tableID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
 		fmt.Println("table_id shoud be a number")
 		return
 	}
 
-	input := map[string]interface{}{
-		"namespace": args[0],
-		"table_id":  args[1],
-		"action":    "remove",
+	if err = getClient(cmd).RemoveNamespaceTableID(context.Background(), args[0], tableID); err != nil {
+		fmt.Println(err)
 	}
-
-	postJSON(cmd, namespaceTablePrefix, input)
 }
 
 // NewSetNamespaceStoreCommand returns a set subcommand of storeNsCmd.
@@ -162,16 +147,14 @@ func setNamespaceStoreCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: namespace set_ns <store_id> <namespace>")
 		return
 	}
-	_, err := strconv.Atoi(args[0])
+	storeID, err := strconv.ParseUint(args[0], 10, 64)
 	if err != nil {
 		fmt.Println("store_id should be a number")
 		return
 	}
-	prefix := fmt.Sprintf(storeNsPrefix, args[0])
-	postJSON(cmd, prefix, map[string]interface{}{
-		"namespace": args[1],
-		"action":    "add",
-	})
+	if err = getClient(cmd).AddNamespaceStore(context.Background(), storeID, args[1]); err != nil {
+		fmt.Println(err)
+	}
 }
 
 func removeNamespaceStoreCommandFunc(cmd *cobra.Command, args []string) {
@@ -179,16 +162,14 @@ func removeNamespaceStoreCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: namespace rm_ns <store_id> <namespace>")
 		return
 	}
-	_, err := strconv.Atoi(args[0])
+	storeID, err := strconv.ParseUint(args[0], 10, 64)
 	if err != nil {
 		fmt.Println("store_id should be a number")
 		return
 	}
-	prefix := fmt.Sprintf(storeNsPrefix, args[0])
-	postJSON(cmd, prefix, map[string]interface{}{
-		"namespace": args[1],
-		"action":    "remove",
-	})
+	if err = getClient(cmd).RemoveNamespaceStore(context.Background(), storeID, args[1]); err != nil {
+		fmt.Println(err)
+	}
 }
 
 func newSetMetaNamespaceCommand() *cobra.Command {
@@ -212,11 +193,9 @@ func setMetaNamespaceCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: set_meta <namespace>")
 		return
 	}
-	input := map[string]interface{}{
-		"namespace": args[0],
-		"action":    "add",
+	if err := getClient(cmd).AddNamespaceMeta(context.Background(), args[0]); err != nil {
+		fmt.Println(err)
 	}
-	postJSON(cmd, namespaceMetaPrefix, input)
 }
 
 func removeMetaNamespaceCommandFunc(cmd *cobra.Command, args []string) {
@@ -224,9 +203,7 @@ func removeMetaNamespaceCommandFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("Usage: rm_meta <namespace>")
 		return
 	}
-	input := map[string]interface{}{
-		"namespace": args[0],
-		"action":    "remove",
+	if err := getClient(cmd).RemoveNamespaceMeta(context.Background(), args[0]); err != nil {
+		fmt.Println(err)
 	}
-	postJSON(cmd, namespaceMetaPrefix, input)
 }
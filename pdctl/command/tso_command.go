@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
 )
 
 const (
@@ -33,9 +34,41 @@ func NewTSOCommand() *cobra.Command {
 		Short: "parse TSO to the system and logic time",
 		Run:   showTSOCommandFunc,
 	}
+	cmd.AddCommand(NewNewTSOCommand())
 	return cmd
 }
 
+// NewNewTSOCommand return a new subcommand of tsoCmd
+func NewNewTSOCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "new [count]",
+		Short: "allocate new timestamps from PD, they are consecutive from the returned one",
+		Run:   newTSOCommandFunc,
+	}
+	return cmd
+}
+
+func newTSOCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		fmt.Println(cmd.UsageString())
+		return
+	}
+	count := uint64(1)
+	if len(args) == 1 {
+		var err error
+		if count, err = strconv.ParseUint(args[0], 10, 32); err != nil {
+			fmt.Println("count should be a number")
+			return
+		}
+	}
+	ts, err := getClient(cmd).GetTSO(context.Background(), uint32(count))
+	if err != nil {
+		fmt.Printf("Failed to get new TSO: %s\n", err)
+		return
+	}
+	printJSON(ts)
+}
+
 func showTSOCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 1 {
 		fmt.Println("Usage: tso <timestamp>")
@@ -26,6 +26,7 @@ type CommandFlags struct {
 	CAPath   string
 	CertPath string
 	KeyPath  string
+	Token    string
 }
 
 var (
@@ -42,10 +43,11 @@ func Start(args []string) {
 		Use:   "pdctl",
 		Short: "Placement Driver control",
 	}
-	rootCmd.PersistentFlags().StringVarP(&commandFlags.URL, "pd", "u", "http://127.0.0.1:2379", "pd address")
+	rootCmd.PersistentFlags().StringVarP(&commandFlags.URL, "pd", "u", "http://127.0.0.1:2379", "pd addresses separated by commas")
 	rootCmd.Flags().StringVar(&commandFlags.CAPath, "cacert", "", "path of file that contains list of trusted SSL CAs.")
 	rootCmd.Flags().StringVar(&commandFlags.CertPath, "cert", "", "path of file that contains X509 certificate in PEM format.")
 	rootCmd.Flags().StringVar(&commandFlags.KeyPath, "key", "", "path of file that contains X509 key in PEM format.")
+	rootCmd.Flags().StringVar(&commandFlags.Token, "token", "", "bearer token of the pd HTTP API.")
 	rootCmd.AddCommand(
 		command.NewConfigCommand(),
 		command.NewRegionCommand(),
@@ -62,6 +64,8 @@ func Start(args []string) {
 		command.NewTableNamespaceCommand(),
 		command.NewHealthCommand(),
 		command.NewLogCommand(),
+		command.NewServiceGCSafePointCommand(),
+		command.NewAlertCommand(),
 	)
 
 	rootCmd.SetArgs(args)
@@ -76,6 +80,8 @@ func Start(args []string) {
 		}
 	}
 
+	command.InitAuthToken(commandFlags.Token)
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(rootCmd.UsageString())
 	}
@@ -0,0 +1,262 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certutil
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+)
+
+// DefaultCheckInterval is the min interval between two checks of the files
+// when building the TLS configs.
+const DefaultCheckInterval = 10 * time.Second
+
+// Paths are the paths of the files of the CA and the key pair, the CA and
+// the key pair are optional.
+type Paths struct {
+	CAPath   string
+	CertPath string
+	KeyPath  string
+}
+
+// Reloader loads the CA and the key pair from the files, and loads them again
+// once the files change, so the certificates can be rotated online. Only the
+// new connections use the new certificates, the existing ones are kept.
+type Reloader struct {
+	mu            sync.RWMutex
+	paths         Paths
+	caData        []byte
+	certData      []byte
+	keyData       []byte
+	pool          *x509.CertPool
+	cert          *tls.Certificate
+	caExpiry      time.Time
+	certExpiry    time.Time
+	lastCheck     time.Time
+	checkInterval time.Duration
+}
+
+// NewReloader creates a Reloader with the certificates loaded.
+func NewReloader(paths Paths) (*Reloader, error) {
+	r := &Reloader{checkInterval: DefaultCheckInterval}
+	if err := r.SetPaths(paths); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r, nil
+}
+
+// SetPaths loads the certificates from the other files. The files are
+// checked afterwards instead of the previous ones.
+func (r *Reloader) SetPaths(paths Paths) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.paths
+	r.paths = paths
+	if _, err := r.reloadLocked(true); err != nil {
+		r.paths = old
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// Reload loads the certificates again if the files change, it returns true
+// if they are reloaded. The certificates in use are kept if any file is
+// invalid.
+func (r *Reloader) Reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reloadLocked(false)
+}
+
+func (r *Reloader) reloadLocked(force bool) (bool, error) {
+	r.lastCheck = time.Now()
+	caData, err := readFile(r.paths.CAPath)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	certData, err := readFile(r.paths.CertPath)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	keyData, err := readFile(r.paths.KeyPath)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if !force && bytes.Equal(caData, r.caData) && bytes.Equal(certData, r.certData) && bytes.Equal(keyData, r.keyData) {
+		return false, nil
+	}
+
+	var (
+		pool                 *x509.CertPool
+		cert                 *tls.Certificate
+		caExpiry, certExpiry time.Time
+	)
+	if len(caData) != 0 {
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return false, errors.Errorf("failed to append ca certs from %s", r.paths.CAPath)
+		}
+		if caExpiry, err = earliestExpiry(caData); err != nil {
+			return false, errors.Annotatef(err, "invalid ca certs in %s", r.paths.CAPath)
+		}
+	}
+	if len(certData) != 0 || len(keyData) != 0 {
+		pair, err := tls.X509KeyPair(certData, keyData)
+		if err != nil {
+			return false, errors.Annotatef(err, "could not load key pair from %s and %s", r.paths.CertPath, r.paths.KeyPath)
+		}
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		pair.Leaf = leaf
+		cert, certExpiry = &pair, leaf.NotAfter
+	}
+
+	r.caData, r.certData, r.keyData = caData, certData, keyData
+	r.pool, r.cert = pool, cert
+	r.caExpiry, r.certExpiry = caExpiry, certExpiry
+	return true, nil
+}
+
+func readFile(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return ioutil.ReadFile(path)
+}
+
+// earliestExpiry returns the earliest expiry time of the certificates.
+func earliestExpiry(data []byte) (time.Time, error) {
+	var expiry time.Time
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return expiry, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, errors.Trace(err)
+		}
+		if expiry.IsZero() || cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+}
+
+// Expiry returns the expiry time of the CA and the certificate, the earliest
+// one of the CA certificates is returned. It is zero if there is no CA or
+// certificate.
+func (r *Reloader) Expiry() (ca time.Time, cert time.Time) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.caExpiry, r.certExpiry
+}
+
+// TLSConfig returns the TLS config with the current certificates, which can
+// be used by both the clients and the servers. The files are checked if not
+// checked in the check interval.
+func (r *Reloader) TLSConfig() *tls.Config {
+	r.mu.RLock()
+	stale := time.Since(r.lastCheck) >= r.checkInterval
+	r.mu.RUnlock()
+	if stale {
+		if _, err := r.Reload(); err != nil {
+			log.Errorf("failed to reload certificates, keep the previous ones: %v", err)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cfg := &tls.Config{
+		RootCAs:   r.pool,
+		ClientCAs: r.pool,
+	}
+	if r.cert != nil {
+		cfg.Certificates = []tls.Certificate{*r.cert}
+	}
+	if r.pool != nil {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg
+}
+
+// DialTLS connects to the address with the current certificates, it can be
+// used as the DialTLS of http.Transport.
+func (r *Reloader) DialTLS(network, addr string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cfg := r.TLSConfig()
+	cfg.ServerName = host
+	conn, err := tls.Dial(network, addr, cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return conn, nil
+}
+
+// TransportCredentials returns the gRPC credentials which handshake with the
+// current certificates.
+func (r *Reloader) TransportCredentials() credentials.TransportCredentials {
+	return &reloadingCreds{reloader: r}
+}
+
+type reloadingCreds struct {
+	reloader   *Reloader
+	serverName string
+}
+
+func (c *reloadingCreds) current() credentials.TransportCredentials {
+	cfg := c.reloader.TLSConfig()
+	cfg.ServerName = c.serverName
+	return credentials.NewTLS(cfg)
+}
+
+func (c *reloadingCreds) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (c *reloadingCreds) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ServerHandshake(conn)
+}
+
+func (c *reloadingCreds) Info() credentials.ProtocolInfo {
+	return credentials.NewTLS(&tls.Config{ServerName: c.serverName}).Info()
+}
+
+func (c *reloadingCreds) Clone() credentials.TransportCredentials {
+	return &reloadingCreds{reloader: c.reloader, serverName: c.serverName}
+}
+
+func (c *reloadingCreds) OverrideServerName(serverName string) error {
+	c.serverName = serverName
+	return nil
+}
@@ -0,0 +1,201 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+func TestCertUtil(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testReloaderSuite{})
+
+type testReloaderSuite struct {
+	dir    string
+	caCert *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	paths  Paths
+}
+
+func (s *testReloaderSuite) SetUpTest(c *C) {
+	var err error
+	s.dir, err = ioutil.TempDir("", "pd_certutil")
+	c.Assert(err, IsNil)
+	s.paths = Paths{
+		CAPath:   filepath.Join(s.dir, "ca.pem"),
+		CertPath: filepath.Join(s.dir, "cert.pem"),
+		KeyPath:  filepath.Join(s.dir, "key.pem"),
+	}
+
+	s.caKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(48 * time.Hour).Truncate(time.Second),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &s.caKey.PublicKey, s.caKey)
+	c.Assert(err, IsNil)
+	s.caCert, err = x509.ParseCertificate(der)
+	c.Assert(err, IsNil)
+	writePEM(c, s.paths.CAPath, "CERTIFICATE", der)
+}
+
+func (s *testReloaderSuite) TearDownTest(c *C) {
+	os.RemoveAll(s.dir)
+}
+
+func writePEM(c *C, path, typ string, der []byte) {
+	c.Assert(ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600), IsNil)
+}
+
+// writeCert writes a key pair signed by the CA, which expires at notAfter.
+func (s *testReloaderSuite) writeCert(c *C, serial int64, notAfter time.Time) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "pd"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, s.caCert, &key.PublicKey, s.caKey)
+	c.Assert(err, IsNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, IsNil)
+	writePEM(c, s.paths.CertPath, "CERTIFICATE", der)
+	writePEM(c, s.paths.KeyPath, "EC PRIVATE KEY", keyDER)
+}
+
+func (s *testReloaderSuite) TestReload(c *C) {
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	s.writeCert(c, 2, expiry)
+	r, err := NewReloader(s.paths)
+	c.Assert(err, IsNil)
+	ca, cert := r.Expiry()
+	c.Assert(ca.Equal(s.caCert.NotAfter), IsTrue)
+	c.Assert(cert.Equal(expiry), IsTrue)
+
+	reloaded, err := r.Reload()
+	c.Assert(err, IsNil)
+	c.Assert(reloaded, IsFalse)
+
+	s.writeCert(c, 3, expiry.Add(time.Hour))
+	reloaded, err = r.Reload()
+	c.Assert(err, IsNil)
+	c.Assert(reloaded, IsTrue)
+	_, cert = r.Expiry()
+	c.Assert(cert.Equal(expiry.Add(time.Hour)), IsTrue)
+
+	// The previous certificates are kept if the files are invalid.
+	c.Assert(ioutil.WriteFile(s.paths.KeyPath, []byte("invalid"), 0600), IsNil)
+	_, err = r.Reload()
+	c.Assert(err, NotNil)
+	_, cert = r.Expiry()
+	c.Assert(cert.Equal(expiry.Add(time.Hour)), IsTrue)
+	c.Assert(r.TLSConfig().Certificates, HasLen, 1)
+
+	paths := s.paths
+	paths.CertPath = filepath.Join(s.dir, "missing.pem")
+	c.Assert(r.SetPaths(paths), NotNil)
+	c.Assert(r.paths, Equals, s.paths)
+}
+
+func (s *testReloaderSuite) TestRotate(c *C) {
+	s.writeCert(c, 2, time.Now().Add(time.Hour).Truncate(time.Second))
+	r, err := NewReloader(s.paths)
+	c.Assert(err, IsNil)
+	r.checkInterval = 0
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	l = tls.NewListener(l, &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.TLSConfig(), nil
+		},
+	})
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 1)
+				for {
+					if _, err := conn.Read(buf); err != nil {
+						return
+					}
+					if _, err := conn.Write(buf); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	serial := func(conn net.Conn) int64 {
+		tlsConn := conn.(*tls.Conn)
+		c.Assert(tlsConn.Handshake(), IsNil)
+		return tlsConn.ConnectionState().PeerCertificates[0].SerialNumber.Int64()
+	}
+	echo := func(conn net.Conn) {
+		_, err := conn.Write([]byte{1})
+		c.Assert(err, IsNil)
+		_, err = conn.Read(make([]byte, 1))
+		c.Assert(err, IsNil)
+	}
+
+	conn1, err := r.DialTLS("tcp", l.Addr().String())
+	c.Assert(err, IsNil)
+	defer conn1.Close()
+	c.Assert(serial(conn1), Equals, int64(2))
+	echo(conn1)
+
+	// The new connections use the new certificate, while the existing ones
+	// are kept.
+	s.writeCert(c, 3, time.Now().Add(2*time.Hour).Truncate(time.Second))
+	conn2, err := r.DialTLS("tcp", l.Addr().String())
+	c.Assert(err, IsNil)
+	defer conn2.Close()
+	c.Assert(serial(conn2), Equals, int64(3))
+	echo(conn1)
+	echo(conn2)
+}
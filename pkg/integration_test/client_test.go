@@ -50,6 +50,11 @@ func (s *integrationTestSuite) TestClientLeaderChange(c *C) {
 	}
 	cli, err := pd.NewClient(endpoints, pd.SecurityOption{})
 	c.Assert(err, IsNil)
+	leaderCh := make(chan string, 10)
+	cli.OnLeaderChange(func(newLeader, oldLeader string) {
+		c.Assert(newLeader, Not(Equals), oldLeader)
+		leaderCh <- newLeader
+	})
 
 	var p1, l1 int64
 	testutil.WaitUntil(c, func(c *C) bool {
@@ -63,12 +68,25 @@ func (s *integrationTestSuite) TestClientLeaderChange(c *C) {
 
 	leader := cluster.GetLeader()
 	s.waitLeader(c, cli.(client), cluster.GetServer(leader).GetConfig().ClientUrls)
+	members, leaderMember, err := cli.GetMembers(context.TODO())
+	c.Assert(err, IsNil)
+	c.Assert(members, HasLen, 3)
+	c.Assert(leaderMember.GetName(), Equals, leader)
 
 	err = cluster.GetServer(leader).Stop()
 	c.Assert(err, IsNil)
 	leader = cluster.WaitLeader()
 	c.Assert(leader, Not(Equals), "")
 	s.waitLeader(c, cli.(client), cluster.GetServer(leader).GetConfig().ClientUrls)
+	// The callback is notified of the new leader.
+	testutil.WaitUntil(c, func(c *C) bool {
+		select {
+		case newLeader := <-leaderCh:
+			return newLeader == cluster.GetServer(leader).GetConfig().ClientUrls
+		default:
+			return false
+		}
+	})
 
 	// Check TS won't fall back after leader changed.
 	testutil.WaitUntil(c, func(c *C) bool {
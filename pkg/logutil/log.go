@@ -247,6 +247,17 @@ func InitLogger(cfg *LogConfig) error {
 	return nil
 }
 
+// ReloadLogger applies the level and the format of the config to the
+// initialized logger, the file log can't be changed.
+func ReloadLogger(cfg *LogConfig) {
+	log.SetLevel(StringToLogLevel(cfg.Level))
+	format := cfg.Format
+	if format == "" {
+		format = defaultLogFormat
+	}
+	log.SetFormatter(stringToLogFormatter(format, cfg.DisableTimestamp))
+}
+
 // LogPanic logs the panic reason and stack, then exit the process.
 // Commonly used with a `defer`.
 func LogPanic() {
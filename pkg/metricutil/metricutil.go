@@ -14,6 +14,7 @@
 package metricutil
 
 import (
+	"sync"
 	"time"
 	"unicode"
 
@@ -57,31 +58,58 @@ func camelCaseToSnakeCase(str string) string {
 	return string(ret)
 }
 
-// prometheusPushClient pushs metrics to Prometheus Pushgateway.
-func prometheusPushClient(job, addr string, interval time.Duration) {
+var pushClient struct {
+	sync.Mutex
+	cfg     MetricConfig
+	running bool
+}
+
+// prometheusPushClient pushs metrics to Prometheus Pushgateway, it exits
+// once the push client is disabled.
+func prometheusPushClient() {
 	for {
+		pushClient.Lock()
+		cfg := pushClient.cfg
+		if !pushEnabled(&cfg) {
+			pushClient.running = false
+			pushClient.Unlock()
+			return
+		}
+		pushClient.Unlock()
+
 		err := push.FromGatherer(
-			job, push.HostnameGroupingKey(),
-			addr,
+			cfg.PushJob, push.HostnameGroupingKey(),
+			cfg.PushAddress,
 			prometheus.DefaultGatherer,
 		)
 		if err != nil {
 			log.Errorf("could not push metrics to Prometheus Pushgateway: %v", err)
 		}
 
-		time.Sleep(interval)
+		time.Sleep(cfg.PushInterval.Duration)
 	}
 }
 
-// Push metircs in background.
+func pushEnabled(cfg *MetricConfig) bool {
+	return cfg.PushInterval.Duration != zeroDuration && len(cfg.PushAddress) != 0
+}
+
+// Push metircs in background. It can be called again to change the config
+// of the running push client.
 func Push(cfg *MetricConfig) {
-	if cfg.PushInterval.Duration == zeroDuration || len(cfg.PushAddress) == 0 {
+	pushClient.Lock()
+	defer pushClient.Unlock()
+	pushClient.cfg = *cfg
+	if !pushEnabled(cfg) {
 		log.Info("disable Prometheus push client")
 		return
 	}
+	if pushClient.running {
+		log.Info("update Prometheus push client")
+		return
+	}
 
 	log.Info("start Prometheus push client")
-
-	interval := cfg.PushInterval.Duration
-	go prometheusPushClient(cfg.PushJob, cfg.PushAddress, interval)
+	pushClient.running = true
+	go prometheusPushClient()
 }
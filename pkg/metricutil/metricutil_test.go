@@ -84,3 +84,23 @@ func (s *testMetricsSuite) TestCoverage(c *C) {
 		Push(cfg)
 	}
 }
+
+func (s *testMetricsSuite) TestPushReload(c *C) {
+	cfg := &MetricConfig{
+		PushJob:      "j1",
+		PushAddress:  "127.0.0.1:9091",
+		PushInterval: typeutil.NewDuration(time.Hour),
+	}
+	Push(cfg)
+	cfg.PushJob = "j2"
+	Push(cfg)
+	pushClient.Lock()
+	c.Assert(pushClient.running, IsTrue)
+	c.Assert(pushClient.cfg.PushJob, Equals, "j2")
+	pushClient.Unlock()
+
+	Push(&MetricConfig{})
+	pushClient.Lock()
+	c.Assert(pushEnabled(&pushClient.cfg), IsFalse)
+	pushClient.Unlock()
+}
@@ -0,0 +1,1363 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: pdextpb.proto
+
+/*
+Package pdextpb is a generated protocol buffer package.
+
+It is generated from these files:
+
+	pdextpb.proto
+
+It has these top-level messages:
+
+	ScanRegionsRequest
+	ScanRegionsResponse
+	GetOperatorRequest
+	GetOperatorResponse
+*/
+package pdextpb
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import metapb "github.com/pingcap/kvproto/pkg/metapb"
+import pdpb "github.com/pingcap/kvproto/pkg/pdpb"
+import _ "github.com/gogo/protobuf/gogoproto"
+
+import context "golang.org/x/net/context"
+import grpc "google.golang.org/grpc"
+
+import io "io"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type ScanRegionsRequest struct {
+	Header   *pdpb.RequestHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	StartKey []byte              `protobuf:"bytes,2,opt,name=start_key,json=startKey,proto3" json:"start_key,omitempty"`
+	// limit is the max number of regions, it should be positive.
+	Limit int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// end_key is the end of the range, empty means scanning to the end.
+	EndKey []byte `protobuf:"bytes,4,opt,name=end_key,json=endKey,proto3" json:"end_key,omitempty"`
+}
+
+func (m *ScanRegionsRequest) Reset()                    { *m = ScanRegionsRequest{} }
+func (m *ScanRegionsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ScanRegionsRequest) ProtoMessage()               {}
+func (*ScanRegionsRequest) Descriptor() ([]byte, []int) { return fileDescriptorPdextpb, []int{0} }
+
+func (m *ScanRegionsRequest) GetHeader() *pdpb.RequestHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *ScanRegionsRequest) GetStartKey() []byte {
+	if m != nil {
+		return m.StartKey
+	}
+	return nil
+}
+
+func (m *ScanRegionsRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *ScanRegionsRequest) GetEndKey() []byte {
+	if m != nil {
+		return m.EndKey
+	}
+	return nil
+}
+
+type ScanRegionsResponse struct {
+	Header *pdpb.ResponseHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	// regions are in ascending key order.
+	Regions []*metapb.Region `protobuf:"bytes,2,rep,name=regions" json:"regions,omitempty"`
+	// leaders are the leaders of regions, a peer with zero id means no leader.
+	Leaders []*metapb.Peer `protobuf:"bytes,3,rep,name=leaders" json:"leaders,omitempty"`
+}
+
+func (m *ScanRegionsResponse) Reset()                    { *m = ScanRegionsResponse{} }
+func (m *ScanRegionsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ScanRegionsResponse) ProtoMessage()               {}
+func (*ScanRegionsResponse) Descriptor() ([]byte, []int) { return fileDescriptorPdextpb, []int{1} }
+
+func (m *ScanRegionsResponse) GetHeader() *pdpb.ResponseHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *ScanRegionsResponse) GetRegions() []*metapb.Region {
+	if m != nil {
+		return m.Regions
+	}
+	return nil
+}
+
+func (m *ScanRegionsResponse) GetLeaders() []*metapb.Peer {
+	if m != nil {
+		return m.Leaders
+	}
+	return nil
+}
+
+type GetOperatorRequest struct {
+	Header   *pdpb.RequestHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	RegionId uint64              `protobuf:"varint,2,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+}
+
+func (m *GetOperatorRequest) Reset()                    { *m = GetOperatorRequest{} }
+func (m *GetOperatorRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetOperatorRequest) ProtoMessage()               {}
+func (*GetOperatorRequest) Descriptor() ([]byte, []int) { return fileDescriptorPdextpb, []int{2} }
+
+func (m *GetOperatorRequest) GetHeader() *pdpb.RequestHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *GetOperatorRequest) GetRegionId() uint64 {
+	if m != nil {
+		return m.RegionId
+	}
+	return 0
+}
+
+// GetOperatorResponse is the status of the operator of a region, region_id is
+// 0 if the region has no operator.
+type GetOperatorResponse struct {
+	Header   *pdpb.ResponseHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	RegionId uint64               `protobuf:"varint,2,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	Desc     string               `protobuf:"bytes,3,opt,name=desc,proto3" json:"desc,omitempty"`
+	// status is one of "running", "timeout" and "finished".
+	Status string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Kind   string `protobuf:"bytes,5,opt,name=kind,proto3" json:"kind,omitempty"`
+}
+
+func (m *GetOperatorResponse) Reset()                    { *m = GetOperatorResponse{} }
+func (m *GetOperatorResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetOperatorResponse) ProtoMessage()               {}
+func (*GetOperatorResponse) Descriptor() ([]byte, []int) { return fileDescriptorPdextpb, []int{3} }
+
+func (m *GetOperatorResponse) GetHeader() *pdpb.ResponseHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *GetOperatorResponse) GetRegionId() uint64 {
+	if m != nil {
+		return m.RegionId
+	}
+	return 0
+}
+
+func (m *GetOperatorResponse) GetDesc() string {
+	if m != nil {
+		return m.Desc
+	}
+	return ""
+}
+
+func (m *GetOperatorResponse) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *GetOperatorResponse) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*ScanRegionsRequest)(nil), "pdextpb.ScanRegionsRequest")
+	proto.RegisterType((*ScanRegionsResponse)(nil), "pdextpb.ScanRegionsResponse")
+	proto.RegisterType((*GetOperatorRequest)(nil), "pdextpb.GetOperatorRequest")
+	proto.RegisterType((*GetOperatorResponse)(nil), "pdextpb.GetOperatorResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for PD service
+
+type PDClient interface {
+	// GetPrevRegion gets the region before the region which contains the key,
+	// the response has no region if there is a hole between them.
+	GetPrevRegion(ctx context.Context, in *pdpb.GetRegionRequest, opts ...grpc.CallOption) (*pdpb.GetRegionResponse, error)
+	ScanRegions(ctx context.Context, in *ScanRegionsRequest, opts ...grpc.CallOption) (*ScanRegionsResponse, error)
+	GetOperator(ctx context.Context, in *GetOperatorRequest, opts ...grpc.CallOption) (*GetOperatorResponse, error)
+}
+
+type pDClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewPDClient(cc *grpc.ClientConn) PDClient {
+	return &pDClient{cc}
+}
+
+func (c *pDClient) GetPrevRegion(ctx context.Context, in *pdpb.GetRegionRequest, opts ...grpc.CallOption) (*pdpb.GetRegionResponse, error) {
+	out := new(pdpb.GetRegionResponse)
+	err := grpc.Invoke(ctx, "/pdextpb.PD/GetPrevRegion", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pDClient) ScanRegions(ctx context.Context, in *ScanRegionsRequest, opts ...grpc.CallOption) (*ScanRegionsResponse, error) {
+	out := new(ScanRegionsResponse)
+	err := grpc.Invoke(ctx, "/pdextpb.PD/ScanRegions", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pDClient) GetOperator(ctx context.Context, in *GetOperatorRequest, opts ...grpc.CallOption) (*GetOperatorResponse, error) {
+	out := new(GetOperatorResponse)
+	err := grpc.Invoke(ctx, "/pdextpb.PD/GetOperator", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for PD service
+
+type PDServer interface {
+	// GetPrevRegion gets the region before the region which contains the key,
+	// the response has no region if there is a hole between them.
+	GetPrevRegion(context.Context, *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error)
+	ScanRegions(context.Context, *ScanRegionsRequest) (*ScanRegionsResponse, error)
+	GetOperator(context.Context, *GetOperatorRequest) (*GetOperatorResponse, error)
+}
+
+func RegisterPDServer(s *grpc.Server, srv PDServer) {
+	s.RegisterService(&_PD_serviceDesc, srv)
+}
+
+func _PD_GetPrevRegion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pdpb.GetRegionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PDServer).GetPrevRegion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pdextpb.PD/GetPrevRegion",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PDServer).GetPrevRegion(ctx, req.(*pdpb.GetRegionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PD_ScanRegions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanRegionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PDServer).ScanRegions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pdextpb.PD/ScanRegions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PDServer).ScanRegions(ctx, req.(*ScanRegionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PD_GetOperator_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOperatorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PDServer).GetOperator(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pdextpb.PD/GetOperator",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PDServer).GetOperator(ctx, req.(*GetOperatorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _PD_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pdextpb.PD",
+	HandlerType: (*PDServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPrevRegion",
+			Handler:    _PD_GetPrevRegion_Handler,
+		},
+		{
+			MethodName: "ScanRegions",
+			Handler:    _PD_ScanRegions_Handler,
+		},
+		{
+			MethodName: "GetOperator",
+			Handler:    _PD_GetOperator_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pdextpb.proto",
+}
+
+func (m *ScanRegionsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ScanRegionsRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(m.Header.Size()))
+		n1, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	if len(m.StartKey) > 0 {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(len(m.StartKey)))
+		i += copy(dAtA[i:], m.StartKey)
+	}
+	if m.Limit != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(m.Limit))
+	}
+	if len(m.EndKey) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(len(m.EndKey)))
+		i += copy(dAtA[i:], m.EndKey)
+	}
+	return i, nil
+}
+
+func (m *ScanRegionsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ScanRegionsResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(m.Header.Size()))
+		n2, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	if len(m.Regions) > 0 {
+		for _, msg := range m.Regions {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintPdextpb(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Leaders) > 0 {
+		for _, msg := range m.Leaders {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintPdextpb(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	return i, nil
+}
+
+func (m *GetOperatorRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetOperatorRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(m.Header.Size()))
+		n3, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	if m.RegionId != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(m.RegionId))
+	}
+	return i, nil
+}
+
+func (m *GetOperatorResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GetOperatorResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(m.Header.Size()))
+		n4, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
+	if m.RegionId != 0 {
+		dAtA[i] = 0x10
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(m.RegionId))
+	}
+	if len(m.Desc) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(len(m.Desc)))
+		i += copy(dAtA[i:], m.Desc)
+	}
+	if len(m.Status) > 0 {
+		dAtA[i] = 0x22
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(len(m.Status)))
+		i += copy(dAtA[i:], m.Status)
+	}
+	if len(m.Kind) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintPdextpb(dAtA, i, uint64(len(m.Kind)))
+		i += copy(dAtA[i:], m.Kind)
+	}
+	return i, nil
+}
+
+func encodeVarintPdextpb(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *ScanRegionsRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovPdextpb(uint64(l))
+	}
+	l = len(m.StartKey)
+	if l > 0 {
+		n += 1 + l + sovPdextpb(uint64(l))
+	}
+	if m.Limit != 0 {
+		n += 1 + sovPdextpb(uint64(m.Limit))
+	}
+	l = len(m.EndKey)
+	if l > 0 {
+		n += 1 + l + sovPdextpb(uint64(l))
+	}
+	return n
+}
+
+func (m *ScanRegionsResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovPdextpb(uint64(l))
+	}
+	if len(m.Regions) > 0 {
+		for _, e := range m.Regions {
+			l = e.Size()
+			n += 1 + l + sovPdextpb(uint64(l))
+		}
+	}
+	if len(m.Leaders) > 0 {
+		for _, e := range m.Leaders {
+			l = e.Size()
+			n += 1 + l + sovPdextpb(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *GetOperatorRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovPdextpb(uint64(l))
+	}
+	if m.RegionId != 0 {
+		n += 1 + sovPdextpb(uint64(m.RegionId))
+	}
+	return n
+}
+
+func (m *GetOperatorResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovPdextpb(uint64(l))
+	}
+	if m.RegionId != 0 {
+		n += 1 + sovPdextpb(uint64(m.RegionId))
+	}
+	l = len(m.Desc)
+	if l > 0 {
+		n += 1 + l + sovPdextpb(uint64(l))
+	}
+	l = len(m.Status)
+	if l > 0 {
+		n += 1 + l + sovPdextpb(uint64(l))
+	}
+	l = len(m.Kind)
+	if l > 0 {
+		n += 1 + l + sovPdextpb(uint64(l))
+	}
+	return n
+}
+
+func sovPdextpb(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozPdextpb(x uint64) (n int) {
+	return sovPdextpb(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *ScanRegionsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPdextpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ScanRegionsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ScanRegionsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &pdpb.RequestHeader{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StartKey = append(m.StartKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.StartKey == nil {
+				m.StartKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field EndKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.EndKey = append(m.EndKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.EndKey == nil {
+				m.EndKey = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPdextpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ScanRegionsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPdextpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ScanRegionsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ScanRegionsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &pdpb.ResponseHeader{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Regions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Regions = append(m.Regions, &metapb.Region{})
+			if err := m.Regions[len(m.Regions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Leaders", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Leaders = append(m.Leaders, &metapb.Peer{})
+			if err := m.Leaders[len(m.Leaders)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPdextpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetOperatorRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPdextpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetOperatorRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetOperatorRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &pdpb.RequestHeader{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RegionId", wireType)
+			}
+			m.RegionId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RegionId |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPdextpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GetOperatorResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPdextpb
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GetOperatorResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GetOperatorResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &pdpb.ResponseHeader{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RegionId", wireType)
+			}
+			m.RegionId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.RegionId |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Desc", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Desc = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Status = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Kind", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Kind = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPdextpb(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPdextpb
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipPdextpb(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowPdextpb
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowPdextpb
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			iNdEx += length
+			if length < 0 {
+				return 0, ErrInvalidLengthPdextpb
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowPdextpb
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipPdextpb(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthPdextpb = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowPdextpb   = fmt.Errorf("proto: integer overflow")
+)
+
+func init() { proto.RegisterFile("pdextpb.proto", fileDescriptorPdextpb) }
+
+var fileDescriptorPdextpb = []byte{
+	// 418 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x53, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0xee, 0xe6, 0xc7, 0x6e, 0x26, 0x29, 0x42, 0x93, 0xa8, 0xb5, 0xdc, 0xca, 0xb2, 0x7c, 0x40,
+	0x96, 0x40, 0x46, 0x0a, 0x6f, 0x50, 0x21, 0x05, 0xe8, 0x81, 0x68, 0xb9, 0x53, 0xb9, 0xdd, 0x51,
+	0xb0, 0xda, 0xd8, 0x66, 0x77, 0x83, 0xc8, 0x43, 0x70, 0x44, 0xe2, 0xc8, 0xe3, 0x70, 0xe4, 0x11,
+	0xa2, 0xf0, 0x22, 0x28, 0xbb, 0x36, 0x72, 0x7e, 0xc4, 0x21, 0xb7, 0x99, 0xf9, 0x66, 0xbe, 0xf9,
+	0xfc, 0xcd, 0x1a, 0xce, 0x4a, 0x41, 0x5f, 0x75, 0x79, 0x97, 0x94, 0xb2, 0xd0, 0x05, 0xba, 0x55,
+	0xea, 0x0f, 0xe6, 0xa4, 0xd3, 0xba, 0xec, 0x43, 0x29, 0xfe, 0xc5, 0xa3, 0x59, 0x31, 0x2b, 0x4c,
+	0xf8, 0x72, 0x13, 0xd9, 0x6a, 0xf4, 0x8d, 0x01, 0x7e, 0xb8, 0x4f, 0x73, 0x4e, 0xb3, 0xac, 0xc8,
+	0x15, 0xa7, 0xcf, 0x0b, 0x52, 0x1a, 0x9f, 0x83, 0xf3, 0x89, 0x52, 0x41, 0xd2, 0x63, 0x21, 0x8b,
+	0xfb, 0xe3, 0x61, 0x62, 0x98, 0x2a, 0xf8, 0x8d, 0x81, 0x78, 0xd5, 0x82, 0x97, 0xd0, 0x53, 0x3a,
+	0x95, 0xfa, 0xf6, 0x81, 0x96, 0x5e, 0x2b, 0x64, 0xf1, 0x80, 0x9f, 0x9a, 0xc2, 0x0d, 0x2d, 0x71,
+	0x04, 0xdd, 0xc7, 0x6c, 0x9e, 0x69, 0xaf, 0x1d, 0xb2, 0xb8, 0xcb, 0x6d, 0x82, 0x17, 0xe0, 0x52,
+	0x2e, 0xcc, 0x40, 0xc7, 0x0c, 0x38, 0x94, 0x8b, 0x1b, 0x5a, 0x46, 0xdf, 0x19, 0x0c, 0xb7, 0xf4,
+	0xa8, 0xb2, 0xc8, 0x15, 0xe1, 0x8b, 0x1d, 0x41, 0xa3, 0x5a, 0x90, 0xc5, 0x77, 0x14, 0xc5, 0xe0,
+	0x4a, 0x4b, 0xe0, 0xb5, 0xc2, 0x76, 0xdc, 0x1f, 0x3f, 0x49, 0x2a, 0x5f, 0x2c, 0x2f, 0xaf, 0x61,
+	0x7c, 0x06, 0xee, 0xa3, 0x99, 0x51, 0x5e, 0xdb, 0x74, 0x0e, 0xea, 0xce, 0x29, 0x91, 0xe4, 0x35,
+	0x18, 0x7d, 0x04, 0x9c, 0x90, 0x7e, 0x5f, 0x92, 0x4c, 0x75, 0x21, 0x8f, 0xb5, 0xc9, 0x6e, 0xbd,
+	0xcd, 0x84, 0xb1, 0xa9, 0xc3, 0x4f, 0x6d, 0xe1, 0xad, 0x88, 0x7e, 0x32, 0x18, 0x6e, 0x2d, 0x38,
+	0xea, 0xbb, 0xff, 0xb7, 0x02, 0x11, 0x3a, 0x82, 0xd4, 0xbd, 0x39, 0x44, 0x8f, 0x9b, 0x18, 0xcf,
+	0xc1, 0x51, 0x3a, 0xd5, 0x0b, 0x65, 0xce, 0xd0, 0xe3, 0x55, 0xb6, 0xe9, 0x7d, 0xc8, 0x72, 0xe1,
+	0x75, 0x6d, 0xef, 0x26, 0x1e, 0xaf, 0x18, 0xb4, 0xa6, 0xaf, 0xf1, 0x1a, 0xce, 0x26, 0xa4, 0xa7,
+	0x92, 0xbe, 0x58, 0x2f, 0xf1, 0xdc, 0x4a, 0x9a, 0x90, 0xae, 0xcc, 0xb5, 0x5f, 0xef, 0x5f, 0xec,
+	0xd5, 0xad, 0xe6, 0xe8, 0x04, 0xdf, 0x41, 0xbf, 0x71, 0x64, 0xbc, 0x4c, 0xea, 0xd7, 0xbc, 0xff,
+	0x14, 0xfd, 0xab, 0xc3, 0x60, 0x93, 0xab, 0x61, 0x5c, 0x83, 0x6b, 0xff, 0x5e, 0xfe, 0xd5, 0x61,
+	0xb0, 0xe6, 0xba, 0x7e, 0xfa, 0x6b, 0x1d, 0xb0, 0xdf, 0xeb, 0x80, 0xad, 0xd6, 0x01, 0xfb, 0xf1,
+	0x27, 0x38, 0xb9, 0x73, 0xcc, 0x6f, 0xf2, 0xea, 0xef, 0x00, 0xaa, 0xd9, 0x04, 0x87, 0x70, 0x03,
+	0x00, 0x00,
+}
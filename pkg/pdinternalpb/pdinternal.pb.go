@@ -0,0 +1,851 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: pdinternal.proto
+
+/*
+Package pdinternalpb is a generated protocol buffer package.
+
+It is generated from these files:
+
+	pdinternal.proto
+
+It has these top-level messages:
+
+	SyncRegionRequest
+	SyncRegionResponse
+*/
+package pdinternalpb
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import metapb "github.com/pingcap/kvproto/pkg/metapb"
+import pdpb "github.com/pingcap/kvproto/pkg/pdpb"
+import _ "github.com/gogo/protobuf/gogoproto"
+
+import context "golang.org/x/net/context"
+import grpc "google.golang.org/grpc"
+
+import io "io"
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type SyncRegionRequest struct {
+	Header *pdpb.RequestHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	Member *pdpb.Member        `protobuf:"bytes,2,opt,name=member" json:"member,omitempty"`
+	// start_index is the first history index the follower wants, 0 means it
+	// needs all the regions.
+	StartIndex uint64 `protobuf:"varint,3,opt,name=start_index,json=startIndex,proto3" json:"start_index,omitempty"`
+}
+
+func (m *SyncRegionRequest) Reset()                    { *m = SyncRegionRequest{} }
+func (m *SyncRegionRequest) String() string            { return proto.CompactTextString(m) }
+func (*SyncRegionRequest) ProtoMessage()               {}
+func (*SyncRegionRequest) Descriptor() ([]byte, []int) { return fileDescriptorPdinternal, []int{0} }
+
+func (m *SyncRegionRequest) GetHeader() *pdpb.RequestHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *SyncRegionRequest) GetMember() *pdpb.Member {
+	if m != nil {
+		return m.Member
+	}
+	return nil
+}
+
+func (m *SyncRegionRequest) GetStartIndex() uint64 {
+	if m != nil {
+		return m.StartIndex
+	}
+	return 0
+}
+
+type SyncRegionResponse struct {
+	Header  *pdpb.ResponseHeader `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
+	Regions []*metapb.Region     `protobuf:"bytes,2,rep,name=regions" json:"regions,omitempty"`
+	// leaders are the leaders of regions, a peer with zero id means no leader.
+	Leaders []*metapb.Peer `protobuf:"bytes,3,rep,name=leaders" json:"leaders,omitempty"`
+	// next_index is the index the follower should start from after applying
+	// this batch.
+	NextIndex uint64 `protobuf:"varint,4,opt,name=next_index,json=nextIndex,proto3" json:"next_index,omitempty"`
+	// leader_index is the next history index of the leader when sending.
+	LeaderIndex uint64 `protobuf:"varint,5,opt,name=leader_index,json=leaderIndex,proto3" json:"leader_index,omitempty"`
+}
+
+func (m *SyncRegionResponse) Reset()                    { *m = SyncRegionResponse{} }
+func (m *SyncRegionResponse) String() string            { return proto.CompactTextString(m) }
+func (*SyncRegionResponse) ProtoMessage()               {}
+func (*SyncRegionResponse) Descriptor() ([]byte, []int) { return fileDescriptorPdinternal, []int{1} }
+
+func (m *SyncRegionResponse) GetHeader() *pdpb.ResponseHeader {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *SyncRegionResponse) GetRegions() []*metapb.Region {
+	if m != nil {
+		return m.Regions
+	}
+	return nil
+}
+
+func (m *SyncRegionResponse) GetLeaders() []*metapb.Peer {
+	if m != nil {
+		return m.Leaders
+	}
+	return nil
+}
+
+func (m *SyncRegionResponse) GetNextIndex() uint64 {
+	if m != nil {
+		return m.NextIndex
+	}
+	return 0
+}
+
+func (m *SyncRegionResponse) GetLeaderIndex() uint64 {
+	if m != nil {
+		return m.LeaderIndex
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*SyncRegionRequest)(nil), "pdinternal.SyncRegionRequest")
+	proto.RegisterType((*SyncRegionResponse)(nil), "pdinternal.SyncRegionResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for RegionSync service
+
+type RegionSyncClient interface {
+	SyncRegions(ctx context.Context, in *SyncRegionRequest, opts ...grpc.CallOption) (RegionSync_SyncRegionsClient, error)
+}
+
+type regionSyncClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewRegionSyncClient(cc *grpc.ClientConn) RegionSyncClient {
+	return &regionSyncClient{cc}
+}
+
+func (c *regionSyncClient) SyncRegions(ctx context.Context, in *SyncRegionRequest, opts ...grpc.CallOption) (RegionSync_SyncRegionsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_RegionSync_serviceDesc.Streams[0], c.cc, "/pdinternal.RegionSync/SyncRegions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &regionSyncSyncRegionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RegionSync_SyncRegionsClient interface {
+	Recv() (*SyncRegionResponse, error)
+	grpc.ClientStream
+}
+
+type regionSyncSyncRegionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *regionSyncSyncRegionsClient) Recv() (*SyncRegionResponse, error) {
+	m := new(SyncRegionResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for RegionSync service
+
+type RegionSyncServer interface {
+	SyncRegions(*SyncRegionRequest, RegionSync_SyncRegionsServer) error
+}
+
+func RegisterRegionSyncServer(s *grpc.Server, srv RegionSyncServer) {
+	s.RegisterService(&_RegionSync_serviceDesc, srv)
+}
+
+func _RegionSync_SyncRegions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SyncRegionRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RegionSyncServer).SyncRegions(m, &regionSyncSyncRegionsServer{stream})
+}
+
+type RegionSync_SyncRegionsServer interface {
+	Send(*SyncRegionResponse) error
+	grpc.ServerStream
+}
+
+type regionSyncSyncRegionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *regionSyncSyncRegionsServer) Send(m *SyncRegionResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _RegionSync_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pdinternal.RegionSync",
+	HandlerType: (*RegionSyncServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SyncRegions",
+			Handler:       _RegionSync_SyncRegions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pdinternal.proto",
+}
+
+func (m *SyncRegionRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SyncRegionRequest) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintPdinternal(dAtA, i, uint64(m.Header.Size()))
+		n1, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n1
+	}
+	if m.Member != nil {
+		dAtA[i] = 0x12
+		i++
+		i = encodeVarintPdinternal(dAtA, i, uint64(m.Member.Size()))
+		n2, err := m.Member.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n2
+	}
+	if m.StartIndex != 0 {
+		dAtA[i] = 0x18
+		i++
+		i = encodeVarintPdinternal(dAtA, i, uint64(m.StartIndex))
+	}
+	return i, nil
+}
+
+func (m *SyncRegionResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalTo(dAtA)
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SyncRegionResponse) MarshalTo(dAtA []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if m.Header != nil {
+		dAtA[i] = 0xa
+		i++
+		i = encodeVarintPdinternal(dAtA, i, uint64(m.Header.Size()))
+		n3, err := m.Header.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n3
+	}
+	if len(m.Regions) > 0 {
+		for _, msg := range m.Regions {
+			dAtA[i] = 0x12
+			i++
+			i = encodeVarintPdinternal(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if len(m.Leaders) > 0 {
+		for _, msg := range m.Leaders {
+			dAtA[i] = 0x1a
+			i++
+			i = encodeVarintPdinternal(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.NextIndex != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintPdinternal(dAtA, i, uint64(m.NextIndex))
+	}
+	if m.LeaderIndex != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintPdinternal(dAtA, i, uint64(m.LeaderIndex))
+	}
+	return i, nil
+}
+
+func encodeVarintPdinternal(dAtA []byte, offset int, v uint64) int {
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return offset + 1
+}
+func (m *SyncRegionRequest) Size() (n int) {
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovPdinternal(uint64(l))
+	}
+	if m.Member != nil {
+		l = m.Member.Size()
+		n += 1 + l + sovPdinternal(uint64(l))
+	}
+	if m.StartIndex != 0 {
+		n += 1 + sovPdinternal(uint64(m.StartIndex))
+	}
+	return n
+}
+
+func (m *SyncRegionResponse) Size() (n int) {
+	var l int
+	_ = l
+	if m.Header != nil {
+		l = m.Header.Size()
+		n += 1 + l + sovPdinternal(uint64(l))
+	}
+	if len(m.Regions) > 0 {
+		for _, e := range m.Regions {
+			l = e.Size()
+			n += 1 + l + sovPdinternal(uint64(l))
+		}
+	}
+	if len(m.Leaders) > 0 {
+		for _, e := range m.Leaders {
+			l = e.Size()
+			n += 1 + l + sovPdinternal(uint64(l))
+		}
+	}
+	if m.NextIndex != 0 {
+		n += 1 + sovPdinternal(uint64(m.NextIndex))
+	}
+	if m.LeaderIndex != 0 {
+		n += 1 + sovPdinternal(uint64(m.LeaderIndex))
+	}
+	return n
+}
+
+func sovPdinternal(x uint64) (n int) {
+	for {
+		n++
+		x >>= 7
+		if x == 0 {
+			break
+		}
+	}
+	return n
+}
+func sozPdinternal(x uint64) (n int) {
+	return sovPdinternal(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *SyncRegionRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPdinternal
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SyncRegionRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SyncRegionRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdinternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &pdpb.RequestHeader{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Member", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdinternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Member == nil {
+				m.Member = &pdpb.Member{}
+			}
+			if err := m.Member.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StartIndex", wireType)
+			}
+			m.StartIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.StartIndex |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPdinternal(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPdinternal
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SyncRegionResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPdinternal
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SyncRegionResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SyncRegionResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdinternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Header == nil {
+				m.Header = &pdpb.ResponseHeader{}
+			}
+			if err := m.Header.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Regions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdinternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Regions = append(m.Regions, &metapb.Region{})
+			if err := m.Regions[len(m.Regions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Leaders", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPdinternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Leaders = append(m.Leaders, &metapb.Peer{})
+			if err := m.Leaders[len(m.Leaders)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NextIndex", wireType)
+			}
+			m.NextIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.NextIndex |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LeaderIndex", wireType)
+			}
+			m.LeaderIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LeaderIndex |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPdinternal(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthPdinternal
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipPdinternal(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowPdinternal
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+			return iNdEx, nil
+		case 1:
+			iNdEx += 8
+			return iNdEx, nil
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowPdinternal
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			iNdEx += length
+			if length < 0 {
+				return 0, ErrInvalidLengthPdinternal
+			}
+			return iNdEx, nil
+		case 3:
+			for {
+				var innerWire uint64
+				var start int = iNdEx
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return 0, ErrIntOverflowPdinternal
+					}
+					if iNdEx >= l {
+						return 0, io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					innerWire |= (uint64(b) & 0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				innerWireType := int(innerWire & 0x7)
+				if innerWireType == 4 {
+					break
+				}
+				next, err := skipPdinternal(dAtA[start:])
+				if err != nil {
+					return 0, err
+				}
+				iNdEx = start + next
+			}
+			return iNdEx, nil
+		case 4:
+			return iNdEx, nil
+		case 5:
+			iNdEx += 4
+			return iNdEx, nil
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+	}
+	panic("unreachable")
+}
+
+var (
+	ErrInvalidLengthPdinternal = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowPdinternal   = fmt.Errorf("proto: integer overflow")
+)
+
+func init() { proto.RegisterFile("pdinternal.proto", fileDescriptorPdinternal) }
+
+var fileDescriptorPdinternal = []byte{
+	// 329 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0xc1, 0x4a, 0xfb, 0x40,
+	0x10, 0xc6, 0xbb, 0x6d, 0xff, 0x2d, 0xff, 0x49, 0x10, 0x5d, 0x7b, 0x08, 0x85, 0xc6, 0x5a, 0x44,
+	0x02, 0x4a, 0x94, 0xfa, 0x06, 0x9e, 0xf4, 0x20, 0x94, 0x78, 0xf3, 0xa0, 0x24, 0x66, 0xa8, 0x85,
+	0x76, 0xb3, 0xee, 0xae, 0x50, 0x9f, 0xc0, 0x57, 0xf0, 0x91, 0x3c, 0x7a, 0xf2, 0x2c, 0xf5, 0x45,
+	0x24, 0xb3, 0x1b, 0x53, 0x2a, 0xde, 0x86, 0xef, 0xfb, 0xcd, 0xec, 0x37, 0x3b, 0xb0, 0x2d, 0xf3,
+	0x99, 0x30, 0xa8, 0x44, 0x3a, 0x8f, 0xa5, 0x2a, 0x4c, 0xc1, 0xa1, 0x56, 0xfa, 0xfe, 0x02, 0x4d,
+	0x2a, 0x33, 0xeb, 0xf4, 0x41, 0xe6, 0x3f, 0x75, 0x6f, 0x5a, 0x4c, 0x0b, 0x2a, 0x4f, 0xca, 0xca,
+	0xaa, 0xa3, 0x17, 0x06, 0x3b, 0xd7, 0xcf, 0xe2, 0x3e, 0xc1, 0xe9, 0xac, 0x10, 0x09, 0x3e, 0x3e,
+	0xa1, 0x36, 0xfc, 0x08, 0x3a, 0x0f, 0x98, 0xe6, 0xa8, 0x02, 0x36, 0x64, 0x91, 0x37, 0xde, 0x8d,
+	0x69, 0x90, 0xb3, 0x2f, 0xc8, 0x4a, 0x1c, 0xc2, 0x0f, 0xa0, 0xb3, 0xc0, 0x45, 0x86, 0x2a, 0x68,
+	0x12, 0xec, 0x5b, 0xf8, 0x8a, 0xb4, 0xc4, 0x79, 0x7c, 0x0f, 0x3c, 0x6d, 0x52, 0x65, 0xee, 0x66,
+	0x22, 0xc7, 0x65, 0xd0, 0x1a, 0xb2, 0xa8, 0x9d, 0x00, 0x49, 0x97, 0xa5, 0x32, 0xfa, 0x60, 0xc0,
+	0xd7, 0x93, 0x68, 0x59, 0x08, 0x8d, 0xfc, 0x78, 0x23, 0x4a, 0xaf, 0x8a, 0x62, 0xfd, 0x8d, 0x2c,
+	0x11, 0x74, 0x15, 0xf5, 0xeb, 0xa0, 0x39, 0x6c, 0x45, 0xde, 0x78, 0x2b, 0x76, 0x1f, 0xe2, 0xc6,
+	0x56, 0x36, 0x3f, 0x84, 0xee, 0x9c, 0x7a, 0x74, 0xd0, 0x22, 0xd2, 0xaf, 0xc8, 0x09, 0xa2, 0x4a,
+	0x2a, 0x93, 0x0f, 0x00, 0x04, 0x2e, 0xab, 0xd8, 0x6d, 0x8a, 0xfd, 0xbf, 0x54, 0x28, 0x35, 0xdf,
+	0x07, 0xdf, 0x92, 0x0e, 0xf8, 0x47, 0x80, 0x67, 0x35, 0x42, 0xc6, 0xb7, 0x00, 0xf6, 0xf1, 0x72,
+	0x3b, 0x3e, 0x01, 0xaf, 0xde, 0x52, 0xf3, 0x41, 0xbc, 0x76, 0xce, 0x5f, 0x87, 0xe8, 0x87, 0x7f,
+	0xd9, 0x76, 0xfb, 0x51, 0xe3, 0x94, 0x9d, 0x87, 0x6f, 0xab, 0x90, 0xbd, 0xaf, 0x42, 0xf6, 0xb9,
+	0x0a, 0xd9, 0xeb, 0x57, 0xd8, 0xb8, 0xf1, 0xeb, 0x26, 0x99, 0x65, 0x1d, 0xba, 0xf4, 0xd9, 0xf7,
+	0x00, 0xaa, 0xe4, 0xce, 0x75, 0x39, 0x02, 0x00, 0x00,
+}
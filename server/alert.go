@@ -0,0 +1,356 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/pkg/logutil"
+	"github.com/pingcap/pd/server/core"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	alertNameLabel = "alertname"
+	// alertValidFor is how many resend intervals a firing alert is valid
+	// for. Alertmanager resolves the alert afterwards if it is not sent
+	// again, e.g. the leader is down.
+	alertValidFor       = 3
+	alertWebhookTimeout = 10 * time.Second
+)
+
+// Alert is a problem found by a diagnose rule, it is sent to the webhooks in
+// the format of the alerts API of Alertmanager. EndsAt is the time when the
+// alert is resolved, or the time until which it is valid if it is firing.
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// AlertStatus is a firing alert and whether it is muted by any silence.
+type AlertStatus struct {
+	*Alert
+	Silenced bool `json:"silenced"`
+}
+
+type activeAlert struct {
+	*Alert
+	// lastSent is zero if the alert has not been sent.
+	lastSent time.Time
+}
+
+// alertManager deduplicates the alerts found by the evaluations, an alert is
+// identified by its rule. The firing alerts are sent again after the resend
+// interval, and the alerts which are not found any more are resolved.
+type alertManager struct {
+	sync.RWMutex
+	kv       *core.KV
+	client   *http.Client
+	alerts   map[string]*activeAlert
+	silences map[uint64]*core.AlertSilence
+}
+
+// newAlertManager creates an alert manager with the silences in the storage.
+// It starts with no silences if they fail to load, so that the cluster can
+// still start.
+func newAlertManager(kv *core.KV) *alertManager {
+	silences, err := kv.LoadAlertSilences()
+	if err != nil {
+		log.Errorf("failed to load the alert silences, start with no silences: %v", err)
+		silences = nil
+	}
+	m := &alertManager{
+		kv:       kv,
+		client:   &http.Client{Timeout: alertWebhookTimeout},
+		alerts:   make(map[string]*activeAlert),
+		silences: make(map[uint64]*core.AlertSilence, len(silences)),
+	}
+	for _, silence := range silences {
+		m.silences[silence.ID] = silence
+	}
+	return m
+}
+
+func (m *alertManager) silencedLocked(labels map[string]string, now time.Time) bool {
+	for _, silence := range m.silences {
+		if silence.Active(now) && silence.Matches(labels) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *alertManager) removeExpiredSilencesLocked(now time.Time) {
+	for id, silence := range m.silences {
+		if now.Before(silence.EndsAt) {
+			continue
+		}
+		if err := m.kv.RemoveAlertSilence(id); err != nil {
+			log.Errorf("failed to remove expired alert silence %d: %v", id, err)
+			continue
+		}
+		delete(m.silences, id)
+	}
+}
+
+// evaluate updates the active alerts with the ones found at now, and returns
+// the alerts to send. The new alerts are sent at once, while the existing
+// ones are sent again after the resend interval. The silenced alerts are not
+// sent.
+func (m *alertManager) evaluate(now time.Time, firing []*Alert, resendInterval time.Duration) []*Alert {
+	m.Lock()
+	defer m.Unlock()
+
+	m.removeExpiredSilencesLocked(now)
+	var alerts []*Alert
+	found := make(map[string]struct{}, len(firing))
+	for _, alert := range firing {
+		name := alert.Labels[alertNameLabel]
+		if _, ok := found[name]; ok {
+			continue
+		}
+		found[name] = struct{}{}
+		a, ok := m.alerts[name]
+		if ok {
+			a.Labels, a.Annotations, a.GeneratorURL = alert.Labels, alert.Annotations, alert.GeneratorURL
+		} else {
+			a = &activeAlert{Alert: alert}
+			m.alerts[name] = a
+		}
+		a.EndsAt = now.Add(alertValidFor * resendInterval)
+		if m.silencedLocked(a.Labels, now) {
+			continue
+		}
+		if a.lastSent.IsZero() || now.Sub(a.lastSent) >= resendInterval {
+			a.lastSent = now
+			sent := *a.Alert
+			alerts = append(alerts, &sent)
+		}
+	}
+	for name, a := range m.alerts {
+		if _, ok := found[name]; ok {
+			continue
+		}
+		delete(m.alerts, name)
+		if !a.lastSent.IsZero() {
+			a.EndsAt = now
+			alerts = append(alerts, a.Alert)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Labels[alertNameLabel] < alerts[j].Labels[alertNameLabel]
+	})
+	return alerts
+}
+
+// notify posts the alerts to all the webhooks. The alerts failed to send are
+// sent again with the next resend. It stops once the context is done.
+func (m *alertManager) notify(ctx context.Context, webhooks []string, alerts []*Alert) {
+	if len(alerts) == 0 {
+		return
+	}
+	data, err := json.Marshal(alerts)
+	if err != nil {
+		log.Errorf("failed to encode the alerts: %v", err)
+		return
+	}
+	for _, webhook := range webhooks {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := m.post(ctx, webhook, data); err != nil {
+			log.Errorf("failed to send %d alerts to %s: %v", len(alerts), webhook, err)
+			alertNotificationCounter.WithLabelValues("failed").Inc()
+			continue
+		}
+		alertNotificationCounter.WithLabelValues("success").Inc()
+	}
+}
+
+func (m *alertManager) post(ctx context.Context, webhook string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPost, webhook, bytes.NewReader(data))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := m.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("[%d] %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+func (m *alertManager) getAlerts(now time.Time) []*AlertStatus {
+	m.RLock()
+	defer m.RUnlock()
+	alerts := make([]*AlertStatus, 0, len(m.alerts))
+	for _, a := range m.alerts {
+		alert := *a.Alert
+		alerts = append(alerts, &AlertStatus{Alert: &alert, Silenced: m.silencedLocked(a.Labels, now)})
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Labels[alertNameLabel] < alerts[j].Labels[alertNameLabel]
+	})
+	return alerts
+}
+
+// getSilences returns the silences which are not expired in the order of ids.
+func (m *alertManager) getSilences(now time.Time) []*core.AlertSilence {
+	m.RLock()
+	defer m.RUnlock()
+	silences := make([]*core.AlertSilence, 0, len(m.silences))
+	for _, silence := range m.silences {
+		if now.Before(silence.EndsAt) {
+			silences = append(silences, silence)
+		}
+	}
+	sort.Slice(silences, func(i, j int) bool { return silences[i].ID < silences[j].ID })
+	return silences
+}
+
+func (m *alertManager) addSilence(silence *core.AlertSilence) error {
+	m.Lock()
+	defer m.Unlock()
+	if err := m.kv.SaveAlertSilence(silence); err != nil {
+		return errors.Trace(err)
+	}
+	m.silences[silence.ID] = silence
+	return nil
+}
+
+func (m *alertManager) removeSilence(id uint64) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.silences[id]; !ok {
+		return errors.Trace(ErrAlertSilenceNotFound)
+	}
+	if err := m.kv.RemoveAlertSilence(id); err != nil {
+		return errors.Trace(err)
+	}
+	delete(m.silences, id)
+	return nil
+}
+
+// newAlert converts a recommendation found at now to an alert.
+func (c *RaftCluster) newAlert(rec *Recommendation, now time.Time) *Alert {
+	return &Alert{
+		Labels: map[string]string{
+			alertNameLabel: rec.Rule,
+			"severity":     strings.ToLower(rec.Level),
+			"module":       rec.Module,
+			"cluster_id":   fmt.Sprint(c.clusterID),
+		},
+		Annotations: map[string]string{
+			"description": rec.Description,
+			"instruction": rec.Instruction,
+		},
+		StartsAt:     now,
+		GeneratorURL: strings.Split(c.s.GetAddr(), ",")[0] + "/pd/api/v1/diagnose",
+	}
+}
+
+// runAlertEvaluator evaluates the diagnose rules periodically. The config is
+// loaded in each round so that it can be reloaded online. The alerts being
+// sent are cancelled once the cluster stops.
+func (c *RaftCluster) runAlertEvaluator() {
+	defer logutil.LogPanic()
+	defer c.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-c.quit:
+			return
+		case <-time.After(c.s.getAlertConfig().EvaluationInterval.Duration):
+		}
+		if cfg := c.s.getAlertConfig(); !cfg.Disable {
+			c.evaluateAlerts(ctx, time.Now(), cfg)
+		}
+	}
+}
+
+func (c *RaftCluster) evaluateAlerts(ctx context.Context, now time.Time, cfg *AlertConfig) {
+	recs, err := c.s.Diagnose()
+	if err != nil {
+		log.Errorf("failed to evaluate the diagnose rules: %v", err)
+		return
+	}
+	firing := make([]*Alert, 0, len(recs))
+	for _, rec := range recs {
+		firing = append(firing, c.newAlert(rec, now))
+	}
+	alerts := c.alerts.evaluate(now, firing, cfg.ResendInterval.Duration)
+	c.alerts.notify(ctx, cfg.Webhooks, alerts)
+}
+
+// GetAlerts returns the firing alerts found by the last evaluation.
+func (c *RaftCluster) GetAlerts() []*AlertStatus {
+	return c.alerts.getAlerts(time.Now())
+}
+
+// GetAlertSilences returns the alert silences which are not expired.
+func (c *RaftCluster) GetAlertSilences() []*core.AlertSilence {
+	return c.alerts.getSilences(time.Now())
+}
+
+// AddAlertSilence saves the silence with a new id allocated. It starts now if
+// the start time is not specified.
+func (c *RaftCluster) AddAlertSilence(silence *core.AlertSilence) error {
+	now := time.Now()
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = now
+	}
+	if len(silence.Matchers) == 0 || !silence.EndsAt.After(now) || !silence.EndsAt.After(silence.StartsAt) {
+		return errors.Trace(ErrInvalidAlertSilence)
+	}
+	id, err := c.s.idAlloc.Alloc()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	silence.ID = id
+	return c.alerts.addSilence(silence)
+}
+
+// RemoveAlertSilence removes the silence so that the alerts are sent again.
+func (c *RaftCluster) RemoveAlertSilence(id uint64) error {
+	return c.alerts.removeSilence(id)
+}
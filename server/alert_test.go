@@ -0,0 +1,174 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testAlertSuite{})
+
+type testAlertSuite struct{}
+
+func newTestAlert(name string, now time.Time) *Alert {
+	return &Alert{
+		Labels:      map[string]string{alertNameLabel: name, "severity": "minor"},
+		Annotations: map[string]string{"description": name},
+		StartsAt:    now,
+	}
+}
+
+func alertNames(alerts []*Alert) []string {
+	names := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		names = append(names, a.Labels[alertNameLabel])
+	}
+	return names
+}
+
+func (s *testAlertSuite) TestEvaluate(c *C) {
+	m := newAlertManager(core.NewKV(core.NewMemoryKV()))
+	resend := time.Minute
+	start := time.Now()
+
+	alerts := m.evaluate(start, []*Alert{newTestAlert("a", start), newTestAlert("b", start), newTestAlert("a", start)}, resend)
+	c.Assert(alertNames(alerts), DeepEquals, []string{"a", "b"})
+	c.Assert(alerts[0].EndsAt.Equal(start.Add(alertValidFor*resend)), IsTrue)
+
+	// The firing alerts are not sent again before the resend interval.
+	now := start.Add(resend / 2)
+	alerts = m.evaluate(now, []*Alert{newTestAlert("a", now), newTestAlert("b", now)}, resend)
+	c.Assert(alerts, HasLen, 0)
+	now = start.Add(resend)
+	alerts = m.evaluate(now, []*Alert{newTestAlert("a", now), newTestAlert("b", now)}, resend)
+	c.Assert(alertNames(alerts), DeepEquals, []string{"a", "b"})
+	c.Assert(alerts[0].StartsAt.Equal(start), IsTrue)
+
+	// The alerts not found any more are resolved.
+	now = now.Add(time.Second)
+	alerts = m.evaluate(now, []*Alert{newTestAlert("b", now)}, resend)
+	c.Assert(alertNames(alerts), DeepEquals, []string{"a"})
+	c.Assert(alerts[0].EndsAt.Equal(now), IsTrue)
+	statuses := m.getAlerts(now)
+	c.Assert(statuses, HasLen, 1)
+	c.Assert(statuses[0].Labels[alertNameLabel], Equals, "b")
+	c.Assert(statuses[0].Silenced, IsFalse)
+}
+
+func (s *testAlertSuite) TestSilence(c *C) {
+	kv := core.NewKV(core.NewMemoryKV())
+	m := newAlertManager(kv)
+	resend := time.Minute
+	now := time.Now()
+
+	c.Assert(m.addSilence(&core.AlertSilence{
+		ID:       1,
+		Matchers: map[string]string{alertNameLabel: "a"},
+		StartsAt: now,
+		EndsAt:   now.Add(resend),
+	}), IsNil)
+	alerts := m.evaluate(now, []*Alert{newTestAlert("a", now), newTestAlert("b", now)}, resend)
+	c.Assert(alertNames(alerts), DeepEquals, []string{"b"})
+	statuses := m.getAlerts(now)
+	c.Assert(statuses, HasLen, 2)
+	c.Assert(statuses[0].Silenced, IsTrue)
+	c.Assert(statuses[1].Silenced, IsFalse)
+
+	// The silences are loaded again.
+	m = newAlertManager(kv)
+	c.Assert(m.getSilences(now), HasLen, 1)
+
+	// The alert is sent once the silence expires, and the expired silence
+	// is removed.
+	now = now.Add(resend)
+	alerts = m.evaluate(now, []*Alert{newTestAlert("a", now)}, resend)
+	c.Assert(alertNames(alerts), DeepEquals, []string{"a"})
+	c.Assert(m.getSilences(now), HasLen, 0)
+	silences, err := kv.LoadAlertSilences()
+	c.Assert(err, IsNil)
+	c.Assert(silences, HasLen, 0)
+
+	c.Assert(m.removeSilence(1), NotNil)
+}
+
+func (s *testAlertSuite) TestNotify(c *C) {
+	var (
+		mu       sync.Mutex
+		received []*Alert
+	)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alerts []*Alert
+		c.Assert(json.NewDecoder(r.Body).Decode(&alerts), IsNil)
+		mu.Lock()
+		received = append(received, alerts...)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+	failed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failed.Close()
+
+	m := newAlertManager(core.NewKV(core.NewMemoryKV()))
+	ctx := context.Background()
+	c.Assert(m.post(ctx, failed.URL, []byte("[]")), NotNil)
+	now := time.Now()
+	m.notify(ctx, []string{failed.URL, hook.URL}, []*Alert{newTestAlert("a", now)})
+	mu.Lock()
+	c.Assert(alertNames(received), DeepEquals, []string{"a"})
+	c.Assert(received[0].StartsAt.Equal(now), IsTrue)
+	mu.Unlock()
+
+	// The alerts being sent are cancelled with the context.
+	release := make(chan struct{})
+	blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer blocked.Close()
+	defer close(release)
+	ctx, cancel := context.WithCancel(ctx)
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	m.notify(ctx, []string{blocked.URL, hook.URL}, []*Alert{newTestAlert("b", now)})
+	c.Assert(time.Since(start), Less, alertWebhookTimeout)
+	mu.Lock()
+	defer mu.Unlock()
+	c.Assert(alertNames(received), DeepEquals, []string{"a"})
+}
+
+func (s *testAlertSuite) TestLoadSilencesFailed(c *C) {
+	base := core.NewMemoryKV()
+	kv := core.NewKV(base)
+	now := time.Now()
+	c.Assert(kv.SaveAlertSilence(&core.AlertSilence{
+		ID:       1,
+		Matchers: map[string]string{alertNameLabel: "a"},
+		StartsAt: now,
+		EndsAt:   now.Add(time.Minute),
+	}), IsNil)
+	c.Assert(base.Save("alert/silence/00000000000000000002", "invalid"), IsNil)
+
+	m := newAlertManager(kv)
+	c.Assert(m.getSilences(now), HasLen, 0)
+	alerts := m.evaluate(now, []*Alert{newTestAlert("a", now)}, time.Minute)
+	c.Assert(alertNames(alerts), DeepEquals, []string{"a"})
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+	"github.com/unrolled/render"
+)
+
+type alertHandler struct {
+	*server.Handler
+	rd *render.Render
+}
+
+func newAlertHandler(handler *server.Handler, rd *render.Render) *alertHandler {
+	return &alertHandler{
+		Handler: handler,
+		rd:      rd,
+	}
+}
+
+func (h *alertHandler) List(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.GetAlerts()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, alerts)
+}
+
+func (h *alertHandler) ListSilences(w http.ResponseWriter, r *http.Request) {
+	silences, err := h.GetAlertSilences()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, silences)
+}
+
+// AddSilence adds a silence, the id is ignored and a new one is returned.
+// The caller is recorded as the creator if it is not specified.
+func (h *alertHandler) AddSilence(w http.ResponseWriter, r *http.Request) {
+	var silence core.AlertSilence
+	if err := readJSONRespondError(h.rd, w, r.Body, &silence); err != nil {
+		return
+	}
+	if p := getPrincipal(r); p != nil && silence.CreatedBy == "" {
+		silence.CreatedBy = p.Name
+	}
+	err := h.AddAlertSilence(&silence)
+	switch errors.Cause(err) {
+	case nil:
+		h.rd.JSON(w, http.StatusOK, &silence)
+	case server.ErrInvalidAlertSilence:
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+	default:
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *alertHandler) DeleteSilence(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	err = h.RemoveAlertSilence(id)
+	switch errors.Cause(err) {
+	case nil:
+		h.rd.JSON(w, http.StatusOK, nil)
+	case server.ErrAlertSilenceNotFound:
+		h.rd.JSON(w, http.StatusNotFound, err.Error())
+	default:
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testAlertSuite{})
+
+type testAlertSuite struct {
+	cfg       *server.Config
+	svr       *server.Server
+	hook      *httptest.Server
+	urlPrefix string
+
+	mu     sync.Mutex
+	alerts []*server.Alert
+}
+
+func (s *testAlertSuite) SetUpSuite(c *C) {
+	s.hook = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alerts []*server.Alert
+		if err := json.NewDecoder(r.Body).Decode(&alerts); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.alerts = append(s.alerts, alerts...)
+		s.mu.Unlock()
+	}))
+
+	s.cfg = server.NewTestSingleConfig()
+	s.cfg.Alert.EvaluationInterval.Duration = 50 * time.Millisecond
+	s.cfg.Alert.Webhooks = []string{s.hook.URL}
+	var err error
+	s.svr, err = server.CreateServer(s.cfg, NewHandler)
+	c.Assert(err, IsNil)
+	c.Assert(s.svr.Run(context.TODO()), IsNil)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/alerts", s.svr.GetAddr(), apiPrefix)
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testAlertSuite) TearDownSuite(c *C) {
+	s.svr.Close()
+	cleanServer(s.cfg)
+	s.hook.Close()
+}
+
+func (s *testAlertSuite) received(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range s.alerts {
+		if a.Labels["alertname"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *testAlertSuite) getAlert(c *C, name string) *server.AlertStatus {
+	var alerts []*server.AlertStatus
+	c.Assert(readJSONWithURL(s.urlPrefix, &alerts), IsNil)
+	for _, a := range alerts {
+		if a.Labels["alertname"] == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *testAlertSuite) TestAlerts(c *C) {
+	// A single PD instance is running.
+	name := "member_one_instance"
+	for i := 0; i < 100 && !s.received(name); i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	c.Assert(s.received(name), IsTrue)
+	alert := s.getAlert(c, name)
+	c.Assert(alert, NotNil)
+	c.Assert(alert.Silenced, IsFalse)
+	c.Assert(alert.Labels["severity"], Equals, "warning")
+
+	silence := &core.AlertSilence{
+		Matchers: map[string]string{"alertname": name},
+		EndsAt:   time.Now().Add(time.Hour),
+		Comment:  "test",
+	}
+	data, err := json.Marshal(silence)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/silences", data), IsNil)
+	var silences []*core.AlertSilence
+	c.Assert(readJSONWithURL(s.urlPrefix+"/silences", &silences), IsNil)
+	c.Assert(silences, HasLen, 1)
+	c.Assert(silences[0].ID, Not(Equals), uint64(0))
+	c.Assert(silences[0].Comment, Equals, "test")
+	c.Assert(s.getAlert(c, name).Silenced, IsTrue)
+
+	// The silence must end in the future.
+	silence.EndsAt = time.Now().Add(-time.Hour)
+	data, err = json.Marshal(silence)
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix+"/silences", data), NotNil)
+
+	url := fmt.Sprintf("%s/silences/%d", s.urlPrefix, silences[0].ID)
+	c.Assert(s.deleteStatus(c, url), Equals, http.StatusOK)
+	c.Assert(s.deleteStatus(c, url), Equals, http.StatusNotFound)
+	c.Assert(s.getAlert(c, name).Silenced, IsFalse)
+}
+
+func (s *testAlertSuite) deleteStatus(c *C, url string) int {
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	c.Assert(err, IsNil)
+	resp, err := server.DialClient.Do(req)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	return resp.StatusCode
+}
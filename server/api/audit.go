@@ -0,0 +1,157 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+	log "github.com/sirupsen/logrus"
+	"github.com/unrolled/render"
+)
+
+const (
+	// The address of the caller of the request redirected to the leader.
+	sourceHeader = "PD-Source"
+
+	// maxAuditBodySize is the max size of the request body and the error
+	// recorded in an audit entry.
+	maxAuditBodySize = 256
+)
+
+// auditor records the mutating calls to the audit log. It runs after the
+// redirector, so the calls are recorded by the leader which serves them.
+type auditor struct {
+	h *server.Handler
+}
+
+func newAuditor(s *server.Server) *auditor {
+	return &auditor{h: s.GetHandler()}
+}
+
+func (a *auditor) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if r.Method == http.MethodGet || !a.h.IsAuditEnabled() {
+		next(w, r)
+		return
+	}
+
+	entry := &core.AuditEntry{
+		Source: r.RemoteAddr,
+		Method: r.Method,
+		URL:    r.URL.RequestURI(),
+	}
+	// The authenticator has removed the source unless the request is
+	// redirected by a member.
+	if len(r.Header.Get(sourceHeader)) != 0 {
+		entry.Source = r.Header.Get(sourceHeader)
+	}
+	if p := getPrincipal(r); p != nil {
+		entry.Caller, entry.Role = p.Name, string(p.Role)
+	}
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		entry.Body = truncateAudit(body)
+	}
+
+	rw := &auditResponseWriter{ResponseWriter: w, status: http.StatusOK}
+	next(rw, r)
+
+	entry.Status = rw.status
+	if rw.status != http.StatusOK {
+		entry.Error = truncateAudit(rw.body.Bytes())
+	}
+	if err := a.h.RecordAudit(entry); err != nil {
+		log.Errorf("record audit entry %s %s meet error: %v", entry.Method, entry.URL, err)
+	}
+}
+
+func truncateAudit(b []byte) string {
+	b = bytes.TrimSpace(b)
+	if len(b) > maxAuditBodySize {
+		return string(b[:maxAuditBodySize]) + "..."
+	}
+	return string(b)
+}
+
+// auditResponseWriter keeps the status and the beginning of the body.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if n := maxAuditBodySize + 1 - w.body.Len(); n > 0 {
+		if n > len(b) {
+			n = len(b)
+		}
+		w.body.Write(b[:n])
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+type auditHandler struct {
+	*server.Handler
+	rd *render.Render
+}
+
+func newAuditHandler(handler *server.Handler, rd *render.Render) *auditHandler {
+	return &auditHandler{
+		Handler: handler,
+		rd:      rd,
+	}
+}
+
+// Get returns the audit entries received in [start, end), which are unix
+// timestamps in seconds. The end defaults to now, and the start defaults to
+// an hour before the end.
+func (h *auditHandler) Get(w http.ResponseWriter, r *http.Request) {
+	start, end, err := parseTimeRange(r)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	entries, err := h.GetAuditEntries(start, end, limit)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []*core.AuditEntry{}
+	}
+	h.rd.JSON(w, http.StatusOK, entries)
+}
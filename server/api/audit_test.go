@@ -0,0 +1,123 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testAuditSuite{})
+
+type testAuditSuite struct {
+	cfg       *server.Config
+	svr       *server.Server
+	urlPrefix string
+	file      string
+}
+
+func (s *testAuditSuite) SetUpSuite(c *C) {
+	f, err := ioutil.TempFile("", "pd_audit")
+	c.Assert(err, IsNil)
+	f.Close()
+	s.file = f.Name()
+
+	s.cfg = server.NewTestSingleConfig()
+	s.cfg.Audit.File = s.file
+	s.svr, err = server.CreateServer(s.cfg, NewHandler)
+	c.Assert(err, IsNil)
+	c.Assert(s.svr.Run(context.TODO()), IsNil)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", s.svr.GetAddr(), apiPrefix)
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testAuditSuite) TearDownSuite(c *C) {
+	s.svr.Close()
+	cleanServer(s.cfg)
+	os.Remove(s.file)
+}
+
+func (s *testAuditSuite) getEntries(c *C, query string) []*core.AuditEntry {
+	var entries []*core.AuditEntry
+	err := readJSONWithURL(s.urlPrefix+"/audit"+query, &entries)
+	c.Assert(err, IsNil)
+	return entries
+}
+
+func (s *testAuditSuite) TestAudit(c *C) {
+	start := time.Now().Unix()
+	c.Assert(postJSON(s.urlPrefix+"/config", []byte(`{"max-replicas":5}`)), IsNil)
+	resp, err := newHTTPClient().Post(s.urlPrefix+"/operators", "application/json", bytes.NewBufferString(`{"name":"no-such-operator"}`))
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+	// GET is not recorded.
+	c.Assert(readJSONWithURL(s.urlPrefix+"/config", &server.Config{}), IsNil)
+
+	query := fmt.Sprintf("?start=%d&end=%d", start, time.Now().Unix()+1)
+	entries := s.getEntries(c, query)
+	c.Assert(entries, HasLen, 2)
+	c.Assert(entries[0].ID, Less, entries[1].ID)
+	c.Assert(entries[0].Method, Equals, "POST")
+	c.Assert(entries[0].URL, Equals, apiPrefix+"/api/v1/config")
+	c.Assert(entries[0].Body, Equals, `{"max-replicas":5}`)
+	c.Assert(entries[0].Status, Equals, http.StatusOK)
+	c.Assert(entries[0].Error, Equals, "")
+	c.Assert(entries[0].Source, Not(Equals), "")
+	c.Assert(entries[1].Status, Equals, http.StatusBadRequest)
+	c.Assert(entries[1].Error, Not(Equals), "")
+
+	c.Assert(s.getEntries(c, query+"&limit=1"), HasLen, 1)
+	c.Assert(s.getEntries(c, fmt.Sprintf("?end=%d", start-1)), HasLen, 0)
+
+	data, err := ioutil.ReadFile(s.file)
+	c.Assert(err, IsNil)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	c.Assert(lines, HasLen, 2)
+	var entry core.AuditEntry
+	c.Assert(json.Unmarshal([]byte(lines[0]), &entry), IsNil)
+	c.Assert(&entry, DeepEquals, entries[0])
+
+	// The source passed by a caller which is not a member is ignored.
+	req, err := http.NewRequest(http.MethodPost, s.urlPrefix+"/config", bytes.NewBufferString(`{"max-replicas":3}`))
+	c.Assert(err, IsNil)
+	req.Header.Set(redirectorHeader, "pd2")
+	req.Header.Set(sourceHeader, "1.2.3.4:5678")
+	resp, err = newHTTPClient().Do(req)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	entries = s.getEntries(c, "")
+	last := entries[len(entries)-1]
+	c.Assert(last.Body, Equals, `{"max-replicas":3}`)
+	c.Assert(last.Source, Not(Equals), "1.2.3.4:5678")
+
+	resp, err = newHTTPClient().Get(s.urlPrefix + "/audit?start=abc")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+}
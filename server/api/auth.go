@@ -0,0 +1,282 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+	"github.com/unrolled/render"
+)
+
+const (
+	// The caller authenticated by the member which redirects the request.
+	authNameHeader = "PD-Auth-Name"
+	authRoleHeader = "PD-Auth-Role"
+
+	errUnauthenticated = "unauthenticated"
+	errForbidden       = "forbidden"
+)
+
+// publicAPIs can be called without authentication.
+var publicAPIs = map[string]struct{}{
+	"GET " + pingAPI: {},
+	"GET /health":    {},
+	// The clock is probed by the other members.
+	"GET /api/v1/clock": {},
+}
+
+// adminAPIs require the admin role. The other APIs require the read-only
+// role for GET and the operator role for the other methods.
+var adminAPIs = map[string]struct{}{
+	"DELETE /api/v1/admin/cache/region/{id}":     {},
+	"POST /api/v1/log":                           {},
+	"POST /api/v1/config/reload":                 {},
+	"DELETE /api/v1/members/name/{name}":         {},
+	"DELETE /api/v1/members/id/{id}":             {},
+	"POST /api/v1/members/name/{name}":           {},
+	"POST /api/v1/leader/resign":                 {},
+	"POST /api/v1/leader/transfer/{next_leader}": {},
+	"GET /api/v1/auth/tokens":                    {},
+	"POST /api/v1/auth/tokens":                   {},
+	"DELETE /api/v1/auth/tokens/{name}":          {},
+	"GET /api/v1/auth/identities":                {},
+	"POST /api/v1/auth/identities":               {},
+	"DELETE /api/v1/auth/identities/{name}":      {},
+	"GET /api/v1/audit":                          {},
+}
+
+type principalKey struct{}
+
+func getPrincipal(r *http.Request) *server.Principal {
+	p, _ := r.Context().Value(principalKey{}).(*server.Principal)
+	return p
+}
+
+// authenticator resolves the caller of a request by the bearer token or the
+// client certificate. It runs before the redirector, and the caller and the
+// source are passed to the leader by headers, which are trusted only if the
+// client certificate belongs to the member in the redirector header.
+type authenticator struct {
+	h *server.Handler
+}
+
+func newAuthenticator(s *server.Server) *authenticator {
+	return &authenticator{h: s.GetHandler()}
+}
+
+func (a *authenticator) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if !a.h.IsAuthEnabled() {
+		// The source can't be trusted if the caller is not known to be a
+		// member.
+		r.Header.Del(sourceHeader)
+		next(w, r)
+		return
+	}
+
+	var cert *x509.Certificate
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		cert = r.TLS.PeerCertificates[0]
+	}
+	if member := r.Header.Get(redirectorHeader); len(member) != 0 {
+		ok, err := a.h.AuthenticateMember(member, cert)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if ok {
+			// The caller is authenticated by the member.
+			var p *server.Principal
+			if name := r.Header.Get(authNameHeader); len(name) != 0 {
+				p = &server.Principal{Name: name, Role: server.Role(r.Header.Get(authRoleHeader))}
+			}
+			next(w, withPrincipal(r, p))
+			return
+		}
+	}
+
+	r.Header.Del(sourceHeader)
+	p, err := a.h.Authenticate(bearerToken(r), cert)
+	if err != nil {
+		if errors.Cause(err) == server.ErrInvalidAuthToken {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	next(w, withPrincipal(r, p))
+}
+
+// withPrincipal sets the caller to the context and the headers, which are
+// passed to the leader if the request is redirected.
+func withPrincipal(r *http.Request, p *server.Principal) *http.Request {
+	r.Header.Del(authNameHeader)
+	r.Header.Del(authRoleHeader)
+	if p == nil {
+		return r
+	}
+	r.Header.Set(authNameHeader, p.Name)
+	r.Header.Set(authRoleHeader, string(p.Role))
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, p))
+}
+
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, prefix) {
+		return ""
+	}
+	return strings.TrimSpace(auth[len(prefix):])
+}
+
+// newAuthorizer checks the role of the caller against the matched route.
+func newAuthorizer(h *server.Handler) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !h.IsAuthEnabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tpl, err := mux.CurrentRoute(r).GetPathTemplate()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			api := r.Method + " " + strings.TrimPrefix(tpl, apiPrefix)
+			if _, ok := publicAPIs[api]; ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			p := getPrincipal(r)
+			if p == nil {
+				http.Error(w, errUnauthenticated, http.StatusUnauthorized)
+				return
+			}
+			if !p.Role.Allows(requiredRole(api, r.Method)) {
+				http.Error(w, errForbidden, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func requiredRole(api, method string) server.Role {
+	if _, ok := adminAPIs[api]; ok {
+		return server.RoleAdmin
+	}
+	if method == http.MethodGet {
+		return server.RoleReadOnly
+	}
+	return server.RoleOperator
+}
+
+type authInfo struct {
+	Name string      `json:"name"`
+	Role server.Role `json:"role"`
+	// Token is only responded when the token is created.
+	Token string `json:"token,omitempty"`
+}
+
+type authHandler struct {
+	*server.Handler
+	rd *render.Render
+}
+
+func newAuthHandler(handler *server.Handler, rd *render.Render) *authHandler {
+	return &authHandler{
+		Handler: handler,
+		rd:      rd,
+	}
+}
+
+func (h *authHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.GetAuthTokens()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	infos := make([]*authInfo, 0, len(tokens))
+	for _, t := range tokens {
+		infos = append(infos, &authInfo{Name: t.Name, Role: server.Role(t.Role)})
+	}
+	h.rd.JSON(w, http.StatusOK, infos)
+}
+
+func (h *authHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	var input authInfo
+	if err := readJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	token, err := h.CreateAuthToken(input.Name, input.Role)
+	switch errors.Cause(err) {
+	case nil:
+		h.rd.JSON(w, http.StatusOK, &authInfo{Name: input.Name, Role: input.Role, Token: token})
+	case server.ErrInvalidAuthName, server.ErrInvalidRole:
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+	default:
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *authHandler) DeleteToken(w http.ResponseWriter, r *http.Request) {
+	if err := h.DeleteAuthToken(mux.Vars(r)["name"]); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *authHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	identities, err := h.GetAuthIdentities()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if identities == nil {
+		identities = []*core.AuthIdentity{}
+	}
+	h.rd.JSON(w, http.StatusOK, identities)
+}
+
+func (h *authHandler) SetIdentity(w http.ResponseWriter, r *http.Request) {
+	var input authInfo
+	if err := readJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	err := h.SetAuthIdentity(input.Name, input.Role)
+	switch errors.Cause(err) {
+	case nil:
+		h.rd.JSON(w, http.StatusOK, nil)
+	case server.ErrInvalidAuthName, server.ErrInvalidRole:
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+	default:
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *authHandler) DeleteIdentity(w http.ResponseWriter, r *http.Request) {
+	if err := h.DeleteAuthIdentity(mux.Vars(r)["name"]); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
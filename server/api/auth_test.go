@@ -0,0 +1,202 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testAuthSuite{})
+
+type testAuthSuite struct {
+	cfg       *server.Config
+	svr       *server.Server
+	urlPrefix string
+}
+
+const testRootToken = "root-token"
+
+func (s *testAuthSuite) SetUpSuite(c *C) {
+	s.cfg = server.NewTestSingleConfig()
+	s.cfg.Security.EnableAuth = true
+	s.cfg.Security.AuthRootToken = testRootToken
+	var err error
+	s.svr, err = server.CreateServer(s.cfg, NewHandler)
+	c.Assert(err, IsNil)
+	c.Assert(s.svr.Run(context.TODO()), IsNil)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	s.urlPrefix = fmt.Sprintf("%s%s", s.svr.GetAddr(), apiPrefix)
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testAuthSuite) TearDownSuite(c *C) {
+	s.svr.Close()
+	cleanServer(s.cfg)
+}
+
+// do sends a request with the token, and returns the status code and the body.
+func (s *testAuthSuite) do(c *C, method, path, token string, input interface{}) (int, []byte) {
+	var body []byte
+	if input != nil {
+		var err error
+		body, err = json.Marshal(input)
+		c.Assert(err, IsNil)
+	}
+	req, err := http.NewRequest(method, s.urlPrefix+path, bytes.NewReader(body))
+	c.Assert(err, IsNil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := newHTTPClient().Do(req)
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	return resp.StatusCode, data
+}
+
+func (s *testAuthSuite) createToken(c *C, name string, role server.Role) string {
+	code, data := s.do(c, "POST", "/api/v1/auth/tokens", testRootToken, &authInfo{Name: name, Role: role})
+	c.Assert(code, Equals, http.StatusOK)
+	var info authInfo
+	c.Assert(json.Unmarshal(data, &info), IsNil)
+	c.Assert(info.Token, Not(Equals), "")
+	return info.Token
+}
+
+func (s *testAuthSuite) TestRoles(c *C) {
+	viewer := s.createToken(c, "viewer", server.RoleReadOnly)
+	ops := s.createToken(c, "ops", server.RoleOperator)
+
+	code, _ := s.do(c, "GET", "/ping", "", nil)
+	c.Assert(code, Equals, http.StatusOK)
+	// The clock is probed by the other members without tokens.
+	code, _ = s.do(c, "GET", "/api/v1/clock", "", nil)
+	c.Assert(code, Equals, http.StatusOK)
+	code, _ = s.do(c, "GET", "/api/v1/stores", "", nil)
+	c.Assert(code, Equals, http.StatusUnauthorized)
+	code, _ = s.do(c, "GET", "/api/v1/stores", "bad-token", nil)
+	c.Assert(code, Equals, http.StatusUnauthorized)
+
+	code, _ = s.do(c, "GET", "/api/v1/stores", viewer, nil)
+	c.Assert(code, Equals, http.StatusOK)
+	code, _ = s.do(c, "POST", "/api/v1/config", viewer, map[string]interface{}{"max-replicas": 5})
+	c.Assert(code, Equals, http.StatusForbidden)
+	code, _ = s.do(c, "POST", "/api/v1/config", ops, map[string]interface{}{"max-replicas": 5})
+	c.Assert(code, Equals, http.StatusOK)
+	code, _ = s.do(c, "DELETE", "/api/v1/admin/cache/region/1", ops, nil)
+	c.Assert(code, Equals, http.StatusForbidden)
+	code, _ = s.do(c, "GET", "/api/v1/auth/tokens", ops, nil)
+	c.Assert(code, Equals, http.StatusForbidden)
+
+	// The hashes of the tokens are not responded.
+	code, data := s.do(c, "GET", "/api/v1/auth/tokens", testRootToken, nil)
+	c.Assert(code, Equals, http.StatusOK)
+	var infos []*authInfo
+	c.Assert(json.Unmarshal(data, &infos), IsNil)
+	c.Assert(infos, DeepEquals, []*authInfo{
+		{Name: "ops", Role: server.RoleOperator},
+		{Name: "viewer", Role: server.RoleReadOnly},
+	})
+
+	code, _ = s.do(c, "DELETE", "/api/v1/auth/tokens/viewer", testRootToken, nil)
+	c.Assert(code, Equals, http.StatusOK)
+	code, _ = s.do(c, "GET", "/api/v1/stores", viewer, nil)
+	c.Assert(code, Equals, http.StatusUnauthorized)
+}
+
+func (s *testAuthSuite) TestInvalidInput(c *C) {
+	code, _ := s.do(c, "POST", "/api/v1/auth/tokens", testRootToken, &authInfo{Name: "a/b", Role: server.RoleAdmin})
+	c.Assert(code, Equals, http.StatusBadRequest)
+	code, _ = s.do(c, "POST", "/api/v1/auth/tokens", testRootToken, &authInfo{Name: "t", Role: "superuser"})
+	c.Assert(code, Equals, http.StatusBadRequest)
+	code, _ = s.do(c, "POST", "/api/v1/auth/identities", testRootToken, &authInfo{Role: server.RoleAdmin})
+	c.Assert(code, Equals, http.StatusBadRequest)
+}
+
+func (s *testAuthSuite) TestIdentities(c *C) {
+	code, _ := s.do(c, "POST", "/api/v1/auth/identities", testRootToken, &authInfo{Name: "tidb", Role: server.RoleOperator})
+	c.Assert(code, Equals, http.StatusOK)
+	code, data := s.do(c, "GET", "/api/v1/auth/identities", testRootToken, nil)
+	c.Assert(code, Equals, http.StatusOK)
+	var identities []*core.AuthIdentity
+	c.Assert(json.Unmarshal(data, &identities), IsNil)
+	c.Assert(identities, DeepEquals, []*core.AuthIdentity{{Name: "tidb", Role: string(server.RoleOperator)}})
+
+	code, _ = s.do(c, "DELETE", "/api/v1/auth/identities/tidb", testRootToken, nil)
+	c.Assert(code, Equals, http.StatusOK)
+	code, data = s.do(c, "GET", "/api/v1/auth/identities", testRootToken, nil)
+	c.Assert(code, Equals, http.StatusOK)
+	c.Assert(json.Unmarshal(data, &identities), IsNil)
+	c.Assert(identities, HasLen, 0)
+}
+
+func (s *testAuthSuite) TestMember(c *C) {
+	h := s.svr.GetHandler()
+	local := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "pd"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	ok, err := h.AuthenticateMember(s.svr.Name(), local)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	ok, err = h.AuthenticateMember("unknown", local)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+
+	// The common name of a member is not enough.
+	other := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "pd"},
+		IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+	}
+	ok, err = h.AuthenticateMember(s.svr.Name(), other)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	ok, err = h.AuthenticateMember(s.svr.Name(), nil)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+
+	// The forwarded caller is ignored if the request is not from a member.
+	req, err := http.NewRequest("GET", s.urlPrefix+"/api/v1/stores", nil)
+	c.Assert(err, IsNil)
+	req.Header.Set(redirectorHeader, s.svr.Name())
+	req.Header.Set(authNameHeader, "root")
+	req.Header.Set(authRoleHeader, string(server.RoleAdmin))
+	resp, err := newHTTPClient().Do(req)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusUnauthorized)
+}
+
+func (s *testAuthSuite) TestRequiredRole(c *C) {
+	c.Assert(requiredRole("GET /api/v1/stores", "GET"), Equals, server.RoleReadOnly)
+	c.Assert(requiredRole("POST /api/v1/operators", "POST"), Equals, server.RoleOperator)
+	c.Assert(requiredRole("POST /api/v1/leader/resign", "POST"), Equals, server.RoleAdmin)
+	c.Assert(server.RoleAdmin.Allows(server.RoleOperator), IsTrue)
+	c.Assert(server.RoleReadOnly.Allows(server.RoleOperator), IsFalse)
+	c.Assert(server.Role("").Allows(server.RoleReadOnly), IsFalse)
+}
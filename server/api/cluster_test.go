@@ -50,7 +50,8 @@ func (s *testClusterInfo) TestCluster(c *C) {
 
 	c2 := &metapb.Cluster{}
 	r := server.ReplicationConfig{MaxReplicas: 6}
-	s.svr.SetReplicationConfig(r)
+	_, err = s.svr.SetReplicationConfig(r)
+	c.Assert(err, IsNil)
 	err = readJSONWithURL(url, c2)
 	c.Assert(err, IsNil)
 
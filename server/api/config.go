@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/juju/errors"
@@ -38,66 +40,202 @@ func newConfHandler(svr *server.Server, rd *render.Render) *confHandler {
 	}
 }
 
-func (h *confHandler) Get(w http.ResponseWriter, r *http.Request) {
-	h.rd.JSON(w, http.StatusOK, h.svr.GetConfig())
+// setETag sets the revision of the config as the ETag of the response.
+func (h *confHandler) setETag(w http.ResponseWriter) {
+	w.Header().Set("ETag", fmt.Sprintf("\"%d\"", h.svr.GetConfigRevision()))
 }
 
-func (h *confHandler) Post(w http.ResponseWriter, r *http.Request) {
-	config := h.svr.GetConfig()
+// parseIfMatch returns the revision in the If-Match header, it returns 0 if
+// any revision matches.
+func parseIfMatch(r *http.Request) (uint64, error) {
+	v := r.Header.Get("If-Match")
+	if v == "" || v == "*" {
+		return 0, nil
+	}
+	revision, err := strconv.ParseUint(strings.Trim(v, "\""), 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid If-Match %q", v)
+	}
+	return revision, nil
+}
+
+// updateConfig reads the request body, then runs update with it if the
+// If-Match header matches the revision of the config. The warnings returned
+// by update are the body of the response.
+func (h *confHandler) updateConfig(w http.ResponseWriter, r *http.Request, update func(data []byte) (*server.ConfigCheckResult, error)) {
+	revision, err := parseIfMatch(r)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	data, err := ioutil.ReadAll(r.Body)
 	r.Body.Close()
 	if err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	if err := json.Unmarshal(data, &config.Schedule); err != nil {
-		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	var res *server.ConfigCheckResult
+	err = h.svr.UpdateConfig(revision, func() error {
+		res, err = update(data)
+		return err
+	})
+	switch errors.Cause(err) {
+	case nil:
+		h.setETag(w)
+		h.rd.JSON(w, http.StatusOK, res)
+	case server.ErrConfigRevisionMismatch:
+		h.setETag(w)
+		h.rd.JSON(w, http.StatusPreconditionFailed, err.Error())
+	case server.ErrConfigRevisionNotFound:
+		h.rd.JSON(w, http.StatusNotFound, err.Error())
+	default:
+		errorResp(h.rd, w, err)
+	}
+}
+
+// Get returns the config by the source. The effective config is the running
+// one, which merges the config file with the online changes, and the file
+// config is the one loaded from the config file last time.
+func (h *confHandler) Get(w http.ResponseWriter, r *http.Request) {
+	switch source := r.URL.Query().Get("source"); source {
+	case "", "effective":
+		h.setETag(w)
+		h.rd.JSON(w, http.StatusOK, h.svr.GetConfig())
+	case "file":
+		h.rd.JSON(w, http.StatusOK, h.svr.GetFileConfig())
+	default:
+		h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("invalid source %q", source))
+	}
+}
+
+// Reload reloads the config file like SIGHUP.
+func (h *confHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	res, err := h.svr.ReloadConfigFile()
+	if err != nil {
+		errorResp(h.rd, w, err)
 		return
 	}
-	if err := json.Unmarshal(data, &config.Replication); err != nil {
+	h.setETag(w)
+	h.rd.JSON(w, http.StatusOK, res)
+}
+
+func (h *confHandler) Post(w http.ResponseWriter, r *http.Request) {
+	h.updateConfig(w, r, func(data []byte) (*server.ConfigCheckResult, error) {
+		config := h.svr.GetConfig()
+		if err := json.Unmarshal(data, &config.Schedule); err != nil {
+			return nil, errors.Trace(err)
+		}
+		if err := json.Unmarshal(data, &config.Replication); err != nil {
+			return nil, errors.Trace(err)
+		}
+		res, err := h.svr.SetScheduleAndReplicationConfig(&config.Schedule, &config.Replication)
+		return res, errors.Trace(err)
+	})
+}
+
+// Validate checks the config items as the input of Post without applying
+// them, and returns the errors and the warnings.
+func (h *confHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	if err := h.svr.SetScheduleConfig(config.Schedule); err != nil {
-		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	config := h.svr.GetConfig()
+	if err := json.Unmarshal(data, &config.Schedule); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if err := h.svr.SetReplicationConfig(config.Replication); err != nil {
-		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	if err := json.Unmarshal(data, &config.Replication); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	h.rd.JSON(w, http.StatusOK, nil)
+	h.rd.JSON(w, http.StatusOK, h.svr.CheckConfig(config))
 }
 
 func (h *confHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	h.setETag(w)
 	h.rd.JSON(w, http.StatusOK, h.svr.GetScheduleConfig())
 }
 
 func (h *confHandler) SetSchedule(w http.ResponseWriter, r *http.Request) {
-	config := h.svr.GetScheduleConfig()
-	if err := readJSONRespondError(h.rd, w, r.Body, &config); err != nil {
-		return
-	}
-
-	if err := h.svr.SetScheduleConfig(*config); err != nil {
-		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	h.rd.JSON(w, http.StatusOK, nil)
+	h.updateConfig(w, r, func(data []byte) (*server.ConfigCheckResult, error) {
+		config := h.svr.GetScheduleConfig()
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, errcode.NewInvalidInputErr(err)
+		}
+		res, err := h.svr.SetScheduleConfig(*config)
+		return res, errors.Trace(err)
+	})
 }
 
 func (h *confHandler) GetReplication(w http.ResponseWriter, r *http.Request) {
+	h.setETag(w)
 	h.rd.JSON(w, http.StatusOK, h.svr.GetReplicationConfig())
 }
 
 func (h *confHandler) SetReplication(w http.ResponseWriter, r *http.Request) {
-	config := h.svr.GetReplicationConfig()
-	if err := readJSONRespondError(h.rd, w, r.Body, &config); err != nil {
+	h.updateConfig(w, r, func(data []byte) (*server.ConfigCheckResult, error) {
+		config := h.svr.GetReplicationConfig()
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, errcode.NewInvalidInputErr(err)
+		}
+		res, err := h.svr.SetReplicationConfig(*config)
+		return res, errors.Trace(err)
+	})
+}
+
+// GetHistory returns the latest revisions of the config with the changes.
+func (h *confHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	history, err := h.svr.GetConfigHistory(limit)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if history == nil {
+		history = []*server.ConfigRevision{}
+	}
+	h.rd.JSON(w, http.StatusOK, history)
+}
 
-	h.svr.SetReplicationConfig(*config)
-	h.rd.JSON(w, http.StatusOK, nil)
+// GetRevision returns a revision of the config with the changes.
+func (h *confHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	revision, err := strconv.ParseUint(mux.Vars(r)["revision"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rev, err := h.svr.GetConfigRevisionDetail(revision)
+	if errors.Cause(err) == server.ErrConfigRevisionNotFound {
+		h.rd.JSON(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, rev)
+}
+
+// Rollback restores the config to a revision as a new revision.
+func (h *confHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	revision, err := strconv.ParseUint(mux.Vars(r)["revision"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.updateConfig(w, r, func([]byte) (*server.ConfigCheckResult, error) {
+		return nil, h.svr.RollbackConfig(revision)
+	})
 }
 
 func (h *confHandler) GetNamespace(w http.ResponseWriter, r *http.Request) {
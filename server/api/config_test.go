@@ -14,8 +14,12 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"math/rand"
+	"net/http"
+	"strconv"
 	"time"
 
 	. "github.com/pingcap/check"
@@ -68,6 +72,8 @@ func (s *testConfigSuite) TestConfigAll(c *C) {
 	cfg.Replication.MaxReplicas = 5
 	cfg.Replication.LocationLabels = []string{"zone", "rack"}
 	cfg.Schedule.RegionScheduleLimit = 10
+	// Each post is one revision.
+	cfg.ConfigRevision += 2
 	c.Assert(cfg, DeepEquals, newCfg)
 }
 
@@ -172,3 +178,148 @@ func (s *testConfigSuite) TestConfigLabelProperty(c *C) {
 	c.Assert(cfg, HasLen, 1)
 	c.Assert(cfg["foo"], DeepEquals, []server.StoreLabel{{Key: "zone", Value: "cn2"}})
 }
+
+func (s *testConfigSuite) TestConfigRevision(c *C) {
+	addr := s.cfgs[rand.Intn(len(s.cfgs))].ClientUrls + apiPrefix + "/api/v1/config"
+	post := func(url, etag string, data string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(data))
+		c.Assert(err, IsNil)
+		req.Header.Set("If-Match", etag)
+		resp, err := newHTTPClient().Do(req)
+		c.Assert(err, IsNil)
+		resp.Body.Close()
+		return resp, resp.Header.Get("ETag")
+	}
+
+	resp, err := doGet(addr + "/schedule")
+	c.Assert(err, IsNil)
+	sc := &server.ScheduleConfig{}
+	c.Assert(readJSON(resp.Body, sc), IsNil)
+	etag := resp.Header.Get("ETag")
+	c.Assert(etag, Not(Equals), "")
+
+	resp, newETag := post(addr+"/schedule", etag, `{"leader-schedule-limit":77}`)
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(newETag, Not(Equals), etag)
+	// The stale revision is rejected.
+	resp, current := post(addr+"/schedule", etag, `{"leader-schedule-limit":78}`)
+	c.Assert(resp.StatusCode, Equals, http.StatusPreconditionFailed)
+	c.Assert(current, Equals, newETag)
+	resp, _ = post(addr+"/schedule", "abc", `{"leader-schedule-limit":78}`)
+	c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+
+	var history []*server.ConfigRevision
+	c.Assert(readJSONWithURL(addr+"/history?limit=1", &history), IsNil)
+	c.Assert(history, HasLen, 1)
+	c.Assert(fmt.Sprintf("%q", strconv.FormatUint(history[0].Revision, 10)), Equals, newETag)
+	c.Assert(history[0].Config, IsNil)
+	c.Assert(history[0].Changes, HasLen, 1)
+	c.Assert(history[0].Changes[0].Path, Equals, "schedule.leader-schedule-limit")
+	c.Assert(history[0].Changes[0].New, Equals, float64(77))
+
+	var rev server.ConfigRevision
+	c.Assert(readJSONWithURL(fmt.Sprintf("%s/history/%d", addr, history[0].Revision-1), &rev), IsNil)
+	c.Assert(rev.Config.Schedule.LeaderScheduleLimit, Equals, sc.LeaderScheduleLimit)
+
+	// Rollback to the revision before the post.
+	resp, _ = post(fmt.Sprintf("%s/rollback/%d", addr, rev.Revision), newETag, "")
+	c.Assert(resp.StatusCode, Equals, http.StatusOK)
+	c.Assert(readJSONWithURL(addr+"/schedule", sc), IsNil)
+	c.Assert(sc.LeaderScheduleLimit, Equals, rev.Config.Schedule.LeaderScheduleLimit)
+	resp, _ = post(addr+"/rollback/100000", "", "")
+	c.Assert(resp.StatusCode, Equals, http.StatusNotFound)
+}
+
+func (s *testConfigSuite) TestConfigValidate(c *C) {
+	addr := s.cfgs[rand.Intn(len(s.cfgs))].ClientUrls + apiPrefix + "/api/v1/config"
+	validate := func(data string) *server.ConfigCheckResult {
+		resp, err := newHTTPClient().Post(addr+"/validate", "application/json", bytes.NewBufferString(data))
+		c.Assert(err, IsNil)
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+		res := &server.ConfigCheckResult{}
+		c.Assert(readJSON(resp.Body, res), IsNil)
+		return res
+	}
+
+	res := validate(`{"high-space-ratio":0.9,"location-labels":"zone,zone"}`)
+	c.Assert(res.Errors, HasLen, 2)
+	c.Assert(res.Errors[0].Field, Equals, "schedule.low-space-ratio")
+	c.Assert(res.Errors[1].Field, Equals, "replication.location-labels")
+	res = validate(`{"max-replicas":4,"leader-schedule-limit":0}`)
+	c.Assert(res.Errors, HasLen, 0)
+	c.Assert(res.Warnings, HasLen, 2)
+
+	// Nothing is applied.
+	sc := &server.ScheduleConfig{}
+	c.Assert(readJSONWithURL(addr+"/schedule", sc), IsNil)
+	c.Assert(sc.LeaderScheduleLimit, Not(Equals), uint64(0))
+
+	resp, err := newHTTPClient().Post(addr+"/validate", "application/json", bytes.NewBufferString(`{"max-replicas":"a"}`))
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+}
+
+func (s *testConfigSuite) TestConfigSource(c *C) {
+	addr := s.cfgs[rand.Intn(len(s.cfgs))].ClientUrls + apiPrefix + "/api/v1/config"
+	cfg := &server.Config{}
+	c.Assert(readJSONWithURL(addr+"?source=effective", cfg), IsNil)
+	c.Assert(cfg.Schedule.LeaderScheduleLimit, Not(Equals), uint64(0))
+	fileCfg := &server.Config{}
+	c.Assert(readJSONWithURL(addr+"?source=file", fileCfg), IsNil)
+	c.Assert(fileCfg.PeerUrls, Equals, cfg.PeerUrls)
+
+	resp, err := newHTTPClient().Get(addr + "?source=etcd")
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+
+	// The test servers have no config file.
+	resp, err = newHTTPClient().Post(addr+"/reload", "application/json", nil)
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusInternalServerError)
+}
+
+var _ = Suite(&testConfigStoresSuite{})
+
+type testConfigStoresSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testConfigStoresSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", s.svr.GetAddr(), apiPrefix)
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testConfigStoresSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testConfigStoresSuite) TestWarnings(c *C) {
+	post := func(path, data string) *server.ConfigCheckResult {
+		resp, err := newHTTPClient().Post(s.urlPrefix+path, "application/json", bytes.NewBufferString(data))
+		c.Assert(err, IsNil)
+		defer resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, http.StatusOK)
+		res := &server.ConfigCheckResult{}
+		c.Assert(readJSON(resp.Body, res), IsNil)
+		return res
+	}
+
+	// The cluster has only one store without labels.
+	res := post("/config", `{"max-replicas":3,"location-labels":"zone"}`)
+	c.Assert(res.Warnings, DeepEquals, []*server.ConfigIssue{
+		{Field: "replication.max-replicas", Message: "is larger than the number of the stores 1"},
+		{Field: "replication.location-labels", Message: `no store has the label "zone"`},
+	})
+	res = post("/config/replicate", `{"max-replicas":1,"location-labels":""}`)
+	c.Assert(res.Warnings, HasLen, 0)
+	res = post("/config/schedule", `{"leader-schedule-limit":0}`)
+	c.Assert(res.Warnings, HasLen, 1)
+	c.Assert(res.Warnings[0].Field, Equals, "schedule.leader-schedule-limit")
+}
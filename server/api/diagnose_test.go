@@ -33,9 +33,10 @@ func (s *testDiagnoseAPISuite) SetUpSuite(c *C) {
 }
 
 func checkDiagnoseResponse(c *C, body []byte) {
-	got := []Recommendation{}
+	got := []server.Recommendation{}
 	json.Unmarshal(body, &got)
 	for _, r := range got {
+		c.Assert(len(r.Rule) != 0, IsTrue)
 		c.Assert(len(r.Module) != 0, IsTrue)
 		c.Assert(len(r.Level) != 0, IsTrue)
 		c.Assert(len(r.Description) != 0, IsTrue)
@@ -0,0 +1,101 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/server/core"
+	"github.com/unrolled/render"
+)
+
+// GCSafePoints contains the gc safe point and the safe points of services.
+type GCSafePoints struct {
+	GCSafePoint         uint64                   `json:"gc_safe_point"`
+	ServiceGCSafePoints []*core.ServiceSafePoint `json:"service_gc_safe_points"`
+}
+
+type serviceGCSafePointInput struct {
+	SafePoint uint64 `json:"safe_point"`
+	// TTL is the time to live of the safe point in seconds.
+	TTL int64 `json:"ttl"`
+}
+
+type gcHandler struct {
+	*server.Handler
+	rd *render.Render
+}
+
+func newGCHandler(handler *server.Handler, rd *render.Render) *gcHandler {
+	return &gcHandler{
+		Handler: handler,
+		rd:      rd,
+	}
+}
+
+func (h *gcHandler) Get(w http.ResponseWriter, r *http.Request) {
+	safePoint, err := h.GetGCSafePoint()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ssps, err := h.GetServiceGCSafePoints()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if ssps == nil {
+		ssps = []*core.ServiceSafePoint{}
+	}
+	h.rd.JSON(w, http.StatusOK, &GCSafePoints{
+		GCSafePoint:         safePoint,
+		ServiceGCSafePoints: ssps,
+	})
+}
+
+func (h *gcHandler) UpdateService(w http.ResponseWriter, r *http.Request) {
+	serviceID := mux.Vars(r)["service_id"]
+	var input serviceGCSafePointInput
+	if err := readJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if input.TTL > math.MaxInt64/int64(time.Second) {
+		h.rd.JSON(w, http.StatusBadRequest, "ttl is too large")
+		return
+	}
+
+	err := h.UpdateServiceGCSafePoint(serviceID, input.SafePoint, time.Duration(input.TTL)*time.Second)
+	switch errors.Cause(err) {
+	case nil:
+		h.rd.JSON(w, http.StatusOK, nil)
+	case server.ErrInvalidServiceID, server.ErrServiceSafePointRollback:
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+	default:
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *gcHandler) DeleteService(w http.ResponseWriter, r *http.Request) {
+	serviceID := mux.Vars(r)["service_id"]
+	if err := h.RemoveServiceGCSafePoint(serviceID); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
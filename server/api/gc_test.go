@@ -0,0 +1,82 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/server"
+)
+
+var _ = Suite(&testGCSuite{})
+
+type testGCSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testGCSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/gc/safepoint", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testGCSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testGCSuite) updateService(c *C, serviceID string, safePoint uint64, ttl int64) error {
+	data, err := json.Marshal(&serviceGCSafePointInput{SafePoint: safePoint, TTL: ttl})
+	c.Assert(err, IsNil)
+	return postJSON(fmt.Sprintf("%s/service/%s", s.urlPrefix, serviceID), data)
+}
+
+func (s *testGCSuite) TestServiceGCSafePoint(c *C) {
+	var safePoints GCSafePoints
+	c.Assert(readJSONWithURL(s.urlPrefix, &safePoints), IsNil)
+	c.Assert(safePoints.GCSafePoint, Equals, uint64(0))
+	c.Assert(safePoints.ServiceGCSafePoints, HasLen, 0)
+
+	c.Assert(s.updateService(c, "br", 100, 600), IsNil)
+	c.Assert(s.updateService(c, "cdc", 200, 600), IsNil)
+	c.Assert(s.updateService(c, "bad.id", 200, 600), NotNil)
+	c.Assert(s.updateService(c, "br", 100, 1<<62), NotNil)
+	c.Assert(readJSONWithURL(s.urlPrefix, &safePoints), IsNil)
+	c.Assert(safePoints.ServiceGCSafePoints, HasLen, 2)
+
+	// The GC safe point is blocked by the service safe points.
+	resp, err := s.svr.UpdateGCSafePoint(context.Background(), &pdpb.UpdateGCSafePointRequest{
+		Header:    &pdpb.RequestHeader{ClusterId: s.svr.ClusterID()},
+		SafePoint: 300,
+	})
+	c.Assert(err, IsNil)
+	c.Assert(resp.GetNewSafePoint(), Equals, uint64(100))
+	c.Assert(s.updateService(c, "cdc", 50, 600), NotNil)
+
+	c.Assert(doDelete(fmt.Sprintf("%s/service/%s", s.urlPrefix, "br")), IsNil)
+	// A non-positive ttl removes the safe point too.
+	c.Assert(s.updateService(c, "cdc", 0, 0), IsNil)
+	c.Assert(readJSONWithURL(s.urlPrefix, &safePoints), IsNil)
+	c.Assert(safePoints.GCSafePoint, Equals, uint64(100))
+	c.Assert(safePoints.ServiceGCSafePoints, HasLen, 0)
+}
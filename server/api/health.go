@@ -15,6 +15,7 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/pingcap/pd/server"
 	"github.com/unrolled/render"
@@ -25,11 +26,17 @@ type healthHandler struct {
 	rd  *render.Render
 }
 
-type health struct {
+// Health is the health status of a member.
+type Health struct {
 	Name       string   `json:"name"`
 	MemberID   uint64   `json:"member_id"`
 	ClientUrls []string `json:"client_urls"`
 	Health     bool     `json:"health"`
+	// ClockOffset is the offset of the member's clock relative to the
+	// leader, it's not set if the clock has not been probed.
+	ClockOffset *time.Duration `json:"clock_offset,omitempty"`
+	// TSO is only set for the leader.
+	TSO *server.TSOHealth `json:"tso,omitempty"`
 }
 
 func newHealthHandler(svr *server.Server, rd *render.Render) *healthHandler {
@@ -47,18 +54,32 @@ func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	unhealthMembers := h.svr.CheckHealth(members)
-	healths := []health{}
+	clocks := make(map[uint64]*server.MemberClock)
+	for _, c := range h.svr.GetMemberClocks() {
+		clocks[c.MemberID] = c
+	}
+	healths := []Health{}
 	for _, member := range members {
-		h := health{
+		mh := Health{
 			Name:       member.Name,
 			MemberID:   member.MemberId,
 			ClientUrls: member.ClientUrls,
 			Health:     true,
 		}
 		if _, ok := unhealthMembers[member.GetMemberId()]; ok {
-			h.Health = false
+			mh.Health = false
+		}
+		if c, ok := clocks[member.GetMemberId()]; ok && c.Error == "" {
+			offset := c.Offset
+			mh.ClockOffset = &offset
+			if c.Exceeded {
+				mh.Health = false
+			}
+		}
+		if member.GetMemberId() == h.svr.ID() {
+			mh.TSO = h.svr.GetTSOHealth()
 		}
-		healths = append(healths, h)
+		healths = append(healths, mh)
 	}
 	h.rd.JSON(w, http.StatusOK, healths)
 }
@@ -34,7 +34,7 @@ func (s *testHealthAPISuite) SetUpSuite(c *C) {
 }
 
 func checkSliceResponse(c *C, body []byte, cfgs []*server.Config, unhealth string) {
-	got := []health{}
+	got := []Health{}
 	json.Unmarshal(body, &got)
 
 	c.Assert(len(got), Equals, len(cfgs))
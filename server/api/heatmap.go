@@ -0,0 +1,125 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pingcap/pd/server"
+	"github.com/pingcap/pd/table"
+	"github.com/unrolled/render"
+)
+
+// Heatmap is the value of a stat of the key ranges over time, the value of
+// the key range [Keys[j], Keys[j+1]) at Times[i] is Values[i][j]. Labels[j]
+// describes where Keys[j] is in the table data. The keys are encoded in hex.
+type Heatmap struct {
+	Type   string     `json:"type"`
+	Times  []int64    `json:"times"`
+	Keys   []string   `json:"keys"`
+	Labels []string   `json:"labels"`
+	Values [][]uint64 `json:"values"`
+}
+
+type heatmapHandler struct {
+	*server.Handler
+	rd *render.Render
+}
+
+func newHeatmapHandler(handler *server.Handler, rd *render.Render) *heatmapHandler {
+	return &heatmapHandler{
+		Handler: handler,
+		rd:      rd,
+	}
+}
+
+// Get returns the heatmap of the stat specified by type in [start_key,
+// end_key) sampled in [start, end). The keys are encoded in hex. The type is
+// one of written_bytes, read_bytes, size and keys, and defaults to
+// written_bytes.
+func (h *heatmapHandler) Get(w http.ResponseWriter, r *http.Request) {
+	start, end, err := parseTimeRange(r)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := r.URL.Query()
+	startKey, err := hex.DecodeString(query.Get("start_key"))
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, "start_key should be encoded in hex")
+		return
+	}
+	endKey, err := hex.DecodeString(query.Get("end_key"))
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, "end_key should be encoded in hex")
+		return
+	}
+	if len(endKey) > 0 && string(startKey) >= string(endKey) {
+		h.rd.JSON(w, http.StatusBadRequest, "start_key should be less than end_key")
+		return
+	}
+	var buckets int
+	if v := query.Get("buckets"); v != "" {
+		if buckets, err = strconv.Atoi(v); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	typ := query.Get("type")
+	if typ == "" {
+		typ = "written_bytes"
+	}
+
+	heatmap, err := h.GetHeatmap(start, end, startKey, endKey, buckets)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var values [][]uint64
+	switch typ {
+	case "written_bytes":
+		values = heatmap.WrittenBytes
+	case "read_bytes":
+		values = heatmap.ReadBytes
+	case "size":
+		values = heatmap.Size
+	case "keys":
+		values = heatmap.KeyCount
+	default:
+		h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("invalid type %q", typ))
+		return
+	}
+
+	res := &Heatmap{
+		Type:   typ,
+		Times:  make([]int64, 0, len(heatmap.Times)),
+		Keys:   make([]string, 0, len(heatmap.Keys)),
+		Labels: make([]string, 0, len(heatmap.Keys)),
+		Values: values,
+	}
+	if res.Values == nil {
+		res.Values = [][]uint64{}
+	}
+	for _, t := range heatmap.Times {
+		res.Times = append(res.Times, t.Unix())
+	}
+	for _, key := range heatmap.Keys {
+		res.Keys = append(res.Keys, hex.EncodeToString(key))
+		res.Labels = append(res.Labels, table.Key(key).Describe())
+	}
+	h.rd.JSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server"
+)
+
+var _ = Suite(&testHeatmapSuite{})
+
+type testHeatmapSuite struct {
+	cfg       *server.Config
+	svr       *server.Server
+	urlPrefix string
+}
+
+func (s *testHeatmapSuite) SetUpSuite(c *C) {
+	s.cfg = server.NewTestSingleConfig()
+	s.cfg.Heatmap.SampleInterval.Duration = 50 * time.Millisecond
+	var err error
+	s.svr, err = server.CreateServer(s.cfg, NewHandler)
+	c.Assert(err, IsNil)
+	c.Assert(s.svr.Run(context.TODO()), IsNil)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/regions/heatmap", s.svr.GetAddr(), apiPrefix)
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testHeatmapSuite) TearDownSuite(c *C) {
+	s.svr.Close()
+	cleanServer(s.cfg)
+}
+
+func (s *testHeatmapSuite) TestHeatmap(c *C) {
+	r1 := newTestRegionInfo(10, 1, []byte(""), []byte("t\x80\x00\x00\x00\x00\x00\x00\xff\xff\x00\x00\x00\x00\x00\x00\x00\xf8"))
+	r1.WrittenBytes = 100
+	r2 := newTestRegionInfo(11, 1, r1.GetEndKey(), []byte(""))
+	r2.WrittenBytes = 200
+	mustRegionHeartbeat(c, s.svr, r1)
+	mustRegionHeartbeat(c, s.svr, r2)
+	since := time.Now().Unix()
+
+	var heatmap Heatmap
+	for i := 0; i < 100; i++ {
+		time.Sleep(50 * time.Millisecond)
+		err := readJSONWithURL(fmt.Sprintf("%s?start=%d&end=%d", s.urlPrefix, since, time.Now().Unix()+1), &heatmap)
+		c.Assert(err, IsNil)
+		if len(heatmap.Times) > 0 {
+			break
+		}
+	}
+	c.Assert(heatmap.Type, Equals, "written_bytes")
+	c.Assert(heatmap.Keys, DeepEquals, []string{"", hex.EncodeToString(r1.GetEndKey()), ""})
+	c.Assert(heatmap.Labels, DeepEquals, []string{"", "table 255", ""})
+	c.Assert(heatmap.Values, HasLen, len(heatmap.Times))
+	c.Assert(heatmap.Values[0], DeepEquals, []uint64{100, 200})
+
+	err := readJSONWithURL(s.urlPrefix+"?type=size&buckets=1", &heatmap)
+	c.Assert(err, IsNil)
+	c.Assert(heatmap.Keys, HasLen, 2)
+	c.Assert(heatmap.Values[len(heatmap.Values)-1], DeepEquals, []uint64{20})
+
+	err = readJSONWithURL(s.urlPrefix+"?end_key="+hex.EncodeToString(r1.GetEndKey()), &heatmap)
+	c.Assert(err, IsNil)
+	c.Assert(heatmap.Keys, DeepEquals, []string{"", hex.EncodeToString(r1.GetEndKey())})
+
+	for _, query := range []string{"?type=unknown", "?buckets=abc", "?start_key=62&end_key=61", "?start_key=b", "?end_key=zz"} {
+		resp, err := newHTTPClient().Get(s.urlPrefix + query)
+		c.Assert(err, IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+	}
+}
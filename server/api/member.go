@@ -39,13 +39,27 @@ func newMemberHandler(svr *server.Server, rd *render.Render) *memberHandler {
 	}
 }
 
+// MembersInfo contains the members of PD and their clocks.
+type MembersInfo struct {
+	*pdpb.GetMembersResponse
+	// Clocks are the clocks of members probed by the leader.
+	Clocks []*server.MemberClock `json:"clocks"`
+}
+
 func (h *memberHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
 	members, err := h.listMembers()
 	if err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	h.rd.JSON(w, http.StatusOK, members)
+	h.rd.JSON(w, http.StatusOK, &MembersInfo{
+		GetMembersResponse: members,
+		Clocks:             h.svr.GetMemberClocks(),
+	})
+}
+
+func (h *memberHandler) GetClock(w http.ResponseWriter, r *http.Request) {
+	h.rd.JSON(w, http.StatusOK, h.svr.GetServerClock())
 }
 
 func (h *memberHandler) listMembers() (*pdpb.GetMembersResponse, error) {
@@ -20,9 +20,11 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/testutil"
 	"github.com/pingcap/pd/server"
 )
 
@@ -100,3 +102,45 @@ func (s *testMemberAPISuite) TestMemberLeader(c *C) {
 	c.Assert(got.GetClientUrls(), DeepEquals, leader.GetClientUrls())
 	c.Assert(got.GetMemberId(), Equals, leader.GetMemberId())
 }
+
+func (s *testMemberAPISuite) TestMemberClock(c *C) {
+	// The clock API is served by the server which receives the request.
+	for _, cfg := range s.cfgs {
+		var clock server.ServerClock
+		err := readJSONWithURL(cfg.ClientUrls+server.ClockURL, &clock)
+		c.Assert(err, IsNil)
+		c.Assert(clock.Name, Equals, cfg.Name)
+		c.Assert(time.Since(time.Unix(0, clock.UnixNano)), Less, time.Minute)
+	}
+
+	// The leader probes the clocks of all members.
+	addr := s.cfgs[rand.Intn(len(s.cfgs))].ClientUrls + apiPrefix + "/api/v1/members"
+	testutil.WaitUntil(c, func(c *C) bool {
+		var got MembersInfo
+		c.Assert(readJSONWithURL(addr, &got), IsNil)
+		if len(got.Clocks) != len(s.cfgs) {
+			return false
+		}
+		for _, clock := range got.Clocks {
+			if clock.Error != "" {
+				return false
+			}
+			c.Assert(clock.Exceeded, IsFalse)
+		}
+		return true
+	})
+
+	addr = s.cfgs[rand.Intn(len(s.cfgs))].ClientUrls + apiPrefix + "/health"
+	var healths []Health
+	c.Assert(readJSONWithURL(addr, &healths), IsNil)
+	c.Assert(healths, HasLen, len(s.cfgs))
+	leaders := 0
+	for _, h := range healths {
+		c.Assert(h.Health, IsTrue)
+		c.Assert(h.ClockOffset, NotNil)
+		if h.TSO != nil {
+			leaders++
+		}
+	}
+	c.Assert(leaders, Equals, 1)
+}
@@ -14,10 +14,13 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/server"
 	"github.com/pingcap/pd/server/schedule"
 	"github.com/unrolled/render"
@@ -53,6 +56,39 @@ func (h *operatorHandler) Get(w http.ResponseWriter, r *http.Request) {
 	h.r.JSON(w, http.StatusOK, op)
 }
 
+type operatorStatus struct {
+	RegionID uint64 `json:"region_id"`
+	Desc     string `json:"desc"`
+	Kind     string `json:"kind"`
+	// Status is one of "running", "timeout" and "finished".
+	Status string `json:"status"`
+}
+
+func (h *operatorHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	regionID, err := strconv.ParseUint(mux.Vars(r)["region_id"], 10, 64)
+	if err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	op, err := h.GetOperator(regionID)
+	if err != nil {
+		if errors.Cause(err) == server.ErrOperatorNotFound {
+			h.r.JSON(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.r.JSON(w, http.StatusOK, &operatorStatus{
+		RegionID: op.RegionID(),
+		Desc:     op.Desc(),
+		Kind:     op.Kind().String(),
+		Status:   op.Status(),
+	})
+}
+
 func (h *operatorHandler) List(w http.ResponseWriter, r *http.Request) {
 	var (
 		results []*schedule.Operator
@@ -93,143 +129,267 @@ func (h *operatorHandler) Post(w http.ResponseWriter, r *http.Request) {
 	if err := readJSONRespondError(h.r, w, r.Body, &input); err != nil {
 		return
 	}
+	if status, err := addOperator(h.Handler, input); err != nil {
+		h.r.JSON(w, status, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, nil)
+}
+
+// operatorAdder adds operators, it is implemented by *server.Handler which
+// adds them at once and *server.OperatorBatch which collects them.
+type operatorAdder interface {
+	AddTransferLeaderOperator(regionID uint64, storeID uint64) error
+	AddTransferRegionOperator(regionID uint64, storeIDs map[uint64]struct{}) error
+	AddTransferPeerOperator(regionID uint64, fromStoreID, toStoreID uint64) error
+	AddAddPeerOperator(regionID uint64, toStoreID uint64) error
+	AddRemovePeerOperator(regionID uint64, fromStoreID uint64) error
+	AddMergeRegionOperator(regionID uint64, targetID uint64) error
+	AddSplitRegionOperator(regionID uint64, policy string) error
+	AddScatterRegionOperator(regionID uint64) error
+}
 
+// addOperator adds the operator described by the input, it returns the
+// status code of the response if it fails.
+func addOperator(adder operatorAdder, input map[string]interface{}) (int, error) {
 	name, ok := input["name"].(string)
 	if !ok {
-		h.r.JSON(w, http.StatusBadRequest, "missing operator name")
-		return
+		return http.StatusBadRequest, errors.New("missing operator name")
 	}
 
 	switch name {
 	case "transfer-leader":
 		regionID, ok := input["region_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing region id")
-			return
+			return http.StatusBadRequest, errors.New("missing region id")
 		}
 		storeID, ok := input["to_store_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing store id to transfer leader to")
-			return
+			return http.StatusBadRequest, errors.New("missing store id to transfer leader to")
 		}
-		if err := h.AddTransferLeaderOperator(uint64(regionID), uint64(storeID)); err != nil {
-			h.r.JSON(w, http.StatusInternalServerError, err.Error())
-			return
+		if err := adder.AddTransferLeaderOperator(uint64(regionID), uint64(storeID)); err != nil {
+			return http.StatusInternalServerError, err
 		}
 	case "transfer-region":
 		regionID, ok := input["region_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing region id")
-			return
+			return http.StatusBadRequest, errors.New("missing region id")
 		}
 		storeIDs, ok := parseStoreIDs(input["to_store_ids"])
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "invalid store ids to transfer region to")
-			return
+			return http.StatusBadRequest, errors.New("invalid store ids to transfer region to")
 		}
 		if len(storeIDs) == 0 {
-			h.r.JSON(w, http.StatusBadRequest, "missing store ids to transfer region to")
-			return
+			return http.StatusBadRequest, errors.New("missing store ids to transfer region to")
 		}
-		if err := h.AddTransferRegionOperator(uint64(regionID), storeIDs); err != nil {
-			h.r.JSON(w, http.StatusInternalServerError, err.Error())
-			return
+		if err := adder.AddTransferRegionOperator(uint64(regionID), storeIDs); err != nil {
+			return http.StatusInternalServerError, err
 		}
 	case "transfer-peer":
 		regionID, ok := input["region_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing region id")
-			return
+			return http.StatusBadRequest, errors.New("missing region id")
 		}
 		fromID, ok := input["from_store_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "invalid store id to transfer peer from")
-			return
+			return http.StatusBadRequest, errors.New("invalid store id to transfer peer from")
 		}
 		toID, ok := input["to_store_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "invalid store id to transfer peer to")
-			return
+			return http.StatusBadRequest, errors.New("invalid store id to transfer peer to")
 		}
-		if err := h.AddTransferPeerOperator(uint64(regionID), uint64(fromID), uint64(toID)); err != nil {
-			h.r.JSON(w, http.StatusInternalServerError, err.Error())
-			return
+		if err := adder.AddTransferPeerOperator(uint64(regionID), uint64(fromID), uint64(toID)); err != nil {
+			return http.StatusInternalServerError, err
 		}
 	case "add-peer":
 		regionID, ok := input["region_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing region id")
-			return
+			return http.StatusBadRequest, errors.New("missing region id")
 		}
 		storeID, ok := input["store_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "invalid store id to transfer peer to")
-			return
+			return http.StatusBadRequest, errors.New("invalid store id to transfer peer to")
 		}
-		if err := h.AddAddPeerOperator(uint64(regionID), uint64(storeID)); err != nil {
-			h.r.JSON(w, http.StatusInternalServerError, err.Error())
-			return
+		if err := adder.AddAddPeerOperator(uint64(regionID), uint64(storeID)); err != nil {
+			return http.StatusInternalServerError, err
 		}
 	case "remove-peer":
 		regionID, ok := input["region_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing region id")
-			return
+			return http.StatusBadRequest, errors.New("missing region id")
 		}
 		storeID, ok := input["store_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "invalid store id to transfer peer to")
-			return
+			return http.StatusBadRequest, errors.New("invalid store id to transfer peer to")
 		}
-		if err := h.AddRemovePeerOperator(uint64(regionID), uint64(storeID)); err != nil {
-			h.r.JSON(w, http.StatusInternalServerError, err.Error())
-			return
+		if err := adder.AddRemovePeerOperator(uint64(regionID), uint64(storeID)); err != nil {
+			return http.StatusInternalServerError, err
 		}
 	case "merge-region":
 		regionID, ok := input["source_region_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing region id")
-			return
+			return http.StatusBadRequest, errors.New("missing region id")
 		}
 		targetID, ok := input["target_region_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "invalid target region id to merge to")
-			return
+			return http.StatusBadRequest, errors.New("invalid target region id to merge to")
 		}
-		if err := h.AddMergeRegionOperator(uint64(regionID), uint64(targetID)); err != nil {
-			h.r.JSON(w, http.StatusInternalServerError, err.Error())
-			return
+		if err := adder.AddMergeRegionOperator(uint64(regionID), uint64(targetID)); err != nil {
+			return http.StatusInternalServerError, err
 		}
 	case "split-region":
 		regionID, ok := input["region_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing region id")
-			return
+			return http.StatusBadRequest, errors.New("missing region id")
 		}
 		policy, ok := input["policy"].(string)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing split policy")
-			return
+			return http.StatusBadRequest, errors.New("missing split policy")
 		}
-		if err := h.AddSplitRegionOperator(uint64(regionID), policy); err != nil {
-			h.r.JSON(w, http.StatusInternalServerError, err.Error())
-			return
+		if err := adder.AddSplitRegionOperator(uint64(regionID), policy); err != nil {
+			return http.StatusInternalServerError, err
 		}
 	case "scatter-region":
 		regionID, ok := input["region_id"].(float64)
 		if !ok {
-			h.r.JSON(w, http.StatusBadRequest, "missing region id")
+			return http.StatusBadRequest, errors.New("missing region id")
+		}
+		if err := adder.AddScatterRegionOperator(uint64(regionID)); err != nil {
+			return http.StatusInternalServerError, err
+		}
+	default:
+		return http.StatusBadRequest, errors.New("unknown operator")
+	}
+
+	return http.StatusOK, nil
+}
+
+type operatorBatchInput struct {
+	Operators []map[string]interface{} `json:"operators"`
+}
+
+type operatorBatchResult struct {
+	ID uint64 `json:"id"`
+}
+
+// PostBatch validates all the operators first, then adds them all or nothing.
+func (h *operatorHandler) PostBatch(w http.ResponseWriter, r *http.Request) {
+	var input operatorBatchInput
+	if err := readJSONRespondError(h.r, w, r.Body, &input); err != nil {
+		return
+	}
+	if len(input.Operators) == 0 {
+		h.r.JSON(w, http.StatusBadRequest, "missing operators")
+		return
+	}
+
+	batch, err := h.NewOperatorBatch()
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for i, op := range input.Operators {
+		if err := expectRegionEpoch(batch, op); err != nil {
+			h.r.JSON(w, http.StatusBadRequest, fmt.Sprintf("operator %d: %s", i, err))
 			return
 		}
-		if err := h.AddScatterRegionOperator(uint64(regionID)); err != nil {
-			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		status, err := addOperator(batch, op)
+		switch errors.Cause(err) {
+		case nil:
+			continue
+		case server.ErrOperatorBatchTooLarge, server.ErrOperatorBatchConflict:
+			status = http.StatusBadRequest
+		case server.ErrOperatorBatchRejected:
+			status = http.StatusConflict
+		}
+		h.r.JSON(w, status, fmt.Sprintf("operator %d: %s", i, err))
+		return
+	}
+
+	id, err := h.SubmitOperatorBatch(batch)
+	if err != nil {
+		if errors.Cause(err) == server.ErrOperatorBatchRejected {
+			h.r.JSON(w, http.StatusConflict, err.Error())
 			return
 		}
-	default:
-		h.r.JSON(w, http.StatusBadRequest, "unknown operator")
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	h.r.JSON(w, http.StatusOK, &operatorBatchResult{ID: id})
+}
 
+// expectRegionEpoch passes the optional region epoch of the operator to the
+// batch, it's the epoch of the region in region_id, or source_region_id for
+// merge-region.
+func expectRegionEpoch(batch *server.OperatorBatch, input map[string]interface{}) error {
+	v, ok := input["region_epoch"]
+	if !ok {
+		return nil
+	}
+	epoch, ok := v.(map[string]interface{})
+	if !ok {
+		return errors.New("invalid region epoch")
+	}
+	// The fields are omitted if zero, like the regions responded by the API.
+	var confVer, version float64
+	for name, field := range map[string]*float64{"conf_ver": &confVer, "version": &version} {
+		if v, ok := epoch[name]; ok {
+			if *field, ok = v.(float64); !ok {
+				return errors.Errorf("invalid %s of region epoch", name)
+			}
+		}
+	}
+	regionID, ok := input["region_id"].(float64)
+	if !ok {
+		if regionID, ok = input["source_region_id"].(float64); !ok {
+			return errors.New("missing region id")
+		}
+	}
+	batch.ExpectRegionEpoch(uint64(regionID), &metapb.RegionEpoch{ConfVer: uint64(confVer), Version: uint64(version)})
+	return nil
+}
+
+func (h *operatorHandler) ListBatches(w http.ResponseWriter, r *http.Request) {
+	batches, err := h.GetOperatorBatches()
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, batches)
+}
+
+func (h *operatorHandler) GetBatch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	status, err := h.GetOperatorBatch(id)
+	if err != nil {
+		if errors.Cause(err) == server.ErrOperatorBatchNotFound {
+			h.r.JSON(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, status)
+}
+
+func (h *operatorHandler) DeleteBatch(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.CancelOperatorBatch(id); err != nil {
+		if errors.Cause(err) == server.ErrOperatorBatchNotFound {
+			h.r.JSON(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 	h.r.JSON(w, http.StatusOK, nil)
 }
 
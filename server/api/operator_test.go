@@ -15,6 +15,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -69,8 +70,18 @@ func (s *testOperatorSuite) TestAddRemovePeer(c *C) {
 	operator = mustReadURL(c, regionURL)
 	c.Assert(strings.Contains(operator, "add learner peer 1 on store 3"), IsTrue)
 
+	var status operatorStatus
+	c.Assert(readJSONWithURL(regionURL+"/status", &status), IsNil)
+	c.Assert(status.RegionID, Equals, region.GetId())
+	c.Assert(status.Kind, Matches, ".*region.*")
+	c.Assert(status.Status, Equals, "running")
+
 	err = doDelete(regionURL)
 	c.Assert(err, IsNil)
+	res, err := http.Get(regionURL + "/status")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusNotFound)
 
 	err = postJSON(fmt.Sprintf("%s/operators", s.urlPrefix), []byte(`{"name":"remove-peer", "region_id": 1, "store_id": 2}`))
 	c.Assert(err, IsNil)
@@ -107,3 +118,108 @@ func mustReadURL(c *C, url string) string {
 	c.Assert(err, IsNil)
 	return string(data)
 }
+
+var _ = Suite(&testOperatorBatchSuite{})
+
+type testOperatorBatchSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testOperatorBatchSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testOperatorBatchSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testOperatorBatchSuite) postBatch(c *C, ops string) (int, string) {
+	resp, err := http.Post(s.urlPrefix+"/operators/batch", "application/json", strings.NewReader(`{"operators":[`+ops+`]}`))
+	c.Assert(err, IsNil)
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	c.Assert(err, IsNil)
+	return resp.StatusCode, string(data)
+}
+
+func (s *testOperatorBatchSuite) TestBatch(c *C) {
+	for id := uint64(1); id <= 3; id++ {
+		mustPutStore(c, s.svr, id, metapb.StoreState_Up, nil)
+	}
+	storeHeartbeat := func(id uint64) {
+		_, err := s.svr.StoreHeartbeat(context.Background(), &pdpb.StoreHeartbeatRequest{
+			Header: &pdpb.RequestHeader{ClusterId: s.svr.ClusterID()},
+			Stats:  &pdpb.StoreStats{StoreId: id},
+		})
+		c.Assert(err, IsNil)
+	}
+	storeHeartbeat(1)
+	storeHeartbeat(2)
+	peers := func(regionID uint64) []*metapb.Peer {
+		return []*metapb.Peer{{Id: regionID*10 + 1, StoreId: 1}, {Id: regionID*10 + 2, StoreId: 2}}
+	}
+	for i, key := range []string{"a", "b", "c", "d", "e", "f"} {
+		id := uint64(i + 10)
+		region := &metapb.Region{Id: id, StartKey: []byte(key), EndKey: []byte(key + "z"), Peers: peers(id)}
+		mustRegionHeartbeat(c, s.svr, core.NewRegionInfo(region, region.Peers[0]))
+	}
+
+	// More than one operator of a region.
+	code, _ := s.postBatch(c, `{"name":"transfer-leader","region_id":10,"to_store_id":2},{"name":"add-peer","region_id":10,"store_id":3}`)
+	c.Assert(code, Equals, http.StatusBadRequest)
+	// The second operator is invalid, so the first one is not added.
+	code, res := s.postBatch(c, `{"name":"transfer-leader","region_id":12,"to_store_id":2},{"name":"add-peer","region_id":99,"store_id":3}`)
+	c.Assert(code, Equals, http.StatusInternalServerError)
+	c.Assert(strings.Contains(res, "operator 1: region 99 not found"), IsTrue)
+	c.Assert(strings.Contains(mustReadURL(c, fmt.Sprintf("%s/operators/12", s.urlPrefix)), "operator not found"), IsTrue)
+	code, _ = s.postBatch(c, "")
+	c.Assert(code, Equals, http.StatusBadRequest)
+	// The store 3 has not sent heartbeats yet.
+	code, res = s.postBatch(c, `{"name":"transfer-leader","region_id":10,"to_store_id":2},{"name":"add-peer","region_id":11,"store_id":3}`)
+	c.Assert(code, Equals, http.StatusConflict)
+	c.Assert(strings.Contains(res, "[region 11] operator adminAddPeer: store 3 is rejected"), IsTrue)
+	storeHeartbeat(3)
+	// The region is changed since the caller got the epoch.
+	code, res = s.postBatch(c, `{"name":"add-peer","region_id":11,"store_id":3,"region_epoch":{"conf_ver":1,"version":1}}`)
+	c.Assert(code, Equals, http.StatusConflict)
+	c.Assert(strings.Contains(res, "operator 0: [region 11] region epoch"), IsTrue)
+	code, _ = s.postBatch(c, `{"name":"add-peer","region_id":11,"store_id":3,"region_epoch":{"version":"1"}}`)
+	c.Assert(code, Equals, http.StatusBadRequest)
+
+	// The batch is not limited by the schedule limits, the epoch fields are
+	// omitted if zero.
+	ops := `{"name":"transfer-leader","region_id":10,"to_store_id":2,"region_epoch":{}}`
+	for id := 11; id <= 15; id++ {
+		ops += fmt.Sprintf(`,{"name":"add-peer","region_id":%d,"store_id":3}`, id)
+	}
+	code, res = s.postBatch(c, ops)
+	c.Assert(code, Equals, http.StatusOK)
+	var result operatorBatchResult
+	c.Assert(json.Unmarshal([]byte(res), &result), IsNil)
+	batchURL := fmt.Sprintf("%s/operators/batch/%d", s.urlPrefix, result.ID)
+	var status server.OperatorBatchStatus
+	c.Assert(readJSONWithURL(batchURL, &status), IsNil)
+	c.Assert(status.Total, Equals, 6)
+	c.Assert(status.Running, Equals, 6)
+	var batches []*server.OperatorBatchStatus
+	c.Assert(readJSONWithURL(s.urlPrefix+"/operators/batch", &batches), IsNil)
+	c.Assert(batches, HasLen, 1)
+
+	c.Assert(doDelete(batchURL), IsNil)
+	c.Assert(readJSONWithURL(batchURL, &status), IsNil)
+	c.Assert(status.Canceled, Equals, 6)
+	c.Assert(strings.Contains(mustReadURL(c, fmt.Sprintf("%s/operators/11", s.urlPrefix)), "operator not found"), IsTrue)
+
+	resp, err := http.Get(fmt.Sprintf("%s/operators/batch/%d", s.urlPrefix, result.ID+1))
+	c.Assert(err, IsNil)
+	resp.Body.Close()
+	c.Assert(resp.StatusCode, Equals, http.StatusNotFound)
+}
@@ -40,8 +40,13 @@ func newRedirector(s *server.Server) *redirector {
 	return &redirector{s: s}
 }
 
+// localAPIs are served by the server which receives the request.
+var localAPIs = map[string]struct{}{
+	server.ClockURL: {},
+}
+
 func (h *redirector) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-	if h.s.IsLeader() {
+	if _, ok := localAPIs[r.URL.Path]; ok || h.s.IsLeader() {
 		next(w, r)
 		return
 	}
@@ -54,6 +59,7 @@ func (h *redirector) ServeHTTP(w http.ResponseWriter, r *http.Request, next http
 	}
 
 	r.Header.Set(redirectorHeader, h.s.Name())
+	r.Header.Set(sourceHeader, r.RemoteAddr)
 
 	leader, err := h.s.GetLeader()
 	if err != nil {
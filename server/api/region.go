@@ -14,13 +14,18 @@
 package api
 
 import (
+	"bytes"
 	"container/heap"
+	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/juju/errors"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/server"
@@ -28,7 +33,8 @@ import (
 	"github.com/unrolled/render"
 )
 
-type regionInfo struct {
+// RegionInfo records detail region info for api usage.
+type RegionInfo struct {
 	ID          uint64              `json:"id"`
 	StartKey    string              `json:"start_key"`
 	EndKey      string              `json:"end_key"`
@@ -44,11 +50,11 @@ type regionInfo struct {
 	ApproximateKeys int64             `json:"approximate_keys,omitempty"`
 }
 
-func newRegionInfo(r *core.RegionInfo) *regionInfo {
+func newRegionInfo(r *core.RegionInfo) *RegionInfo {
 	if r == nil {
 		return nil
 	}
-	return &regionInfo{
+	return &RegionInfo{
 		ID:              r.Id,
 		StartKey:        strings.Trim(fmt.Sprintf("%q", r.StartKey), "\""),
 		EndKey:          strings.Trim(fmt.Sprintf("%q", r.EndKey), "\""),
@@ -64,9 +70,30 @@ func newRegionInfo(r *core.RegionInfo) *regionInfo {
 	}
 }
 
-type regionsInfo struct {
+// regionMeta is the region meta and its leader with the raw keys, it is used
+// by the clients which need the exact region meta.
+type regionMeta struct {
+	Region *metapb.Region `json:"region"`
+	Leader *metapb.Peer   `json:"leader,omitempty"`
+}
+
+func newRegionMeta(r *core.RegionInfo) *regionMeta {
+	if r == nil {
+		return nil
+	}
+	return &regionMeta{
+		Region: r.Region,
+		Leader: r.Leader,
+	}
+}
+
+// RegionsInfo contains some regions with the detailed region info.
+type RegionsInfo struct {
 	Count   int           `json:"count"`
-	Regions []*regionInfo `json:"regions"`
+	Regions []*RegionInfo `json:"regions"`
+	// Next is the continuation token to get the next page of the regions, it
+	// is empty if there are no more regions.
+	Next string `json:"next,omitempty"`
 }
 
 type regionHandler struct {
@@ -112,6 +139,38 @@ func (h *regionHandler) GetRegionByKey(w http.ResponseWriter, r *http.Request) {
 	h.rd.JSON(w, http.StatusOK, newRegionInfo(regionInfo))
 }
 
+func (h *regionHandler) GetPrevRegion(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+	key := r.URL.Query().Get("key")
+	regionInfo := cluster.GetPrevRegionInfoByKey([]byte(key))
+	h.rd.JSON(w, http.StatusOK, newRegionMeta(regionInfo))
+}
+
+func (h *regionHandler) GetRegionHistory(w http.ResponseWriter, r *http.Request) {
+	cluster := h.svr.GetRaftCluster()
+	if cluster == nil {
+		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
+		return
+	}
+
+	vars := mux.Vars(r)
+	regionID, err := strconv.ParseUint(vars["id"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history := cluster.GetRegionHistory(regionID)
+	if history == nil {
+		history = []*server.RegionChange{}
+	}
+	h.rd.JSON(w, http.StatusOK, history)
+}
+
 type regionsHandler struct {
 	svr *server.Server
 	rd  *render.Render
@@ -130,19 +189,207 @@ func (h *regionsHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
 		return
 	}
+	if isRegionListQuery(r.URL.Query()) {
+		h.listRegions(w, r, cluster)
+		return
+	}
 
 	regions := cluster.GetMetaRegions()
-	regionInfos := make([]*regionInfo, len(regions))
+	regionInfos := make([]*RegionInfo, len(regions))
 	for i, r := range regions {
 		regionInfos[i] = newRegionInfo(&core.RegionInfo{Region: r})
 	}
-	regionsInfo := &regionsInfo{
+	regionsInfo := &RegionsInfo{
 		Count:   len(regions),
 		Regions: regionInfos,
 	}
 	h.rd.JSON(w, http.StatusOK, regionsInfo)
 }
 
+// regionListParams are the query parameters of listing regions page by page.
+// All regions are returned if none of them is specified.
+var regionListParams = []string{
+	"start_key", "end_key", "limit", "next", "sort_by",
+	"store_id", "leader_store_id", "peer_state",
+	"min_size", "max_size", "min_keys", "max_keys",
+}
+
+func isRegionListQuery(query url.Values) bool {
+	for _, name := range regionListParams {
+		if _, ok := query[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// regionSortFuncs are the less functions of the fields which the regions can
+// be sorted by.
+var regionSortFuncs = map[string]func(a, b *core.RegionInfo) bool{
+	"size":  func(a, b *core.RegionInfo) bool { return a.ApproximateSize < b.ApproximateSize },
+	"keys":  func(a, b *core.RegionInfo) bool { return a.ApproximateKeys < b.ApproximateKeys },
+	"read":  func(a, b *core.RegionInfo) bool { return a.ReadBytes < b.ReadBytes },
+	"write": func(a, b *core.RegionInfo) bool { return a.WrittenBytes < b.WrittenBytes },
+}
+
+// maxSortedRegionScan is the max number of the regions scanned to sort for a
+// page, so that a page doesn't hold the cluster lock to scan all the regions.
+var maxSortedRegionScan = 102400
+
+// listRegions lists the regions which overlap with [start_key, end_key) and
+// match the filters page by page. The next page starts from the end key of
+// the last region, which is returned as the continuation token. If sort_by is
+// specified, the top limit regions among at most maxSortedRegionScan regions
+// are returned in descending order, and the continuation token is returned if
+// the rest of the range is not scanned.
+func (h *regionsHandler) listRegions(w http.ResponseWriter, r *http.Request, cluster *server.RaftCluster) {
+	limit, err := parseRegionLimit(r)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit <= 0 {
+		h.rd.JSON(w, http.StatusBadRequest, "limit should be positive")
+		return
+	}
+	query := r.URL.Query()
+	startKey, endKey := []byte(query.Get("start_key")), []byte(query.Get("end_key"))
+	if next := query.Get("next"); next != "" {
+		if startKey, err = base64.RawURLEncoding.DecodeString(next); err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, "invalid continuation token")
+			return
+		}
+	}
+	opts, err := parseRegionFilters(query)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var regions []*core.RegionInfo
+	res := &RegionsInfo{}
+	if sortBy := query.Get("sort_by"); sortBy != "" {
+		less, ok := regionSortFuncs[sortBy]
+		if !ok {
+			h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("unknown sort_by %q", sortBy))
+			return
+		}
+		scanned := cluster.ScanRegions(startKey, endKey, maxSortedRegionScan, opts...)
+		res.Next = nextRegionToken(scanned, endKey, maxSortedRegionScan)
+		regions = topNRegions(scanned, less, limit)
+	} else {
+		regions = cluster.ScanRegions(startKey, endKey, limit, opts...)
+		res.Next = nextRegionToken(regions, endKey, limit)
+	}
+
+	res.Count = len(regions)
+	res.Regions = make([]*RegionInfo, 0, len(regions))
+	for _, region := range regions {
+		res.Regions = append(res.Regions, newRegionInfo(region))
+	}
+	h.rd.JSON(w, http.StatusOK, res)
+}
+
+// nextRegionToken returns the continuation token of the scanned regions, it's
+// empty if the scan didn't reach the limit or has reached the end key.
+func nextRegionToken(regions []*core.RegionInfo, endKey []byte, limit int) string {
+	if len(regions) < limit {
+		return ""
+	}
+	next := regions[len(regions)-1].GetEndKey()
+	if len(next) == 0 || (len(endKey) != 0 && bytes.Compare(next, endKey) >= 0) {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(next)
+}
+
+// parseRegionFilters parses the filters of listing regions. The peer_state
+// filter is applied to the peer in the store if store_id is specified.
+func parseRegionFilters(query url.Values) ([]core.RegionOption, error) {
+	var opts []core.RegionOption
+	parseUint := func(name string) (uint64, bool, error) {
+		v := query.Get(name)
+		if v == "" {
+			return 0, false, nil
+		}
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, false, errors.Annotatef(err, "invalid %s", name)
+		}
+		return n, true, nil
+	}
+
+	storeID, hasStore, err := parseUint("store_id")
+	if err != nil {
+		return nil, err
+	}
+	if hasStore {
+		opts = append(opts, core.PeerInStore(storeID))
+	}
+	if leaderStoreID, ok, err := parseUint("leader_store_id"); err != nil {
+		return nil, err
+	} else if ok {
+		opts = append(opts, core.LeaderInStore(leaderStoreID))
+	}
+
+	if state := query.Get("peer_state"); state != "" {
+		var hasState func(region *core.RegionInfo, peer *metapb.Peer) bool
+		switch state {
+		case "pending":
+			hasState = func(region *core.RegionInfo, peer *metapb.Peer) bool {
+				return region.GetPendingPeer(peer.GetId()) != nil
+			}
+		case "down":
+			hasState = func(region *core.RegionInfo, peer *metapb.Peer) bool {
+				return region.GetDownPeer(peer.GetId()) != nil
+			}
+		case "learner":
+			hasState = func(region *core.RegionInfo, peer *metapb.Peer) bool {
+				return peer.GetIsLearner()
+			}
+		default:
+			return nil, errors.Errorf("unknown peer_state %q", state)
+		}
+		opts = append(opts, func(region *core.RegionInfo) bool {
+			for _, peer := range region.GetPeers() {
+				if (!hasStore || peer.GetStoreId() == storeID) && hasState(region, peer) {
+					return true
+				}
+			}
+			return false
+		})
+	}
+
+	bounds := []struct {
+		name  string
+		value func(*core.RegionInfo) int64
+		min   bool
+	}{
+		{"min_size", func(r *core.RegionInfo) int64 { return r.ApproximateSize }, true},
+		{"max_size", func(r *core.RegionInfo) int64 { return r.ApproximateSize }, false},
+		{"min_keys", func(r *core.RegionInfo) int64 { return r.ApproximateKeys }, true},
+		{"max_keys", func(r *core.RegionInfo) int64 { return r.ApproximateKeys }, false},
+	}
+	for _, b := range bounds {
+		v := query.Get(b.name)
+		if v == "" {
+			continue
+		}
+		bound, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.Annotatef(err, "invalid %s", b.name)
+		}
+		value, min := b.value, b.min
+		opts = append(opts, func(region *core.RegionInfo) bool {
+			if min {
+				return value(region) >= bound
+			}
+			return value(region) <= bound
+		})
+	}
+	return opts, nil
+}
+
 func (h *regionsHandler) GetMissPeerRegions(w http.ResponseWriter, r *http.Request) {
 	handler := h.svr.GetHandler()
 	res, err := handler.GetMissPeerRegions()
@@ -183,6 +430,67 @@ func (h *regionsHandler) GetDownPeerRegions(w http.ResponseWriter, r *http.Reque
 	h.rd.JSON(w, http.StatusOK, res)
 }
 
+// defaultNoHeartbeatThreshold is the default threshold of the regions which
+// are regarded as no heartbeat, it is long enough to tolerate the leader
+// election and the heartbeat interval of TiKV.
+const defaultNoHeartbeatThreshold = 10 * time.Minute
+
+func (h *regionsHandler) GetNoHeartbeatRegions(w http.ResponseWriter, r *http.Request) {
+	threshold := defaultNoHeartbeatThreshold
+	if thresholdStr := r.URL.Query().Get("threshold"); thresholdStr != "" {
+		var err error
+		threshold, err = time.ParseDuration(thresholdStr)
+		if err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	handler := h.svr.GetHandler()
+	res, err := handler.GetNoHeartbeatRegions(threshold)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, res)
+}
+
+// KeyRange is a key range with the escaped keys.
+type KeyRange struct {
+	StartKey string `json:"start_key"`
+	EndKey   string `json:"end_key"`
+}
+
+// KeyRangeCheck is the result of checking the key ranges of the regions.
+type KeyRangeCheck struct {
+	// Holes are the key ranges not covered by any region.
+	Holes []*KeyRange `json:"holes"`
+	// Overlaps are the pairs of regions overlapped with each other.
+	Overlaps [][2]*RegionInfo `json:"overlaps"`
+}
+
+func (h *regionsHandler) CheckKeyRange(w http.ResponseWriter, r *http.Request) {
+	handler := h.svr.GetHandler()
+	holes, overlaps, err := handler.CheckRegionKeyRange()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	res := &KeyRangeCheck{
+		Holes:    make([]*KeyRange, 0, len(holes)),
+		Overlaps: make([][2]*RegionInfo, 0, len(overlaps)),
+	}
+	for _, hole := range holes {
+		res.Holes = append(res.Holes, &KeyRange{
+			StartKey: strings.Trim(fmt.Sprintf("%q", hole.StartKey), "\""),
+			EndKey:   strings.Trim(fmt.Sprintf("%q", hole.EndKey), "\""),
+		})
+	}
+	for _, pair := range overlaps {
+		res.Overlaps = append(res.Overlaps, [2]*RegionInfo{newRegionInfo(pair[0]), newRegionInfo(pair[1])})
+	}
+	h.rd.JSON(w, http.StatusOK, res)
+}
+
 func (h *regionsHandler) GetIncorrectNamespaceRegions(w http.ResponseWriter, r *http.Request) {
 	handler := h.svr.GetHandler()
 	res, err := handler.GetIncorrectNamespaceRegions()
@@ -213,7 +521,7 @@ func (h *regionsHandler) GetRegionSiblings(w http.ResponseWriter, r *http.Reques
 	}
 
 	left, right := cluster.GetAdjacentRegions(region)
-	res := []*regionInfo{newRegionInfo(left), newRegionInfo(right)}
+	res := []*RegionInfo{newRegionInfo(left), newRegionInfo(right)}
 	h.rd.JSON(w, http.StatusOK, res)
 }
 
@@ -222,6 +530,21 @@ const (
 	maxRegionLimit     = 10240
 )
 
+func parseRegionLimit(r *http.Request) (int, error) {
+	limit := defaultRegionLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	if limit > maxRegionLimit {
+		limit = maxRegionLimit
+	}
+	return limit, nil
+}
+
 func (h *regionsHandler) GetTopWriteFlow(w http.ResponseWriter, r *http.Request) {
 	h.GetTopNRegions(w, r, func(a, b *core.RegionInfo) bool { return a.WrittenBytes < b.WrittenBytes })
 }
@@ -236,24 +559,17 @@ func (h *regionsHandler) GetTopNRegions(w http.ResponseWriter, r *http.Request,
 		h.rd.JSON(w, http.StatusInternalServerError, server.ErrNotBootstrapped.Error())
 		return
 	}
-	limit := defaultRegionLimit
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		var err error
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil {
-			h.rd.JSON(w, http.StatusBadRequest, err.Error())
-			return
-		}
-	}
-	if limit > maxRegionLimit {
-		limit = maxRegionLimit
+	limit, err := parseRegionLimit(r)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
 	}
 	regions := topNRegions(cluster.GetRegions(), less, limit)
-	regionInfos := make([]*regionInfo, len(regions))
+	regionInfos := make([]*RegionInfo, len(regions))
 	for i, r := range regions {
 		regionInfos[i] = newRegionInfo(r)
 	}
-	res := &regionsInfo{
+	res := &RegionsInfo{
 		Count:   len(regions),
 		Regions: regionInfos,
 	}
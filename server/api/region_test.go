@@ -16,6 +16,8 @@ package api
 import (
 	"fmt"
 	"math/rand"
+	"net/http"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -68,13 +70,13 @@ func (s *testRegionSuite) TestRegion(c *C) {
 	r := newTestRegionInfo(2, 1, []byte("a"), []byte("b"))
 	mustRegionHeartbeat(c, s.svr, r)
 	url := fmt.Sprintf("%s/region/id/%d", s.urlPrefix, r.GetId())
-	r1 := &regionInfo{}
+	r1 := &RegionInfo{}
 	err := readJSONWithURL(url, r1)
 	c.Assert(err, IsNil)
 	c.Assert(r1, DeepEquals, newRegionInfo(r))
 
 	url = fmt.Sprintf("%s/region/key/%s", s.urlPrefix, "a")
-	r2 := &regionInfo{}
+	r2 := &RegionInfo{}
 	err = readJSONWithURL(url, r2)
 	c.Assert(err, IsNil)
 	c.Assert(r2, DeepEquals, newRegionInfo(r))
@@ -96,7 +98,7 @@ func (s *testRegionSuite) TestTopFlow(c *C) {
 }
 
 func (s *testRegionSuite) checkTopFlow(c *C, url string, regionIDs []uint64) {
-	regions := &regionsInfo{}
+	regions := &RegionsInfo{}
 	err := readJSONWithURL(url, regions)
 	c.Assert(err, IsNil)
 	c.Assert(regions.Count, Equals, len(regionIDs))
@@ -126,3 +128,214 @@ func (s *testRegionSuite) TestTopN(c *C) {
 		}
 	}
 }
+
+var _ = Suite(&testRegionScanSuite{})
+
+type testRegionScanSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testRegionScanSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testRegionScanSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testRegionScanSuite) TestGetPrevRegion(c *C) {
+	r1 := newTestRegionInfo(11, 1, []byte("w"), []byte("x"))
+	r2 := newTestRegionInfo(12, 1, []byte("x"), []byte("y"))
+	r3 := newTestRegionInfo(13, 1, []byte("y"), []byte("z"))
+	for _, r := range []*core.RegionInfo{r1, r2, r3} {
+		mustRegionHeartbeat(c, s.svr, r)
+	}
+
+	var meta *regionMeta
+	url := fmt.Sprintf("%s/region/prev?key=%s", s.urlPrefix, "ya")
+	c.Assert(readJSONWithURL(url, &meta), IsNil)
+	c.Assert(meta, DeepEquals, newRegionMeta(r2))
+	// There is a hole before the region w-x.
+	url = fmt.Sprintf("%s/region/prev?key=%s", s.urlPrefix, "w")
+	c.Assert(readJSONWithURL(url, &meta), IsNil)
+	c.Assert(meta, IsNil)
+}
+
+func (s *testRegionScanSuite) TestListRegions(c *C) {
+	r1 := newTestRegionInfo(21, 1, []byte("a"), []byte("b"))
+	r1.WrittenBytes = 100
+	r2 := newTestRegionInfo(22, 2, []byte("b"), []byte("c"))
+	r2.Peers = append(r2.Peers, &metapb.Peer{Id: 122, StoreId: 1})
+	r2.PendingPeers = []*metapb.Peer{r2.Peers[1]}
+	r2.ApproximateSize, r2.WrittenBytes = 30, 200
+	r3 := newTestRegionInfo(23, 1, []byte("c"), []byte("d"))
+	r3.ApproximateSize, r3.WrittenBytes = 20, 300
+	r4 := newTestRegionInfo(24, 2, []byte("d"), []byte("e"))
+	for _, r := range []*core.RegionInfo{r1, r2, r3, r4} {
+		mustRegionHeartbeat(c, s.svr, r)
+	}
+
+	list := func(query string, ids ...uint64) string {
+		regions := &RegionsInfo{}
+		url := fmt.Sprintf("%s/regions?start_key=a&end_key=e&%s", s.urlPrefix, query)
+		c.Assert(readJSONWithURL(url, regions), IsNil)
+		c.Assert(regions.Count, Equals, len(ids))
+		for i, r := range regions.Regions {
+			c.Assert(r.ID, Equals, ids[i])
+		}
+		return regions.Next
+	}
+
+	// The last page reaches the end key, so there is no next page.
+	next := list("limit=2", 21, 22)
+	c.Assert(next, Not(Equals), "")
+	c.Assert(list("limit=2&next="+next, 23, 24), Equals, "")
+
+	list("store_id=1", 21, 22, 23)
+	list("leader_store_id=2", 22, 24)
+	list("peer_state=pending", 22)
+	list("store_id=2&peer_state=pending")
+	list("min_size=20", 22, 23)
+	list("max_size=10&max_keys=10", 21, 24)
+	c.Assert(list("sort_by=write&limit=2", 23, 22), Equals, "")
+
+	// The sorted page only scans the first maxSortedRegionScan regions.
+	defer func(n int) { maxSortedRegionScan = n }(maxSortedRegionScan)
+	maxSortedRegionScan = 3
+	next = list("sort_by=write&limit=2", 23, 22)
+	c.Assert(next, Not(Equals), "")
+	c.Assert(list("sort_by=write&limit=2&next="+next, 24), Equals, "")
+
+	// The unknown parameters don't turn on listing page by page.
+	all, unknown := &RegionsInfo{}, &RegionsInfo{}
+	c.Assert(readJSONWithURL(s.urlPrefix+"/regions", all), IsNil)
+	c.Assert(readJSONWithURL(s.urlPrefix+"/regions?_=1", unknown), IsNil)
+	c.Assert(unknown.Count, Equals, all.Count)
+	c.Assert(unknown.Next, Equals, "")
+
+	for _, query := range []string{"sort_by=name", "peer_state=up", "store_id=a", "next=!", "limit=0"} {
+		resp, err := http.Get(fmt.Sprintf("%s/regions?%s", s.urlPrefix, query))
+		c.Assert(err, IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+	}
+}
+
+var _ = Suite(&testRegionHistorySuite{})
+
+type testRegionHistorySuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testRegionHistorySuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testRegionHistorySuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testRegionHistorySuite) TestRegionHistory(c *C) {
+	r := newTestRegionInfo(10, 1, []byte("x"), []byte("z"))
+	r.RegionEpoch = &metapb.RegionEpoch{Version: 1, ConfVer: 1}
+	mustRegionHeartbeat(c, s.svr, r)
+	r = r.Clone()
+	r.EndKey = []byte("y")
+	r.RegionEpoch = &metapb.RegionEpoch{Version: 2, ConfVer: 1}
+	mustRegionHeartbeat(c, s.svr, r)
+
+	url := fmt.Sprintf("%s/region/id/%d/history", s.urlPrefix, r.GetId())
+	var changes []*server.RegionChange
+	err := readJSONWithURL(url, &changes)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[0].Kind, Equals, server.RegionChangeCreate)
+	c.Assert(changes[1].Kind, Equals, server.RegionChangeVersion)
+	c.Assert(changes[1].ToEpoch.GetVersion(), Equals, uint64(2))
+
+	url = fmt.Sprintf("%s/region/id/%d/history", s.urlPrefix, 12345)
+	err = readJSONWithURL(url, &changes)
+	c.Assert(err, IsNil)
+	c.Assert(changes, HasLen, 0)
+}
+
+var _ = Suite(&testRegionCheckSuite{})
+
+type testRegionCheckSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testRegionCheckSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testRegionCheckSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testRegionCheckSuite) TestNoHeartbeat(c *C) {
+	r := newTestRegionInfo(20, 1, []byte("a"), []byte("b"))
+	mustRegionHeartbeat(c, s.svr, r)
+
+	var regions []*core.RegionInfo
+	url := fmt.Sprintf("%s/regions/check/no-heartbeat", s.urlPrefix)
+	err := readJSONWithURL(url, &regions)
+	c.Assert(err, IsNil)
+	c.Assert(regions, HasLen, 0)
+
+	time.Sleep(10 * time.Millisecond)
+	err = readJSONWithURL(url+"?threshold=1ms", &regions)
+	c.Assert(err, IsNil)
+	var found bool
+	for _, region := range regions {
+		found = found || region.GetId() == r.GetId()
+	}
+	c.Assert(found, IsTrue)
+
+	res, err := http.Get(url + "?threshold=abc")
+	c.Assert(err, IsNil)
+	res.Body.Close()
+	c.Assert(res.StatusCode, Equals, http.StatusBadRequest)
+}
+
+func (s *testRegionCheckSuite) TestKeyRange(c *C) {
+	url := fmt.Sprintf("%s/regions/check/key-range", s.urlPrefix)
+	mustRegionHeartbeat(c, s.svr, newTestRegionInfo(30, 1, []byte(""), []byte("a")))
+	mustRegionHeartbeat(c, s.svr, newTestRegionInfo(31, 1, []byte("a"), []byte("b")))
+	mustRegionHeartbeat(c, s.svr, newTestRegionInfo(32, 1, []byte("c"), []byte("")))
+
+	res := &KeyRangeCheck{}
+	err := readJSONWithURL(url, res)
+	c.Assert(err, IsNil)
+	c.Assert(res.Holes, DeepEquals, []*KeyRange{{StartKey: "b", EndKey: "c"}})
+	c.Assert(res.Overlaps, HasLen, 0)
+
+	mustRegionHeartbeat(c, s.svr, newTestRegionInfo(33, 1, []byte("b"), []byte("c")))
+	err = readJSONWithURL(url, res)
+	c.Assert(err, IsNil)
+	c.Assert(res.Holes, HasLen, 0)
+}
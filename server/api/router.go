@@ -35,7 +35,12 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	operatorHandler := newOperatorHandler(handler, rd)
 	router.HandleFunc("/api/v1/operators", operatorHandler.List).Methods("GET")
 	router.HandleFunc("/api/v1/operators", operatorHandler.Post).Methods("POST")
+	router.HandleFunc("/api/v1/operators/batch", operatorHandler.ListBatches).Methods("GET")
+	router.HandleFunc("/api/v1/operators/batch", operatorHandler.PostBatch).Methods("POST")
+	router.HandleFunc("/api/v1/operators/batch/{id}", operatorHandler.GetBatch).Methods("GET")
+	router.HandleFunc("/api/v1/operators/batch/{id}", operatorHandler.DeleteBatch).Methods("DELETE")
 	router.HandleFunc("/api/v1/operators/{region_id}", operatorHandler.Get).Methods("GET")
+	router.HandleFunc("/api/v1/operators/{region_id}/status", operatorHandler.GetStatus).Methods("GET")
 	router.HandleFunc("/api/v1/operators/{region_id}", operatorHandler.Delete).Methods("DELETE")
 
 	schedulerHandler := newSchedulerHandler(handler, rd)
@@ -49,10 +54,15 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	confHandler := newConfHandler(svr, rd)
 	router.HandleFunc("/api/v1/config", confHandler.Get).Methods("GET")
 	router.HandleFunc("/api/v1/config", confHandler.Post).Methods("POST")
+	router.HandleFunc("/api/v1/config/validate", confHandler.Validate).Methods("POST")
+	router.HandleFunc("/api/v1/config/reload", confHandler.Reload).Methods("POST")
 	router.HandleFunc("/api/v1/config/schedule", confHandler.SetSchedule).Methods("POST")
 	router.HandleFunc("/api/v1/config/schedule", confHandler.GetSchedule).Methods("GET")
 	router.HandleFunc("/api/v1/config/replicate", confHandler.SetReplication).Methods("POST")
 	router.HandleFunc("/api/v1/config/replicate", confHandler.GetReplication).Methods("GET")
+	router.HandleFunc("/api/v1/config/history", confHandler.GetHistory).Methods("GET")
+	router.HandleFunc("/api/v1/config/history/{revision}", confHandler.GetRevision).Methods("GET")
+	router.HandleFunc("/api/v1/config/rollback/{revision}", confHandler.Rollback).Methods("POST")
 	router.HandleFunc("/api/v1/config/namespace/{name}", confHandler.GetNamespace).Methods("GET")
 	router.HandleFunc("/api/v1/config/namespace/{name}", confHandler.SetNamespace).Methods("POST")
 	router.HandleFunc("/api/v1/config/namespace/{name}", confHandler.DeleteNamespace).Methods("DELETE")
@@ -80,16 +90,21 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 
 	regionHandler := newRegionHandler(svr, rd)
 	router.HandleFunc("/api/v1/region/id/{id}", regionHandler.GetRegionByID).Methods("GET")
+	router.HandleFunc("/api/v1/region/id/{id}/history", regionHandler.GetRegionHistory).Methods("GET")
 	router.HandleFunc("/api/v1/region/key/{key}", regionHandler.GetRegionByKey).Methods("GET")
+	router.HandleFunc("/api/v1/region/prev", regionHandler.GetPrevRegion).Methods("GET")
 
 	regionsHandler := newRegionsHandler(svr, rd)
 	router.HandleFunc("/api/v1/regions", regionsHandler.GetAll).Methods("GET")
 	router.HandleFunc("/api/v1/regions/writeflow", regionsHandler.GetTopWriteFlow).Methods("GET")
 	router.HandleFunc("/api/v1/regions/readflow", regionsHandler.GetTopReadFlow).Methods("GET")
+	router.HandleFunc("/api/v1/regions/heatmap", newHeatmapHandler(handler, rd).Get).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/miss-peer", regionsHandler.GetMissPeerRegions).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/extra-peer", regionsHandler.GetExtraPeerRegions).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/pending-peer", regionsHandler.GetPendingPeerRegions).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/down-peer", regionsHandler.GetDownPeerRegions).Methods("GET")
+	router.HandleFunc("/api/v1/regions/check/no-heartbeat", regionsHandler.GetNoHeartbeatRegions).Methods("GET")
+	router.HandleFunc("/api/v1/regions/check/key-range", regionsHandler.CheckKeyRange).Methods("GET")
 	router.HandleFunc("/api/v1/regions/sibling/{id}", regionsHandler.GetRegionSiblings).Methods("GET")
 	router.HandleFunc("/api/v1/regions/check/incorrect-ns", regionsHandler.GetIncorrectNamespaceRegions).Methods("GET")
 
@@ -101,6 +116,7 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	router.HandleFunc("/api/v1/members/name/{name}", memberHandler.DeleteByName).Methods("DELETE")
 	router.HandleFunc("/api/v1/members/id/{id}", memberHandler.DeleteByID).Methods("DELETE")
 	router.HandleFunc("/api/v1/members/name/{name}", memberHandler.SetMemberPropertyByName).Methods("POST")
+	router.HandleFunc("/api/v1/clock", memberHandler.GetClock).Methods("GET")
 
 	leaderHandler := newLeaderHandler(svr, rd)
 	router.HandleFunc("/api/v1/leader", leaderHandler.Get).Methods("GET")
@@ -117,14 +133,43 @@ func createRouter(prefix string, svr *server.Server) *mux.Router {
 	trendHandler := newTrendHandler(svr, rd)
 	router.HandleFunc("/api/v1/trend", trendHandler.Handle).Methods("GET")
 
+	tsoHandler := newTSOHandler(handler, rd)
+	router.HandleFunc("/api/v1/tso", tsoHandler.GetTS).Methods("GET")
+	router.HandleFunc("/api/v1/id/alloc", tsoHandler.AllocID).Methods("POST")
+
+	gcHandler := newGCHandler(handler, rd)
+	router.HandleFunc("/api/v1/gc/safepoint", gcHandler.Get).Methods("GET")
+	router.HandleFunc("/api/v1/gc/safepoint/service/{service_id}", gcHandler.UpdateService).Methods("POST")
+	router.HandleFunc("/api/v1/gc/safepoint/service/{service_id}", gcHandler.DeleteService).Methods("DELETE")
+
 	adminHandler := newAdminHandler(svr, rd)
 	router.HandleFunc("/api/v1/admin/cache/region/{id}", adminHandler.HandleDropCacheRegion).Methods("DELETE")
 
 	logHanler := newlogHandler(svr, rd)
 	router.HandleFunc("/api/v1/log", logHanler.Handle).Methods("POST")
 
+	authHandler := newAuthHandler(handler, rd)
+	router.HandleFunc("/api/v1/auth/tokens", authHandler.ListTokens).Methods("GET")
+	router.HandleFunc("/api/v1/auth/tokens", authHandler.CreateToken).Methods("POST")
+	router.HandleFunc("/api/v1/auth/tokens/{name}", authHandler.DeleteToken).Methods("DELETE")
+	router.HandleFunc("/api/v1/auth/identities", authHandler.ListIdentities).Methods("GET")
+	router.HandleFunc("/api/v1/auth/identities", authHandler.SetIdentity).Methods("POST")
+	router.HandleFunc("/api/v1/auth/identities/{name}", authHandler.DeleteIdentity).Methods("DELETE")
+
+	auditHandler := newAuditHandler(handler, rd)
+	router.HandleFunc("/api/v1/audit", auditHandler.Get).Methods("GET")
+
+	alertHandler := newAlertHandler(handler, rd)
+	router.HandleFunc("/api/v1/alerts", alertHandler.List).Methods("GET")
+	router.HandleFunc("/api/v1/alerts/silences", alertHandler.ListSilences).Methods("GET")
+	router.HandleFunc("/api/v1/alerts/silences", alertHandler.AddSilence).Methods("POST")
+	router.HandleFunc("/api/v1/alerts/silences/{id}", alertHandler.DeleteSilence).Methods("DELETE")
+
 	router.HandleFunc(pingAPI, func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
 	router.Handle("/health", newHealthHandler(svr, rd)).Methods("GET")
 	router.Handle("/diagnose", newDiagnoseHandler(svr, rd)).Methods("GET")
+
+	// The roles required by the routes are checked after matching.
+	router.Use(newAuthorizer(handler))
 	return router
 }
@@ -32,7 +32,9 @@ func NewHandler(svr *server.Server) http.Handler {
 
 	router := mux.NewRouter()
 	router.PathPrefix(apiPrefix).Handler(negroni.New(
+		newAuthenticator(svr),
 		newRedirector(svr),
+		newAuditor(svr),
 		negroni.Wrap(createRouter(apiPrefix, svr)),
 	))
 
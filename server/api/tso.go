@@ -0,0 +1,99 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/server"
+	"github.com/unrolled/render"
+)
+
+const (
+	// maxTSOCount is the max count of timestamps allocated by a request.
+	maxTSOCount       = 10000
+	physicalShiftBits = 18
+)
+
+// TSOResponse contains the timestamps allocated by a request.
+type TSOResponse struct {
+	// Timestamp is the first of the allocated timestamps, the timestamps are
+	// consecutive from it.
+	Timestamp uint64 `json:"timestamp"`
+	// Physical is in milliseconds.
+	Physical int64  `json:"physical"`
+	Logical  int64  `json:"logical"`
+	Count    uint32 `json:"count"`
+}
+
+type idResponse struct {
+	ID uint64 `json:"id"`
+}
+
+type tsoHandler struct {
+	*server.Handler
+	rd *render.Render
+}
+
+func newTSOHandler(handler *server.Handler, rd *render.Render) *tsoHandler {
+	return &tsoHandler{
+		Handler: handler,
+		rd:      rd,
+	}
+}
+
+func (h *tsoHandler) GetTS(w http.ResponseWriter, r *http.Request) {
+	count := uint64(1)
+	if countStr := r.URL.Query().Get("count"); countStr != "" {
+		var err error
+		count, err = strconv.ParseUint(countStr, 10, 32)
+		if err != nil || count == 0 || count > maxTSOCount {
+			h.rd.JSON(w, http.StatusBadRequest, fmt.Sprintf("count should be in [1, %d]", maxTSOCount))
+			return
+		}
+	}
+
+	ts, err := h.Handler.GetTS(uint32(count))
+	if err != nil {
+		h.rd.JSON(w, allocErrorStatus(err), err.Error())
+		return
+	}
+	// The logical of the returned timestamp is the last one.
+	logical := ts.GetLogical() - int64(count) + 1
+	h.rd.JSON(w, http.StatusOK, &TSOResponse{
+		Timestamp: uint64(ts.GetPhysical())<<physicalShiftBits + uint64(logical),
+		Physical:  ts.GetPhysical(),
+		Logical:   logical,
+		Count:     uint32(count),
+	})
+}
+
+func (h *tsoHandler) AllocID(w http.ResponseWriter, r *http.Request) {
+	id, err := h.Handler.AllocID()
+	if err != nil {
+		h.rd.JSON(w, allocErrorStatus(err), err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, &idResponse{ID: id})
+}
+
+func allocErrorStatus(err error) int {
+	if errors.Cause(err) == server.ErrNotLeader {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server"
+)
+
+var _ = Suite(&testTSOSuite{})
+
+type testTSOSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testTSOSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+}
+
+func (s *testTSOSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testTSOSuite) TestGetTS(c *C) {
+	var ts1, ts2 TSOResponse
+	c.Assert(readJSONWithURL(s.urlPrefix+"/tso", &ts1), IsNil)
+	c.Assert(ts1.Count, Equals, uint32(1))
+	c.Assert(ts1.Timestamp, Equals, uint64(ts1.Physical)<<physicalShiftBits+uint64(ts1.Logical))
+
+	c.Assert(readJSONWithURL(s.urlPrefix+"/tso?count=10", &ts2), IsNil)
+	c.Assert(ts2.Count, Equals, uint32(10))
+	c.Assert(ts2.Timestamp, Greater, ts1.Timestamp)
+
+	c.Assert(readJSONWithURL(s.urlPrefix+"/tso?count=10", &ts1), IsNil)
+	c.Assert(ts1.Timestamp, Greater, ts2.Timestamp+9)
+
+	for _, count := range []string{"0", "-1", "abc", "10001"} {
+		resp, err := http.Get(s.urlPrefix + "/tso?count=" + count)
+		c.Assert(err, IsNil)
+		resp.Body.Close()
+		c.Assert(resp.StatusCode, Equals, http.StatusBadRequest)
+	}
+}
+
+func (s *testTSOSuite) TestAllocID(c *C) {
+	var last uint64
+	for i := 0; i < 3; i++ {
+		var id idResponse
+		resp, err := http.Post(s.urlPrefix+"/id/alloc", "application/json", nil)
+		c.Assert(err, IsNil)
+		c.Assert(readJSON(resp.Body, &id), IsNil)
+		c.Assert(id.ID, Greater, last)
+		last = id.ID
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/pd/pkg/apiutil"
@@ -48,6 +50,29 @@ func errorResp(rd *render.Render, w http.ResponseWriter, err error) {
 	}
 }
 
+// parseTimeRange parses the start and end query parameters, which are unix
+// timestamps in seconds. The end defaults to now, and the start defaults to
+// an hour before the end.
+func parseTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	end := time.Now()
+	if v := r.URL.Query().Get("end"); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.Trace(err)
+		}
+		end = time.Unix(sec, 0)
+	}
+	start := end.Add(-time.Hour)
+	if v := r.URL.Query().Get("start"); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.Trace(err)
+		}
+		start = time.Unix(sec, 0)
+	}
+	return start, end, nil
+}
+
 // Write json into data.
 // On error respond with a 400 Bad Request
 func readJSONRespondError(rd *render.Render, w http.ResponseWriter, body io.ReadCloser, data interface{}) error {
@@ -0,0 +1,119 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/server/core"
+	log "github.com/sirupsen/logrus"
+)
+
+// auditTrimInterval is how many entries are appended between two trims of
+// the audit log.
+const auditTrimInterval = 64
+
+// auditLog keeps the state of appending the audit entries.
+type auditLog struct {
+	sync.Mutex
+	lastID   int64
+	appended int
+	file     *os.File
+}
+
+// appendAuditEntry assigns an id to the entry, saves it and mirrors it to the
+// audit file if configured. The oldest entries are removed if there are more
+// than the max entries.
+func (s *Server) appendAuditEntry(entry *core.AuditEntry) error {
+	cfg := s.cfg.Audit
+	s.auditLog.Lock()
+	defer s.auditLog.Unlock()
+
+	entry.ID = time.Now().UnixNano()
+	if entry.ID <= s.auditLog.lastID {
+		entry.ID = s.auditLog.lastID + 1
+	}
+	s.auditLog.lastID = entry.ID
+	if err := s.kv.SaveAuditEntry(entry); err != nil {
+		return errors.Trace(err)
+	}
+
+	if len(cfg.File) != 0 {
+		if err := s.mirrorAuditEntry(cfg.File, entry); err != nil {
+			log.Errorf("mirror audit entry %d to %s meet error: %v", entry.ID, cfg.File, err)
+		}
+	}
+
+	// Trims at the first append so that the entries left by the previous
+	// leader are counted.
+	if s.auditLog.appended%auditTrimInterval == 0 {
+		if err := s.trimAuditLog(cfg.MaxEntries); err != nil {
+			log.Errorf("trim audit log meet error: %v", err)
+		}
+	}
+	s.auditLog.appended++
+	return nil
+}
+
+func (s *Server) mirrorAuditEntry(path string, entry *core.AuditEntry) error {
+	if s.auditLog.file == nil {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		s.auditLog.file = f
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = s.auditLog.file.Write(append(data, '\n'))
+	return errors.Trace(err)
+}
+
+func (s *Server) trimAuditLog(maxEntries int64) error {
+	entries, err := s.kv.LoadAuditEntries(0, math.MaxInt64, 0)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for i := 0; int64(len(entries)-i) > maxEntries; i++ {
+		if err := s.kv.RemoveAuditEntry(entries[i].ID); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// closeAuditLog closes the audit file.
+func (s *Server) closeAuditLog() {
+	s.auditLog.Lock()
+	defer s.auditLog.Unlock()
+	if s.auditLog.file != nil {
+		if err := s.auditLog.file.Close(); err != nil {
+			log.Errorf("close audit file meet error: %v", err)
+		}
+		s.auditLog.file = nil
+	}
+}
+
+// getAuditEntries returns at most limit entries received in [start, end).
+func (s *Server) getAuditEntries(start, end time.Time, limit int) ([]*core.AuditEntry, error) {
+	entries, err := s.kv.LoadAuditEntries(start.UnixNano(), end.UnixNano(), limit)
+	return entries, errors.Trace(err)
+}
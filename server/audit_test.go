@@ -0,0 +1,59 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testAuditSuite{})
+
+type testAuditSuite struct {
+	svr     *Server
+	cleanup cleanupFunc
+}
+
+func (s *testAuditSuite) SetUpTest(c *C) {
+	s.svr, s.cleanup = mustRunTestServer(c)
+}
+
+func (s *testAuditSuite) TearDownTest(c *C) {
+	s.cleanup()
+}
+
+func (s *testAuditSuite) TestTrim(c *C) {
+	s.svr.cfg.Audit.MaxEntries = 3
+	// The entries left by the previous leader.
+	for id := int64(1); id <= 5; id++ {
+		c.Assert(s.svr.kv.SaveAuditEntry(&core.AuditEntry{ID: id}), IsNil)
+	}
+
+	// The first append trims the log.
+	entry := &core.AuditEntry{Method: "POST", URL: "/pd/api/v1/config"}
+	c.Assert(s.svr.appendAuditEntry(entry), IsNil)
+	entries, err := s.svr.getAuditEntries(time.Unix(0, 0), time.Now().Add(time.Hour), 0)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 3)
+	c.Assert(entries[0].ID, Equals, int64(4))
+	c.Assert(entries[2], DeepEquals, entry)
+
+	// The ids are increasing even if the clock goes back.
+	s.svr.auditLog.lastID = entry.ID + int64(time.Hour)
+	next := &core.AuditEntry{Method: "DELETE"}
+	c.Assert(s.svr.appendAuditEntry(next), IsNil)
+	c.Assert(next.ID, Equals, entry.ID+int64(time.Hour)+1)
+}
@@ -0,0 +1,265 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/server/core"
+	log "github.com/sirupsen/logrus"
+)
+
+// Role is the role of the callers of the HTTP API.
+type Role string
+
+// The roles are ordered, a role has all the privileges of the lower ones.
+const (
+	// RoleReadOnly can only read.
+	RoleReadOnly Role = "read-only"
+	// RoleOperator can change the scheduling, such as adding operators and
+	// setting stores offline.
+	RoleOperator Role = "operator"
+	// RoleAdmin can manage the PD members and the auth tokens.
+	RoleAdmin Role = "admin"
+)
+
+var roleLevels = map[Role]int{
+	RoleReadOnly: 1,
+	RoleOperator: 2,
+	RoleAdmin:    3,
+}
+
+// Valid returns true if the role is one of the predefined roles.
+func (r Role) Valid() bool {
+	_, ok := roleLevels[r]
+	return ok
+}
+
+// Allows returns true if the role has the privileges of the required role.
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleLevels[r] >= roleLevels[required]
+}
+
+// Principal is an authenticated caller of the HTTP API.
+type Principal struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+const (
+	rootPrincipalName = "root"
+	authTokenBytes    = 16
+	// authCacheTTL is how long the tokens and identities loaded from etcd are
+	// used, the changes made by the other members take effect after it.
+	authCacheTTL = 3 * time.Second
+)
+
+var authTokenNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// authCache caches the tokens, identities and members loaded from etcd.
+type authCache struct {
+	sync.Mutex
+	loadedAt time.Time
+	// tokens are indexed by the hash.
+	tokens     map[string]*core.AuthToken
+	identities map[string]*core.AuthIdentity
+	// memberHosts are the hosts of the peer and client URLs of the members,
+	// indexed by the member name.
+	memberHosts map[string][]string
+}
+
+func hashAuthToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticate returns the caller with the token or the client certificate,
+// the token goes first. It returns nil if neither of them is provided or the
+// certificate is not bound to a role.
+func (s *Server) authenticate(token string, cert *x509.Certificate) (*Principal, error) {
+	if token != "" {
+		root := s.cfg.Security.AuthRootToken
+		if root != "" && subtle.ConstantTimeCompare([]byte(token), []byte(root)) == 1 {
+			return &Principal{Name: rootPrincipalName, Role: RoleAdmin}, nil
+		}
+		if err := s.loadAuthCache(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		s.authCache.Lock()
+		t, ok := s.authCache.tokens[hashAuthToken(token)]
+		s.authCache.Unlock()
+		if !ok {
+			return nil, errors.Trace(ErrInvalidAuthToken)
+		}
+		return &Principal{Name: t.Name, Role: Role(t.Role)}, nil
+	}
+
+	if cert == nil {
+		return nil, nil
+	}
+	name := cert.Subject.CommonName
+	if err := s.loadAuthCache(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	s.authCache.Lock()
+	defer s.authCache.Unlock()
+	if identity, ok := s.authCache.identities[name]; ok {
+		return &Principal{Name: identity.Name, Role: Role(identity.Role)}, nil
+	}
+	return nil, nil
+}
+
+// authenticateMember returns true if the client certificate belongs to the
+// member with the name, that is, it is valid for one of the hosts in the URLs
+// of the member. The common name is not checked, since any certificate issued
+// by the CA can have the same one.
+func (s *Server) authenticateMember(name string, cert *x509.Certificate) (bool, error) {
+	if cert == nil {
+		return false, nil
+	}
+	if err := s.loadAuthCache(); err != nil {
+		return false, errors.Trace(err)
+	}
+	s.authCache.Lock()
+	hosts := s.authCache.memberHosts[name]
+	s.authCache.Unlock()
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// loadAuthCache reloads the tokens, identities and members if the cache
+// expires.
+func (s *Server) loadAuthCache() error {
+	s.authCache.Lock()
+	defer s.authCache.Unlock()
+	if time.Since(s.authCache.loadedAt) < authCacheTTL {
+		return nil
+	}
+	tokens, err := s.kv.LoadAuthTokens()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	identities, err := s.kv.LoadAuthIdentities()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	members, err := GetMembers(s.client)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.authCache.tokens = make(map[string]*core.AuthToken, len(tokens))
+	for _, t := range tokens {
+		s.authCache.tokens[t.Hash] = t
+	}
+	s.authCache.identities = make(map[string]*core.AuthIdentity, len(identities))
+	for _, identity := range identities {
+		s.authCache.identities[identity.Name] = identity
+	}
+	s.authCache.memberHosts = make(map[string][]string, len(members))
+	for _, m := range members {
+		urls, err := ParseUrls(strings.Join(append(m.GetPeerUrls(), m.GetClientUrls()...), ","))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, u := range urls {
+			s.authCache.memberHosts[m.GetName()] = append(s.authCache.memberHosts[m.GetName()], u.Hostname())
+		}
+	}
+	s.authCache.loadedAt = time.Now()
+	return nil
+}
+
+// invalidateAuthCache makes the changes take effect on this server at once.
+func (s *Server) invalidateAuthCache() {
+	s.authCache.Lock()
+	defer s.authCache.Unlock()
+	s.authCache.loadedAt = time.Time{}
+}
+
+// createAuthToken creates a token with the role, the token is regenerated if
+// the name exists. Only the hash of the token is saved, so the token can't be
+// got again.
+func (s *Server) createAuthToken(name string, role Role) (string, error) {
+	if !authTokenNamePattern.MatchString(name) {
+		return "", errors.Annotatef(ErrInvalidAuthName, "token name %q", name)
+	}
+	if !role.Valid() {
+		return "", errors.Annotatef(ErrInvalidRole, "role %q", role)
+	}
+	b := make([]byte, authTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Trace(err)
+	}
+	token := hex.EncodeToString(b)
+	err := s.kv.SaveAuthToken(&core.AuthToken{
+		Name: name,
+		Role: string(role),
+		Hash: hashAuthToken(token),
+	})
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	s.invalidateAuthCache()
+	log.Infof("create auth token %s with role %s", name, role)
+	return token, nil
+}
+
+// deleteAuthToken deletes a token by name.
+func (s *Server) deleteAuthToken(name string) error {
+	if err := s.kv.RemoveAuthToken(name); err != nil {
+		return errors.Trace(err)
+	}
+	s.invalidateAuthCache()
+	log.Infof("delete auth token %s", name)
+	return nil
+}
+
+// setAuthIdentity binds the role to the common name of client certificates.
+func (s *Server) setAuthIdentity(name string, role Role) error {
+	if name == "" {
+		return errors.Annotate(ErrInvalidAuthName, "empty identity name")
+	}
+	if !role.Valid() {
+		return errors.Annotatef(ErrInvalidRole, "role %q", role)
+	}
+	if err := s.kv.SaveAuthIdentity(&core.AuthIdentity{Name: name, Role: string(role)}); err != nil {
+		return errors.Trace(err)
+	}
+	s.invalidateAuthCache()
+	log.Infof("set auth identity %s with role %s", name, role)
+	return nil
+}
+
+// deleteAuthIdentity deletes the role binding of a common name.
+func (s *Server) deleteAuthIdentity(name string) error {
+	if err := s.kv.RemoveAuthIdentity(name); err != nil {
+		return errors.Trace(err)
+	}
+	s.invalidateAuthCache()
+	log.Infof("delete auth identity %s", name)
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/logutil"
+	log "github.com/sirupsen/logrus"
+)
+
+// ClockURL is the API which returns the local clock of the PD server, it is
+// not redirected to the leader.
+const ClockURL = "/pd/api/v1/clock"
+
+const clockProbeTimeout = 3 * time.Second
+
+// ServerClock is the wall clock of a PD server.
+type ServerClock struct {
+	Name     string `json:"name"`
+	MemberID uint64 `json:"member_id"`
+	// UnixNano is the wall clock in nanoseconds since the Unix epoch.
+	UnixNano int64 `json:"unix_nano"`
+}
+
+// MemberClock is the clock of a member observed by the leader.
+type MemberClock struct {
+	Name     string `json:"name"`
+	MemberID uint64 `json:"member_id"`
+	// Time is the wall clock of the member when it was probed.
+	Time time.Time `json:"time"`
+	// Offset is the offset of the member's clock relative to the leader,
+	// estimated NTP-style with the round trip time of the probe.
+	Offset time.Duration `json:"offset"`
+	RTT    time.Duration `json:"rtt"`
+	// Exceeded is true if the offset exceeds max-clock-offset.
+	Exceeded bool   `json:"exceeded"`
+	Error    string `json:"error,omitempty"`
+}
+
+// GetServerClock returns the local clock of the server.
+func (s *Server) GetServerClock() *ServerClock {
+	return &ServerClock{
+		Name:     s.Name(),
+		MemberID: s.ID(),
+		UnixNano: time.Now().UnixNano(),
+	}
+}
+
+// GetMemberClocks returns the clocks of the members probed by the leader
+// recently. It returns an empty slice if the server is not leader.
+func (s *Server) GetMemberClocks() []*MemberClock {
+	s.clocksMu.RLock()
+	defer s.clocksMu.RUnlock()
+	clocks := make([]*MemberClock, 0, len(s.clocksMu.clocks))
+	for _, c := range s.clocksMu.clocks {
+		clocks = append(clocks, c)
+	}
+	sort.Slice(clocks, func(i, j int) bool { return clocks[i].MemberID < clocks[j].MemberID })
+	return clocks
+}
+
+func (s *Server) clockMonitorLoop() {
+	defer logutil.LogPanic()
+	defer s.serverLoopWg.Done()
+
+	ctx, cancel := context.WithCancel(s.serverLoopCtx)
+	defer cancel()
+	for {
+		select {
+		case <-time.After(s.cfg.clockProbeInterval.Duration):
+			if s.IsLeader() {
+				s.probeMemberClocks(ctx)
+			} else {
+				s.setMemberClocks(nil)
+			}
+		case <-ctx.Done():
+			log.Info("server is closed, exit clock monitor loop")
+			return
+		}
+	}
+}
+
+func (s *Server) probeMemberClocks(ctx context.Context) {
+	members, err := GetMembers(s.client)
+	if err != nil {
+		log.Errorf("failed to get members to probe clocks: %v", err)
+		return
+	}
+
+	clocks := make([]*MemberClock, len(members))
+	var wg sync.WaitGroup
+	for i, member := range members {
+		wg.Add(1)
+		go func(i int, member *pdpb.Member) {
+			defer wg.Done()
+			clocks[i] = probeMemberClock(ctx, member)
+		}(i, member)
+	}
+	wg.Wait()
+
+	maxOffset := s.cfg.MaxClockOffset.Duration
+	clockOffsetGauge.Reset()
+	for _, c := range clocks {
+		if c.Error != "" {
+			log.Warnf("failed to probe the clock of member %s: %s", c.Name, c.Error)
+			continue
+		}
+		clockOffsetGauge.WithLabelValues(c.Name).Set(c.Offset.Seconds())
+		if c.Offset > maxOffset || c.Offset < -maxOffset {
+			c.Exceeded = true
+			clockOffsetExceededCounter.WithLabelValues(c.Name).Inc()
+			log.Warnf("the clock of member %s is offset by %v relative to the leader, exceeds %v, rtt %v", c.Name, c.Offset, maxOffset, c.RTT)
+		}
+	}
+	s.setMemberClocks(clocks)
+}
+
+func (s *Server) setMemberClocks(clocks []*MemberClock) {
+	s.clocksMu.Lock()
+	defer s.clocksMu.Unlock()
+	s.clocksMu.clocks = make(map[uint64]*MemberClock, len(clocks))
+	for _, c := range clocks {
+		s.clocksMu.clocks[c.MemberID] = c
+	}
+}
+
+// probeMemberClock gets the clock of a member through its client URLs.
+func probeMemberClock(ctx context.Context, member *pdpb.Member) *MemberClock {
+	c := &MemberClock{
+		Name:     member.GetName(),
+		MemberID: member.GetMemberId(),
+	}
+	var err error
+	for _, cURL := range member.GetClientUrls() {
+		if err = probeClock(ctx, cURL, c); err == nil {
+			return c
+		}
+	}
+	if err == nil {
+		err = errors.New("no client url")
+	}
+	c.Error = err.Error()
+	return c
+}
+
+func probeClock(ctx context.Context, cURL string, c *MemberClock) error {
+	ctx, cancel := context.WithTimeout(ctx, clockProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, cURL+ClockURL, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// Start timing after the connection is established to exclude the
+	// handshake from the round trip.
+	var sent time.Time
+	trace := &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) { sent = time.Now() },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(ctx, trace))
+
+	resp, err := DialClient.Do(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	received := time.Now()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("probe clock of %s got status %d", cURL, resp.StatusCode)
+	}
+	var sc ServerClock
+	if err = json.NewDecoder(resp.Body).Decode(&sc); err != nil {
+		return errors.Trace(err)
+	}
+	if sent.IsZero() {
+		sent = received
+	}
+
+	c.Time = time.Unix(0, sc.UnixNano)
+	c.RTT = received.Sub(sent)
+	// The member's clock is read in the middle of the round trip.
+	c.Offset = time.Duration(sc.UnixNano-sent.UnixNano()) - c.RTT/2
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+)
+
+var _ = Suite(&testClockMonitorSuite{})
+
+type testClockMonitorSuite struct{}
+
+func newClockTestServer(offset time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != ClockURL {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(&ServerClock{
+			Name:     "pd2",
+			MemberID: 2,
+			UnixNano: time.Now().Add(offset).UnixNano(),
+		})
+	}))
+}
+
+func (s *testClockMonitorSuite) TestProbeMemberClock(c *C) {
+	ts := newClockTestServer(-time.Hour)
+	defer ts.Close()
+
+	member := &pdpb.Member{Name: "pd2", MemberId: 2, ClientUrls: []string{"http://127.0.0.1:1", ts.URL}}
+	clock := probeMemberClock(context.Background(), member)
+	c.Assert(clock.Error, Equals, "")
+	c.Assert(clock.MemberID, Equals, uint64(2))
+	c.Assert(clock.RTT, Less, time.Second)
+	c.Assert(clock.Offset+time.Hour, Less, time.Second)
+	c.Assert(clock.Offset+time.Hour, Greater, -time.Second)
+
+	clock = probeMemberClock(context.Background(), &pdpb.Member{Name: "pd3", MemberId: 3, ClientUrls: []string{"http://127.0.0.1:1"}})
+	c.Assert(clock.Error, Not(Equals), "")
+	clock = probeMemberClock(context.Background(), &pdpb.Member{Name: "pd3", MemberId: 3})
+	c.Assert(clock.Error, Not(Equals), "")
+}
+
+func (s *testClockMonitorSuite) TestTSOHealth(c *C) {
+	now := time.Now()
+	m := newTSOHealthMonitor()
+	m.now = func() time.Time { return now }
+
+	m.observeSave(time.Millisecond)
+	h := m.health()
+	c.Assert(h.Healthy, IsTrue)
+	c.Assert(h.LastSaveLatency, Equals, time.Millisecond)
+
+	m.observeSave(2 * slowRequestTime)
+	m.record(tsoEventClockFallback)
+	for i := 0; i < maxTSOEvents+10; i++ {
+		m.record(tsoEventLogicalExhausted)
+	}
+	h = m.health()
+	c.Assert(h.Healthy, IsFalse)
+	c.Assert(h.SlowSaves, Equals, 1)
+	c.Assert(h.ClockFallbacks, Equals, 1)
+	c.Assert(h.LogicalExhausted, Equals, maxTSOEvents)
+	c.Assert(h.Problems, HasLen, 3)
+
+	// The events out of the window are not counted.
+	now = now.Add(tsoHealthWindow / 2)
+	m.record(tsoEventClockFallback)
+	now = now.Add(tsoHealthWindow/2 + time.Second)
+	h = m.health()
+	c.Assert(h.Healthy, IsFalse)
+	c.Assert(h.SlowSaves, Equals, 0)
+	c.Assert(h.ClockFallbacks, Equals, 1)
+	c.Assert(h.LogicalExhausted, Equals, 0)
+	c.Assert(h.Problems, HasLen, 1)
+}
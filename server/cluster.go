@@ -30,6 +30,9 @@ import (
 
 const (
 	backgroundJobInterval = time.Minute
+	// changedRegionsLimit is the buffer size of the regions waiting to be
+	// synced to followers.
+	changedRegionsLimit = 10000
 )
 
 // RaftCluster is used for cluster config management.
@@ -54,6 +57,12 @@ type RaftCluster struct {
 
 	coordinator *coordinator
 
+	// heatmap is nil if the sampling is disabled or the cluster is stopped,
+	// it's protected by the lock.
+	heatmap *keyHeatmap
+
+	alerts *alertManager
+
 	wg   sync.WaitGroup
 	quit chan struct{}
 }
@@ -103,14 +112,30 @@ func (c *RaftCluster) start() error {
 	if cluster == nil {
 		return nil
 	}
+	if regions := c.s.regionSyncer.TakeRegions(); len(regions) > 0 {
+		cluster.applySyncedRegions(regions)
+		log.Infof("apply %v regions synced from the previous leader", len(regions))
+	}
+	cluster.changedRegions = make(chan *core.RegionInfo, changedRegionsLimit)
+	c.alerts = newAlertManager(c.s.kv)
 	c.cachedCluster = cluster
 	c.coordinator = newCoordinator(c.cachedCluster, c.s.hbStreams, c.s.classifier)
 	c.cachedCluster.regionStats = newRegionStatistics(c.s.scheduleOpt, c.s.classifier)
+	c.cachedCluster.regionHistory = newRegionHistory(c.coordinator.getOperator)
 	c.quit = make(chan struct{})
 
-	c.wg.Add(2)
+	c.wg.Add(3)
 	go c.runCoordinator()
 	go c.runBackgroundJobs(backgroundJobInterval)
+	go c.syncRegions()
+	if cfg := c.s.cfg.Heatmap; !cfg.Disable {
+		interval := cfg.SampleInterval.Duration
+		c.heatmap = newKeyHeatmap(int(cfg.Retention.Duration / interval))
+		c.wg.Add(1)
+		go c.runHeatmapSampler(c.heatmap, interval, int(cfg.MaxBuckets))
+	}
+	c.wg.Add(1)
+	go c.runAlertEvaluator()
 
 	c.running = true
 
@@ -124,6 +149,13 @@ func (c *RaftCluster) runCoordinator() {
 	c.wg.Done()
 }
 
+func (c *RaftCluster) syncRegions() {
+	defer logutil.LogPanic()
+	defer c.wg.Done()
+
+	c.s.regionSyncer.RunServer(c.cachedCluster.changedRegions, c.quit)
+}
+
 func (c *RaftCluster) stop() {
 	c.Lock()
 	defer c.Unlock()
@@ -137,6 +169,11 @@ func (c *RaftCluster) stop() {
 	close(c.quit)
 	c.coordinator.stop()
 	c.wg.Wait()
+	c.heatmap = nil
+
+	if err := c.s.kv.Flush(); err != nil {
+		log.Errorf("flush region storage meet error: %v", err)
+	}
 }
 
 func (c *RaftCluster) isRunning() bool {
@@ -212,6 +249,19 @@ func (c *RaftCluster) GetRegionInfoByKey(regionKey []byte) *core.RegionInfo {
 	return c.cachedCluster.searchRegion(regionKey)
 }
 
+// GetPrevRegionInfoByKey gets the region before the region which contains
+// the key.
+func (c *RaftCluster) GetPrevRegionInfoByKey(regionKey []byte) *core.RegionInfo {
+	return c.cachedCluster.searchPrevRegion(regionKey)
+}
+
+// ScanRegions scans the regions which overlap with [startKey, endKey) and
+// match all the options, an empty endKey means scanning to the end and a
+// non-positive limit means no limit.
+func (c *RaftCluster) ScanRegions(startKey, endKey []byte, limit int, opts ...core.RegionOption) []*core.RegionInfo {
+	return c.cachedCluster.scanRegionsInRange(startKey, endKey, limit, opts...)
+}
+
 // GetRegionByID gets region and leader peer by regionID from cluster.
 func (c *RaftCluster) GetRegionByID(regionID uint64) (*metapb.Region, *metapb.Peer) {
 	region := c.cachedCluster.GetRegion(regionID)
@@ -226,6 +276,15 @@ func (c *RaftCluster) GetRegionInfoByID(regionID uint64) *core.RegionInfo {
 	return c.cachedCluster.GetRegion(regionID)
 }
 
+// GetRegionHistory gets the recent changes of a region from the oldest to the
+// latest.
+func (c *RaftCluster) GetRegionHistory(regionID uint64) []*RegionChange {
+	if c.cachedCluster.regionHistory == nil {
+		return nil
+	}
+	return c.cachedCluster.regionHistory.getHistory(regionID)
+}
+
 // GetMetaRegions gets regions from cluster.
 func (c *RaftCluster) GetMetaRegions() []*metapb.Region {
 	return c.cachedCluster.getMetaRegions()
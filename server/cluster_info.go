@@ -32,13 +32,20 @@ type clusterInfo struct {
 	sync.RWMutex
 	core *schedule.BasicCluster
 
-	id              core.IDAllocator
-	kv              *core.KV
-	meta            *metapb.Cluster
-	activeRegions   int
+	id            core.IDAllocator
+	kv            *core.KV
+	meta          *metapb.Cluster
+	activeRegions int
+	// syncedRegions are the regions synced from the previous leader which
+	// have not reported heartbeat, they are not counted in activeRegions.
+	syncedRegions   map[uint64]struct{}
 	opt             *scheduleOption
 	regionStats     *regionStatistics
+	regionHistory   *regionHistory
 	labelLevelStats *labelLevelStatistics
+	changedRegions  chan *core.RegionInfo
+	// storeStatusLocks serialize the status updates of stores by heartbeats.
+	storeStatusLocks [storeStatusShards]sync.Mutex
 }
 
 func newClusterInfo(id core.IDAllocator, opt *scheduleOption, kv *core.KV) *clusterInfo {
@@ -70,6 +77,18 @@ func loadClusterInfo(id core.IDAllocator, kv *core.KV, opt *scheduleOption) (*cl
 	}
 	log.Infof("load %v stores cost %v", c.core.Stores.GetStoreCount(), time.Since(start))
 
+	if kv.GetRegionKV() != nil {
+		start = time.Now()
+		n, err := kv.MigrateRegions()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if n > 0 {
+			log.Infof("migrate %v regions to region storage cost %v", n, time.Since(start))
+		}
+		kv.SwitchToRegionStorage()
+	}
+
 	start = time.Now()
 	if err := kv.LoadRegions(c.core.Regions); err != nil {
 		return nil, errors.Trace(err)
@@ -79,6 +98,33 @@ func loadClusterInfo(id core.IDAllocator, kv *core.KV, opt *scheduleOption) (*cl
 	return c, nil
 }
 
+// applySyncedRegions puts the regions synced from the previous leader into
+// the cache, so the cluster can serve without waiting for every region to
+// heartbeat again. The synced regions may be stale, so they are not counted
+// as active until they report heartbeat, and scheduling waits for it.
+func (c *clusterInfo) applySyncedRegions(regions []*core.RegionInfo) {
+	c.Lock()
+	defer c.Unlock()
+	if c.syncedRegions == nil {
+		c.syncedRegions = make(map[uint64]struct{}, len(regions))
+	}
+	for _, region := range regions {
+		if origin := c.core.Regions.GetRegion(region.GetId()); origin != nil {
+			r, o := region.GetRegionEpoch(), origin.GetRegionEpoch()
+			if r.GetVersion() < o.GetVersion() || r.GetConfVer() < o.GetConfVer() {
+				continue
+			}
+		}
+		for _, item := range c.core.Regions.SetRegion(region) {
+			delete(c.syncedRegions, item.GetId())
+		}
+		c.syncedRegions[region.GetId()] = struct{}{}
+	}
+	for _, store := range c.core.Stores.GetStores() {
+		c.updateStoreStatusLocked(store.GetId())
+	}
+}
+
 func (c *clusterInfo) OnStoreVersionChange() {
 	var (
 		minVersion     *semver.Version
@@ -247,6 +293,22 @@ func (c *clusterInfo) ScanRegions(startKey []byte, limit int) []*core.RegionInfo
 	return c.core.Regions.ScanRange(startKey, limit)
 }
 
+// GetRangeRegionStats returns the statistics of regions whose start key is in
+// range [startKey, endKey).
+func (c *clusterInfo) GetRangeRegionStats(startKey, endKey []byte) *core.RegionStats {
+	c.RLock()
+	defer c.RUnlock()
+	return c.core.GetRangeRegionStats(startKey, endKey)
+}
+
+// RandRangeRegion returns a random region whose start key is in range
+// [startKey, endKey).
+func (c *clusterInfo) RandRangeRegion(startKey, endKey []byte, opts ...core.RegionOption) *core.RegionInfo {
+	c.RLock()
+	defer c.RUnlock()
+	return c.core.RandRangeRegion(startKey, endKey, opts...)
+}
+
 // GetAdjacentRegions returns region's info that is adjacent with specific region
 func (c *clusterInfo) GetAdjacentRegions(region *core.RegionInfo) (*core.RegionInfo, *core.RegionInfo) {
 	c.RLock()
@@ -285,6 +347,18 @@ func (c *clusterInfo) searchRegion(regionKey []byte) *core.RegionInfo {
 	return c.core.Regions.SearchRegion(regionKey)
 }
 
+func (c *clusterInfo) searchPrevRegion(regionKey []byte) *core.RegionInfo {
+	c.RLock()
+	defer c.RUnlock()
+	return c.core.Regions.SearchPrevRegion(regionKey)
+}
+
+func (c *clusterInfo) scanRegionsInRange(startKey, endKey []byte, limit int, opts ...core.RegionOption) []*core.RegionInfo {
+	c.RLock()
+	defer c.RUnlock()
+	return c.core.Regions.ScanRegions(startKey, endKey, limit, opts...)
+}
+
 func (c *clusterInfo) putRegion(region *core.RegionInfo) error {
 	c.Lock()
 	defer c.Unlock()
@@ -428,6 +502,33 @@ func (c *clusterInfo) updateStoreStatusLocked(id uint64) {
 	c.core.Stores.SetRegionSize(id, c.core.Regions.GetStoreRegionSize(id))
 }
 
+// storeStatusShards is the number of locks which serialize the status updates
+// of stores, the stores are mapped to the locks by id.
+const storeStatusShards = 64
+
+// updateStoreStatus updates the status of a store without holding the cluster
+// lock while counting the regions. The lock of the store keeps the counts of
+// concurrent heartbeats from being applied out of order.
+func (c *clusterInfo) updateStoreStatus(id uint64) {
+	mu := &c.storeStatusLocks[id%storeStatusShards]
+	mu.Lock()
+	defer mu.Unlock()
+	var (
+		leaderCount  = c.core.Regions.GetStoreLeaderCount(id)
+		regionCount  = c.core.Regions.GetStoreRegionCount(id)
+		pendingCount = c.core.Regions.GetStorePendingPeerCount(id)
+		leaderSize   = c.core.Regions.GetStoreLeaderRegionSize(id)
+		regionSize   = c.core.Regions.GetStoreRegionSize(id)
+	)
+	c.Lock()
+	defer c.Unlock()
+	c.core.Stores.SetLeaderCount(id, leaderCount)
+	c.core.Stores.SetRegionCount(id, regionCount)
+	c.core.Stores.SetPendingPeerCount(id, pendingCount)
+	c.core.Stores.SetLeaderSize(id, leaderSize)
+	c.core.Stores.SetRegionSize(id, regionSize)
+}
+
 // handleRegionHeartbeat updates the region information.
 func (c *clusterInfo) handleRegionHeartbeat(region *core.RegionInfo) error {
 	region = region.Clone()
@@ -435,6 +536,7 @@ func (c *clusterInfo) handleRegionHeartbeat(region *core.RegionInfo) error {
 	origin := c.core.Regions.GetRegion(region.GetId())
 	isWriteUpdate, writeItem := c.core.CheckWriteStatus(region)
 	isReadUpdate, readItem := c.core.CheckReadStatus(region)
+	_, isSynced := c.syncedRegions[region.GetId()]
 	c.RUnlock()
 
 	// Save to KV if meta is updated.
@@ -490,18 +592,21 @@ func (c *clusterInfo) handleRegionHeartbeat(region *core.RegionInfo) error {
 			log.Errorf("[region %d] fail to save region %v: %v", region.GetId(), region, err)
 		}
 	}
-	if !isWriteUpdate && !isReadUpdate && !saveCache && !isNew {
+	// The time of new regions is recorded when they are added.
+	c.core.Regions.UpdateRegionHeartbeat(region.GetId(), time.Now())
+	if !isWriteUpdate && !isReadUpdate && !saveCache && !isNew && !isSynced {
 		return nil
 	}
 
-	c.Lock()
-	defer c.Unlock()
-	if isNew {
-		c.activeRegions++
-	}
-
+	// The regions cache is safe for concurrent use, only the bookkeeping of
+	// stores and statistics below needs the cluster lock. So the heartbeats of
+	// unrelated regions are mostly processed in parallel.
+	var overlaps []*metapb.Region
 	if saveCache {
-		overlaps := c.core.Regions.SetRegion(region)
+		overlaps = c.core.Regions.SetRegion(region)
+		if c.regionHistory != nil {
+			c.regionHistory.observe(origin, region, overlaps)
+		}
 		if c.kv != nil {
 			for _, item := range overlaps {
 				if err := c.kv.DeleteRegion(item); err != nil {
@@ -509,26 +614,55 @@ func (c *clusterInfo) handleRegionHeartbeat(region *core.RegionInfo) error {
 				}
 			}
 		}
+		if c.changedRegions != nil {
+			select {
+			case c.changedRegions <- region:
+			default:
+				regionSyncDroppedCounter.Inc()
+			}
+		}
+	}
+
+	if saveCache {
 		for _, item := range overlaps {
 			if c.regionStats != nil {
 				c.regionStats.clearDefunctRegion(item.GetId())
 			}
 			c.labelLevelStats.clearDefunctRegion(item.GetId())
 		}
+	}
+
+	c.Lock()
+	if _, ok := c.syncedRegions[region.GetId()]; ok {
+		delete(c.syncedRegions, region.GetId())
+		c.activeRegions++
+	} else if isNew {
+		c.activeRegions++
+	}
+	for _, item := range overlaps {
+		delete(c.syncedRegions, item.GetId())
+	}
+	c.Unlock()
 
-		// Update related stores.
+	// The statistics and the hot cache have their own locks, and the status
+	// of stores is computed under the lock of each store, so the global lock
+	// is only held for the bookkeeping above and the store updates.
+	if saveCache {
 		if origin != nil {
 			for _, p := range origin.Peers {
-				c.updateStoreStatusLocked(p.GetStoreId())
+				c.updateStoreStatus(p.GetStoreId())
 			}
 		}
 		for _, p := range region.Peers {
-			c.updateStoreStatusLocked(p.GetStoreId())
+			c.updateStoreStatus(p.GetStoreId())
 		}
 	}
 
 	if c.regionStats != nil {
-		c.regionStats.Observe(region, c.getRegionStoresLocked(region))
+		c.RLock()
+		stores := c.getRegionStoresLocked(region)
+		c.RUnlock()
+		c.regionStats.Observe(region, stores)
 	}
 
 	key := region.GetId()
@@ -542,8 +676,8 @@ func (c *clusterInfo) handleRegionHeartbeat(region *core.RegionInfo) error {
 }
 
 func (c *clusterInfo) updateRegionsLabelLevelStats(regions []*core.RegionInfo) {
-	c.Lock()
-	defer c.Unlock()
+	c.RLock()
+	defer c.RUnlock()
 	for _, region := range regions {
 		c.labelLevelStats.Observe(region, c.getRegionStoresLocked(region), c.GetLocationLabels())
 	}
@@ -570,6 +704,18 @@ func (c *clusterInfo) GetRegionStatsByType(typ regionStatisticType) []*core.Regi
 	return c.regionStats.getRegionStatsByType(typ)
 }
 
+// getNoHeartbeatRegions returns the regions whose leader has not reported
+// heartbeat within the threshold.
+func (c *clusterInfo) getNoHeartbeatRegions(threshold time.Duration) []*core.RegionInfo {
+	return c.core.Regions.GetNoHeartbeatRegions(time.Now().Add(-threshold))
+}
+
+// checkKeyRange returns the key ranges not covered by any region and the
+// overlapped regions.
+func (c *clusterInfo) checkKeyRange() ([]core.KeyRange, [][2]*core.RegionInfo) {
+	return c.core.Regions.CheckKeyRange()
+}
+
 func (c *clusterInfo) GetOpt() schedule.NamespaceOptions {
 	return c.opt
 }
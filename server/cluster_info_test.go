@@ -14,13 +14,18 @@
 package server
 
 import (
+	"fmt"
 	"math/rand"
+	"sync"
+	"testing"
 
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/namespace"
+	log "github.com/sirupsen/logrus"
 )
 
 var _ = Suite(&testStoresInfoSuite{})
@@ -276,6 +281,37 @@ func (s *testClusterInfoSuite) TestLoadClusterInfo(c *C) {
 	}
 }
 
+func (s *testClusterInfoSuite) TestLoadClusterInfoWithRegionStorage(c *C) {
+	kv := core.NewKV(core.NewMemoryKV())
+	_, opt := newTestScheduleConfig()
+
+	n := 10
+	meta := &metapb.Cluster{Id: 123}
+	c.Assert(kv.SaveMeta(meta), IsNil)
+	regions := mustSaveRegions(c, kv, n)
+
+	// Regions in the default storage are migrated to the region storage.
+	regionKV := core.NewRegionKV(core.NewMemoryKV())
+	defer regionKV.Close()
+	kv.SetRegionKV(regionKV)
+	cluster, err := loadClusterInfo(core.NewMockIDAllocator(), kv, opt)
+	c.Assert(err, IsNil)
+	c.Assert(cluster.getRegionCount(), Equals, n)
+	for _, region := range cluster.getMetaRegions() {
+		c.Assert(region, DeepEquals, regions[region.GetId()])
+	}
+
+	// New regions are only saved to the region storage.
+	region := &metapb.Region{Id: uint64(n), StartKey: []byte("a"), EndKey: []byte("b")}
+	c.Assert(kv.SaveRegion(region), IsNil)
+	value, err := kv.Load(makeRegionKey("raft", uint64(n)))
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "")
+	ok, err := kv.LoadRegion(uint64(n), &metapb.Region{})
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+}
+
 func (s *testClusterInfoSuite) TestStoreHeartbeat(c *C) {
 	_, opt := newTestScheduleConfig()
 	cluster := newClusterInfo(core.NewMockIDAllocator(), opt, core.NewKV(core.NewMemoryKV()))
@@ -688,3 +724,95 @@ func mustSaveRegions(c *C, kv *core.KV, n int) []*metapb.Region {
 
 	return regions
 }
+
+const (
+	benchmarkRegionCount = 1000000
+	benchmarkStoreCount  = 100
+)
+
+var benchmarkCluster struct {
+	sync.Once
+	regions []*core.RegionInfo
+	cluster *clusterInfo
+}
+
+// prepareBenchmarkCluster returns a cluster with 1M regions on 100 stores,
+// which is shared by the heartbeat benchmarks.
+func prepareBenchmarkCluster(b *testing.B) ([]*core.RegionInfo, *clusterInfo) {
+	// Leader transfers are logged at info level, which would dominate the
+	// benchmarks.
+	log.SetLevel(log.WarnLevel)
+	benchmarkCluster.Do(func() {
+		_, opt := newTestScheduleConfig()
+		cluster := newClusterInfo(core.NewMockIDAllocator(), opt, nil)
+		cluster.regionStats = newRegionStatistics(opt, namespace.DefaultClassifier)
+		for i := uint64(1); i <= benchmarkStoreCount; i++ {
+			if err := cluster.putStore(core.NewStoreInfo(&metapb.Store{Id: i})); err != nil {
+				b.Fatal(err)
+			}
+		}
+		regions := make([]*core.RegionInfo, 0, benchmarkRegionCount)
+		for i := 0; i < benchmarkRegionCount; i++ {
+			peers := make([]*metapb.Peer, 0, 3)
+			for j := 0; j < 3; j++ {
+				peers = append(peers, &metapb.Peer{
+					Id:      uint64(i*3 + j + 1),
+					StoreId: uint64((i+j)%benchmarkStoreCount + 1),
+				})
+			}
+			region := core.NewRegionInfo(&metapb.Region{
+				Id:          uint64(i + 1),
+				StartKey:    []byte(fmt.Sprintf("%20d", i)),
+				EndKey:      []byte(fmt.Sprintf("%20d", i+1)),
+				Peers:       peers,
+				RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+			}, peers[0])
+			region.ApproximateSize = 1
+			if err := cluster.handleRegionHeartbeat(region); err != nil {
+				b.Fatal(err)
+			}
+			regions = append(regions, region)
+		}
+		benchmarkCluster.regions, benchmarkCluster.cluster = regions, cluster
+	})
+	return benchmarkCluster.regions, benchmarkCluster.cluster
+}
+
+// newBenchmarkHeartbeat simulates a heartbeat which transfers the leader and
+// reports the written bytes of a region, so the stores, statistics and hot
+// cache are all updated.
+func newBenchmarkHeartbeat(r *rand.Rand, regions []*core.RegionInfo) *core.RegionInfo {
+	region := regions[r.Intn(len(regions))].Clone()
+	region.Leader = region.Peers[r.Intn(len(region.Peers))]
+	region.ApproximateSize = r.Int63n(96) + 1
+	region.WrittenBytes = uint64(r.Int63n(1 << 30))
+	return region
+}
+
+// BenchmarkHandleRegionHeartbeat handles the heartbeats of 1M regions one by
+// one.
+func BenchmarkHandleRegionHeartbeat(b *testing.B) {
+	regions, cluster := prepareBenchmarkCluster(b)
+	r := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := cluster.handleRegionHeartbeat(newBenchmarkHeartbeat(r, regions)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkHandleRegionHeartbeatParallel handles the heartbeats of 1M regions
+// concurrently, like the heartbeat streams of many stores do.
+func BenchmarkHandleRegionHeartbeatParallel(b *testing.B) {
+	regions, cluster := prepareBenchmarkCluster(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			if err := cluster.handleRegionHeartbeat(newBenchmarkHeartbeat(r, regions)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
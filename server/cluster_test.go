@@ -16,6 +16,7 @@ package server
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/coreos/etcd/clientv3"
 	. "github.com/pingcap/check"
@@ -438,6 +439,36 @@ func (s *testClusterSuite) TestClosedChannel(c *C) {
 	cluster.stop()
 }
 
+func (s *testClusterSuite) TestHeatmapAfterStop(c *C) {
+	svr, cleanup := newTestServer(c)
+	defer cleanup()
+	err := svr.Run(context.TODO())
+	c.Assert(err, IsNil)
+
+	leader := mustGetLeader(c, svr.client, svr.getLeaderPath())
+	grpcPDClient := mustNewGrpcClient(c, getLeaderAddr(leader))
+	s.tryBootstrapCluster(c, grpcPDClient, svr.clusterID, "127.0.0.1:0")
+
+	cluster := svr.GetRaftCluster()
+	c.Assert(cluster, NotNil)
+	getHeatmap := func() *Heatmap {
+		return cluster.GetHeatmap(time.Time{}, time.Now(), nil, nil, 1)
+	}
+	c.Assert(getHeatmap(), NotNil)
+
+	// The heatmap is read while the cluster is stopping.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			getHeatmap()
+		}
+	}()
+	cluster.stop()
+	<-done
+	c.Assert(getHeatmap(), IsNil)
+}
+
 func (s *testClusterSuite) TestGetPDMembers(c *C) {
 
 	req := &pdpb.GetMembersRequest{
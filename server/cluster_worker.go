@@ -108,5 +108,8 @@ func (c *RaftCluster) handleReportSplit(request *pdpb.ReportSplitRequest) (*pdpb
 	originRegion.RegionEpoch = nil
 	originRegion.StartKey = left.GetStartKey()
 	log.Infof("[region %d] region split, generate new region: %v", originRegion.GetId(), left)
+	if c.cachedCluster != nil && c.cachedCluster.regionHistory != nil {
+		c.cachedCluster.regionHistory.observeSplit(left, right)
+	}
 	return &pdpb.ReportSplitResponse{}, nil
 }
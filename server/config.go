@@ -28,6 +28,7 @@ import (
 	"github.com/coreos/etcd/pkg/transport"
 	"github.com/coreos/go-semver/semver"
 	"github.com/juju/errors"
+	"github.com/pingcap/pd/pkg/certutil"
 	"github.com/pingcap/pd/pkg/logutil"
 	"github.com/pingcap/pd/pkg/metricutil"
 	"github.com/pingcap/pd/pkg/typeutil"
@@ -70,6 +71,10 @@ type Config struct {
 	// TsoSaveInterval is the interval to save timestamp.
 	TsoSaveInterval typeutil.Duration `toml:"tso-save-interval" json:"tso-save-interval"`
 
+	// MaxClockOffset is the max offset of the clock of a member relative to
+	// the leader, the member is regarded as unhealthy if exceeded.
+	MaxClockOffset typeutil.Duration `toml:"max-clock-offset" json:"max-clock-offset"`
+
 	Metric metricutil.MetricConfig `toml:"metric" json:"metric"`
 
 	Schedule ScheduleConfig `toml:"schedule" json:"schedule"`
@@ -80,6 +85,9 @@ type Config struct {
 
 	ClusterVersion semver.Version `json:"cluster-version"`
 
+	// ConfigRevision is bumped on each change of the persisted config.
+	ConfigRevision uint64 `toml:"-" json:"config-revision"`
+
 	// QuotaBackendBytes Raise alarms when backend size exceeds the given quota. 0 means use the default quota.
 	// the default size is 2GB, the maximum is 8GB.
 	QuotaBackendBytes typeutil.ByteSize `toml:"quota-backend-bytes" json:"quota-backend-bytes"`
@@ -107,7 +115,21 @@ type Config struct {
 
 	LabelProperty LabelPropertyConfig `toml:"label-property" json:"label-property"`
 
+	Audit AuditConfig `toml:"audit" json:"audit"`
+
+	Heatmap HeatmapConfig `toml:"heatmap" json:"heatmap"`
+
+	Alert AlertConfig `toml:"alert" json:"alert"`
+
+	// UseRegionStorage enables the independent region storage, which saves
+	// region meta in a local database under data-dir instead of etcd. The
+	// regions in etcd are copied to it at the first start and kept as a fallback.
+	UseRegionStorage bool `toml:"use-region-storage" json:"use-region-storage"`
+
 	configFile string
+	// arguments are the command line arguments, they are parsed again with
+	// the config file when reloading.
+	arguments []string
 
 	// For all warnings during parsing.
 	WarningMsgs []string
@@ -123,6 +145,8 @@ type Config struct {
 	heartbeatStreamBindInterval typeutil.Duration
 
 	leaderPriorityCheckInterval typeutil.Duration
+
+	clockProbeInterval typeutil.Duration
 }
 
 // NewConfig creates a new config.
@@ -181,6 +205,18 @@ const (
 	defaultHeartbeatStreamRebindInterval = time.Minute
 
 	defaultLeaderPriorityCheckInterval = time.Minute
+
+	defaultMaxClockOffset     = 500 * time.Millisecond
+	defaultClockProbeInterval = 10 * time.Second
+
+	defaultAuditMaxEntries = 10000
+
+	defaultHeatmapSampleInterval = time.Minute
+	defaultHeatmapRetention      = 24 * time.Hour
+	defaultHeatmapMaxBuckets     = 256
+
+	defaultAlertEvaluationInterval = time.Minute
+	defaultAlertResendInterval     = 5 * time.Minute
 )
 
 func adjustString(v *string, defValue string) {
@@ -221,6 +257,7 @@ func adjustSchedulers(v *SchedulerConfigs, defValue SchedulerConfigs) {
 
 // Parse parses flag definitions from the argument list.
 func (c *Config) Parse(arguments []string) error {
+	c.arguments = arguments
 	// Parse first to get config file.
 	err := c.FlagSet.Parse(arguments)
 	if err != nil {
@@ -322,6 +359,8 @@ func (c *Config) adjust(meta *toml.MetaData) error {
 
 	adjustDuration(&c.TsoSaveInterval, time.Duration(defaultLeaderLease)*time.Second)
 
+	adjustDuration(&c.MaxClockOffset, defaultMaxClockOffset)
+
 	if c.nextRetryDelay == 0 {
 		c.nextRetryDelay = defaultNextRetryDelay
 	}
@@ -335,17 +374,30 @@ func (c *Config) adjust(meta *toml.MetaData) error {
 
 	adjustString(&c.Metric.PushJob, c.Name)
 
+	adjustInt64(&c.Audit.MaxEntries, defaultAuditMaxEntries)
+
+	if err := c.Heatmap.adjust(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := c.Alert.adjust(); err != nil {
+		return errors.Trace(err)
+	}
 	if err := c.Schedule.adjust(); err != nil {
 		return errors.Trace(err)
 	}
 	if err := c.Replication.adjust(); err != nil {
 		return errors.Trace(err)
 	}
+	for _, issue := range c.check().Warnings {
+		c.WarningMsgs = append(c.WarningMsgs, issue.String())
+	}
 
 	adjustDuration(&c.heartbeatStreamBindInterval, defaultHeartbeatStreamRebindInterval)
 
 	adjustDuration(&c.leaderPriorityCheckInterval, defaultLeaderPriorityCheckInterval)
 
+	adjustDuration(&c.clockProbeInterval, defaultClockProbeInterval)
+
 	// enable PreVote by default
 	if meta == nil || !meta.IsDefined("enable-prevote") {
 		c.PreVote = true
@@ -502,22 +554,6 @@ func (c *ScheduleConfig) adjust() error {
 	return c.validate()
 }
 
-func (c *ScheduleConfig) validate() error {
-	if c.TolerantSizeRatio < 0 {
-		return errors.New("tolerant-size-ratio should be nonnegative")
-	}
-	if c.LowSpaceRatio < 0 || c.LowSpaceRatio > 1 {
-		return errors.New("low-space-ratio should between 0 and 1")
-	}
-	if c.HighSpaceRatio < 0 || c.HighSpaceRatio > 1 {
-		return errors.New("high-space-ratio should between 0 and 1")
-	}
-	if c.LowSpaceRatio <= c.HighSpaceRatio {
-		return errors.New("low-space-ratio should be larger than high-space-ratio")
-	}
-	return nil
-}
-
 // SchedulerConfigs is a slice of customized scheduler configuration.
 type SchedulerConfigs []SchedulerConfig
 
@@ -566,16 +602,6 @@ func (c *ReplicationConfig) clone() *ReplicationConfig {
 	}
 }
 
-func (c *ReplicationConfig) validate() error {
-	for _, label := range c.LocationLabels {
-		err := ValidateLabelString(label)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 func (c *ReplicationConfig) adjust() error {
 	adjustUint64(&c.MaxReplicas, defaultMaxReplicas)
 	return c.validate()
@@ -611,11 +637,25 @@ type SecurityConfig struct {
 	CertPath string `toml:"cert-path" json:"cert-path"`
 	// KeyPath is the path of file that contains X509 key in PEM format.
 	KeyPath string `toml:"key-path" json:"key-path"`
+	// EnableAuth requires the callers of the HTTP API to be authenticated by
+	// bearer tokens or client certificates, and authorizes them by roles.
+	EnableAuth bool `toml:"enable-auth" json:"enable-auth"`
+	// AuthRootToken is the token which always has the admin role, it's used
+	// to create the other tokens.
+	AuthRootToken string `toml:"auth-root-token" json:"-"`
+}
+
+func (s SecurityConfig) tlsEnabled() bool {
+	return len(s.CertPath) != 0 || len(s.KeyPath) != 0
+}
+
+func (s SecurityConfig) certPaths() certutil.Paths {
+	return certutil.Paths{CAPath: s.CAPath, CertPath: s.CertPath, KeyPath: s.KeyPath}
 }
 
 // ToTLSConfig generatres tls config.
 func (s SecurityConfig) ToTLSConfig() (*tls.Config, error) {
-	if len(s.CertPath) == 0 && len(s.KeyPath) == 0 {
+	if !s.tlsEnabled() {
 		return nil, nil
 	}
 	tlsInfo := transport.TLSInfo{
@@ -630,6 +670,87 @@ func (s SecurityConfig) ToTLSConfig() (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
+// AuditConfig is the configuration for the audit log of the mutating calls
+// of the HTTP API.
+type AuditConfig struct {
+	// Disable stops recording the audit log.
+	Disable bool `toml:"disable" json:"disable"`
+	// MaxEntries is the max number of the entries kept in etcd, the oldest
+	// ones are removed.
+	MaxEntries int64 `toml:"max-entries" json:"max-entries"`
+	// File is the path of the file which the entries are mirrored to as JSON
+	// lines, it's disabled if empty.
+	File string `toml:"file" json:"file"`
+}
+
+// HeatmapConfig is the configuration for sampling the flow and size of the
+// regions over the key space.
+type HeatmapConfig struct {
+	// Disable stops sampling the regions.
+	Disable bool `toml:"disable" json:"disable"`
+	// SampleInterval is the interval between two samples.
+	SampleInterval typeutil.Duration `toml:"sample-interval" json:"sample-interval"`
+	// Retention is how long the samples are kept in memory.
+	Retention typeutil.Duration `toml:"retention" json:"retention"`
+	// MaxBuckets is the max number of the key ranges in a sample, the
+	// adjacent regions are compacted into one bucket.
+	MaxBuckets int64 `toml:"max-buckets" json:"max-buckets"`
+}
+
+func (c *HeatmapConfig) adjust() error {
+	adjustDuration(&c.SampleInterval, defaultHeatmapSampleInterval)
+	adjustDuration(&c.Retention, defaultHeatmapRetention)
+	adjustInt64(&c.MaxBuckets, defaultHeatmapMaxBuckets)
+	if c.SampleInterval.Duration <= 0 {
+		return errors.Errorf("invalid heatmap sample-interval %v, it should be positive", c.SampleInterval.Duration)
+	}
+	if c.Retention.Duration < c.SampleInterval.Duration {
+		return errors.Errorf("invalid heatmap retention %v, it should not be less than the sample-interval", c.Retention.Duration)
+	}
+	return nil
+}
+
+// AlertConfig is the configuration for evaluating the diagnose rules in the
+// background and sending the alerts to the webhooks.
+type AlertConfig struct {
+	// Disable stops evaluating the rules.
+	Disable bool `toml:"disable" json:"disable"`
+	// EvaluationInterval is the interval between two evaluations.
+	EvaluationInterval typeutil.Duration `toml:"evaluation-interval" json:"evaluation-interval"`
+	// ResendInterval is the interval to send the firing alerts again.
+	ResendInterval typeutil.Duration `toml:"resend-interval" json:"resend-interval"`
+	// Webhooks are the URLs which the alerts are posted to, such as the
+	// alerts API of Alertmanager "http://127.0.0.1:9093/api/v1/alerts".
+	Webhooks []string `toml:"webhooks" json:"webhooks"`
+}
+
+func (c *AlertConfig) adjust() error {
+	adjustDuration(&c.EvaluationInterval, defaultAlertEvaluationInterval)
+	adjustDuration(&c.ResendInterval, defaultAlertResendInterval)
+	if c.EvaluationInterval.Duration <= 0 {
+		return errors.Errorf("invalid alert evaluation-interval %v, it should be positive", c.EvaluationInterval.Duration)
+	}
+	if c.ResendInterval.Duration <= 0 {
+		return errors.Errorf("invalid alert resend-interval %v, it should be positive", c.ResendInterval.Duration)
+	}
+	for _, webhook := range c.Webhooks {
+		u, err := url.Parse(webhook)
+		if err != nil {
+			return errors.Annotatef(err, "invalid alert webhook %s", webhook)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.Errorf("invalid alert webhook %s, the scheme must be http or https", webhook)
+		}
+	}
+	return nil
+}
+
+func (c *AlertConfig) clone() *AlertConfig {
+	cfg := *c
+	cfg.Webhooks = append([]string(nil), c.Webhooks...)
+	return &cfg
+}
+
 // StoreLabel is the config item of LabelPropertyConfig.
 type StoreLabel struct {
 	Key   string `toml:"key" json:"key"`
@@ -0,0 +1,183 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	log "github.com/sirupsen/logrus"
+)
+
+// ConfigIssue is a problem of a config item, the field is the JSON names
+// joined by dots, such as "schedule.low-space-ratio".
+type ConfigIssue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (i *ConfigIssue) String() string {
+	return fmt.Sprintf("%s: %s", i.Field, i.Message)
+}
+
+// ConfigCheckResult is the problems found in the config. The config is
+// rejected if there is any error, while the warnings are only reported.
+type ConfigCheckResult struct {
+	Errors   []*ConfigIssue `json:"errors,omitempty"`
+	Warnings []*ConfigIssue `json:"warnings,omitempty"`
+}
+
+func (r *ConfigCheckResult) addError(field, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, &ConfigIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *ConfigCheckResult) addWarning(field, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, &ConfigIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns the errors as one, it returns nil if there is no error.
+func (r *ConfigCheckResult) Err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(r.Errors))
+	for _, issue := range r.Errors {
+		msgs = append(msgs, issue.String())
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+func logConfigWarnings(res *ConfigCheckResult) {
+	for _, issue := range res.Warnings {
+		log.Warnf("config warning: %v", issue)
+	}
+}
+
+func (c *ScheduleConfig) check(res *ConfigCheckResult) {
+	if c.TolerantSizeRatio < 0 {
+		res.addError("schedule.tolerant-size-ratio", "should be nonnegative")
+	}
+	if c.LowSpaceRatio < 0 || c.LowSpaceRatio > 1 {
+		res.addError("schedule.low-space-ratio", "should be between 0 and 1")
+	}
+	if c.HighSpaceRatio < 0 || c.HighSpaceRatio > 1 {
+		res.addError("schedule.high-space-ratio", "should be between 0 and 1")
+	}
+	if c.LowSpaceRatio <= c.HighSpaceRatio {
+		res.addError("schedule.low-space-ratio", "should be larger than high-space-ratio")
+	}
+	limits := []struct {
+		name  string
+		value uint64
+	}{
+		{"leader-schedule-limit", c.LeaderScheduleLimit},
+		{"region-schedule-limit", c.RegionScheduleLimit},
+		{"replica-schedule-limit", c.ReplicaScheduleLimit},
+	}
+	for _, limit := range limits {
+		if limit.value == 0 {
+			res.addWarning("schedule."+limit.name, "is 0, the related operators are never created")
+		}
+	}
+	if c.MaxSnapshotCount == 0 {
+		res.addWarning("schedule.max-snapshot-count", "is 0, no replica can be added")
+	}
+}
+
+func (c *ScheduleConfig) validate() error {
+	res := &ConfigCheckResult{}
+	c.check(res)
+	return res.Err()
+}
+
+func checkMaxReplicas(field string, maxReplicas uint64, res *ConfigCheckResult) {
+	if maxReplicas == 0 {
+		res.addError(field, "should be positive")
+	} else if maxReplicas%2 == 0 {
+		res.addWarning(field, "is even, which tolerates no more failures than %d replicas", maxReplicas-1)
+	}
+}
+
+func (c *ReplicationConfig) check(res *ConfigCheckResult) {
+	checkMaxReplicas("replication.max-replicas", c.MaxReplicas, res)
+	labels := make(map[string]struct{}, len(c.LocationLabels))
+	for _, label := range c.LocationLabels {
+		if err := ValidateLabelString(label); err != nil {
+			res.addError("replication.location-labels", "%v", err)
+		}
+		if _, ok := labels[label]; ok {
+			res.addError("replication.location-labels", "label %q is duplicated", label)
+		}
+		labels[label] = struct{}{}
+	}
+}
+
+func (c *ReplicationConfig) validate() error {
+	res := &ConfigCheckResult{}
+	c.check(res)
+	return res.Err()
+}
+
+// check checks the items which can be updated online.
+func (c *Config) check() *ConfigCheckResult {
+	res := &ConfigCheckResult{}
+	c.Schedule.check(res)
+	c.Replication.check(res)
+	for name, ns := range c.Namespace {
+		if ns.MaxReplicas != 0 {
+			checkMaxReplicas(fmt.Sprintf("namespace.%s.max-replicas", name), ns.MaxReplicas, res)
+		}
+	}
+	return res
+}
+
+// checkStores checks the config against the stores which are not tombstone.
+func (c *Config) checkStores(stores []*metapb.Store, res *ConfigCheckResult) {
+	var count uint64
+	labels := make(map[string]struct{})
+	for _, store := range stores {
+		if store.GetState() == metapb.StoreState_Tombstone {
+			continue
+		}
+		count++
+		for _, label := range store.GetLabels() {
+			labels[label.GetKey()] = struct{}{}
+		}
+	}
+	if c.Replication.MaxReplicas > count {
+		res.addWarning("replication.max-replicas", "is larger than the number of the stores %d", count)
+	}
+	for name, ns := range c.Namespace {
+		if ns.MaxReplicas > count {
+			res.addWarning(fmt.Sprintf("namespace.%s.max-replicas", name), "is larger than the number of the stores %d", count)
+		}
+	}
+	for _, label := range c.Replication.LocationLabels {
+		if _, ok := labels[label]; !ok {
+			res.addWarning("replication.location-labels", "no store has the label %q", label)
+		}
+	}
+}
+
+// CheckConfig checks the config which can be updated online, including the
+// stores of the cluster if it is bootstrapped, but doesn't apply it.
+func (s *Server) CheckConfig(cfg *Config) *ConfigCheckResult {
+	res := cfg.check()
+	if cluster := s.GetRaftCluster(); cluster != nil {
+		cfg.checkStores(cluster.GetStores(), res)
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+var _ = Suite(&testConfigCheckSuite{})
+
+type testConfigCheckSuite struct{}
+
+func (s *testConfigCheckSuite) TestCheck(c *C) {
+	cfg := NewConfig()
+	c.Assert(cfg.Schedule.adjust(), IsNil)
+	c.Assert(cfg.Replication.adjust(), IsNil)
+	res := cfg.check()
+	c.Assert(res.Errors, HasLen, 0)
+	c.Assert(res.Warnings, HasLen, 0)
+	c.Assert(res.Err(), IsNil)
+
+	cfg.Schedule.LowSpaceRatio, cfg.Schedule.HighSpaceRatio = 0.5, 1.5
+	cfg.Schedule.RegionScheduleLimit = 0
+	cfg.Replication.LocationLabels = []string{"zone", "zone", "-host"}
+	cfg.Namespace = map[string]NamespaceConfig{"ns1": {MaxReplicas: 2}}
+	res = cfg.check()
+	c.Assert(res.Errors, DeepEquals, []*ConfigIssue{
+		{Field: "schedule.high-space-ratio", Message: "should be between 0 and 1"},
+		{Field: "schedule.low-space-ratio", Message: "should be larger than high-space-ratio"},
+		{Field: "replication.location-labels", Message: `label "zone" is duplicated`},
+		{Field: "replication.location-labels", Message: res.Errors[3].Message},
+	})
+	c.Assert(res.Warnings, HasLen, 2)
+	c.Assert(res.Warnings[0].Field, Equals, "schedule.region-schedule-limit")
+	c.Assert(res.Warnings[1].Field, Equals, "namespace.ns1.max-replicas")
+	c.Assert(res.Err(), ErrorMatches, "schedule.high-space-ratio: should be between 0 and 1; .*")
+}
+
+func (s *testConfigCheckSuite) TestCheckStores(c *C) {
+	cfg := NewConfig()
+	c.Assert(cfg.Replication.adjust(), IsNil)
+	cfg.Replication.LocationLabels = []string{"zone", "host"}
+	stores := []*metapb.Store{
+		{Id: 1, Labels: []*metapb.StoreLabel{{Key: "zone", Value: "z1"}}},
+		{Id: 2, Labels: []*metapb.StoreLabel{{Key: "zone", Value: "z2"}}},
+		{Id: 3, State: metapb.StoreState_Tombstone, Labels: []*metapb.StoreLabel{{Key: "host", Value: "h1"}}},
+	}
+	res := &ConfigCheckResult{}
+	cfg.checkStores(stores, res)
+	c.Assert(res.Errors, HasLen, 0)
+	c.Assert(res.Warnings, DeepEquals, []*ConfigIssue{
+		{Field: "replication.max-replicas", Message: "is larger than the number of the stores 2"},
+		{Field: "replication.location-labels", Message: `no store has the label "host"`},
+	})
+}
@@ -0,0 +1,246 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/server/core"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxConfigHistory is the max number of the revisions kept in the history,
+// the oldest ones are removed.
+const maxConfigHistory = 64
+
+// ConfigRevision is a revision of the persisted config.
+type ConfigRevision struct {
+	Revision uint64    `json:"revision"`
+	Time     time.Time `json:"time"`
+	Config   *Config   `json:"config,omitempty"`
+	// Changes is the difference from the previous revision, it is empty if
+	// the previous revision is not in the history.
+	Changes []*ConfigChange `json:"changes,omitempty"`
+}
+
+// ConfigChange is a changed item of the config, the path is the JSON names
+// joined by dots, such as "schedule.leader-schedule-limit".
+type ConfigChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+func saveConfigRevision(kv *core.KV, cfg *Config) error {
+	rev := &ConfigRevision{
+		Revision: cfg.ConfigRevision,
+		Time:     time.Now(),
+		Config:   cfg,
+	}
+	if err := kv.SaveConfigRevision(rev.Revision, rev); err != nil {
+		return errors.Trace(err)
+	}
+	if rev.Revision > maxConfigHistory {
+		return errors.Trace(kv.RemoveConfigRevision(rev.Revision - maxConfigHistory))
+	}
+	return nil
+}
+
+func loadConfigRevision(kv *core.KV, revision uint64) (*ConfigRevision, error) {
+	rev := &ConfigRevision{}
+	ok, err := kv.LoadConfigRevision(revision, rev)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return rev, nil
+}
+
+// flattenConfig flattens the JSON objects into the leaf values by the paths.
+func flattenConfig(prefix string, v interface{}, res map[string]interface{}) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		res[prefix] = v
+		return
+	}
+	for k, item := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+		flattenConfig(k, item, res)
+	}
+}
+
+func configToMap(cfg *Config) (map[string]interface{}, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, errors.Trace(err)
+	}
+	res := make(map[string]interface{})
+	flattenConfig("", v, res)
+	// The revision always changes.
+	delete(res, "config-revision")
+	return res, nil
+}
+
+// diffConfig returns the changed items sorted by the paths.
+func diffConfig(oldCfg, newCfg *Config) ([]*ConfigChange, error) {
+	oldItems, err := configToMap(oldCfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	newItems, err := configToMap(newCfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var changes []*ConfigChange
+	for path, v := range newItems {
+		if old, ok := oldItems[path]; !ok || !reflect.DeepEqual(old, v) {
+			changes = append(changes, &ConfigChange{Path: path, Old: old, New: v})
+		}
+	}
+	for path, old := range oldItems {
+		if _, ok := newItems[path]; !ok {
+			changes = append(changes, &ConfigChange{Path: path, Old: old})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// GetConfigRevision returns the revision of the persisted config.
+func (s *Server) GetConfigRevision() uint64 {
+	return s.scheduleOpt.loadRevision()
+}
+
+// UpdateConfig runs update with the config locked, so the config is not
+// updated by the other callers of UpdateConfig meanwhile. If revision is not
+// 0, update runs only if it is the revision of the persisted config.
+func (s *Server) UpdateConfig(revision uint64, update func() error) error {
+	s.configLock.Lock()
+	defer s.configLock.Unlock()
+	if current := s.GetConfigRevision(); revision != 0 && revision != current {
+		return errors.Annotatef(ErrConfigRevisionMismatch, "expected %v, current %v", revision, current)
+	}
+	return update()
+}
+
+// GetConfigRevisionDetail returns the revision in the history with the
+// config and the changes.
+func (s *Server) GetConfigRevisionDetail(revision uint64) (*ConfigRevision, error) {
+	rev, err := loadConfigRevision(s.kv, revision)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if rev == nil {
+		return nil, errors.Trace(ErrConfigRevisionNotFound)
+	}
+	prev, err := loadConfigRevision(s.kv, revision-1)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if prev != nil {
+		if rev.Changes, err = diffConfig(prev.Config, rev.Config); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return rev, nil
+}
+
+// GetConfigHistory returns at most limit latest revisions in the history
+// with the changes, from the newest to the oldest. The configs are omitted.
+func (s *Server) GetConfigHistory(limit int) ([]*ConfigRevision, error) {
+	if limit <= 0 || limit > maxConfigHistory {
+		limit = maxConfigHistory
+	}
+	// Load one more revision to get the changes of the oldest one.
+	var revs []*ConfigRevision
+	for revision := s.GetConfigRevision(); revision > 0 && len(revs) <= limit; revision-- {
+		rev, err := loadConfigRevision(s.kv, revision)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if rev == nil {
+			break
+		}
+		revs = append(revs, rev)
+	}
+	res := revs
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	for i, rev := range res {
+		if i+1 < len(revs) {
+			changes, err := diffConfig(revs[i+1].Config, rev.Config)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			rev.Changes = changes
+		}
+	}
+	for _, rev := range res {
+		rev.Config = nil
+	}
+	return res, nil
+}
+
+// RollbackConfig restores the schedule, replication, namespace and label
+// property config to the revision, as a new revision. The schedulers and
+// the cluster version are not changed.
+func (s *Server) RollbackConfig(revision uint64) error {
+	rev, err := loadConfigRevision(s.kv, revision)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if rev == nil {
+		return errors.Trace(ErrConfigRevisionNotFound)
+	}
+	cfg := rev.Config
+	schedule := cfg.Schedule.clone()
+	schedule.Schedulers = s.scheduleOpt.load().clone().Schedulers
+	if err := schedule.validate(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := cfg.Replication.validate(); err != nil {
+		return errors.Trace(err)
+	}
+
+	s.scheduleOpt.store(schedule)
+	s.scheduleOpt.rep.store(&cfg.Replication)
+	for name := range s.scheduleOpt.ns {
+		if _, ok := cfg.Namespace[name]; !ok {
+			delete(s.scheduleOpt.ns, name)
+		}
+	}
+	for name, nsCfg := range cfg.Namespace {
+		nsCfg := nsCfg
+		s.scheduleOpt.ns[name] = newNamespaceOption(&nsCfg)
+	}
+	s.scheduleOpt.labelProperty.Store(cfg.LabelProperty)
+	if err := s.scheduleOpt.persist(s.kv); err != nil {
+		return errors.Trace(err)
+	}
+	log.Infof("config is rolled back to revision %v as revision %v", revision, s.GetConfigRevision())
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testConfigHistorySuite{})
+
+type testConfigHistorySuite struct {
+	svr     *Server
+	cleanup cleanupFunc
+}
+
+func (s *testConfigHistorySuite) SetUpTest(c *C) {
+	s.svr, s.cleanup = mustRunTestServer(c)
+}
+
+func (s *testConfigHistorySuite) TearDownTest(c *C) {
+	s.cleanup()
+}
+
+func (s *testConfigHistorySuite) TestDiffConfig(c *C) {
+	oldCfg, newCfg := &Config{}, &Config{}
+	newCfg.Schedule.LeaderScheduleLimit = 8
+	newCfg.Namespace = map[string]NamespaceConfig{"ns1": {MaxReplicas: 5}}
+	newCfg.ConfigRevision = 2
+	changes, err := diffConfig(oldCfg, newCfg)
+	c.Assert(err, IsNil)
+	// The null namespace is replaced by the items of ns1.
+	c.Assert(changes[0], DeepEquals, &ConfigChange{Path: "namespace", Old: nil})
+	var found bool
+	for _, change := range changes[1 : len(changes)-1] {
+		c.Assert(strings.HasPrefix(change.Path, "namespace.ns1."), IsTrue)
+		c.Assert(change.Old, IsNil)
+		if change.Path == "namespace.ns1.max-replicas" {
+			found = true
+			c.Assert(change.New, Equals, float64(5))
+		}
+	}
+	c.Assert(found, IsTrue)
+	c.Assert(changes[len(changes)-1], DeepEquals, &ConfigChange{Path: "schedule.leader-schedule-limit", Old: float64(0), New: float64(8)})
+}
+
+func (s *testConfigHistorySuite) TestHistory(c *C) {
+	svr := s.svr
+	base := svr.GetConfigRevision()
+	cfg := svr.GetScheduleConfig()
+	oldLimit := cfg.RegionScheduleLimit
+	cfg.LeaderScheduleLimit = 100
+	_, err := svr.SetScheduleConfig(*cfg)
+	c.Assert(err, IsNil)
+	cfg.RegionScheduleLimit = 200
+	_, err = svr.SetScheduleConfig(*cfg)
+	c.Assert(err, IsNil)
+	c.Assert(svr.GetConfigRevision(), Equals, base+2)
+
+	history, err := svr.GetConfigHistory(1)
+	c.Assert(err, IsNil)
+	c.Assert(history, HasLen, 1)
+	c.Assert(history[0].Revision, Equals, base+2)
+	c.Assert(history[0].Config, IsNil)
+	c.Assert(history[0].Changes, DeepEquals, []*ConfigChange{
+		{Path: "schedule.region-schedule-limit", Old: float64(oldLimit), New: float64(200)},
+	})
+	rev, err := svr.GetConfigRevisionDetail(base + 2)
+	c.Assert(err, IsNil)
+	c.Assert(rev.Config.Schedule.RegionScheduleLimit, Equals, uint64(200))
+	c.Assert(rev.Changes, DeepEquals, history[0].Changes)
+
+	// The update runs only if the revision matches.
+	err = svr.UpdateConfig(base+1, func() error {
+		c.Fatal("should not update")
+		return nil
+	})
+	c.Assert(errors.Cause(err), Equals, ErrConfigRevisionMismatch)
+	err = svr.UpdateConfig(base+2, func() error { return svr.RollbackConfig(base + 1) })
+	c.Assert(err, IsNil)
+	c.Assert(svr.GetConfigRevision(), Equals, base+3)
+	c.Assert(svr.GetScheduleConfig().LeaderScheduleLimit, Equals, uint64(100))
+	c.Assert(svr.GetScheduleConfig().RegionScheduleLimit, Equals, oldLimit)
+	c.Assert(errors.Cause(svr.RollbackConfig(base+100)), Equals, ErrConfigRevisionNotFound)
+
+	// The oldest revision is removed.
+	atomic.StoreUint64(&svr.scheduleOpt.revision, base+2+maxConfigHistory)
+	c.Assert(svr.scheduleOpt.persist(svr.kv), IsNil)
+	rev, err = loadConfigRevision(svr.kv, base+3)
+	c.Assert(err, IsNil)
+	c.Assert(rev, IsNil)
+	rev, err = loadConfigRevision(svr.kv, base+2)
+	c.Assert(err, IsNil)
+	c.Assert(rev, NotNil)
+}
@@ -0,0 +1,248 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/pkg/certutil"
+	"github.com/pingcap/pd/pkg/logutil"
+	"github.com/pingcap/pd/pkg/metricutil"
+	log "github.com/sirupsen/logrus"
+)
+
+// ConfigReloadResult is the result of reloading the config file. Applied
+// are the changes of the file which take effect, Ignored are the changes
+// which need a restart or can only be applied on the leader.
+type ConfigReloadResult struct {
+	Applied []*ConfigChange `json:"applied,omitempty"`
+	Ignored []*ConfigIssue  `json:"ignored,omitempty"`
+}
+
+// reloadableSecurityItems are the items of the security config which can be
+// changed online, the other ones need a restart.
+var reloadableSecurityItems = map[string]struct{}{
+	"security.cacert-path": {},
+	"security.cert-path":   {},
+	"security.key-path":    {},
+}
+
+// inSection returns true if the path is the section or an item in it.
+func inSection(path, section string) bool {
+	return path == section || strings.HasPrefix(path, section+".")
+}
+
+// overlayConfig overwrites the items of cur with the ones of src by the JSON
+// names. The items are the first names of the paths in the section.
+func overlayConfig(section string, changes []*ConfigChange, src, cur interface{}) error {
+	srcItems, err := toJSONObject(src)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	curItems, err := toJSONObject(cur)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, change := range changes {
+		name := strings.SplitN(strings.TrimPrefix(change.Path, section+"."), ".", 2)[0]
+		curItems[name] = srcItems[name]
+	}
+	data, err := json.Marshal(curItems)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(json.Unmarshal(data, cur))
+}
+
+func toJSONObject(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return m, nil
+}
+
+// ReloadConfigFile parses the config file and the command line arguments
+// again, then applies the changes of the file which are safe to change
+// online. Nothing is applied if the new config is invalid.
+func (s *Server) ReloadConfigFile() (*ConfigReloadResult, error) {
+	if s.cfg.configFile == "" {
+		return nil, errors.New("no config file is specified")
+	}
+	cfg := NewConfig()
+	if err := cfg.Parse(s.cfg.arguments); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return s.reloadConfig(cfg)
+}
+
+// reloadConfig applies the changes from the config loaded last time to cfg.
+// The log, metric, alert and TLS certificates are applied to the server,
+// while the schedule, replication and label property are persisted as a new
+// revision if the server is the leader.
+func (s *Server) reloadConfig(cfg *Config) (*ConfigReloadResult, error) {
+	res := &ConfigReloadResult{}
+	err := s.UpdateConfig(0, func() error {
+		changes, err := diffConfig(s.fileCfg, cfg)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		isLeader := s.IsLeader()
+		var logChanged, metricChanged, securityChanged, alertChanged, labelPropertyChanged bool
+		var scheduleChanges, replicationChanges []*ConfigChange
+		for _, change := range changes {
+			switch {
+			case inSection(change.Path, "WarningMsgs"):
+				continue
+			case inSection(change.Path, "log.file"):
+				res.Ignored = append(res.Ignored, &ConfigIssue{Field: change.Path, Message: "needs a restart"})
+				continue
+			case inSection(change.Path, "log"):
+				logChanged = true
+			case inSection(change.Path, "metric"):
+				metricChanged = true
+			case inSection(change.Path, "alert"):
+				alertChanged = true
+			case inSection(change.Path, "security"):
+				// Enabling or disabling TLS needs a restart.
+				if _, ok := reloadableSecurityItems[change.Path]; !ok || s.certReloader == nil || !cfg.Security.tlsEnabled() {
+					res.Ignored = append(res.Ignored, &ConfigIssue{Field: change.Path, Message: "needs a restart"})
+					continue
+				}
+				securityChanged = true
+			case inSection(change.Path, "schedule.schedulers"):
+				res.Ignored = append(res.Ignored, &ConfigIssue{Field: change.Path, Message: "is managed by the scheduler API"})
+				continue
+			case inSection(change.Path, "schedule"), inSection(change.Path, "replication"), inSection(change.Path, "label-property"):
+				if !isLeader {
+					res.Ignored = append(res.Ignored, &ConfigIssue{Field: change.Path, Message: "is only applied on the leader"})
+					continue
+				}
+				if inSection(change.Path, "schedule") {
+					scheduleChanges = append(scheduleChanges, change)
+				} else if inSection(change.Path, "replication") {
+					replicationChanges = append(replicationChanges, change)
+				} else {
+					labelPropertyChanged = true
+				}
+			default:
+				res.Ignored = append(res.Ignored, &ConfigIssue{Field: change.Path, Message: "needs a restart"})
+				continue
+			}
+			res.Applied = append(res.Applied, change)
+		}
+
+		// Check all the changes before applying any of them.
+		schedule := s.scheduleOpt.load().clone()
+		if err := overlayConfig("schedule", scheduleChanges, &cfg.Schedule, schedule); err != nil {
+			return errors.Trace(err)
+		}
+		replication := *s.scheduleOpt.rep.load()
+		if err := overlayConfig("replication", replicationChanges, &cfg.Replication, &replication); err != nil {
+			return errors.Trace(err)
+		}
+		check := &ConfigCheckResult{}
+		schedule.check(check)
+		replication.check(check)
+		if err := check.Err(); err != nil {
+			return errors.Trace(err)
+		}
+		if securityChanged {
+			if _, err := certutil.NewReloader(cfg.Security.certPaths()); err != nil {
+				return errors.Annotate(err, "failed to load the TLS certificates")
+			}
+		}
+
+		s.cfgMu.Lock()
+		if logChanged {
+			level, file := s.cfg.Log.Level, s.cfg.Log.File
+			s.cfg.Log = cfg.Log
+			s.cfg.Log.File = file
+			if cfg.Log.Level == s.fileCfg.Log.Level {
+				// Keep the level set by the API.
+				s.cfg.Log.Level = level
+			}
+			logutil.ReloadLogger(&s.cfg.Log)
+		}
+		if metricChanged {
+			s.cfg.Metric = cfg.Metric
+			metricutil.Push(&s.cfg.Metric)
+		}
+		if alertChanged {
+			s.cfg.Alert = *cfg.Alert.clone()
+		}
+		if securityChanged {
+			s.cfg.Security.CAPath = cfg.Security.CAPath
+			s.cfg.Security.CertPath = cfg.Security.CertPath
+			s.cfg.Security.KeyPath = cfg.Security.KeyPath
+		}
+		s.cfgMu.Unlock()
+		if securityChanged {
+			// The listeners of the embedded etcd keep reading the files at
+			// the previous paths on each handshake.
+			if err := s.certReloader.SetPaths(cfg.Security.certPaths()); err != nil {
+				return errors.Trace(err)
+			}
+		}
+
+		if len(scheduleChanges) > 0 || len(replicationChanges) > 0 || labelPropertyChanged {
+			logConfigWarnings(check)
+			s.scheduleOpt.store(schedule)
+			s.scheduleOpt.rep.store(&replication)
+			if labelPropertyChanged {
+				s.scheduleOpt.labelProperty.Store(cfg.LabelProperty.clone())
+			}
+			if err := s.scheduleOpt.persist(s.kv); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		fileCfg := cfg
+		if !isLeader {
+			// Keep the sections ignored on a follower, so they are applied by
+			// a reload after it becomes the leader.
+			fileCfg = cfg.clone()
+			fileCfg.Schedule = s.fileCfg.Schedule
+			fileCfg.Replication = s.fileCfg.Replication
+			fileCfg.LabelProperty = s.fileCfg.LabelProperty
+		}
+		s.fileCfg = fileCfg
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, change := range res.Applied {
+		log.Infof("config %s is reloaded: %v -> %v", change.Path, change.Old, change.New)
+	}
+	for _, issue := range res.Ignored {
+		log.Warnf("config %v, the change is not applied", issue)
+	}
+	return res, nil
+}
+
+// GetFileConfig returns the config loaded from the config file and the
+// command line arguments last time, the changes which need a restart are
+// included, while the schedule, replication and label property ignored on a
+// follower are not.
+func (s *Server) GetFileConfig() *Config {
+	s.configLock.Lock()
+	defer s.configLock.Unlock()
+	return s.fileCfg.clone()
+}
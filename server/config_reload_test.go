@@ -0,0 +1,142 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testConfigReloadSuite{})
+
+type testConfigReloadSuite struct {
+	svr     *Server
+	cleanup cleanupFunc
+}
+
+func (s *testConfigReloadSuite) SetUpTest(c *C) {
+	s.svr, s.cleanup = mustRunTestServer(c)
+	mustWaitLeader(c, []*Server{s.svr})
+}
+
+func (s *testConfigReloadSuite) TearDownTest(c *C) {
+	s.cleanup()
+}
+
+func (s *testConfigReloadSuite) TestReloadConfig(c *C) {
+	// The online change is kept if the item is not changed in the file.
+	schedule := *s.svr.GetScheduleConfig()
+	schedule.RegionScheduleLimit = 7
+	_, err := s.svr.SetScheduleConfig(schedule)
+	c.Assert(err, IsNil)
+	revision := s.svr.GetConfigRevision()
+
+	cfg := s.svr.GetFileConfig()
+	cfg.Schedule.LeaderScheduleLimit = 9
+	cfg.Replication.MaxReplicas = 5
+	cfg.Metric.PushJob = "reload"
+	cfg.PeerUrls = "http://127.0.0.1:1"
+	cfg.Security.EnableAuth = true
+	res, err := s.svr.reloadConfig(cfg)
+	c.Assert(err, IsNil)
+	var applied []string
+	for _, change := range res.Applied {
+		applied = append(applied, change.Path)
+	}
+	c.Assert(applied, DeepEquals, []string{"metric.job", "replication.max-replicas", "schedule.leader-schedule-limit"})
+	c.Assert(res.Ignored, DeepEquals, []*ConfigIssue{
+		{Field: "peer-urls", Message: "needs a restart"},
+		{Field: "security.enable-auth", Message: "needs a restart"},
+	})
+
+	effective := s.svr.GetConfig()
+	c.Assert(effective.Schedule.LeaderScheduleLimit, Equals, uint64(9))
+	c.Assert(effective.Schedule.RegionScheduleLimit, Equals, uint64(7))
+	c.Assert(effective.Replication.MaxReplicas, Equals, uint64(5))
+	c.Assert(effective.Metric.PushJob, Equals, "reload")
+	c.Assert(effective.PeerUrls, Not(Equals), "http://127.0.0.1:1")
+	c.Assert(effective.Security.EnableAuth, IsFalse)
+	c.Assert(effective.ConfigRevision, Equals, revision+1)
+	c.Assert(s.svr.GetFileConfig().PeerUrls, Equals, "http://127.0.0.1:1")
+
+	// Nothing changes if the file is not changed.
+	res, err = s.svr.reloadConfig(s.svr.GetFileConfig())
+	c.Assert(err, IsNil)
+	c.Assert(res.Applied, HasLen, 0)
+	c.Assert(res.Ignored, HasLen, 0)
+	c.Assert(s.svr.GetConfigRevision(), Equals, revision+1)
+
+	// Nothing is applied if any item is invalid.
+	cfg = s.svr.GetFileConfig()
+	cfg.Schedule.LeaderScheduleLimit = 3
+	cfg.Replication.MaxReplicas = 0
+	_, err = s.svr.reloadConfig(cfg)
+	c.Assert(err, NotNil)
+	c.Assert(s.svr.GetScheduleConfig().LeaderScheduleLimit, Equals, uint64(9))
+	c.Assert(s.svr.GetFileConfig().Replication.MaxReplicas, Equals, uint64(5))
+}
+
+func (s *testConfigReloadSuite) TestReloadConfigOnFollower(c *C) {
+	cfg := s.svr.GetFileConfig()
+	cfg.Schedule.LeaderScheduleLimit = 9
+	cfg.Replication.MaxReplicas = 5
+	cfg.Metric.PushJob = "reload"
+	s.svr.enableLeader(false)
+	res, err := s.svr.reloadConfig(cfg)
+	s.svr.enableLeader(true)
+	c.Assert(err, IsNil)
+	c.Assert(res.Applied, HasLen, 1)
+	c.Assert(res.Applied[0].Path, Equals, "metric.job")
+	c.Assert(res.Ignored, HasLen, 2)
+	fileCfg := s.svr.GetFileConfig()
+	c.Assert(fileCfg.Metric.PushJob, Equals, "reload")
+	c.Assert(fileCfg.Schedule.LeaderScheduleLimit, Not(Equals), uint64(9))
+	c.Assert(fileCfg.Replication.MaxReplicas, Not(Equals), uint64(5))
+
+	// The ignored changes are applied after it becomes the leader.
+	res, err = s.svr.reloadConfig(cfg)
+	c.Assert(err, IsNil)
+	c.Assert(res.Applied, HasLen, 2)
+	c.Assert(s.svr.GetScheduleConfig().LeaderScheduleLimit, Equals, uint64(9))
+	c.Assert(s.svr.GetReplicationConfig().MaxReplicas, Equals, uint64(5))
+}
+
+func (s *testConfigReloadSuite) TestReloadConfigFile(c *C) {
+	_, err := s.svr.ReloadConfigFile()
+	c.Assert(err, NotNil)
+
+	dir, err := ioutil.TempDir("", "pd_config_reload")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.toml")
+	c.Assert(ioutil.WriteFile(file, []byte("[log]\nlevel = \"warn\"\n"), 0644), IsNil)
+	args := []string{"-config", file, "-name", s.svr.cfg.Name, "-data-dir", s.svr.cfg.DataDir}
+	cfg := NewConfig()
+	c.Assert(cfg.Parse(args), IsNil)
+	s.svr.cfg.configFile, s.svr.cfg.arguments = file, args
+	s.svr.fileCfg = cfg
+
+	c.Assert(ioutil.WriteFile(file, []byte("[log]\nlevel = \"info\"\n"), 0644), IsNil)
+	res, err := s.svr.ReloadConfigFile()
+	c.Assert(err, IsNil)
+	c.Assert(res.Applied, DeepEquals, []*ConfigChange{{Path: "log.level", Old: "warn", New: "info"}})
+	c.Assert(s.svr.GetConfig().Log.Level, Equals, "info")
+
+	c.Assert(ioutil.WriteFile(file, []byte("[log\n"), 0644), IsNil)
+	_, err = s.svr.ReloadConfigFile()
+	c.Assert(err, NotNil)
+}
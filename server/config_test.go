@@ -15,6 +15,7 @@ package server
 
 import (
 	"path"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/pd/server/core"
@@ -80,4 +81,19 @@ func (s *testConfigSuite) TestValidation(c *C) {
 	c.Assert(cfg.Schedule.validate(), IsNil)
 	cfg.Schedule.TolerantSizeRatio = -0.6
 	c.Assert(cfg.Schedule.validate(), NotNil)
+
+	// check heatmap config
+	cfg.Heatmap.SampleInterval.Duration = -time.Minute
+	c.Assert(cfg.Heatmap.adjust(), NotNil)
+	cfg.Heatmap.SampleInterval.Duration = time.Hour
+	cfg.Heatmap.Retention.Duration = time.Minute
+	c.Assert(cfg.Heatmap.adjust(), NotNil)
+	cfg.Heatmap.Retention.Duration = time.Hour
+	c.Assert(cfg.Heatmap.adjust(), IsNil)
+
+	// check alert config
+	cfg.Alert.EvaluationInterval.Duration = -time.Minute
+	c.Assert(cfg.Alert.adjust(), NotNil)
+	cfg.Alert.EvaluationInterval.Duration = time.Minute
+	c.Assert(cfg.Alert.adjust(), IsNil)
 }
@@ -66,6 +66,8 @@ type coordinator struct {
 	classifier       namespace.Classifier
 	histories        *list.List
 	hbStreams        *heartbeatStreams
+	batches          []*operatorBatch
+	nextBatchID      uint64
 }
 
 func newCoordinator(cluster *clusterInfo, hbStreams *heartbeatStreams, classifier namespace.Classifier) *coordinator {
@@ -84,6 +86,7 @@ func newCoordinator(cluster *clusterInfo, hbStreams *heartbeatStreams, classifie
 		classifier:       classifier,
 		histories:        list.New(),
 		hbStreams:        hbStreams,
+		nextBatchID:      1,
 	}
 }
 
@@ -467,7 +470,11 @@ func (c *coordinator) addOperatorLocked(op *schedule.Operator) bool {
 func (c *coordinator) addOperator(ops ...*schedule.Operator) bool {
 	c.Lock()
 	defer c.Unlock()
+	return c.addOperatorsLocked(ops...)
+}
 
+// addOperatorsLocked adds the operators only if all of them can be added.
+func (c *coordinator) addOperatorsLocked(ops ...*schedule.Operator) bool {
 	for _, op := range ops {
 		if !c.checkAddOperator(op) {
 			operatorCounter.WithLabelValues(op.Desc(), "canceled").Inc()
@@ -0,0 +1,115 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/juju/errors"
+)
+
+const (
+	boltFileName    = "kv.db"
+	boltOpenTimeout = time.Second * 10
+)
+
+var boltBucket = []byte("pd")
+
+// BoltKV is a KVBase backed by a local bolt database.
+type BoltKV struct {
+	db *bolt.DB
+}
+
+// NewBoltKV opens (or creates) a bolt database in the directory.
+func NewBoltKV(dir string) (*BoltKV, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Trace(err)
+	}
+	db, err := bolt.Open(filepath.Join(dir, boltFileName), 0600, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Trace(err)
+	}
+	return &BoltKV{db: db}, nil
+}
+
+// Load gets the value of the key.
+func (kv *BoltKV) Load(key string) (string, error) {
+	var value string
+	err := kv.db.View(func(tx *bolt.Tx) error {
+		value = string(tx.Bucket(boltBucket).Get([]byte(key)))
+		return nil
+	})
+	return value, errors.Trace(err)
+}
+
+// LoadRange gets the values of keys in [key, endKey), at most limit items.
+func (kv *BoltKV) LoadRange(key, endKey string, limit int) ([]string, error) {
+	res := make([]string, 0, limit)
+	err := kv.db.View(func(tx *bolt.Tx) error {
+		end := []byte(endKey)
+		c := tx.Bucket(boltBucket).Cursor()
+		for k, v := c.Seek([]byte(key)); k != nil && bytes.Compare(k, end) < 0 && len(res) < limit; k, v = c.Next() {
+			res = append(res, string(v))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return res, nil
+}
+
+// Save puts the key-value pair.
+func (kv *BoltKV) Save(key, value string) error {
+	return errors.Trace(kv.SaveBatch(map[string]string{key: value}))
+}
+
+// SaveBatch puts all the key-value pairs in one transaction.
+func (kv *BoltKV) SaveBatch(kvs map[string]string) error {
+	err := kv.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucket)
+		for k, v := range kvs {
+			if err := b.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return errors.Trace(err)
+}
+
+// Delete removes the key.
+func (kv *BoltKV) Delete(key string) error {
+	err := kv.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete([]byte(key))
+	})
+	return errors.Trace(err)
+}
+
+// Close closes the underlying database.
+func (kv *BoltKV) Close() error {
+	return errors.Trace(kv.db.Close())
+}
@@ -17,8 +17,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"net/url"
 	"path"
 	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/juju/errors"
@@ -30,6 +33,15 @@ const (
 	configPath   = "config"
 	schedulePath = "schedule"
 	gcPath       = "gc"
+	authPath     = "auth"
+	auditPath    = "audit"
+	alertPath    = "alert"
+	// configHistoryPath keeps the recent revisions of the config.
+	configHistoryPath = "config_history"
+
+	// regionMigratedPath marks that the regions in the default storage have
+	// been copied to the region storage.
+	regionMigratedPath = "region_migrated"
 )
 
 const (
@@ -40,6 +52,8 @@ const (
 // KV wraps all kv operations, keep it stateless.
 type KV struct {
 	KVBase
+	regionKV    *RegionKV
+	useRegionKV int32
 }
 
 // NewKV creates KV instance with KVBase.
@@ -49,6 +63,36 @@ func NewKV(base KVBase) *KV {
 	}
 }
 
+// SetRegionKV sets the storage for region meta.
+func (kv *KV) SetRegionKV(regionKV *RegionKV) *KV {
+	kv.regionKV = regionKV
+	return kv
+}
+
+// GetRegionKV gets the storage for region meta.
+func (kv *KV) GetRegionKV() *RegionKV {
+	return kv.regionKV
+}
+
+// SwitchToRegionStorage switches region meta operations to the region storage.
+func (kv *KV) SwitchToRegionStorage() {
+	if kv.regionKV != nil {
+		atomic.StoreInt32(&kv.useRegionKV, 1)
+	}
+}
+
+// SwitchToDefaultStorage switches region meta operations to the default storage.
+func (kv *KV) SwitchToDefaultStorage() {
+	atomic.StoreInt32(&kv.useRegionKV, 0)
+}
+
+func (kv *KV) regionBase() KVBase {
+	if atomic.LoadInt32(&kv.useRegionKV) == 1 {
+		return kv.regionKV
+	}
+	return kv.KVBase
+}
+
 func (kv *KV) storePath(storeID uint64) string {
 	return path.Join(clusterPath, "s", fmt.Sprintf("%020d", storeID))
 }
@@ -92,17 +136,17 @@ func (kv *KV) SaveStore(store *metapb.Store) error {
 
 // LoadRegion loads one regoin from KV.
 func (kv *KV) LoadRegion(regionID uint64, region *metapb.Region) (bool, error) {
-	return kv.loadProto(kv.regionPath(regionID), region)
+	return loadProto(kv.regionBase(), kv.regionPath(regionID), region)
 }
 
 // SaveRegion saves one region to KV.
 func (kv *KV) SaveRegion(region *metapb.Region) error {
-	return kv.saveProto(kv.regionPath(region.GetId()), region)
+	return saveProto(kv.regionBase(), kv.regionPath(region.GetId()), region)
 }
 
 // DeleteRegion deletes one region from KV.
 func (kv *KV) DeleteRegion(region *metapb.Region) error {
-	return kv.Delete(kv.regionPath(region.GetId()))
+	return kv.regionBase().Delete(kv.regionPath(region.GetId()))
 }
 
 // SaveConfig stores marshalable cfg to the configPath.
@@ -114,6 +158,36 @@ func (kv *KV) SaveConfig(cfg interface{}) error {
 	return kv.Save(configPath, string(value))
 }
 
+func (kv *KV) configRevisionPath(revision uint64) string {
+	return path.Join(configHistoryPath, fmt.Sprintf("%020d", revision))
+}
+
+// SaveConfigRevision stores marshalable cfg as the revision of the config.
+func (kv *KV) SaveConfigRevision(revision uint64, cfg interface{}) error {
+	return kv.saveJSON(kv.configRevisionPath(revision), cfg)
+}
+
+// LoadConfigRevision loads the revision of the config then unmarshal it to
+// cfg.
+func (kv *KV) LoadConfigRevision(revision uint64, cfg interface{}) (bool, error) {
+	value, err := kv.Load(kv.configRevisionPath(revision))
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	if value == "" {
+		return false, nil
+	}
+	if err = json.Unmarshal([]byte(value), cfg); err != nil {
+		return false, errors.Trace(err)
+	}
+	return true, nil
+}
+
+// RemoveConfigRevision removes the revision of the config.
+func (kv *KV) RemoveConfigRevision(revision uint64) error {
+	return kv.Delete(kv.configRevisionPath(revision))
+}
+
 // LoadConfig loads config from configPath then unmarshal it to cfg.
 func (kv *KV) LoadConfig(cfg interface{}) (bool, error) {
 	value, err := kv.Load(configPath)
@@ -196,6 +270,66 @@ func (kv *KV) loadFloatWithDefaultValue(path string, def float64) (float64, erro
 
 // LoadRegions loads all regions from KV to RegionsInfo.
 func (kv *KV) LoadRegions(regions *RegionsInfo) error {
+	return kv.loadRegions(kv.regionBase(), func(region *metapb.Region) error {
+		overlaps := regions.SetRegion(NewRegionInfo(region, nil))
+		for _, item := range overlaps {
+			if err := kv.DeleteRegion(item); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateRegions copies all regions from the default storage to the region
+// storage. It only copies once, later calls return 0 directly. The regions are
+// kept in the default storage as the fallback copy, which is loaded if the
+// region storage is turned off or the leader moves to a member without it.
+func (kv *KV) MigrateRegions() (int, error) {
+	if kv.regionKV == nil {
+		return 0, nil
+	}
+	value, err := kv.regionKV.Load(regionMigratedPath)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if value != "" {
+		return 0, nil
+	}
+	var count int
+	err = kv.loadRegions(kv.KVBase, func(region *metapb.Region) error {
+		count++
+		return saveProto(kv.regionKV, kv.regionPath(region.GetId()), region)
+	})
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if err = kv.regionKV.Flush(); err != nil {
+		return 0, errors.Trace(err)
+	}
+	if err = kv.regionKV.Save(regionMigratedPath, strconv.Itoa(count)); err != nil {
+		return 0, errors.Trace(err)
+	}
+	return count, errors.Trace(kv.regionKV.Flush())
+}
+
+// Flush flushes the batched regions in the region storage.
+func (kv *KV) Flush() error {
+	if kv.regionKV == nil {
+		return nil
+	}
+	return kv.regionKV.Flush()
+}
+
+// Close closes the region storage.
+func (kv *KV) Close() error {
+	if kv.regionKV == nil {
+		return nil
+	}
+	return kv.regionKV.Close()
+}
+
+func (kv *KV) loadRegions(base KVBase, f func(region *metapb.Region) error) error {
 	nextID := uint64(0)
 	endKey := kv.regionPath(math.MaxUint64)
 
@@ -206,7 +340,7 @@ func (kv *KV) LoadRegions(regions *RegionsInfo) error {
 
 	for {
 		key := kv.regionPath(nextID)
-		res, err := kv.LoadRange(key, endKey, rangeLimit)
+		res, err := base.LoadRange(key, endKey, rangeLimit)
 		if err != nil {
 			if rangeLimit /= 2; rangeLimit >= minKVRangeLimit {
 				continue
@@ -221,11 +355,8 @@ func (kv *KV) LoadRegions(regions *RegionsInfo) error {
 			}
 
 			nextID = region.GetId() + 1
-			overlaps := regions.SetRegion(NewRegionInfo(region, nil))
-			for _, item := range overlaps {
-				if err := kv.DeleteRegion(item); err != nil {
-					return errors.Trace(err)
-				}
+			if err := f(region); err != nil {
+				return errors.Trace(err)
 			}
 		}
 
@@ -262,7 +393,274 @@ func (kv *KV) LoadGCSafePoint() (uint64, error) {
 	return safePoint, nil
 }
 
+// ServiceSafePoint is the safe point of a service, the GC safe point can not
+// exceed it until it expires.
+type ServiceSafePoint struct {
+	ServiceID string `json:"service_id"`
+	// ExpiredAt is the unix timestamp in seconds when the safe point expires.
+	ExpiredAt int64  `json:"expired_at"`
+	SafePoint uint64 `json:"safe_point"`
+}
+
+func (kv *KV) serviceGCSafePointPath(serviceID string) string {
+	return path.Join(gcPath, "safe_point", "service", serviceID)
+}
+
+// SaveServiceGCSafePoint saves a service safe point to KV.
+func (kv *KV) SaveServiceGCSafePoint(ssp *ServiceSafePoint) error {
+	return kv.saveJSON(kv.serviceGCSafePointPath(ssp.ServiceID), ssp)
+}
+
+// RemoveServiceGCSafePoint removes a service safe point from KV.
+func (kv *KV) RemoveServiceGCSafePoint(serviceID string) error {
+	return kv.Delete(kv.serviceGCSafePointPath(serviceID))
+}
+
+// LoadAllServiceGCSafePoints loads all the service safe points from KV.
+func (kv *KV) LoadAllServiceGCSafePoints() ([]*ServiceSafePoint, error) {
+	var ssps []*ServiceSafePoint
+	err := kv.loadJSONRange(kv.serviceGCSafePointPath(""), func(value []byte) (string, error) {
+		ssp := &ServiceSafePoint{}
+		if err := json.Unmarshal(value, ssp); err != nil {
+			return "", errors.Trace(err)
+		}
+		ssps = append(ssps, ssp)
+		return kv.serviceGCSafePointPath(ssp.ServiceID), nil
+	})
+	return ssps, errors.Trace(err)
+}
+
+// AuthToken is a bearer token of the HTTP API, only the SHA-256 hash of the
+// token is saved.
+type AuthToken struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	Hash string `json:"hash"`
+}
+
+// AuthIdentity binds a role to the common name of the client certificates.
+type AuthIdentity struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// The names are escaped as they may contain "/".
+func (kv *KV) authTokenPath(name string) string {
+	return path.Join(authPath, "token", url.PathEscape(name))
+}
+
+func (kv *KV) authIdentityPath(name string) string {
+	return path.Join(authPath, "identity", url.PathEscape(name))
+}
+
+// SaveAuthToken saves an auth token to KV.
+func (kv *KV) SaveAuthToken(token *AuthToken) error {
+	return kv.saveJSON(kv.authTokenPath(token.Name), token)
+}
+
+// RemoveAuthToken removes an auth token from KV.
+func (kv *KV) RemoveAuthToken(name string) error {
+	return kv.Delete(kv.authTokenPath(name))
+}
+
+// LoadAuthTokens loads all the auth tokens from KV.
+func (kv *KV) LoadAuthTokens() ([]*AuthToken, error) {
+	var tokens []*AuthToken
+	err := kv.loadJSONRange(kv.authTokenPath(""), func(value []byte) (string, error) {
+		token := &AuthToken{}
+		if err := json.Unmarshal(value, token); err != nil {
+			return "", errors.Trace(err)
+		}
+		tokens = append(tokens, token)
+		return kv.authTokenPath(token.Name), nil
+	})
+	return tokens, errors.Trace(err)
+}
+
+// SaveAuthIdentity saves an auth identity to KV.
+func (kv *KV) SaveAuthIdentity(identity *AuthIdentity) error {
+	return kv.saveJSON(kv.authIdentityPath(identity.Name), identity)
+}
+
+// RemoveAuthIdentity removes an auth identity from KV.
+func (kv *KV) RemoveAuthIdentity(name string) error {
+	return kv.Delete(kv.authIdentityPath(name))
+}
+
+// LoadAuthIdentities loads all the auth identities from KV.
+func (kv *KV) LoadAuthIdentities() ([]*AuthIdentity, error) {
+	var identities []*AuthIdentity
+	err := kv.loadJSONRange(kv.authIdentityPath(""), func(value []byte) (string, error) {
+		identity := &AuthIdentity{}
+		if err := json.Unmarshal(value, identity); err != nil {
+			return "", errors.Trace(err)
+		}
+		identities = append(identities, identity)
+		return kv.authIdentityPath(identity.Name), nil
+	})
+	return identities, errors.Trace(err)
+}
+
+// AuditEntry records a mutating call of the HTTP API.
+type AuditEntry struct {
+	// ID is the unix timestamp in nanoseconds when the call is received, it
+	// is unique and increasing.
+	ID     int64  `json:"id"`
+	Caller string `json:"caller,omitempty"`
+	Role   string `json:"role,omitempty"`
+	Source string `json:"source"`
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Body is the request body, it is truncated if too long.
+	Body   string `json:"body,omitempty"`
+	Status int    `json:"status"`
+	// Error is the response body if the call fails, it is truncated if too
+	// long.
+	Error string `json:"error,omitempty"`
+}
+
+// The ids are padded so that the entries are ordered by time.
+func (kv *KV) auditEntryPath(id int64) string {
+	return path.Join(auditPath, fmt.Sprintf("%020d", id))
+}
+
+// SaveAuditEntry saves an audit entry to KV.
+func (kv *KV) SaveAuditEntry(entry *AuditEntry) error {
+	return kv.saveJSON(kv.auditEntryPath(entry.ID), entry)
+}
+
+// RemoveAuditEntry removes an audit entry from KV.
+func (kv *KV) RemoveAuditEntry(id int64) error {
+	return kv.Delete(kv.auditEntryPath(id))
+}
+
+// LoadAuditEntries loads at most limit audit entries whose ids are in
+// [start, end) in order. A non-positive limit means no limit.
+func (kv *KV) LoadAuditEntries(start, end int64, limit int) ([]*AuditEntry, error) {
+	var entries []*AuditEntry
+	nextKey, endKey := kv.auditEntryPath(start), kv.auditEntryPath(end)
+	for {
+		rangeLimit := minKVRangeLimit
+		if limit > 0 && limit-len(entries) < rangeLimit {
+			rangeLimit = limit - len(entries)
+		}
+		res, err := kv.LoadRange(nextKey, endKey, rangeLimit)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, value := range res {
+			entry := &AuditEntry{}
+			if err := json.Unmarshal([]byte(value), entry); err != nil {
+				return nil, errors.Trace(err)
+			}
+			entries = append(entries, entry)
+			nextKey = kv.auditEntryPath(entry.ID) + "\x00"
+		}
+		if len(res) < rangeLimit || len(entries) == limit {
+			return entries, nil
+		}
+	}
+}
+
+// AlertSilence mutes the alerts whose labels match all the matchers between
+// StartsAt and EndsAt.
+type AlertSilence struct {
+	ID        uint64            `json:"id"`
+	Matchers  map[string]string `json:"matchers"`
+	StartsAt  time.Time         `json:"starts_at"`
+	EndsAt    time.Time         `json:"ends_at"`
+	CreatedBy string            `json:"created_by,omitempty"`
+	Comment   string            `json:"comment,omitempty"`
+}
+
+// Matches returns true if the labels match all the matchers of the silence.
+func (s *AlertSilence) Matches(labels map[string]string) bool {
+	for k, v := range s.Matchers {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Active returns true if the silence is in effect at t.
+func (s *AlertSilence) Active(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
+
+func (kv *KV) alertSilencesPath() string {
+	return path.Join(alertPath, "silence")
+}
+
+func (kv *KV) alertSilencePath(id uint64) string {
+	return path.Join(kv.alertSilencesPath(), fmt.Sprintf("%020d", id))
+}
+
+// SaveAlertSilence saves an alert silence to KV.
+func (kv *KV) SaveAlertSilence(silence *AlertSilence) error {
+	return kv.saveJSON(kv.alertSilencePath(silence.ID), silence)
+}
+
+// RemoveAlertSilence removes an alert silence from KV.
+func (kv *KV) RemoveAlertSilence(id uint64) error {
+	return kv.Delete(kv.alertSilencePath(id))
+}
+
+// LoadAlertSilences loads all the alert silences from KV in the order of ids.
+func (kv *KV) LoadAlertSilences() ([]*AlertSilence, error) {
+	var silences []*AlertSilence
+	err := kv.loadJSONRange(kv.alertSilencesPath(), func(value []byte) (string, error) {
+		silence := &AlertSilence{}
+		if err := json.Unmarshal(value, silence); err != nil {
+			return "", errors.Trace(err)
+		}
+		silences = append(silences, silence)
+		return kv.alertSilencePath(silence.ID), nil
+	})
+	return silences, errors.Trace(err)
+}
+
+func (kv *KV) saveJSON(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return kv.Save(key, string(value))
+}
+
+// loadJSONRange loads the values under the prefix in order, f decodes a value
+// and returns its key.
+func (kv *KV) loadJSONRange(prefix string, f func(value []byte) (string, error)) error {
+	// The prefix does not end with "/" after joined, so the range starts from
+	// the prefix itself, and "0" is the next character of "/".
+	nextKey, endKey := prefix+"/", prefix+"0"
+	for {
+		res, err := kv.LoadRange(nextKey, endKey, minKVRangeLimit)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		for _, value := range res {
+			key, err := f([]byte(value))
+			if err != nil {
+				return errors.Trace(err)
+			}
+			nextKey = key + "\x00"
+		}
+		if len(res) < minKVRangeLimit {
+			return nil
+		}
+	}
+}
+
 func (kv *KV) loadProto(key string, msg proto.Message) (bool, error) {
+	return loadProto(kv.KVBase, key, msg)
+}
+
+func (kv *KV) saveProto(key string, msg proto.Message) error {
+	return saveProto(kv.KVBase, key, msg)
+}
+
+func loadProto(kv KVBase, key string, msg proto.Message) (bool, error) {
 	value, err := kv.Load(key)
 	if err != nil {
 		return false, errors.Trace(err)
@@ -273,7 +671,7 @@ func (kv *KV) loadProto(key string, msg proto.Message) (bool, error) {
 	return true, proto.Unmarshal([]byte(value), msg)
 }
 
-func (kv *KV) saveProto(key string, msg proto.Message) error {
+func saveProto(kv KVBase, key string, msg proto.Message) error {
 	value, err := proto.Marshal(msg)
 	if err != nil {
 		return errors.Trace(err)
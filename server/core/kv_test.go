@@ -16,6 +16,7 @@ package core
 import (
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
@@ -174,6 +175,128 @@ func (s *testKVSuite) TestLoadGCSafePoint(c *C) {
 	}
 }
 
+func (s *testKVSuite) TestServiceGCSafePoints(c *C) {
+	kv := NewKV(NewMemoryKV())
+	// A key which is next to the service safe points.
+	c.Assert(kv.Save(kv.serviceGCSafePointPath("")+"0", "x"), IsNil)
+
+	ssps, err := kv.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 0)
+
+	// More than a page of the range loading.
+	n := 2*minKVRangeLimit + 3
+	for i := 0; i < n; i++ {
+		c.Assert(kv.SaveServiceGCSafePoint(&ServiceSafePoint{
+			ServiceID: fmt.Sprintf("s%d", i),
+			ExpiredAt: int64(i),
+			SafePoint: uint64(i),
+		}), IsNil)
+	}
+	ssps, err = kv.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, n)
+
+	c.Assert(kv.RemoveServiceGCSafePoint("s0"), IsNil)
+	ssps, err = kv.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, n-1)
+	for _, ssp := range ssps {
+		c.Assert(ssp.ServiceID, Not(Equals), "s0")
+		c.Assert(ssp.ServiceID, Equals, fmt.Sprintf("s%d", ssp.SafePoint))
+	}
+}
+
+func (s *testKVSuite) TestAuth(c *C) {
+	kv := NewKV(NewMemoryKV())
+	tokens, err := kv.LoadAuthTokens()
+	c.Assert(err, IsNil)
+	c.Assert(tokens, HasLen, 0)
+
+	c.Assert(kv.SaveAuthToken(&AuthToken{Name: "ctl", Role: "admin", Hash: "h1"}), IsNil)
+	c.Assert(kv.SaveAuthToken(&AuthToken{Name: "a/b", Role: "read-only", Hash: "h2"}), IsNil)
+	c.Assert(kv.SaveAuthIdentity(&AuthIdentity{Name: "CN with spaces", Role: "operator"}), IsNil)
+	tokens, err = kv.LoadAuthTokens()
+	c.Assert(err, IsNil)
+	c.Assert(tokens, DeepEquals, []*AuthToken{
+		{Name: "a/b", Role: "read-only", Hash: "h2"},
+		{Name: "ctl", Role: "admin", Hash: "h1"},
+	})
+	identities, err := kv.LoadAuthIdentities()
+	c.Assert(err, IsNil)
+	c.Assert(identities, DeepEquals, []*AuthIdentity{{Name: "CN with spaces", Role: "operator"}})
+
+	c.Assert(kv.RemoveAuthToken("a/b"), IsNil)
+	c.Assert(kv.RemoveAuthIdentity("CN with spaces"), IsNil)
+	tokens, err = kv.LoadAuthTokens()
+	c.Assert(err, IsNil)
+	c.Assert(tokens, HasLen, 1)
+	identities, err = kv.LoadAuthIdentities()
+	c.Assert(err, IsNil)
+	c.Assert(identities, HasLen, 0)
+}
+
+func (s *testKVSuite) TestAuditEntries(c *C) {
+	kv := NewKV(NewMemoryKV())
+	n := minKVRangeLimit + 10
+	for i := 1; i <= n; i++ {
+		c.Assert(kv.SaveAuditEntry(&AuditEntry{ID: int64(i), Method: "POST"}), IsNil)
+	}
+	entries, err := kv.LoadAuditEntries(0, math.MaxInt64, 0)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, n)
+	for i, e := range entries {
+		c.Assert(e.ID, Equals, int64(i+1))
+	}
+
+	entries, err = kv.LoadAuditEntries(5, 10, 0)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 5)
+	c.Assert(entries[0].ID, Equals, int64(5))
+	entries, err = kv.LoadAuditEntries(5, math.MaxInt64, minKVRangeLimit+1)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, minKVRangeLimit+1)
+
+	c.Assert(kv.RemoveAuditEntry(5), IsNil)
+	entries, err = kv.LoadAuditEntries(5, 6, 0)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 0)
+}
+
+func (s *testKVSuite) TestAlertSilences(c *C) {
+	kv := NewKV(NewMemoryKV())
+	now := time.Now()
+	n := minKVRangeLimit + 10
+	for i := n; i >= 1; i-- {
+		silence := &AlertSilence{
+			ID:       uint64(i),
+			Matchers: map[string]string{"alertname": "tikv_capacity_90"},
+			StartsAt: now,
+			EndsAt:   now.Add(time.Hour),
+		}
+		c.Assert(kv.SaveAlertSilence(silence), IsNil)
+	}
+	silences, err := kv.LoadAlertSilences()
+	c.Assert(err, IsNil)
+	c.Assert(silences, HasLen, n)
+	for i, silence := range silences {
+		c.Assert(silence.ID, Equals, uint64(i+1))
+	}
+
+	silence := silences[0]
+	c.Assert(silence.Matches(map[string]string{"alertname": "tikv_capacity_90", "severity": "critical"}), IsTrue)
+	c.Assert(silence.Matches(map[string]string{"alertname": "tikv_capacity_80"}), IsFalse)
+	c.Assert(silence.Active(now), IsTrue)
+	c.Assert(silence.Active(now.Add(time.Hour)), IsFalse)
+	c.Assert(silence.Active(now.Add(-time.Second)), IsFalse)
+
+	c.Assert(kv.RemoveAlertSilence(1), IsNil)
+	silences, err = kv.LoadAlertSilences()
+	c.Assert(err, IsNil)
+	c.Assert(silences, HasLen, n-1)
+	c.Assert(silences[0].ID, Equals, uint64(2))
+}
+
 type KVWithMaxRangeLimit struct {
 	KVBase
 	rangeLimit int
@@ -193,3 +316,20 @@ func newTestRegionMeta(regionID uint64) *metapb.Region {
 		EndKey:   []byte(fmt.Sprintf("%20d", regionID+1)),
 	}
 }
+
+func (s *testKVSuite) TestConfigRevision(c *C) {
+	kv := NewKV(NewMemoryKV())
+	cfg := map[string]int{"max-replicas": 3}
+	c.Assert(kv.SaveConfigRevision(1, cfg), IsNil)
+
+	loaded := make(map[string]int)
+	ok, err := kv.LoadConfigRevision(1, &loaded)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	c.Assert(loaded, DeepEquals, cfg)
+
+	c.Assert(kv.RemoveConfigRevision(1), IsNil)
+	ok, err = kv.LoadConfigRevision(1, &loaded)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+}
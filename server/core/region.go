@@ -19,6 +19,8 @@ import (
 	"math/rand"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
@@ -36,6 +38,37 @@ func HealthRegion() RegionOption {
 	}
 }
 
+// LeaderInStore checks if the region has leader in the store.
+func LeaderInStore(storeID uint64) RegionOption {
+	return func(region *RegionInfo) bool {
+		return region.Leader != nil && region.Leader.GetStoreId() == storeID
+	}
+}
+
+// FollowerInStore checks if the region has a follower in the store.
+func FollowerInStore(storeID uint64) RegionOption {
+	return func(region *RegionInfo) bool {
+		return region.Leader != nil && region.Leader.GetStoreId() != storeID && region.GetStoreVoter(storeID) != nil
+	}
+}
+
+// PeerInStore checks if the region has a peer in the store.
+func PeerInStore(storeID uint64) RegionOption {
+	return func(region *RegionInfo) bool {
+		return region.GetStorePeer(storeID) != nil
+	}
+}
+
+// matchOptions checks if the region matches all the options.
+func matchOptions(region *RegionInfo, opts []RegionOption) bool {
+	for _, opt := range opts {
+		if !opt(region) {
+			return false
+		}
+	}
+	return true
+}
+
 // RegionInfo records detail region info.
 type RegionInfo struct {
 	*metapb.Region
@@ -345,7 +378,9 @@ type HotRegionsStat struct {
 }
 
 // regionMap wraps a map[uint64]*core.RegionInfo and supports randomly pick a region.
+// It is safe for concurrent use.
 type regionMap struct {
+	sync.RWMutex
 	m         map[uint64]*regionEntry
 	ids       []uint64
 	totalSize int64
@@ -355,6 +390,10 @@ type regionMap struct {
 type regionEntry struct {
 	*RegionInfo
 	pos int
+	// lastHeartbeat is the unix nano time of the latest heartbeat of the
+	// region, or the time when the region is added if it has not reported
+	// heartbeat yet. It is accessed atomically.
+	lastHeartbeat int64
 }
 
 func newRegionMap() *regionMap {
@@ -368,6 +407,8 @@ func (rm *regionMap) Len() int {
 	if rm == nil {
 		return 0
 	}
+	rm.RLock()
+	defer rm.RUnlock()
 	return len(rm.m)
 }
 
@@ -375,6 +416,8 @@ func (rm *regionMap) Get(id uint64) *RegionInfo {
 	if rm == nil {
 		return nil
 	}
+	rm.RLock()
+	defer rm.RUnlock()
 	if entry, ok := rm.m[id]; ok {
 		return entry.RegionInfo
 	}
@@ -382,6 +425,8 @@ func (rm *regionMap) Get(id uint64) *RegionInfo {
 }
 
 func (rm *regionMap) Put(region *RegionInfo) {
+	rm.Lock()
+	defer rm.Unlock()
 	if old, ok := rm.m[region.GetId()]; ok {
 		rm.totalSize += region.ApproximateSize - old.ApproximateSize
 		rm.totalKeys += region.ApproximateKeys - old.ApproximateKeys
@@ -389,8 +434,9 @@ func (rm *regionMap) Put(region *RegionInfo) {
 		return
 	}
 	rm.m[region.GetId()] = &regionEntry{
-		RegionInfo: region,
-		pos:        len(rm.ids),
+		RegionInfo:    region,
+		pos:           len(rm.ids),
+		lastHeartbeat: time.Now().UnixNano(),
 	}
 	rm.ids = append(rm.ids, region.GetId())
 	rm.totalSize += region.ApproximateSize
@@ -398,18 +444,25 @@ func (rm *regionMap) Put(region *RegionInfo) {
 }
 
 func (rm *regionMap) RandomRegion() *RegionInfo {
-	if rm.Len() == 0 {
+	if rm == nil {
+		return nil
+	}
+	rm.RLock()
+	defer rm.RUnlock()
+	if len(rm.ids) == 0 {
 		return nil
 	}
-	return rm.Get(rm.ids[rand.Intn(rm.Len())])
+	return rm.m[rm.ids[rand.Intn(len(rm.ids))]].RegionInfo
 }
 
 func (rm *regionMap) Delete(id uint64) {
 	if rm == nil {
 		return
 	}
+	rm.Lock()
+	defer rm.Unlock()
 	if old, ok := rm.m[id]; ok {
-		len := rm.Len()
+		len := len(rm.ids)
 		last := rm.m[rm.ids[len-1]]
 		last.pos = old.pos
 		rm.ids[last.pos] = last.GetId()
@@ -421,16 +474,142 @@ func (rm *regionMap) Delete(id uint64) {
 }
 
 func (rm *regionMap) TotalSize() int64 {
-	if rm.Len() == 0 {
+	if rm == nil {
 		return 0
 	}
+	rm.RLock()
+	defer rm.RUnlock()
 	return rm.totalSize
 }
 
-// RegionsInfo for export
+// scan calls f for every region in the map, f must not modify the map.
+func (rm *regionMap) scan(f func(*RegionInfo)) {
+	rm.RLock()
+	defer rm.RUnlock()
+	for _, entry := range rm.m {
+		f(entry.RegionInfo)
+	}
+}
+
+func (rm *regionMap) setLastHeartbeat(id uint64, t time.Time) {
+	rm.RLock()
+	defer rm.RUnlock()
+	if entry, ok := rm.m[id]; ok {
+		atomic.StoreInt64(&entry.lastHeartbeat, t.UnixNano())
+	}
+}
+
+func (rm *regionMap) getLastHeartbeat(id uint64) (time.Time, bool) {
+	rm.RLock()
+	defer rm.RUnlock()
+	if entry, ok := rm.m[id]; ok {
+		return time.Unix(0, atomic.LoadInt64(&entry.lastHeartbeat)), true
+	}
+	return time.Time{}, false
+}
+
+// scanHeartbeats calls f for every region in the map with its latest
+// heartbeat time, f must not modify the map.
+func (rm *regionMap) scanHeartbeats(f func(*RegionInfo, time.Time)) {
+	rm.RLock()
+	defer rm.RUnlock()
+	for _, entry := range rm.m {
+		f(entry.RegionInfo, time.Unix(0, atomic.LoadInt64(&entry.lastHeartbeat)))
+	}
+}
+
+// regionShardCount is the number of shards of the regions. Regions are
+// distributed by ID so that the updates of unrelated regions don't contend.
+const regionShardCount = 64
+
+// shardedRegionMap is a regionMap split into shards.
+type shardedRegionMap struct {
+	shards [regionShardCount]*regionMap
+}
+
+func newShardedRegionMap() *shardedRegionMap {
+	sm := &shardedRegionMap{}
+	for i := range sm.shards {
+		sm.shards[i] = newRegionMap()
+	}
+	return sm
+}
+
+func (sm *shardedRegionMap) shard(id uint64) *regionMap {
+	return sm.shards[id%regionShardCount]
+}
+
+func (sm *shardedRegionMap) Len() int {
+	var n int
+	for _, shard := range sm.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+func (sm *shardedRegionMap) Get(id uint64) *RegionInfo {
+	return sm.shard(id).Get(id)
+}
+
+func (sm *shardedRegionMap) Put(region *RegionInfo) {
+	sm.shard(region.GetId()).Put(region)
+}
+
+func (sm *shardedRegionMap) Delete(id uint64) {
+	sm.shard(id).Delete(id)
+}
+
+// RandomRegion picks a shard with the probability of its size, then picks a
+// region from the shard randomly.
+func (sm *shardedRegionMap) RandomRegion() *RegionInfo {
+	n := sm.Len()
+	if n == 0 {
+		return nil
+	}
+	i := rand.Intn(n)
+	for _, shard := range sm.shards {
+		l := shard.Len()
+		if i < l {
+			return shard.RandomRegion()
+		}
+		i -= l
+	}
+	return nil
+}
+
+func (sm *shardedRegionMap) TotalSize() int64 {
+	var total int64
+	for _, shard := range sm.shards {
+		total += shard.TotalSize()
+	}
+	return total
+}
+
+func (sm *shardedRegionMap) scan(f func(*RegionInfo)) {
+	for _, shard := range sm.shards {
+		shard.scan(f)
+	}
+}
+
+func (sm *shardedRegionMap) scanHeartbeats(f func(*RegionInfo, time.Time)) {
+	for _, shard := range sm.shards {
+		shard.scanHeartbeats(f)
+	}
+}
+
+// RegionsInfo for export. It is safe for concurrent use: the regions are
+// sharded by ID, every store has its own region maps and the region tree is
+// only locked exclusively when the key range of a region changes. So the
+// updates of unrelated regions can be processed in parallel.
 type RegionsInfo struct {
-	tree         *regionTree
-	regions      *regionMap            // regionID -> regionInfo
+	treeMu sync.RWMutex
+	tree   *regionTree
+
+	regions *shardedRegionMap // regionID -> regionInfo
+	// updateMu serializes the updates of the same region.
+	updateMu [regionShardCount]sync.Mutex
+
+	storeMu      sync.RWMutex          // protects the maps below, not the regionMaps in them
 	leaders      map[uint64]*regionMap // storeID -> regionID -> regionInfo
 	followers    map[uint64]*regionMap // storeID -> regionID -> regionInfo
 	learners     map[uint64]*regionMap // storeID -> regionID -> regionInfo
@@ -441,7 +620,7 @@ type RegionsInfo struct {
 func NewRegionsInfo() *RegionsInfo {
 	return &RegionsInfo{
 		tree:         newRegionTree(),
-		regions:      newRegionMap(),
+		regions:      newShardedRegionMap(),
 		leaders:      make(map[uint64]*regionMap),
 		followers:    make(map[uint64]*regionMap),
 		learners:     make(map[uint64]*regionMap),
@@ -449,6 +628,32 @@ func NewRegionsInfo() *RegionsInfo {
 	}
 }
 
+func (r *RegionsInfo) lockRegion(regionID uint64) func() {
+	mu := &r.updateMu[regionID%regionShardCount]
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (r *RegionsInfo) getStoreMap(m map[uint64]*regionMap, storeID uint64) *regionMap {
+	r.storeMu.RLock()
+	defer r.storeMu.RUnlock()
+	return m[storeID]
+}
+
+func (r *RegionsInfo) getOrCreateStoreMap(m map[uint64]*regionMap, storeID uint64) *regionMap {
+	if store := r.getStoreMap(m, storeID); store != nil {
+		return store
+	}
+	r.storeMu.Lock()
+	defer r.storeMu.Unlock()
+	store, ok := m[storeID]
+	if !ok {
+		store = newRegionMap()
+		m[storeID] = store
+	}
+	return store
+}
+
 // GetRegion return the RegionInfo with regionID
 func (r *RegionsInfo) GetRegion(regionID uint64) *RegionInfo {
 	region := r.regions.Get(regionID)
@@ -460,10 +665,68 @@ func (r *RegionsInfo) GetRegion(regionID uint64) *RegionInfo {
 
 // SetRegion set the RegionInfo with regionID
 func (r *RegionsInfo) SetRegion(region *RegionInfo) []*metapb.Region {
+	if updated, refresh := r.updateRegion(region); updated {
+		if refresh {
+			r.refreshTree(region.GetId())
+		}
+		return nil
+	}
+	r.treeMu.Lock()
+	defer r.treeMu.Unlock()
 	if origin := r.regions.Get(region.GetId()); origin != nil {
-		r.RemoveRegion(origin)
+		r.tree.remove(origin.Region)
+	}
+	return r.addRegionLocked(region)
+}
+
+// updateRegion replaces the region without changing the structure of the
+// region tree if the key range of the region is not changed. It returns
+// false if the region needs to be updated in the tree, and whether the
+// statistics in the tree need to be refreshed.
+func (r *RegionsInfo) updateRegion(region *RegionInfo) (updated bool, refresh bool) {
+	defer r.lockRegion(region.GetId())()
+	origin := r.regions.Get(region.GetId())
+	if origin == nil ||
+		!bytes.Equal(origin.GetStartKey(), region.GetStartKey()) ||
+		!bytes.Equal(origin.GetEndKey(), region.GetEndKey()) {
+		return false, false
+	}
+	r.removeFromStores(origin)
+	r.regions.Put(region)
+	r.addToStores(region)
+	return true, !sameRangeStats(origin, region)
+}
+
+// refreshTree refreshes the statistics in the region tree with the latest
+// version of the region. The region lock is not held here, so concurrent
+// updates of the same region always converge to the latest version.
+func (r *RegionsInfo) refreshTree(regionID uint64) {
+	r.treeMu.Lock()
+	defer r.treeMu.Unlock()
+	if region := r.regions.Get(regionID); region != nil {
+		r.tree.refresh(region)
+	}
+}
+
+// sameRangeStats returns true if the regions contribute the same to the
+// statistics of the region tree.
+func sameRangeStats(a, b *RegionInfo) bool {
+	if a.ApproximateSize != b.ApproximateSize || a.ApproximateKeys != b.ApproximateKeys ||
+		a.Leader.GetId() != b.Leader.GetId() ||
+		len(a.GetPeers()) != len(b.GetPeers()) || len(a.PendingPeers) != len(b.PendingPeers) {
+		return false
 	}
-	return r.AddRegion(region)
+	for i, p := range a.GetPeers() {
+		if !proto.Equal(p, b.GetPeers()[i]) {
+			return false
+		}
+	}
+	for i, p := range a.PendingPeers {
+		if p.GetStoreId() != b.PendingPeers[i].GetStoreId() {
+			return false
+		}
+	}
+	return true
 }
 
 // Length return the RegionsInfo length
@@ -473,21 +736,47 @@ func (r *RegionsInfo) Length() int {
 
 // TreeLength return the RegionsInfo tree length(now only used in test)
 func (r *RegionsInfo) TreeLength() int {
+	r.treeMu.RLock()
+	defer r.treeMu.RUnlock()
 	return r.tree.length()
 }
 
 // AddRegion add RegionInfo to regionTree and regionMap, also update leadres and followers by region peers
 func (r *RegionsInfo) AddRegion(region *RegionInfo) []*metapb.Region {
+	r.treeMu.Lock()
+	defer r.treeMu.Unlock()
+	return r.addRegionLocked(region)
+}
+
+// addRegionLocked adds the region, the caller must hold the tree lock.
+func (r *RegionsInfo) addRegionLocked(region *RegionInfo) []*metapb.Region {
 	// Add to tree and regions.
-	overlaps := r.tree.update(region.Region)
+	overlaps := r.tree.update(region)
 	for _, item := range overlaps {
-		r.RemoveRegion(r.GetRegion(item.Id))
+		r.removeRegionByID(item.GetId())
 	}
 
+	defer r.lockRegion(region.GetId())()
+	if origin := r.regions.Get(region.GetId()); origin != nil {
+		r.removeFromStores(origin)
+	}
 	r.regions.Put(region)
+	r.addToStores(region)
+	return overlaps
+}
 
+func (r *RegionsInfo) removeRegionByID(regionID uint64) {
+	defer r.lockRegion(regionID)()
+	if region := r.regions.Get(regionID); region != nil {
+		r.regions.Delete(regionID)
+		r.removeFromStores(region)
+	}
+}
+
+// addToStores adds the region to the maps of its stores.
+func (r *RegionsInfo) addToStores(region *RegionInfo) {
 	if region.Leader == nil {
-		return overlaps
+		return
 	}
 
 	// Add to leaders and followers.
@@ -495,66 +784,55 @@ func (r *RegionsInfo) AddRegion(region *RegionInfo) []*metapb.Region {
 		storeID := peer.GetStoreId()
 		if peer.GetId() == region.Leader.GetId() {
 			// Add leader peer to leaders.
-			store, ok := r.leaders[storeID]
-			if !ok {
-				store = newRegionMap()
-				r.leaders[storeID] = store
-			}
-			store.Put(region)
+			r.getOrCreateStoreMap(r.leaders, storeID).Put(region)
 		} else {
 			// Add follower peer to followers.
-			store, ok := r.followers[storeID]
-			if !ok {
-				store = newRegionMap()
-				r.followers[storeID] = store
-			}
-			store.Put(region)
+			r.getOrCreateStoreMap(r.followers, storeID).Put(region)
 		}
 	}
 
 	// Add to learners.
 	for _, peer := range region.GetLearners() {
-		storeID := peer.GetStoreId()
-		store, ok := r.learners[storeID]
-		if !ok {
-			store = newRegionMap()
-			r.learners[storeID] = store
-		}
-		store.Put(region)
+		r.getOrCreateStoreMap(r.learners, peer.GetStoreId()).Put(region)
 	}
 
 	for _, peer := range region.PendingPeers {
-		storeID := peer.GetStoreId()
-		store, ok := r.pendingPeers[storeID]
-		if !ok {
-			store = newRegionMap()
-			r.pendingPeers[storeID] = store
-		}
-		store.Put(region)
+		r.getOrCreateStoreMap(r.pendingPeers, peer.GetStoreId()).Put(region)
 	}
+}
 
-	return overlaps
+// removeFromStores removes the region from the maps of its stores.
+func (r *RegionsInfo) removeFromStores(region *RegionInfo) {
+	for _, peer := range region.GetPeers() {
+		storeID := peer.GetStoreId()
+		r.getStoreMap(r.leaders, storeID).Delete(region.GetId())
+		r.getStoreMap(r.followers, storeID).Delete(region.GetId())
+		r.getStoreMap(r.learners, storeID).Delete(region.GetId())
+		r.getStoreMap(r.pendingPeers, storeID).Delete(region.GetId())
+	}
 }
 
 // RemoveRegion remove RegionInfo from regionTree and regionMap
 func (r *RegionsInfo) RemoveRegion(region *RegionInfo) {
 	// Remove from tree and regions.
+	r.treeMu.Lock()
 	r.tree.remove(region.Region)
-	r.regions.Delete(region.GetId())
+	r.treeMu.Unlock()
 
-	// Remove from leaders and followers.
-	for _, peer := range region.GetPeers() {
-		storeID := peer.GetStoreId()
-		r.leaders[storeID].Delete(region.GetId())
-		r.followers[storeID].Delete(region.GetId())
-		r.learners[storeID].Delete(region.GetId())
-		r.pendingPeers[storeID].Delete(region.GetId())
+	defer r.lockRegion(region.GetId())()
+	if origin := r.regions.Get(region.GetId()); origin != nil {
+		r.removeFromStores(origin)
 	}
+	r.regions.Delete(region.GetId())
+	// Remove from leaders and followers.
+	r.removeFromStores(region)
 }
 
 // SearchRegion search RegionInfo from regionTree
 func (r *RegionsInfo) SearchRegion(regionKey []byte) *RegionInfo {
+	r.treeMu.RLock()
 	region := r.tree.search(regionKey)
+	r.treeMu.RUnlock()
 	if region == nil {
 		return nil
 	}
@@ -564,25 +842,25 @@ func (r *RegionsInfo) SearchRegion(regionKey []byte) *RegionInfo {
 // GetRegions gets all RegionInfo from regionMap
 func (r *RegionsInfo) GetRegions() []*RegionInfo {
 	regions := make([]*RegionInfo, 0, r.regions.Len())
-	for _, region := range r.regions.m {
+	r.regions.scan(func(region *RegionInfo) {
 		regions = append(regions, region.Clone())
-	}
+	})
 	return regions
 }
 
 // GetStoreLeaderRegionSize get total size of store's leader regions
 func (r *RegionsInfo) GetStoreLeaderRegionSize(storeID uint64) int64 {
-	return r.leaders[storeID].TotalSize()
+	return r.getStoreMap(r.leaders, storeID).TotalSize()
 }
 
 // GetStoreFollowerRegionSize get total size of store's follower regions
 func (r *RegionsInfo) GetStoreFollowerRegionSize(storeID uint64) int64 {
-	return r.followers[storeID].TotalSize()
+	return r.getStoreMap(r.followers, storeID).TotalSize()
 }
 
 // GetStoreLearnerRegionSize get total size of store's learner regions
 func (r *RegionsInfo) GetStoreLearnerRegionSize(storeID uint64) int64 {
-	return r.learners[storeID].TotalSize()
+	return r.getStoreMap(r.learners, storeID).TotalSize()
 }
 
 // GetStoreRegionSize get total size of store's regions
@@ -593,9 +871,9 @@ func (r *RegionsInfo) GetStoreRegionSize(storeID uint64) int64 {
 // GetMetaRegions gets a set of metapb.Region from regionMap
 func (r *RegionsInfo) GetMetaRegions() []*metapb.Region {
 	regions := make([]*metapb.Region, 0, r.regions.Len())
-	for _, region := range r.regions.m {
+	r.regions.scan(func(region *RegionInfo) {
 		regions = append(regions, proto.Clone(region.Region).(*metapb.Region))
-	}
+	})
 	return regions
 }
 
@@ -611,22 +889,22 @@ func (r *RegionsInfo) GetStoreRegionCount(storeID uint64) int {
 
 // GetStorePendingPeerCount gets the total count of a store's region that includes pending peer
 func (r *RegionsInfo) GetStorePendingPeerCount(storeID uint64) int {
-	return r.pendingPeers[storeID].Len()
+	return r.getStoreMap(r.pendingPeers, storeID).Len()
 }
 
 // GetStoreLeaderCount get the total count of a store's leader RegionInfo
 func (r *RegionsInfo) GetStoreLeaderCount(storeID uint64) int {
-	return r.leaders[storeID].Len()
+	return r.getStoreMap(r.leaders, storeID).Len()
 }
 
 // GetStoreFollowerCount get the total count of a store's follower RegionInfo
 func (r *RegionsInfo) GetStoreFollowerCount(storeID uint64) int {
-	return r.followers[storeID].Len()
+	return r.getStoreMap(r.followers, storeID).Len()
 }
 
 // GetStoreLearnerCount get the total count of a store's learner RegionInfo
 func (r *RegionsInfo) GetStoreLearnerCount(storeID uint64) int {
-	return r.learners[storeID].Len()
+	return r.getStoreMap(r.learners, storeID).Len()
 }
 
 // RandRegion get a region by random
@@ -636,43 +914,155 @@ func (r *RegionsInfo) RandRegion(opts ...RegionOption) *RegionInfo {
 
 // RandLeaderRegion get a store's leader region by random
 func (r *RegionsInfo) RandLeaderRegion(storeID uint64, opts ...RegionOption) *RegionInfo {
-	return randRegion(r.leaders[storeID], opts...)
+	return randRegion(r.getStoreMap(r.leaders, storeID), opts...)
 }
 
 // RandFollowerRegion get a store's follower region by random
 func (r *RegionsInfo) RandFollowerRegion(storeID uint64, opts ...RegionOption) *RegionInfo {
-	return randRegion(r.followers[storeID], opts...)
+	return randRegion(r.getStoreMap(r.followers, storeID), opts...)
 }
 
 // GetLeader return leader RegionInfo by storeID and regionID(now only used in test)
 func (r *RegionsInfo) GetLeader(storeID uint64, regionID uint64) *RegionInfo {
-	return r.leaders[storeID].Get(regionID)
+	return r.getStoreMap(r.leaders, storeID).Get(regionID)
 }
 
 // GetFollower return follower RegionInfo by storeID and regionID(now only used in test)
 func (r *RegionsInfo) GetFollower(storeID uint64, regionID uint64) *RegionInfo {
-	return r.followers[storeID].Get(regionID)
+	return r.getStoreMap(r.followers, storeID).Get(regionID)
+}
+
+// UpdateRegionHeartbeat records the time of the latest heartbeat of the region.
+func (r *RegionsInfo) UpdateRegionHeartbeat(regionID uint64, t time.Time) {
+	r.regions.shard(regionID).setLastHeartbeat(regionID, t)
+}
+
+// GetRegionLastHeartbeat returns the time of the latest heartbeat of the
+// region, or the time when the region is added if it has not reported
+// heartbeat yet. It returns false if the region does not exist.
+func (r *RegionsInfo) GetRegionLastHeartbeat(regionID uint64) (time.Time, bool) {
+	return r.regions.shard(regionID).getLastHeartbeat(regionID)
+}
+
+// GetNoHeartbeatRegions returns the regions which have not reported
+// heartbeat since the given time.
+func (r *RegionsInfo) GetNoHeartbeatRegions(since time.Time) []*RegionInfo {
+	var res []*RegionInfo
+	r.regions.scanHeartbeats(func(region *RegionInfo, lastHeartbeat time.Time) {
+		if lastHeartbeat.Before(since) {
+			res = append(res, region.Clone())
+		}
+	})
+	return res
+}
+
+// KeyRange is a range of keys, an empty EndKey means the end of the key space.
+type KeyRange struct {
+	StartKey []byte
+	EndKey   []byte
+}
+
+// CheckKeyRange verifies that the regions cover the whole key space without
+// overlapping. It returns the key ranges not covered by any region, and the
+// pairs of adjacent regions which overlap with each other.
+func (r *RegionsInfo) CheckKeyRange() (holes []KeyRange, overlaps [][2]*RegionInfo) {
+	r.treeMu.RLock()
+	defer r.treeMu.RUnlock()
+	var prev *regionItem
+	r.tree.root.ascendGreaterOrEqual(nil, func(item *regionItem) bool {
+		if prev == nil {
+			if len(item.startKey) > 0 {
+				holes = append(holes, KeyRange{EndKey: item.startKey})
+			}
+		} else if cmp := bytes.Compare(prev.endKey, item.startKey); len(prev.endKey) == 0 || cmp > 0 {
+			overlaps = append(overlaps, [2]*RegionInfo{r.getRegionOrClone(prev.region), r.getRegionOrClone(item.region)})
+		} else if cmp < 0 {
+			holes = append(holes, KeyRange{StartKey: prev.endKey, EndKey: item.startKey})
+		}
+		// prev is the region which reaches the farthest so far.
+		if prev == nil || endKeyLess(prev.endKey, item.endKey) {
+			prev = item
+		}
+		return true
+	})
+	if prev == nil {
+		holes = append(holes, KeyRange{})
+	} else if len(prev.endKey) > 0 {
+		holes = append(holes, KeyRange{StartKey: prev.endKey})
+	}
+	return holes, overlaps
+}
+
+// endKeyLess returns true if the end key a is less than b, an empty end key
+// is greater than any other key.
+func endKeyLess(a, b []byte) bool {
+	if len(a) == 0 {
+		return false
+	}
+	return len(b) == 0 || bytes.Compare(a, b) < 0
+}
+
+// getRegionOrClone returns the latest version of the region in the tree.
+func (r *RegionsInfo) getRegionOrClone(region *RegionInfo) *RegionInfo {
+	if info := r.GetRegion(region.GetId()); info != nil {
+		return info
+	}
+	return region.Clone()
 }
 
 // ScanRange scans region with start key, until number greater than limit.
 func (r *RegionsInfo) ScanRange(startKey []byte, limit int) []*RegionInfo {
+	r.treeMu.RLock()
+	defer r.treeMu.RUnlock()
 	res := make([]*RegionInfo, 0, limit)
-	r.tree.scanRange(startKey, func(region *metapb.Region) bool {
-		res = append(res, r.GetRegion(region.GetId()))
+	r.tree.scanRange(startKey, func(region *RegionInfo) bool {
+		if info := r.GetRegion(region.GetId()); info != nil {
+			res = append(res, info)
+		}
 		return len(res) < limit
 	})
 	return res
 }
 
+// ScanRegions scans the regions which overlap with [startKey, endKey) and
+// match all the options, an empty endKey means scanning to the end and a
+// non-positive limit means no limit.
+func (r *RegionsInfo) ScanRegions(startKey, endKey []byte, limit int, opts ...RegionOption) []*RegionInfo {
+	r.treeMu.RLock()
+	defer r.treeMu.RUnlock()
+	var res []*RegionInfo
+	r.tree.scanRegions(startKey, endKey, func(region *RegionInfo) bool {
+		if info := r.GetRegion(region.GetId()); info != nil && matchOptions(info, opts) {
+			res = append(res, info)
+		}
+		return limit <= 0 || len(res) < limit
+	})
+	return res
+}
+
+// SearchPrevRegion searches the region before the region which contains the
+// key.
+func (r *RegionsInfo) SearchPrevRegion(regionKey []byte) *RegionInfo {
+	r.treeMu.RLock()
+	region := r.tree.searchPrev(regionKey)
+	r.treeMu.RUnlock()
+	if region == nil {
+		return nil
+	}
+	return r.GetRegion(region.GetId())
+}
+
 // GetAdjacentRegions returns region's info that is adjacent with specific region
 func (r *RegionsInfo) GetAdjacentRegions(region *RegionInfo) (*RegionInfo, *RegionInfo) {
+	r.treeMu.RLock()
 	metaPrev, metaNext := r.tree.getAdjacentRegions(region.Region)
+	r.treeMu.RUnlock()
 	var prev, next *RegionInfo
 	// check key to avoid key range hole
-	if metaPrev != nil && bytes.Equal(metaPrev.region.EndKey, region.Region.StartKey) {
+	if metaPrev != nil && bytes.Equal(metaPrev.endKey, region.GetStartKey()) {
 		prev = r.GetRegion(metaPrev.region.GetId())
 	}
-	if metaNext != nil && bytes.Equal(region.Region.EndKey, metaNext.region.StartKey) {
+	if metaNext != nil && bytes.Equal(region.GetEndKey(), metaNext.startKey) {
 		next = r.GetRegion(metaNext.region.GetId())
 	}
 	return prev, next
@@ -680,10 +1070,11 @@ func (r *RegionsInfo) GetAdjacentRegions(region *RegionInfo) (*RegionInfo, *Regi
 
 // GetAverageRegionSize returns the average region approximate size.
 func (r *RegionsInfo) GetAverageRegionSize() int64 {
-	if r.regions.Len() == 0 {
+	count := r.regions.Len()
+	if count == 0 {
 		return 0
 	}
-	return r.regions.TotalSize() / int64(r.regions.Len())
+	return r.regions.TotalSize() / int64(count)
 }
 
 // RegionStats records a list of regions' statistics and distribution status.
@@ -698,6 +1089,8 @@ type RegionStats struct {
 	StoreLeaderKeys  map[uint64]int64 `json:"store_leader_keys"`
 	StorePeerSize    map[uint64]int64 `json:"store_peer_size"`
 	StorePeerKeys    map[uint64]int64 `json:"store_peer_keys"`
+
+	StorePendingPeerCount map[uint64]int `json:"store_pending_peer_count,omitempty"`
 }
 
 func newRegionStats() *RegionStats {
@@ -708,6 +1101,8 @@ func newRegionStats() *RegionStats {
 		StoreLeaderKeys:  make(map[uint64]int64),
 		StorePeerSize:    make(map[uint64]int64),
 		StorePeerKeys:    make(map[uint64]int64),
+
+		StorePendingPeerCount: make(map[uint64]int),
 	}
 }
 
@@ -729,27 +1124,109 @@ func (s *RegionStats) Observe(r *RegionInfo) {
 		s.StorePeerSize[p.GetStoreId()] += r.ApproximateSize
 		s.StorePeerKeys[p.GetStoreId()] += r.ApproximateKeys
 	}
+	for _, p := range r.PendingPeers {
+		s.StorePendingPeerCount[p.GetStoreId()]++
+	}
 }
 
-// GetRegionStats scans regions that inside range [startKey, endKey) and sums up
-// their statistics.
+// GetRegionStats sums up the statistics of regions that inside range
+// [startKey, endKey).
 func (r *RegionsInfo) GetRegionStats(startKey, endKey []byte) *RegionStats {
-	stats := newRegionStats()
-	r.tree.scanRange(startKey, func(meta *metapb.Region) bool {
-		if len(endKey) > 0 && (len(meta.EndKey) == 0 || bytes.Compare(meta.EndKey, endKey) >= 0) {
-			return false
+	r.treeMu.RLock()
+	defer r.treeMu.RUnlock()
+	lo, hi := r.tree.rankOfStartKey(startKey), r.tree.length()
+	if len(endKey) > 0 {
+		hi = r.tree.rankOfEndKey(endKey)
+	}
+	return r.tree.getStats(lo, hi).toRegionStats()
+}
+
+// rangeRanks returns the ranks of regions whose start key is in range
+// [startKey, endKey), an empty endKey means no upper bound.
+func (r *RegionsInfo) rangeRanks(startKey, endKey []byte) (int, int) {
+	lo, hi := r.tree.rankOfStartKey(startKey), r.tree.length()
+	if len(endKey) > 0 {
+		hi = r.tree.rankOfStartKey(endKey)
+	}
+	return lo, hi
+}
+
+// GetRangeRegionStats sums up the statistics of regions whose start key is in
+// range [startKey, endKey), an empty endKey means no upper bound.
+func (r *RegionsInfo) GetRangeRegionStats(startKey, endKey []byte) *RegionStats {
+	r.treeMu.RLock()
+	defer r.treeMu.RUnlock()
+	lo, hi := r.rangeRanks(startKey, endKey)
+	return r.tree.getStats(lo, hi).toRegionStats()
+}
+
+const (
+	// randomRangeRegionMaxProbe is the max number of random regions to check
+	// when picking a random region in a range.
+	randomRangeRegionMaxProbe = 64
+	// randomRangeRegionMaxScan is the max number of regions to scan when the
+	// random probes do not match.
+	randomRangeRegionMaxScan = 1024
+)
+
+// RandRangeRegion picks a random region whose start key is in range
+// [startKey, endKey) and matches opts. It probes random regions of the range
+// first, then scans from a random position if the matched regions are rare.
+// The returned region is a copy, so callers are free to modify it.
+func (r *RegionsInfo) RandRangeRegion(startKey, endKey []byte, opts ...RegionOption) *RegionInfo {
+	r.treeMu.RLock()
+	defer r.treeMu.RUnlock()
+	lo, hi := r.rangeRanks(startKey, endKey)
+	if lo >= hi {
+		return nil
+	}
+	match := func(region *RegionInfo) *RegionInfo {
+		if region = r.regions.Get(region.GetId()); region == nil {
+			return nil
 		}
-		if region := r.GetRegion(meta.GetId()); region != nil {
-			stats.Observe(region)
+		for _, opt := range opts {
+			if !opt(region) {
+				return nil
+			}
 		}
-		return true
-	})
-	return stats
+		return region.Clone()
+	}
+	for i := 0; i < randomRangeRegionMaxProbe && i < hi-lo; i++ {
+		if region := match(r.tree.getByRank(lo + rand.Intn(hi-lo))); region != nil {
+			return region
+		}
+	}
+
+	var (
+		res     *RegionInfo
+		scanned int
+	)
+	scan := func(limit int) func(*RegionInfo) bool {
+		return func(region *RegionInfo) bool {
+			if scanned >= limit || scanned >= randomRangeRegionMaxScan {
+				return false
+			}
+			scanned++
+			res = match(region)
+			return res == nil
+		}
+	}
+	// Scan [start, hi) then wrap around to [lo, start).
+	start := lo + rand.Intn(hi-lo)
+	r.tree.ascendFromRank(start, scan(hi-start))
+	if res == nil {
+		r.tree.ascendFromRank(lo, scan(hi-lo))
+	}
+	return res
 }
 
 const randomRegionMaxRetry = 10
 
-func randRegion(regions *regionMap, opts ...RegionOption) *RegionInfo {
+type randomRegionPicker interface {
+	RandomRegion() *RegionInfo
+}
+
+func randRegion(regions randomRegionPicker, opts ...RegionOption) *RegionInfo {
 	for i := 0; i < randomRegionMaxRetry; i++ {
 		region := regions.RandomRegion()
 		if region == nil {
@@ -0,0 +1,164 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultRegionFlushInterval is the interval to flush the batched regions.
+	defaultRegionFlushInterval = 3 * time.Second
+	// defaultRegionBatchSize is the number of batched regions which triggers
+	// a flush immediately.
+	defaultRegionBatchSize = 100
+)
+
+// batchKVBase is a KVBase which can save multiple key-value pairs at once.
+type batchKVBase interface {
+	SaveBatch(kvs map[string]string) error
+}
+
+// RegionKV is the storage for region meta. Saved regions are batched in
+// memory and flushed to the underlying KVBase asynchronously.
+type RegionKV struct {
+	KVBase
+
+	mu           sync.Mutex
+	batchRegions map[string]string
+	batchSize    int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRegionKV creates a RegionKV and starts its background flush loop.
+func NewRegionKV(base KVBase) *RegionKV {
+	return newRegionKV(base, defaultRegionBatchSize, defaultRegionFlushInterval)
+}
+
+func newRegionKV(base KVBase, batchSize int, flushInterval time.Duration) *RegionKV {
+	ctx, cancel := context.WithCancel(context.Background())
+	kv := &RegionKV{
+		KVBase:       base,
+		batchRegions: make(map[string]string, batchSize),
+		batchSize:    batchSize,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+	kv.wg.Add(1)
+	go kv.flushLoop(flushInterval)
+	return kv
+}
+
+func (kv *RegionKV) flushLoop(interval time.Duration) {
+	defer kv.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := kv.Flush(); err != nil {
+				log.Errorf("flush regions error: %v", err)
+			}
+		case <-kv.ctx.Done():
+			return
+		}
+	}
+}
+
+// Load loads the value of the key, batched regions are visible.
+func (kv *RegionKV) Load(key string) (string, error) {
+	kv.mu.Lock()
+	value, ok := kv.batchRegions[key]
+	kv.mu.Unlock()
+	if ok {
+		return value, nil
+	}
+	return kv.KVBase.Load(key)
+}
+
+// LoadRange flushes the batch then loads from the underlying KVBase.
+func (kv *RegionKV) LoadRange(key, endKey string, limit int) ([]string, error) {
+	if err := kv.Flush(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return kv.KVBase.LoadRange(key, endKey, limit)
+}
+
+// Save puts the key-value pair into the batch.
+func (kv *RegionKV) Save(key, value string) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.batchRegions[key] = value
+	if len(kv.batchRegions) < kv.batchSize {
+		return nil
+	}
+	return kv.flushLocked()
+}
+
+// Delete removes the key from both the batch and the underlying KVBase.
+func (kv *RegionKV) Delete(key string) error {
+	kv.mu.Lock()
+	delete(kv.batchRegions, key)
+	kv.mu.Unlock()
+	return kv.KVBase.Delete(key)
+}
+
+// Flush writes all batched regions to the underlying KVBase.
+func (kv *RegionKV) Flush() error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	return kv.flushLocked()
+}
+
+func (kv *RegionKV) flushLocked() error {
+	if len(kv.batchRegions) == 0 {
+		return nil
+	}
+	if base, ok := kv.KVBase.(batchKVBase); ok {
+		if err := base.SaveBatch(kv.batchRegions); err != nil {
+			return errors.Trace(err)
+		}
+	} else {
+		for k, v := range kv.batchRegions {
+			if err := kv.KVBase.Save(k, v); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	kv.batchRegions = make(map[string]string, kv.batchSize)
+	return nil
+}
+
+// Close stops the flush loop, flushes batched regions and closes the
+// underlying KVBase if it is closable.
+func (kv *RegionKV) Close() error {
+	kv.cancel()
+	kv.wg.Wait()
+	if err := kv.Flush(); err != nil {
+		return errors.Trace(err)
+	}
+	if closer, ok := kv.KVBase.(io.Closer); ok {
+		return errors.Trace(closer.Close())
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+var _ = Suite(&testRegionKVSuite{})
+
+type testRegionKVSuite struct {
+}
+
+func (s *testRegionKVSuite) TestBoltKV(c *C) {
+	dir, err := ioutil.TempDir("", "bolt_kv_test")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+
+	kv, err := NewBoltKV(dir)
+	c.Assert(err, IsNil)
+
+	c.Assert(kv.Save("a", "1"), IsNil)
+	c.Assert(kv.SaveBatch(map[string]string{"b": "2", "c": "3", "d": "4"}), IsNil)
+	value, err := kv.Load("a")
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "1")
+	res, err := kv.LoadRange("b", "d", 10)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []string{"2", "3"})
+	res, err = kv.LoadRange("a", "z", 2)
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []string{"1", "2"})
+	c.Assert(kv.Delete("a"), IsNil)
+	value, err = kv.Load("a")
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "")
+	c.Assert(kv.Close(), IsNil)
+
+	// Data is persisted after reopen.
+	kv, err = NewBoltKV(dir)
+	c.Assert(err, IsNil)
+	value, err = kv.Load("d")
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "4")
+	c.Assert(kv.Close(), IsNil)
+}
+
+func (s *testRegionKVSuite) TestRegionKVBatch(c *C) {
+	base := NewMemoryKV()
+	regionKV := newRegionKV(base, 3, time.Hour)
+	defer regionKV.Close()
+	kv := NewKV(NewMemoryKV()).SetRegionKV(regionKV)
+	kv.SwitchToRegionStorage()
+
+	regions := mustSaveRegions(c, kv, 2)
+	// Not flushed yet, but visible through the region storage.
+	value, err := base.Load(kv.regionPath(0))
+	c.Assert(err, IsNil)
+	c.Assert(value, Equals, "")
+	region := &metapb.Region{}
+	ok, err := kv.LoadRegion(1, region)
+	c.Assert(ok, IsTrue)
+	c.Assert(err, IsNil)
+	c.Assert(region, DeepEquals, regions[1])
+
+	// Reaching the batch size triggers a flush.
+	regions = append(regions, newTestRegionMeta(2))
+	c.Assert(kv.SaveRegion(regions[2]), IsNil)
+	res, err := base.LoadRange(kv.regionPath(0), kv.regionPath(10), 10)
+	c.Assert(err, IsNil)
+	c.Assert(res, HasLen, 3)
+
+	// Deleting removes the pending one as well.
+	c.Assert(kv.SaveRegion(newTestRegionMeta(3)), IsNil)
+	c.Assert(kv.DeleteRegion(newTestRegionMeta(3)), IsNil)
+	c.Assert(kv.Flush(), IsNil)
+	ok, err = kv.LoadRegion(3, region)
+	c.Assert(ok, IsFalse)
+	c.Assert(err, IsNil)
+
+	// The default storage is untouched.
+	ok, err = loadProto(kv.KVBase, kv.regionPath(0), region)
+	c.Assert(ok, IsFalse)
+	c.Assert(err, IsNil)
+}
+
+func (s *testRegionKVSuite) TestMigrateRegions(c *C) {
+	kv := NewKV(NewMemoryKV())
+	n := 10
+	regions := mustSaveRegions(c, kv, n)
+
+	regionKV := newRegionKV(NewMemoryKV(), 3, time.Hour)
+	defer regionKV.Close()
+	kv.SetRegionKV(regionKV)
+	count, err := kv.MigrateRegions()
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, n)
+	kv.SwitchToRegionStorage()
+
+	// The default storage keeps the regions as the fallback copy.
+	res, err := kv.KVBase.LoadRange(kv.regionPath(0), kv.regionPath(math.MaxUint64), n+1)
+	c.Assert(err, IsNil)
+	c.Assert(res, HasLen, n)
+
+	// Migration only runs once.
+	c.Assert(kv.KVBase.Save(kv.regionPath(100), "invalid"), IsNil)
+	count, err = kv.MigrateRegions()
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 0)
+
+	cache := NewRegionsInfo()
+	c.Assert(kv.LoadRegions(cache), IsNil)
+	c.Assert(cache.GetRegionCount(), Equals, n)
+	for _, region := range cache.GetMetaRegions() {
+		c.Assert(region, DeepEquals, regions[region.GetId()])
+	}
+}
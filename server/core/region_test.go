@@ -14,7 +14,13 @@
 package core
 
 import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -97,3 +103,405 @@ func (s *testRegionMapSuite) check(c *C, rm *regionMap, ids ...uint64) {
 	}
 	c.Assert(rm.TotalSize(), Equals, total)
 }
+
+var _ = Suite(&testRegionsInfoSuite{})
+
+type testRegionsInfoSuite struct{}
+
+func (s *testRegionsInfoSuite) TestConcurrentSetRegion(c *C) {
+	const (
+		storeCount  = 5
+		regionCount = 1000
+		workers     = 8
+	)
+	regions := newTestRegions(regionCount, storeCount)
+	info := NewRegionsInfo()
+	for _, region := range regions {
+		info.SetRegion(region)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < regionCount; i += workers {
+				region := regions[i].Clone()
+				region.Leader = region.Peers[(i+1)%len(region.Peers)]
+				region.ApproximateSize++
+				info.SetRegion(region)
+				info.SearchRegion(region.StartKey)
+				info.RandRegion()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	c.Assert(info.GetRegionCount(), Equals, regionCount)
+	c.Assert(info.TreeLength(), Equals, regionCount)
+	var leaders, size int
+	for id := uint64(1); id <= storeCount; id++ {
+		leaders += info.GetStoreLeaderCount(id)
+		size += int(info.GetStoreLeaderRegionSize(id))
+		c.Assert(info.GetStoreRegionCount(id), Equals, regionCount*3/storeCount)
+	}
+	c.Assert(leaders, Equals, regionCount)
+	c.Assert(size, Equals, regionCount*2)
+	for i, region := range regions {
+		got := info.GetRegion(region.GetId())
+		c.Assert(got.Leader.GetId(), Equals, region.Peers[(i+1)%len(region.Peers)].GetId())
+	}
+}
+
+func (s *testRegionsInfoSuite) TestConcurrentSplit(c *C) {
+	const workers = 8
+	info := NewRegionsInfo()
+	parents := newTestRegions(workers, 3)
+	for _, region := range parents {
+		info.SetRegion(region)
+	}
+
+	// Every worker splits its own region into halves repeatedly.
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(parent *RegionInfo) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				left := parent.Clone()
+				left.Id = parent.GetId()*1000 + uint64(i) + 1
+				left.EndKey = append(append([]byte{}, parent.StartKey...), byte(i))
+				parent = parent.Clone()
+				parent.StartKey = left.EndKey
+				info.SetRegion(parent)
+				info.SetRegion(left)
+			}
+		}(parents[w])
+	}
+	wg.Wait()
+
+	c.Assert(info.GetRegionCount(), Equals, workers*101)
+	c.Assert(info.TreeLength(), Equals, workers*101)
+}
+
+func newTestRegions(n, storeCount int) []*RegionInfo {
+	regions := make([]*RegionInfo, 0, n)
+	for i := 0; i < n; i++ {
+		peers := make([]*metapb.Peer, 0, 3)
+		for j := 0; j < 3; j++ {
+			peers = append(peers, &metapb.Peer{
+				Id:      uint64(i*3 + j + 1),
+				StoreId: uint64((i+j)%storeCount + 1),
+			})
+		}
+		region := &metapb.Region{
+			Id:          uint64(i + 1),
+			StartKey:    []byte(fmt.Sprintf("%20d", i)),
+			EndKey:      []byte(fmt.Sprintf("%20d", i+1)),
+			Peers:       peers,
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		}
+		if i == n-1 {
+			region.EndKey = nil
+		}
+		info := NewRegionInfo(region, peers[0])
+		info.ApproximateSize = 1
+		regions = append(regions, info)
+	}
+	return regions
+}
+
+const benchmarkRegionCount = 1000000
+
+var benchmarkRegions struct {
+	sync.Once
+	regions []*RegionInfo
+	info    *RegionsInfo
+}
+
+func prepareBenchmarkRegions() ([]*RegionInfo, *RegionsInfo) {
+	benchmarkRegions.Do(func() {
+		benchmarkRegions.regions = newTestRegions(benchmarkRegionCount, 100)
+		benchmarkRegions.info = NewRegionsInfo()
+		for _, region := range benchmarkRegions.regions {
+			benchmarkRegions.info.SetRegion(region)
+		}
+	})
+	return benchmarkRegions.regions, benchmarkRegions.info
+}
+
+// newHeartbeat simulates a heartbeat which changes the leader and the size of
+// a region.
+func newHeartbeat(rand *rand.Rand, regions []*RegionInfo) *RegionInfo {
+	region := regions[rand.Intn(len(regions))].Clone()
+	region.Leader = region.Peers[rand.Intn(len(region.Peers))]
+	region.ApproximateSize = rand.Int63n(96) + 1
+	return region
+}
+
+// BenchmarkSetRegionLocked updates the regions cache of 1M regions from many
+// goroutines with the updates serialized by a mutex, it is the baseline of
+// BenchmarkSetRegionParallel. The whole heartbeat is benchmarked by
+// BenchmarkHandleRegionHeartbeat in the server package.
+func BenchmarkSetRegionLocked(b *testing.B) {
+	regions, info := prepareBenchmarkRegions()
+	var mu sync.Mutex
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			region := newHeartbeat(r, regions)
+			mu.Lock()
+			info.SetRegion(region)
+			mu.Unlock()
+		}
+	})
+}
+
+// BenchmarkSetRegionParallel updates the regions cache of 1M regions from many
+// goroutines concurrently.
+func BenchmarkSetRegionParallel(b *testing.B) {
+	regions, info := prepareBenchmarkRegions()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			info.SetRegion(newHeartbeat(r, regions))
+		}
+	})
+}
+
+// scanRegionStats sums up the statistics by scanning regions, it is used to
+// check the aggregated statistics in the region tree.
+func scanRegionStats(regions *RegionsInfo, startKey, endKey []byte, rangeByStartKey bool) *RegionStats {
+	stats := newRegionStats()
+	for _, region := range regions.ScanRange(startKey, regions.GetRegionCount()) {
+		if rangeByStartKey {
+			if len(endKey) > 0 && bytes.Compare(region.StartKey, endKey) >= 0 {
+				break
+			}
+		} else if len(endKey) > 0 && (len(region.EndKey) == 0 || bytes.Compare(region.EndKey, endKey) >= 0) {
+			break
+		}
+		stats.Observe(region)
+	}
+	return stats
+}
+
+func (s *testRegionsInfoSuite) TestRangeStats(c *C) {
+	const (
+		storeCount  = 7
+		regionCount = 1000
+	)
+	regions := newTestRegions(regionCount, storeCount)
+	info := NewRegionsInfo()
+	for _, region := range regions {
+		info.SetRegion(region)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	checkStats := func() {
+		for i := 0; i < 50; i++ {
+			start, end := r.Intn(regionCount+10), r.Intn(regionCount+10)
+			startKey, endKey := []byte(fmt.Sprintf("%20d", start)), []byte(fmt.Sprintf("%20d", end))
+			if i%10 == 0 {
+				startKey = nil
+			}
+			if i%10 == 1 {
+				endKey = nil
+			}
+			c.Assert(info.GetRegionStats(startKey, endKey), DeepEquals, scanRegionStats(info, startKey, endKey, false))
+			c.Assert(info.GetRangeRegionStats(startKey, endKey), DeepEquals, scanRegionStats(info, startKey, endKey, true))
+		}
+	}
+	checkStats()
+
+	// Update size, leader and pending peers in place.
+	for i := 0; i < regionCount; i++ {
+		region := info.GetRegion(uint64(r.Intn(regionCount) + 1))
+		region.ApproximateSize = r.Int63n(100)
+		region.ApproximateKeys = r.Int63n(1000)
+		region.Leader = region.Peers[r.Intn(len(region.Peers))]
+		region.PendingPeers = nil
+		if r.Intn(5) == 0 {
+			region.PendingPeers = region.Peers[:1]
+		}
+		info.SetRegion(region)
+	}
+	checkStats()
+
+	// Merge adjacent regions.
+	for i := 0; i < regionCount/2; i++ {
+		region := info.RandRegion().Clone()
+		_, next := info.GetAdjacentRegions(region)
+		if next == nil {
+			continue
+		}
+		region.EndKey = next.EndKey
+		region.ApproximateSize += next.ApproximateSize
+		info.SetRegion(region)
+	}
+	c.Assert(info.TreeLength(), Equals, info.GetRegionCount())
+	checkStats()
+
+	// Remove regions.
+	for i := 0; i < 50; i++ {
+		info.RemoveRegion(info.RandRegion())
+	}
+	c.Assert(info.TreeLength(), Equals, info.GetRegionCount())
+	checkStats()
+}
+
+func (s *testRegionsInfoSuite) TestRandRangeRegion(c *C) {
+	const storeCount = 5
+	info := NewRegionsInfo()
+	for _, region := range newTestRegions(1000, storeCount) {
+		info.SetRegion(region)
+	}
+	startKey, endKey := []byte(fmt.Sprintf("%20d", 100)), []byte(fmt.Sprintf("%20d", 200))
+	for i := 0; i < 100; i++ {
+		storeID := uint64(i%storeCount + 1)
+		region := info.RandRangeRegion(startKey, endKey, LeaderInStore(storeID))
+		c.Assert(region, NotNil)
+		c.Assert(region.Leader.GetStoreId(), Equals, storeID)
+		c.Assert(bytes.Compare(region.StartKey, startKey) >= 0, IsTrue)
+		c.Assert(bytes.Compare(region.StartKey, endKey) < 0, IsTrue)
+
+		region = info.RandRangeRegion(startKey, endKey, FollowerInStore(storeID))
+		c.Assert(region, NotNil)
+		c.Assert(region.Leader.GetStoreId(), Not(Equals), storeID)
+		c.Assert(region.GetStoreVoter(storeID), NotNil)
+	}
+	c.Assert(info.RandRangeRegion(endKey, startKey), IsNil)
+	c.Assert(info.RandRangeRegion(startKey, endKey, LeaderInStore(storeCount+1)), IsNil)
+	// Only one region in the range.
+	region := info.RandRangeRegion(startKey, []byte(fmt.Sprintf("%20d", 101)))
+	c.Assert(region.GetId(), Equals, uint64(101))
+}
+
+func (s *testRegionsInfoSuite) TestRegionHeartbeat(c *C) {
+	info := NewRegionsInfo()
+	regions := newTestRegions(10, 3)
+	start := time.Now()
+	for _, region := range regions {
+		info.SetRegion(region)
+	}
+	c.Assert(info.GetNoHeartbeatRegions(start), HasLen, 0)
+	last, ok := info.GetRegionLastHeartbeat(1)
+	c.Assert(ok, IsTrue)
+	c.Assert(last.Before(start), IsFalse)
+	_, ok = info.GetRegionLastHeartbeat(100)
+	c.Assert(ok, IsFalse)
+
+	now := start.Add(time.Minute)
+	for _, region := range regions[:5] {
+		info.UpdateRegionHeartbeat(region.GetId(), now)
+	}
+	// Updating the region keeps the heartbeat time.
+	info.SetRegion(regions[0].Clone())
+	last, _ = info.GetRegionLastHeartbeat(1)
+	c.Assert(last.Equal(now), IsTrue)
+
+	noHeartbeat := info.GetNoHeartbeatRegions(now)
+	c.Assert(noHeartbeat, HasLen, 5)
+	for _, region := range noHeartbeat {
+		c.Assert(region.GetId() > 5, IsTrue)
+	}
+	c.Assert(info.GetNoHeartbeatRegions(now.Add(time.Second)), HasLen, 10)
+}
+
+func (s *testRegionsInfoSuite) TestCheckKeyRange(c *C) {
+	info := NewRegionsInfo()
+	holes, overlaps := info.CheckKeyRange()
+	c.Assert(holes, DeepEquals, []KeyRange{{}})
+	c.Assert(overlaps, HasLen, 0)
+
+	regions := newTestRegions(10, 3)
+	regions[0].StartKey = nil
+	for _, region := range regions {
+		info.SetRegion(region)
+	}
+	holes, overlaps = info.CheckKeyRange()
+	c.Assert(holes, HasLen, 0)
+	c.Assert(overlaps, HasLen, 0)
+
+	// Remove the first, the last and 2 adjacent regions.
+	for _, i := range []int{0, 4, 5, 9} {
+		info.RemoveRegion(regions[i])
+	}
+	holes, overlaps = info.CheckKeyRange()
+	c.Assert(overlaps, HasLen, 0)
+	c.Assert(holes, DeepEquals, []KeyRange{
+		{EndKey: regions[1].GetStartKey()},
+		{StartKey: regions[3].GetEndKey(), EndKey: regions[6].GetStartKey()},
+		{StartKey: regions[8].GetEndKey()},
+	})
+}
+
+// BenchmarkRangeStats computes the statistics of a range of 100K regions
+// among 1M regions.
+func BenchmarkRangeStats(b *testing.B) {
+	_, info := prepareBenchmarkRegions()
+	startKey, endKey := []byte(fmt.Sprintf("%20d", 300000)), []byte(fmt.Sprintf("%20d", 400000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		info.GetRegionStats(startKey, endKey)
+	}
+}
+
+// BenchmarkRegionTreeMemory reports the heap used by the region tree of 1M
+// regions, including the per-store statistics kept by the large subtrees.
+func BenchmarkRegionTreeMemory(b *testing.B) {
+	regions, _ := prepareBenchmarkRegions()
+	var tree *regionTree
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+		tree = nil
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		tree = newRegionTree()
+		for _, region := range regions {
+			tree.update(region)
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc)/float64(len(regions)), "B/region")
+	}
+	runtime.KeepAlive(tree)
+}
+
+func (s *testRegionsInfoSuite) TestScanRegions(c *C) {
+	info := NewRegionsInfo()
+	regions := newTestRegions(10, 3)
+	for _, region := range regions {
+		info.SetRegion(region)
+	}
+	checkIDs := func(res []*RegionInfo, ids ...uint64) {
+		c.Assert(res, HasLen, len(ids))
+		for i, region := range res {
+			c.Assert(region.GetId(), Equals, ids[i])
+		}
+	}
+
+	// The scan starts from the region which contains the start key.
+	startKey := append(regions[2].GetStartKey(), 'x')
+	checkIDs(info.ScanRegions(startKey, regions[5].GetStartKey(), 0), 3, 4, 5)
+	checkIDs(info.ScanRegions(startKey, nil, 2), 3, 4)
+	checkIDs(info.ScanRegions(regions[8].GetStartKey(), nil, 0), 9, 10)
+	checkIDs(info.ScanRegions(regions[1].GetStartKey(), regions[1].GetStartKey(), 0))
+
+	// The leaders of the regions 1, 4, 7 and 10 are in store 1.
+	checkIDs(info.ScanRegions(regions[1].GetStartKey(), nil, 2, LeaderInStore(1)), 4, 7)
+	checkIDs(info.ScanRegions(nil, nil, 0, LeaderInStore(1), PeerInStore(2)), 1, 4, 7, 10)
+	checkIDs(info.ScanRegions(nil, nil, 0, LeaderInStore(1), PeerInStore(4)))
+
+	c.Assert(info.SearchPrevRegion(startKey).GetId(), Equals, uint64(2))
+	c.Assert(info.SearchPrevRegion(regions[0].GetStartKey()), IsNil)
+
+	// There is no previous region if there is a hole before the region.
+	info.RemoveRegion(regions[1])
+	checkIDs(info.ScanRegions(regions[0].GetStartKey(), regions[3].GetStartKey(), 0), 1, 3)
+	c.Assert(info.SearchPrevRegion(startKey), IsNil)
+	c.Assert(info.SearchPrevRegion(regions[1].GetStartKey()), IsNil)
+}
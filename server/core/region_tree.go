@@ -14,83 +14,344 @@ package core
 
 import (
 	"bytes"
+	"math/rand"
 
-	"github.com/google/btree"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	log "github.com/sirupsen/logrus"
 )
 
-var _ btree.Item = &regionItem{}
-
+// regionItem is a node of the region tree. The tree is a treap ordered by
+// the start key of regions, every item keeps the aggregated statistics of the
+// subtree rooted at it so that the statistics of a key range can be computed
+// in logarithmic time.
+//
+// The tree only cares about the key range and the statistics of a region, the
+// region is refreshed lazily, callers should get the latest region by ID. The
+// keys are kept in the item so that the tree stays ordered even if the key of
+// the region is modified by callers, and so are the counted values of the
+// region so that the statistics don't drift in that case.
 type regionItem struct {
-	region *metapb.Region
+	region           *RegionInfo
+	counted          *countedRegion
+	startKey, endKey []byte
+	priority         uint32
+	left, right      *regionItem
+	stats            rangeStats
+}
+
+func newRegionTreeItem(region *RegionInfo) *regionItem {
+	item := &regionItem{
+		region:   region,
+		counted:  newCountedRegion(region),
+		startKey: region.GetStartKey(),
+		endKey:   region.GetEndKey(),
+		priority: rand.Uint32(),
+	}
+	item.stats.observe(item.counted, 1)
+	return item
+}
+
+// countedRegion is the part of a region which is counted in the statistics.
+type countedRegion struct {
+	size, keys    int64
+	leaderStore   uint64
+	hasLeader     bool
+	peerStores    []uint64
+	pendingStores []uint64
+}
+
+func newCountedRegion(region *RegionInfo) *countedRegion {
+	counted := &countedRegion{
+		size:       region.ApproximateSize,
+		keys:       region.ApproximateKeys,
+		peerStores: make([]uint64, 0, len(region.GetPeers())),
+	}
+	// Like the leaders in RegionsInfo, the leader is the voter which has the
+	// same ID with the region leader.
+	if region.Leader != nil {
+		for _, p := range region.GetVoters() {
+			if p.GetId() == region.Leader.GetId() {
+				counted.leaderStore, counted.hasLeader = p.GetStoreId(), true
+				break
+			}
+		}
+	}
+	for _, p := range region.GetPeers() {
+		counted.peerStores = append(counted.peerStores, p.GetStoreId())
+	}
+	for _, p := range region.PendingPeers {
+		counted.pendingStores = append(counted.pendingStores, p.GetStoreId())
+	}
+	return counted
 }
 
 // Less returns true if the region start key is less than the other.
-func (r *regionItem) Less(other btree.Item) bool {
-	left := r.region.GetStartKey()
-	right := other.(*regionItem).region.GetStartKey()
+func (r *regionItem) Less(other *regionItem) bool {
+	left := r.startKey
+	right := other.startKey
 	return bytes.Compare(left, right) < 0
 }
 
 func (r *regionItem) Contains(key []byte) bool {
-	start, end := r.region.GetStartKey(), r.region.GetEndKey()
+	start, end := r.startKey, r.endKey
 	return bytes.Compare(key, start) >= 0 && (len(end) == 0 || bytes.Compare(key, end) < 0)
 }
 
-const (
-	defaultBTreeDegree = 64
-)
+func (r *regionItem) count() int {
+	if r == nil {
+		return 0
+	}
+	return r.stats.count
+}
+
+// observe updates the statistics of the subtree after a region is added
+// (sign > 0) into or removed (sign < 0) from it.
+func (r *regionItem) observe(region *countedRegion, sign int) {
+	r.stats.observe(region, sign)
+	if r.stats.stores == nil && r.stats.count >= storeStatsThreshold {
+		r.stats.stores = make(map[uint64]*storeRangeStats)
+		r.left.collectStores(r.stats.stores)
+		r.right.collectStores(r.stats.stores)
+		observeStores(r.stats.stores, r.counted, 1)
+	} else if r.stats.stores != nil && r.stats.count < storeStatsThreshold {
+		r.stats.stores = nil
+	}
+}
+
+// pull recomputes the statistics of the subtree from its children.
+func (r *regionItem) pull() {
+	var stats rangeStats
+	if r.left != nil {
+		stats.add(&r.left.stats)
+	}
+	if r.right != nil {
+		stats.add(&r.right.stats)
+	}
+	stats.observe(r.counted, 1)
+	if stats.count >= storeStatsThreshold {
+		stats.stores = make(map[uint64]*storeRangeStats)
+		r.left.collectStores(stats.stores)
+		r.right.collectStores(stats.stores)
+		observeStores(stats.stores, r.counted, 1)
+	}
+	r.stats = stats
+}
+
+// collectStores adds the per-store statistics of the subtree into stores.
+func (r *regionItem) collectStores(stores map[uint64]*storeRangeStats) {
+	if r == nil {
+		return
+	}
+	if r.stats.stores != nil {
+		mergeStores(stores, r.stats.stores)
+		return
+	}
+	r.left.collectStores(stores)
+	observeStores(stores, r.counted, 1)
+	r.right.collectStores(stores)
+}
+
+// collectRange adds the statistics of the items whose rank is in [lo, hi)
+// into stats, base is the rank of the first item of the subtree.
+func (r *regionItem) collectRange(base, lo, hi int, stats *rangeStats) {
+	if r == nil || hi <= base || base+r.count() <= lo {
+		return
+	}
+	if lo <= base && base+r.count() <= hi {
+		stats.add(&r.stats)
+		r.collectStores(stats.stores)
+		return
+	}
+	r.left.collectRange(base, lo, hi, stats)
+	self := base + r.left.count()
+	if lo <= self && self < hi {
+		stats.observe(r.counted, 1)
+	}
+	r.right.collectRange(self+1, lo, hi, stats)
+}
+
+// ascendFromRank calls f for the items in order starting from the item of
+// rank k, until f returns false.
+func (r *regionItem) ascendFromRank(base, k int, f func(*regionItem) bool) bool {
+	if r == nil {
+		return true
+	}
+	self := base + r.left.count()
+	if k < self && !r.left.ascendFromRank(base, k, f) {
+		return false
+	}
+	if k <= self && !f(r) {
+		return false
+	}
+	return r.right.ascendFromRank(self+1, k, f)
+}
+
+func (r *regionItem) ascendGreaterOrEqual(key []byte, f func(*regionItem) bool) bool {
+	if r == nil {
+		return true
+	}
+	if bytes.Compare(r.startKey, key) >= 0 {
+		if !r.left.ascendGreaterOrEqual(key, f) || !f(r) {
+			return false
+		}
+	}
+	return r.right.ascendGreaterOrEqual(key, f)
+}
+
+func rotateLeft(r *regionItem) *regionItem {
+	right := r.right
+	r.right = right.left
+	right.left = r
+	right.stats = r.stats
+	r.pull()
+	return right
+}
+
+func rotateRight(r *regionItem) *regionItem {
+	left := r.left
+	r.left = left.right
+	left.right = r
+	left.stats = r.stats
+	r.pull()
+	return left
+}
+
+func insertItem(r *regionItem, item *regionItem) *regionItem {
+	if r == nil {
+		return item
+	}
+	if item.Less(r) {
+		r.left = insertItem(r.left, item)
+		r.observe(item.counted, 1)
+		if r.left.priority > r.priority {
+			r = rotateRight(r)
+		}
+	} else {
+		r.right = insertItem(r.right, item)
+		r.observe(item.counted, 1)
+		if r.right.priority > r.priority {
+			r = rotateLeft(r)
+		}
+	}
+	return r
+}
+
+func deleteItem(r *regionItem, item *regionItem) *regionItem {
+	if r == item {
+		return mergeItems(r.left, r.right)
+	}
+	if item.Less(r) {
+		r.left = deleteItem(r.left, item)
+	} else {
+		r.right = deleteItem(r.right, item)
+	}
+	r.observe(item.counted, -1)
+	return r
+}
+
+// mergeItems merges two subtrees, all items of left are less than right.
+func mergeItems(left, right *regionItem) *regionItem {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	if left.priority > right.priority {
+		left.right = mergeItems(left.right, right)
+		left.pull()
+		return left
+	}
+	right.left = mergeItems(left, right.left)
+	right.pull()
+	return right
+}
 
 type regionTree struct {
-	tree *btree.BTree
+	root *regionItem
 }
 
 func newRegionTree() *regionTree {
-	return &regionTree{
-		tree: btree.New(defaultBTreeDegree),
-	}
+	return &regionTree{}
 }
 
 func (t *regionTree) length() int {
-	return t.tree.Len()
+	return t.root.count()
 }
 
 // update updates the tree with the region.
 // It finds and deletes all the overlapped regions first, and then
 // insert the region.
-func (t *regionTree) update(region *metapb.Region) []*metapb.Region {
-	item := &regionItem{region: region}
-
+func (t *regionTree) update(region *RegionInfo) []*metapb.Region {
 	// note that find() gets the last item that is less or equal than the region.
 	// in the case: |_______a_______|_____b_____|___c___|
 	// new region is     |______d______|
 	// find() will return regionItem of region_a
 	// and both startKey of region_a and region_b are less than endKey of region_d,
 	// thus they are regarded as overlapped regions.
-	result := t.find(region)
-	if result == nil {
-		result = item
+	startKey := region.GetStartKey()
+	if result := t.find(region.Region); result != nil {
+		startKey = result.startKey
 	}
 
-	var overlaps []*metapb.Region
-	t.tree.AscendGreaterOrEqual(result, func(i btree.Item) bool {
-		over := i.(*regionItem)
-		if len(region.EndKey) > 0 && bytes.Compare(region.EndKey, over.region.StartKey) <= 0 {
+	var overlaps []*regionItem
+	t.root.ascendGreaterOrEqual(startKey, func(over *regionItem) bool {
+		if len(region.GetEndKey()) > 0 && bytes.Compare(region.GetEndKey(), over.startKey) <= 0 {
 			return false
 		}
-		overlaps = append(overlaps, over.region)
+		overlaps = append(overlaps, over)
 		return true
 	})
 
+	metas := make([]*metapb.Region, 0, len(overlaps))
 	for _, item := range overlaps {
-		log.Debugf("[region %d] delete region {%v}, cause overlapping with region {%v}", item.GetId(), item, region)
-		t.tree.Delete(&regionItem{item})
+		t.root = deleteItem(t.root, item)
+		// The origin version of the region itself is not an overlapped region.
+		if item.region.GetId() == region.GetId() {
+			continue
+		}
+		log.Debugf("[region %d] delete region {%v}, cause overlapping with region {%v}", item.region.GetId(), item.region, region)
+		metas = append(metas, item.region.Region)
 	}
 
-	t.tree.ReplaceOrInsert(item)
+	t.root = insertItem(t.root, newRegionTreeItem(region))
+
+	return metas
+}
 
-	return overlaps
+// refresh replaces the region in the tree with a new version which has the
+// same key range, and updates the statistics. It returns false if the region
+// is not in the tree.
+func (t *regionTree) refresh(region *RegionInfo) bool {
+	var path []*regionItem
+	item := t.root
+	for item != nil {
+		path = append(path, item)
+		c := bytes.Compare(region.GetStartKey(), item.startKey)
+		if c == 0 {
+			break
+		} else if c < 0 {
+			item = item.left
+		} else {
+			item = item.right
+		}
+	}
+	if item == nil || item.region.GetId() != region.GetId() {
+		return false
+	}
+	// Compute the difference once and apply it to the path.
+	counted := newCountedRegion(region)
+	delta := rangeStats{stores: make(map[uint64]*storeRangeStats)}
+	delta.observe(counted, 1)
+	delta.observe(item.counted, -1)
+	for _, p := range path {
+		p.stats.add(&delta)
+		if p.stats.stores != nil {
+			mergeStores(p.stats.stores, delta.stores)
+		}
+	}
+	item.region, item.counted = region, counted
+	return true
 }
 
 // remove removes a region if the region is in the tree.
@@ -102,11 +363,11 @@ func (t *regionTree) remove(region *metapb.Region) {
 		return
 	}
 
-	t.tree.Delete(result)
+	t.root = deleteItem(t.root, result)
 }
 
 // search returns a region that contains the key.
-func (t *regionTree) search(regionKey []byte) *metapb.Region {
+func (t *regionTree) search(regionKey []byte) *RegionInfo {
 	region := &metapb.Region{StartKey: regionKey}
 	result := t.find(region)
 	if result == nil {
@@ -118,44 +379,258 @@ func (t *regionTree) search(regionKey []byte) *metapb.Region {
 // find is a helper function to find an item that contains the regions start
 // key.
 func (t *regionTree) find(region *metapb.Region) *regionItem {
-	item := &regionItem{region: region}
-
 	var result *regionItem
-	t.tree.DescendLessOrEqual(item, func(i btree.Item) bool {
-		result = i.(*regionItem)
-		return false
-	})
+	for item := t.root; item != nil; {
+		if bytes.Compare(item.startKey, region.GetStartKey()) <= 0 {
+			result = item
+			item = item.right
+		} else {
+			item = item.left
+		}
+	}
 
-	if result == nil || !result.Contains(region.StartKey) {
+	if result == nil || !result.Contains(region.GetStartKey()) {
 		return nil
 	}
 
 	return result
 }
 
-func (t *regionTree) scanRange(startKey []byte, f func(*metapb.Region) bool) {
-	startItem := &regionItem{region: &metapb.Region{StartKey: startKey}}
-	t.tree.AscendGreaterOrEqual(startItem, func(item btree.Item) bool {
-		return f(item.(*regionItem).region)
+func (t *regionTree) scanRange(startKey []byte, f func(*RegionInfo) bool) {
+	t.root.ascendGreaterOrEqual(startKey, func(item *regionItem) bool {
+		return f(item.region)
+	})
+}
+
+// scanRegions calls f on the regions which overlap with [startKey, endKey)
+// in ascending key order until f returns false. An empty endKey means
+// scanning to the end.
+func (t *regionTree) scanRegions(startKey, endKey []byte, f func(*RegionInfo) bool) {
+	if item := t.find(&metapb.Region{StartKey: startKey}); item != nil {
+		startKey = item.startKey
+	}
+	t.root.ascendGreaterOrEqual(startKey, func(item *regionItem) bool {
+		if len(endKey) > 0 && bytes.Compare(item.startKey, endKey) >= 0 {
+			return false
+		}
+		return f(item.region)
 	})
 }
 
+// searchPrev returns the region before the region which contains the key. It
+// returns nil if there is a hole between them.
+func (t *regionTree) searchPrev(regionKey []byte) *RegionInfo {
+	item := t.find(&metapb.Region{StartKey: regionKey})
+	if item == nil {
+		return nil
+	}
+	prev, _ := t.getAdjacentRegions(&metapb.Region{StartKey: item.startKey})
+	if prev == nil || !bytes.Equal(prev.endKey, item.startKey) {
+		return nil
+	}
+	return prev.region
+}
+
 func (t *regionTree) getAdjacentRegions(region *metapb.Region) (*regionItem, *regionItem) {
-	item := &regionItem{region: &metapb.Region{StartKey: region.StartKey}}
 	var prev, next *regionItem
-	t.tree.AscendGreaterOrEqual(item, func(i btree.Item) bool {
-		if bytes.Equal(item.region.StartKey, i.(*regionItem).region.StartKey) {
-			return true
+	for item := t.root; item != nil; {
+		if bytes.Compare(item.startKey, region.GetStartKey()) < 0 {
+			prev = item
+			item = item.right
+		} else {
+			item = item.left
 		}
-		next = i.(*regionItem)
-		return false
-	})
-	t.tree.DescendLessOrEqual(item, func(i btree.Item) bool {
-		if bytes.Equal(item.region.StartKey, i.(*regionItem).region.StartKey) {
-			return true
+	}
+	for item := t.root; item != nil; {
+		if bytes.Compare(item.startKey, region.GetStartKey()) > 0 {
+			next = item
+			item = item.left
+		} else {
+			item = item.right
 		}
-		prev = i.(*regionItem)
-		return false
-	})
+	}
 	return prev, next
 }
+
+// rankOfStartKey returns the number of regions whose start key is less than
+// the key.
+func (t *regionTree) rankOfStartKey(key []byte) int {
+	var rank int
+	for item := t.root; item != nil; {
+		if bytes.Compare(item.startKey, key) < 0 {
+			rank += item.left.count() + 1
+			item = item.right
+		} else {
+			item = item.left
+		}
+	}
+	return rank
+}
+
+// rankOfEndKey returns the number of regions whose end key is not empty and
+// less than the key.
+func (t *regionTree) rankOfEndKey(key []byte) int {
+	var rank int
+	for item := t.root; item != nil; {
+		end := item.endKey
+		if len(end) > 0 && bytes.Compare(end, key) < 0 {
+			rank += item.left.count() + 1
+			item = item.right
+		} else {
+			item = item.left
+		}
+	}
+	return rank
+}
+
+// getStats sums up the statistics of the regions whose rank is in [lo, hi).
+func (t *regionTree) getStats(lo, hi int) *rangeStats {
+	stats := &rangeStats{stores: make(map[uint64]*storeRangeStats)}
+	t.root.collectRange(0, lo, hi, stats)
+	return stats
+}
+
+// getByRank returns the region of rank k.
+func (t *regionTree) getByRank(k int) *RegionInfo {
+	for item := t.root; item != nil; {
+		self := item.left.count()
+		if k < self {
+			item = item.left
+		} else if k == self {
+			return item.region
+		} else {
+			k -= self + 1
+			item = item.right
+		}
+	}
+	return nil
+}
+
+// ascendFromRank calls f for the regions in order starting from the region of
+// rank k, until f returns false.
+func (t *regionTree) ascendFromRank(k int, f func(*RegionInfo) bool) {
+	t.root.ascendFromRank(0, k, func(item *regionItem) bool {
+		return f(item.region)
+	})
+}
+
+// storeStatsThreshold is the minimal size of a subtree to keep the per-store
+// statistics. The statistics of smaller subtrees are collected on demand to
+// save memory. With 1M regions, the per-store statistics take about 5% of the
+// memory of the tree, see BenchmarkRegionTreeMemory.
+const storeStatsThreshold = 1024
+
+// rangeStats is the aggregated statistics of a range of regions.
+type rangeStats struct {
+	count      int
+	emptyCount int
+	size       int64
+	keys       int64
+	stores     map[uint64]*storeRangeStats // nil if not maintained
+}
+
+type storeRangeStats struct {
+	leaderCount      int
+	leaderSize       int64
+	leaderKeys       int64
+	peerCount        int
+	peerSize         int64
+	peerKeys         int64
+	pendingPeerCount int
+}
+
+// observe adds (sign > 0) or removes (sign < 0) the region.
+func (s *rangeStats) observe(region *countedRegion, sign int) {
+	s.count += sign
+	if region.size <= EmptyRegionApproximateSize {
+		s.emptyCount += sign
+	}
+	s.size += int64(sign) * region.size
+	s.keys += int64(sign) * region.keys
+	if s.stores != nil {
+		observeStores(s.stores, region, sign)
+	}
+}
+
+// add adds the counters of other, the per-store statistics are not included.
+func (s *rangeStats) add(other *rangeStats) {
+	s.count += other.count
+	s.emptyCount += other.emptyCount
+	s.size += other.size
+	s.keys += other.keys
+}
+
+func (s *rangeStats) toRegionStats() *RegionStats {
+	stats := newRegionStats()
+	stats.Count = s.count
+	stats.EmptyCount = s.emptyCount
+	stats.StorageSize = s.size
+	stats.StorageKeys = s.keys
+	for id, store := range s.stores {
+		if store.leaderCount > 0 {
+			stats.StoreLeaderCount[id] = store.leaderCount
+			stats.StoreLeaderSize[id] = store.leaderSize
+			stats.StoreLeaderKeys[id] = store.leaderKeys
+		}
+		if store.peerCount > 0 {
+			stats.StorePeerCount[id] = store.peerCount
+			stats.StorePeerSize[id] = store.peerSize
+			stats.StorePeerKeys[id] = store.peerKeys
+		}
+		if store.pendingPeerCount > 0 {
+			stats.StorePendingPeerCount[id] = store.pendingPeerCount
+		}
+	}
+	return stats
+}
+
+func getStoreRangeStats(stores map[uint64]*storeRangeStats, storeID uint64) *storeRangeStats {
+	store, ok := stores[storeID]
+	if !ok {
+		store = &storeRangeStats{}
+		stores[storeID] = store
+	}
+	return store
+}
+
+func observeStores(stores map[uint64]*storeRangeStats, region *countedRegion, sign int) {
+	size, keys := int64(sign)*region.size, int64(sign)*region.keys
+	if region.hasLeader {
+		store := getStoreRangeStats(stores, region.leaderStore)
+		store.leaderCount += sign
+		store.leaderSize += size
+		store.leaderKeys += keys
+	}
+	for _, id := range region.peerStores {
+		store := getStoreRangeStats(stores, id)
+		store.peerCount += sign
+		store.peerSize += size
+		store.peerKeys += keys
+	}
+	for _, id := range region.pendingStores {
+		getStoreRangeStats(stores, id).pendingPeerCount += sign
+	}
+	if sign < 0 {
+		for _, id := range region.peerStores {
+			if store := stores[id]; store != nil && *store == (storeRangeStats{}) {
+				delete(stores, id)
+			}
+		}
+	}
+}
+
+func mergeStores(stores, other map[uint64]*storeRangeStats) {
+	for id, o := range other {
+		store := getStoreRangeStats(stores, id)
+		store.leaderCount += o.leaderCount
+		store.leaderSize += o.leaderSize
+		store.leaderKeys += o.leaderKeys
+		store.peerCount += o.peerCount
+		store.peerSize += o.peerSize
+		store.peerKeys += o.peerKeys
+		store.pendingPeerCount += o.pendingPeerCount
+		if *store == (storeRangeStats{}) {
+			delete(stores, id)
+		}
+	}
+}
@@ -113,10 +113,10 @@ func (s *testRegionSuite) TestRegionTree(c *C) {
 
 	c.Assert(tree.search([]byte("a")), IsNil)
 
-	regionA := NewRegion([]byte("a"), []byte("b"))
-	regionB := NewRegion([]byte("b"), []byte("c"))
-	regionC := NewRegion([]byte("c"), []byte("d"))
-	regionD := NewRegion([]byte("d"), []byte{})
+	regionA := newTestRegionInfo([]byte("a"), []byte("b"))
+	regionB := newTestRegionInfo([]byte("b"), []byte("c"))
+	regionC := newTestRegionInfo([]byte("c"), []byte("d"))
+	regionD := newTestRegionInfo([]byte("d"), []byte{})
 
 	tree.update(regionA)
 	tree.update(regionC)
@@ -127,7 +127,7 @@ func (s *testRegionSuite) TestRegionTree(c *C) {
 	c.Assert(tree.search([]byte("d")), IsNil)
 
 	tree.update(regionB)
-	tree.remove(regionC)
+	tree.remove(regionC.Region)
 	tree.update(regionD)
 	c.Assert(tree.search([]byte{}), IsNil)
 	c.Assert(tree.search([]byte("a")), Equals, regionA)
@@ -136,16 +136,16 @@ func (s *testRegionSuite) TestRegionTree(c *C) {
 	c.Assert(tree.search([]byte("d")), Equals, regionD)
 
 	// check get adjacent regions
-	prev, next := tree.getAdjacentRegions(regionA)
+	prev, next := tree.getAdjacentRegions(regionA.Region)
 	c.Assert(prev, IsNil)
 	c.Assert(next.region, Equals, regionB)
-	prev, next = tree.getAdjacentRegions(regionB)
+	prev, next = tree.getAdjacentRegions(regionB.Region)
 	c.Assert(prev.region, Equals, regionA)
 	c.Assert(next.region, Equals, regionD)
-	prev, next = tree.getAdjacentRegions(regionC)
+	prev, next = tree.getAdjacentRegions(regionC.Region)
 	c.Assert(prev.region, Equals, regionB)
 	c.Assert(next.region, Equals, regionD)
-	prev, next = tree.getAdjacentRegions(regionD)
+	prev, next = tree.getAdjacentRegions(regionD.Region)
 	c.Assert(prev.region, Equals, regionB)
 	c.Assert(next, IsNil)
 
@@ -155,7 +155,7 @@ func (s *testRegionSuite) TestRegionTree(c *C) {
 	c.Assert(tree.search([]byte{}), Equals, region0)
 	anotherRegion0 := newRegionItem([]byte{}, []byte("a")).region
 	anotherRegion0.Id = 123
-	tree.remove(anotherRegion0)
+	tree.remove(anotherRegion0.Region)
 	c.Assert(tree.search([]byte{}), Equals, region0)
 
 	// overlaps with 0, A, B, C.
@@ -179,7 +179,8 @@ func (s *testRegionSuite) TestRegionTree(c *C) {
 }
 
 func updateRegions(c *C, tree *regionTree, regions []*metapb.Region) {
-	for _, region := range regions {
+	for _, meta := range regions {
+		region := &RegionInfo{Region: meta}
 		tree.update(region)
 		c.Assert(tree.search(region.StartKey), Equals, region)
 		if len(region.EndKey) > 0 {
@@ -192,7 +193,7 @@ func updateRegions(c *C, tree *regionTree, regions []*metapb.Region) {
 
 func (s *testRegionSuite) TestRegionTreeSplitAndMerge(c *C) {
 	tree := newRegionTree()
-	regions := []*metapb.Region{newRegionItem([]byte{}, []byte{}).region}
+	regions := []*metapb.Region{NewRegion([]byte{}, []byte{})}
 
 	// Byte will underflow/overflow if n > 7.
 	n := 7
@@ -221,5 +222,39 @@ func (s *testRegionSuite) TestRegionTreeSplitAndMerge(c *C) {
 }
 
 func newRegionItem(start, end []byte) *regionItem {
-	return &regionItem{region: NewRegion(start, end)}
+	return newRegionTreeItem(newTestRegionInfo(start, end))
+}
+
+func newTestRegionInfo(start, end []byte) *RegionInfo {
+	return &RegionInfo{Region: NewRegion(start, end)}
+}
+
+func (s *testRegionSuite) TestRegionTreeStatsWithInPlaceUpdate(c *C) {
+	regions := newTestRegions(3*storeStatsThreshold, 5)
+	origins := make([]*RegionInfo, 0, len(regions))
+	tree := newRegionTree()
+	for _, region := range regions {
+		origins = append(origins, region.Clone())
+		tree.update(region)
+	}
+
+	// Modify the regions in the tree in place, the tree keeps counting the
+	// values at the time they were added.
+	for _, region := range regions[:2*storeStatsThreshold] {
+		region.ApproximateSize += 10
+		region.ApproximateKeys += 100
+		region.Leader = region.Peers[1]
+		region.PendingPeers = region.Peers[:1]
+	}
+	for _, region := range regions[:storeStatsThreshold] {
+		tree.remove(region.Region)
+	}
+
+	expected := newRegionTree()
+	for _, region := range origins[storeStatsThreshold:] {
+		expected.update(region)
+	}
+	c.Assert(tree.length(), Equals, expected.length())
+	c.Assert(tree.getStats(0, tree.length()).toRegionStats(), DeepEquals, expected.getStats(0, expected.length()).toRegionStats())
+	c.Assert(tree.root.stats.stores, NotNil)
 }
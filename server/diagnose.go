@@ -0,0 +1,339 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/server/core"
+)
+
+type diagnoseType int
+
+// Recommendation contains a potential problem and possible way to deal with it.
+// The rule names the check which finds the problem.
+type Recommendation struct {
+	Rule        string `json:"rule"`
+	Module      string `json:"module"`
+	Level       string `json:"level"`
+	Description string `json:"description"`
+	Instruction string `json:"instruction"`
+}
+
+//lint:file-ignore U1000 document available levels and modules
+const (
+	// analyze levels
+	levelNormal   = "Normal"
+	levelWarning  = "Warning"
+	levelMinor    = "Minor"
+	levelMajor    = "Major"
+	levelCritical = "Critical"
+	levelFatal    = "Fatal"
+
+	// analyze modules
+	modMember   = "member"
+	modTiKV     = "TiKV"
+	modReplica  = "Replic"
+	modSchedule = "Schedule"
+	modRegion   = "Region"
+	modDefault  = "Default"
+
+	memberOneInstance diagnoseType = iota
+	memberEvenInstance
+	memberLostPeers
+	memberLostPeersMoreThanHalf
+	memberLeaderChanged
+	tikvCap70
+	tikvCap80
+	tikvCap90
+	tikvLostPeers
+	tikvLostPeersLongTime
+	regionNoHeartbeat
+	regionKeyRangeHole
+	regionKeyRangeOverlap
+	replicaMissPeer
+	replicaExtraPeer
+	replicaDownPeer
+	replicaPendingPeer
+	operatorTimeout
+)
+
+var (
+	diagnoseMap = map[diagnoseType]Recommendation{
+		memberOneInstance:           {"member_one_instance", modMember, levelWarning, "only one PD instance is running.", "please add PD instance."},
+		memberEvenInstance:          {"member_even_instance", modMember, levelMinor, "PD instances is even number.", "the recommended number of PD's instances is odd."},
+		memberLostPeers:             {"member_lost_peers", modMember, levelMajor, "some PD instances is down.", "please check host load and traffic."},
+		memberLostPeersMoreThanHalf: {"member_lost_peers_more_than_half", modMember, levelCritical, "more than half PD instances is down.", "please check host load and traffic."},
+		memberLeaderChanged:         {"member_leader_changed", modMember, levelMinor, "PD cluster leader is changed.", "please check host load and traffic."},
+		tikvCap70:                   {"tikv_capacity_70", modTiKV, levelWarning, "some TiKV stroage used more than 70%.", "plase add TiKV node."},
+		tikvCap80:                   {"tikv_capacity_80", modTiKV, levelMinor, "some TiKV stroage used more than 80%.", "plase add TiKV node."},
+		tikvCap90:                   {"tikv_capacity_90", modTiKV, levelMajor, "some TiKV stroage used more than 90%.", "plase add TiKV node."},
+		tikvLostPeers:               {"tikv_lost_peers", modTiKV, levelWarning, "some TiKV lost connect.", "plase check network."},
+		tikvLostPeersLongTime:       {"tikv_lost_peers_long_time", modTiKV, levelMajor, "some TiKV lost connect more than 1h.", "plase check network."},
+		regionNoHeartbeat:           {"region_no_heartbeat", modRegion, levelMajor, "some regions have not reported heartbeat for a long time.", "please check whether all peers of the regions are lost."},
+		regionKeyRangeHole:          {"region_key_range_hole", modRegion, levelCritical, "some key ranges are not covered by any region.", "please check the regions around the key ranges."},
+		regionKeyRangeOverlap:       {"region_key_range_overlap", modRegion, levelCritical, "some regions overlap with each other.", "please check the overlapped regions."},
+		replicaMissPeer:             {"replica_miss_peer", modReplica, levelMajor, "some regions have fewer replicas than max-replicas.", "please check whether there are enough available stores."},
+		replicaExtraPeer:            {"replica_extra_peer", modReplica, levelMinor, "some regions have more replicas than max-replicas.", "please check whether the replica schedule limit is too small."},
+		replicaDownPeer:             {"replica_down_peer", modReplica, levelMajor, "some regions have down replicas.", "please check the stores of the down replicas."},
+		replicaPendingPeer:          {"replica_pending_peer", modReplica, levelWarning, "some regions have pending replicas.", "please check host load and traffic of the stores."},
+		operatorTimeout:             {"operator_timeout", modSchedule, levelMinor, "some operators are not finished in time.", "please check the progress of the operators and the related stores."},
+	}
+)
+
+const (
+	// maxDiagnoseIDs is the max count of the IDs shown in a recommendation.
+	maxDiagnoseIDs = 16
+	// diagnoseNoHeartbeatThreshold is the threshold of the regions which
+	// have not reported heartbeat for a long time.
+	diagnoseNoHeartbeatThreshold = 10 * time.Minute
+	// diagnoseStoreLostLongTime is the threshold of the stores which lost
+	// connect for a long time.
+	diagnoseStoreLostLongTime = time.Hour
+)
+
+func diagnosePD(key diagnoseType, descAdd, instAdd string) *Recommendation {
+	d, ok := diagnoseMap[key]
+	if !ok {
+		return &Recommendation{
+			Module:      modDefault,
+			Description: descAdd,
+			Instruction: instAdd,
+		}
+	}
+	if descAdd != "" {
+		d.Description = fmt.Sprintf("%s %s", d.Description, descAdd)
+	}
+	if instAdd != "" {
+		d.Instruction = fmt.Sprintf("%s %s", d.Instruction, instAdd)
+	}
+	return &d
+}
+
+// describeIDs lists at most maxDiagnoseIDs IDs after the name.
+func describeIDs(name string, ids []uint64) string {
+	desc := fmt.Sprintf("%d %ss, %s ID", len(ids), name, name)
+	for i, id := range ids {
+		if i >= maxDiagnoseIDs {
+			desc += " ..."
+			break
+		}
+		desc = fmt.Sprintf("%s %d,", desc, id)
+	}
+	return desc
+}
+
+func regionIDs(regions []*core.RegionInfo) []uint64 {
+	ids := make([]uint64, 0, len(regions))
+	for _, region := range regions {
+		ids = append(ids, region.GetId())
+	}
+	return ids
+}
+
+// Diagnose checks the members, the stores, the regions and the operators,
+// and returns the recommendations of the problems found.
+func (s *Server) Diagnose() ([]*Recommendation, error) {
+	rdd := []*Recommendation{}
+	if err := s.membersDiagnose(&rdd); err != nil {
+		return nil, errors.Trace(err)
+	}
+	cluster := s.GetRaftCluster()
+	if cluster == nil {
+		return rdd, nil
+	}
+	cluster.storesDiagnose(&rdd)
+	if err := s.regionsDiagnose(&rdd); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := s.replicasDiagnose(&rdd); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := s.operatorsDiagnose(&rdd); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return rdd, nil
+}
+
+func (s *Server) membersDiagnose(rdd *[]*Recommendation) error {
+	var lostMemberIDs, runningMemberIDs []uint64
+	var newLeaderID uint64
+	req := &pdpb.GetMembersRequest{Header: &pdpb.RequestHeader{ClusterId: s.ClusterID()}}
+	members, err := s.GetMembers(context.Background(), req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	lenMembers := len(members.Members)
+	if lenMembers > 0 {
+		for _, m := range members.Members {
+			pm, err := getEtcdPeerStats(m.ClientUrls[0])
+			if err != nil {
+				// get peer etcd failed
+				lostMemberIDs = append(lostMemberIDs, m.MemberId)
+				continue
+			}
+			runningMemberIDs = append(runningMemberIDs, m.MemberId)
+			if time.Since(pm.LeaderInfo.StartTime) < time.Duration(time.Minute) {
+				newLeaderID = m.MemberId
+			}
+		}
+	} else {
+		return errors.Errorf("get PD member error")
+	}
+	lenLostMembers := len(lostMemberIDs)
+	if newLeaderID != 0 {
+		*rdd = append(*rdd, diagnosePD(memberLeaderChanged, fmt.Sprintf("new leader %d", newLeaderID), ""))
+	}
+	if len(runningMemberIDs) == 1 {
+		// only one pd peer running
+		*rdd = append(*rdd, diagnosePD(memberOneInstance, fmt.Sprintf("running PD member ID %d", runningMemberIDs[0]), ""))
+	}
+	if lenLostMembers > 0 {
+		// some pd's peers can not be connected
+		stringID := "lost members ID "
+		for _, m := range lostMemberIDs {
+			stringID = fmt.Sprintf("%s %d,", stringID, m)
+		}
+		*rdd = append(*rdd, diagnosePD(memberLostPeers, stringID, ""))
+	}
+	if len(runningMemberIDs)%2 == 0 {
+		// alived pd's numbers is even
+		*rdd = append(*rdd, diagnosePD(memberEvenInstance, "", ""))
+	}
+	if float64(lenMembers)/2 < float64(lenLostMembers) {
+		*rdd = append(*rdd, diagnosePD(memberLostPeersMoreThanHalf, "", ""))
+	}
+	return nil
+}
+
+func (c *RaftCluster) storesDiagnose(rdd *[]*Recommendation) {
+	// The store is reported at the highest level of the used space.
+	capLevels := []struct {
+		key   diagnoseType
+		ratio float64
+	}{{tikvCap90, 0.9}, {tikvCap80, 0.8}, {tikvCap70, 0.7}}
+	capStoreIDs := make([][]uint64, len(capLevels))
+	var lostStoreIDs, lostLongTimeStoreIDs []uint64
+	for _, store := range c.cachedCluster.GetStores() {
+		if store.IsTombstone() {
+			continue
+		}
+		if store.IsDisconnected() {
+			if store.DownTime() >= diagnoseStoreLostLongTime {
+				lostLongTimeStoreIDs = append(lostLongTimeStoreIDs, store.GetId())
+			} else {
+				lostStoreIDs = append(lostStoreIDs, store.GetId())
+			}
+		}
+		if store.Stats.GetCapacity() == 0 {
+			continue
+		}
+		used := 1 - store.AvailableRatio()
+		for i, level := range capLevels {
+			if used > level.ratio {
+				capStoreIDs[i] = append(capStoreIDs[i], store.GetId())
+				break
+			}
+		}
+	}
+	for i, level := range capLevels {
+		if len(capStoreIDs[i]) > 0 {
+			*rdd = append(*rdd, diagnosePD(level.key, describeIDs("store", capStoreIDs[i]), ""))
+		}
+	}
+	if len(lostStoreIDs) > 0 {
+		*rdd = append(*rdd, diagnosePD(tikvLostPeers, describeIDs("store", lostStoreIDs), ""))
+	}
+	if len(lostLongTimeStoreIDs) > 0 {
+		*rdd = append(*rdd, diagnosePD(tikvLostPeersLongTime, describeIDs("store", lostLongTimeStoreIDs), ""))
+	}
+}
+
+func (s *Server) regionsDiagnose(rdd *[]*Recommendation) error {
+	handler := s.GetHandler()
+	regions, err := handler.GetNoHeartbeatRegions(diagnoseNoHeartbeatThreshold)
+	if err == ErrNotBootstrapped {
+		return nil
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(regions) > 0 {
+		*rdd = append(*rdd, diagnosePD(regionNoHeartbeat, describeIDs("region", regionIDs(regions)), ""))
+	}
+
+	holes, overlaps, err := handler.CheckRegionKeyRange()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(holes) > 0 {
+		*rdd = append(*rdd, diagnosePD(regionKeyRangeHole, fmt.Sprintf("%d key ranges.", len(holes)), ""))
+	}
+	if len(overlaps) > 0 {
+		*rdd = append(*rdd, diagnosePD(regionKeyRangeOverlap, fmt.Sprintf("%d pairs of regions.", len(overlaps)), ""))
+	}
+	return nil
+}
+
+func (s *Server) replicasDiagnose(rdd *[]*Recommendation) error {
+	handler := s.GetHandler()
+	checks := []struct {
+		key diagnoseType
+		get func() ([]*core.RegionInfo, error)
+	}{
+		{replicaMissPeer, handler.GetMissPeerRegions},
+		{replicaExtraPeer, handler.GetExtraPeerRegions},
+		{replicaDownPeer, handler.GetDownPeerRegions},
+		{replicaPendingPeer, handler.GetPendingPeerRegions},
+	}
+	for _, check := range checks {
+		regions, err := check.get()
+		if err == ErrNotBootstrapped {
+			return nil
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(regions) > 0 {
+			*rdd = append(*rdd, diagnosePD(check.key, describeIDs("region", regionIDs(regions)), ""))
+		}
+	}
+	return nil
+}
+
+func (s *Server) operatorsDiagnose(rdd *[]*Recommendation) error {
+	ops, err := s.GetHandler().GetOperators()
+	if err == ErrNotBootstrapped {
+		return nil
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var ids []uint64
+	for _, op := range ops {
+		if op.IsTimeout() {
+			ids = append(ids, op.RegionID())
+		}
+	}
+	if len(ids) > 0 {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		*rdd = append(*rdd, diagnosePD(operatorTimeout, describeIDs("region", ids), ""))
+	}
+	return nil
+}
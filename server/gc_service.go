@@ -0,0 +1,147 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/pd/server/core"
+	log "github.com/sirupsen/logrus"
+)
+
+var serviceIDPattern = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+
+// getGCSafePoint returns the current GC safe point.
+func (s *Server) getGCSafePoint() (uint64, error) {
+	s.gcSafePointMu.Lock()
+	defer s.gcSafePointMu.Unlock()
+	safePoint, err := s.kv.LoadGCSafePoint()
+	return safePoint, errors.Trace(err)
+}
+
+// getServiceGCSafePoints returns the safe points of the services which have
+// not expired. The expired ones are left in the storage until the GC safe
+// point is updated.
+func (s *Server) getServiceGCSafePoints() ([]*core.ServiceSafePoint, error) {
+	s.gcSafePointMu.Lock()
+	defer s.gcSafePointMu.Unlock()
+	return s.loadServiceGCSafePointsLocked(time.Now(), false)
+}
+
+// updateServiceGCSafePoint sets the safe point of a service, the GC safe
+// point will not exceed it in the ttl. A non-positive ttl removes the safe
+// point of the service.
+func (s *Server) updateServiceGCSafePoint(serviceID string, safePoint uint64, ttl time.Duration) error {
+	if !serviceIDPattern.MatchString(serviceID) {
+		return errors.Annotatef(ErrInvalidServiceID, "service id %q", serviceID)
+	}
+	if ttl <= 0 {
+		return s.removeServiceGCSafePoint(serviceID)
+	}
+
+	s.gcSafePointMu.Lock()
+	defer s.gcSafePointMu.Unlock()
+	gcSafePoint, err := s.kv.LoadGCSafePoint()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if safePoint < gcSafePoint {
+		return errors.Annotatef(ErrServiceSafePointRollback, "service %s safe point %d, gc safe point %d", serviceID, safePoint, gcSafePoint)
+	}
+	// The expiration is in seconds, round it up so that the safe point is
+	// kept for at least the ttl.
+	ssp := &core.ServiceSafePoint{
+		ServiceID: serviceID,
+		ExpiredAt: time.Now().Add(ttl + time.Second - 1).Unix(),
+		SafePoint: safePoint,
+	}
+	if err := s.kv.SaveServiceGCSafePoint(ssp); err != nil {
+		return errors.Trace(err)
+	}
+	log.Infof("update service %s gc safe point to %d, expired at %v", serviceID, safePoint, time.Unix(ssp.ExpiredAt, 0))
+	return nil
+}
+
+// removeServiceGCSafePoint removes the safe point of a service.
+func (s *Server) removeServiceGCSafePoint(serviceID string) error {
+	s.gcSafePointMu.Lock()
+	defer s.gcSafePointMu.Unlock()
+	if err := s.kv.RemoveServiceGCSafePoint(serviceID); err != nil {
+		return errors.Trace(err)
+	}
+	log.Infof("remove service %s gc safe point", serviceID)
+	return nil
+}
+
+// updateGCSafePoint updates the GC safe point, the new safe point is limited
+// by the safe points of the services, and the GC safe point never goes back.
+// It returns the GC safe point after updating.
+func (s *Server) updateGCSafePoint(newSafePoint uint64) (uint64, error) {
+	s.gcSafePointMu.Lock()
+	defer s.gcSafePointMu.Unlock()
+	oldSafePoint, err := s.kv.LoadGCSafePoint()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+
+	ssps, err := s.loadServiceGCSafePointsLocked(time.Now(), true)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	for _, ssp := range ssps {
+		if ssp.SafePoint < newSafePoint {
+			log.Infof("gc safe point %d is blocked by service %s at %d", newSafePoint, ssp.ServiceID, ssp.SafePoint)
+			newSafePoint = ssp.SafePoint
+		}
+	}
+
+	// Only save the safe point if it's greater than the previous one
+	if newSafePoint > oldSafePoint {
+		if err := s.kv.SaveGCSafePoint(newSafePoint); err != nil {
+			return 0, errors.Trace(err)
+		}
+		log.Infof("updated gc safe point to %d", newSafePoint)
+	} else if newSafePoint < oldSafePoint {
+		log.Warnf("trying to update gc safe point from %d to %d", oldSafePoint, newSafePoint)
+		newSafePoint = oldSafePoint
+	}
+	return newSafePoint, nil
+}
+
+// loadServiceGCSafePointsLocked loads the service safe points which have not
+// expired, the expired ones are also removed from the storage if
+// removeExpired is true.
+func (s *Server) loadServiceGCSafePointsLocked(now time.Time, removeExpired bool) ([]*core.ServiceSafePoint, error) {
+	ssps, err := s.kv.LoadAllServiceGCSafePoints()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	alive := ssps[:0]
+	for _, ssp := range ssps {
+		if ssp.ExpiredAt <= now.Unix() {
+			if !removeExpired {
+				continue
+			}
+			log.Infof("service %s gc safe point %d expired", ssp.ServiceID, ssp.SafePoint)
+			if err := s.kv.RemoveServiceGCSafePoint(ssp.ServiceID); err != nil {
+				return nil, errors.Trace(err)
+			}
+			continue
+		}
+		alive = append(alive, ssp)
+	}
+	return alive, nil
+}
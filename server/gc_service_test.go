@@ -0,0 +1,111 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testGCServiceSuite{})
+
+type testGCServiceSuite struct {
+	svr     *Server
+	cleanup cleanupFunc
+}
+
+func (s *testGCServiceSuite) SetUpTest(c *C) {
+	s.svr, s.cleanup = mustRunTestServer(c)
+}
+
+func (s *testGCServiceSuite) TearDownTest(c *C) {
+	s.cleanup()
+}
+
+func (s *testGCServiceSuite) TestServiceSafePoint(c *C) {
+	err := s.svr.updateServiceGCSafePoint("a/b", 10, time.Minute)
+	c.Assert(errors.Cause(err), Equals, ErrInvalidServiceID)
+
+	c.Assert(s.svr.updateServiceGCSafePoint("br", 10, time.Minute), IsNil)
+	c.Assert(s.svr.updateServiceGCSafePoint("cdc", 20, time.Minute), IsNil)
+
+	// The GC safe point is blocked by the minimum service safe point.
+	safePoint, err := s.svr.updateGCSafePoint(30)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(10))
+
+	// The service safe point can not be less than the GC safe point.
+	err = s.svr.updateServiceGCSafePoint("cdc", 5, time.Minute)
+	c.Assert(errors.Cause(err), Equals, ErrServiceSafePointRollback)
+
+	c.Assert(s.svr.updateServiceGCSafePoint("br", 25, time.Minute), IsNil)
+	safePoint, err = s.svr.updateGCSafePoint(30)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(20))
+
+	// A non-positive ttl removes the service safe point.
+	c.Assert(s.svr.updateServiceGCSafePoint("cdc", 0, 0), IsNil)
+	ssps, err := s.svr.getServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 1)
+	c.Assert(ssps[0].ServiceID, Equals, "br")
+	c.Assert(ssps[0].SafePoint, Equals, uint64(25))
+	safePoint, err = s.svr.updateGCSafePoint(30)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(25))
+
+	c.Assert(s.svr.removeServiceGCSafePoint("br"), IsNil)
+	safePoint, err = s.svr.updateGCSafePoint(30)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(30))
+
+	// The GC safe point never goes back.
+	safePoint, err = s.svr.updateGCSafePoint(1)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(30))
+	safePoint, err = s.svr.getGCSafePoint()
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(30))
+}
+
+func (s *testGCServiceSuite) TestExpiredServiceSafePoint(c *C) {
+	c.Assert(s.svr.kv.SaveServiceGCSafePoint(&core.ServiceSafePoint{
+		ServiceID: "expired",
+		ExpiredAt: time.Now().Add(-time.Second).Unix(),
+		SafePoint: 1,
+	}), IsNil)
+	c.Assert(s.svr.updateServiceGCSafePoint("alive", 10, time.Minute), IsNil)
+
+	// Reading the safe points skips the expired one but doesn't remove it.
+	ssps, err := s.svr.getServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 1)
+	c.Assert(ssps[0].ServiceID, Equals, "alive")
+	ssps, err = s.svr.kv.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 2)
+
+	safePoint, err := s.svr.updateGCSafePoint(30)
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(10))
+
+	// The expired safe point is removed from the storage.
+	ssps, err = s.svr.kv.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(ssps, HasLen, 1)
+	c.Assert(ssps[0].ServiceID, Equals, "alive")
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/pdextpb"
 	"github.com/pingcap/pd/server/core"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
@@ -34,28 +35,28 @@ import (
 
 // notLeaderError is returned when current server is not the leader and not possible to process request.
 // TODO: work as proxy.
-var notLeaderError = status.Errorf(codes.Unavailable, "not leader")
+var notLeaderError = status.Error(codes.Unavailable, "not leader")
 
 // GetMembers implements gRPC PDServer.
 func (s *Server) GetMembers(context.Context, *pdpb.GetMembersRequest) (*pdpb.GetMembersResponse, error) {
 	if s.isClosed() {
-		return nil, status.Errorf(codes.Unknown, "server not started")
+		return nil, status.Error(codes.Unknown, "server not started")
 	}
 	members, err := GetMembers(s.GetClient())
 	if err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 	for _, m := range members {
 		leaderPriority, e := s.GetMemberLeaderPriority(m.GetMemberId())
 		if e != nil {
-			return nil, status.Errorf(codes.Unknown, e.Error())
+			return nil, status.Error(codes.Unknown, e.Error())
 		}
 		m.LeaderPriority = int32(leaderPriority)
 	}
 
 	leader, err := s.GetLeader()
 	if err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
 	var etcdLeader *pdpb.Member
@@ -91,7 +92,7 @@ func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
 		count := request.GetCount()
 		ts, err := s.getRespTS(count)
 		if err != nil {
-			return status.Errorf(codes.Unknown, err.Error())
+			return status.Error(codes.Unknown, err.Error())
 		}
 		response := &pdpb.TsoResponse{
 			Header:    s.header(),
@@ -121,7 +122,7 @@ func (s *Server) Bootstrap(ctx context.Context, request *pdpb.BootstrapRequest)
 		}, nil
 	}
 	if _, err := s.bootstrapCluster(request); err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
 	return &pdpb.BootstrapResponse{
@@ -151,7 +152,7 @@ func (s *Server) AllocID(ctx context.Context, request *pdpb.AllocIDRequest) (*pd
 	// We can use an allocator for all types ID allocation.
 	id, err := s.idAlloc.Alloc()
 	if err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
 	return &pdpb.AllocIDResponse{
@@ -173,7 +174,7 @@ func (s *Server) GetStore(ctx context.Context, request *pdpb.GetStoreRequest) (*
 
 	store, err := cluster.GetStore(request.GetStoreId())
 	if err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 	return &pdpb.GetStoreResponse{
 		Header: s.header(),
@@ -216,7 +217,7 @@ func (s *Server) PutStore(ctx context.Context, request *pdpb.PutStoreRequest) (*
 	}
 
 	if err := cluster.putStore(store); err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
 	log.Infof("put store ok - %v", store)
@@ -266,7 +267,7 @@ func (s *Server) StoreHeartbeat(ctx context.Context, request *pdpb.StoreHeartbea
 
 	err := cluster.cachedCluster.handleStoreHeartbeat(request.Stats)
 	if err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
 	return &pdpb.StoreHeartbeatResponse{
@@ -414,6 +415,73 @@ func (s *Server) GetRegionByID(ctx context.Context, request *pdpb.GetRegionByIDR
 	}, nil
 }
 
+// GetPrevRegion implements gRPC pdextpb.PDServer.
+func (s *Server) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
+	if err := s.validateRequest(request.GetHeader()); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	cluster := s.GetRaftCluster()
+	if cluster == nil {
+		return &pdpb.GetRegionResponse{Header: s.notBootstrappedHeader()}, nil
+	}
+	resp := &pdpb.GetRegionResponse{Header: s.header()}
+	if region := cluster.GetPrevRegionInfoByKey(request.GetRegionKey()); region != nil {
+		resp.Region, resp.Leader = region.Region, region.Leader
+	}
+	return resp, nil
+}
+
+// ScanRegions implements gRPC pdextpb.PDServer.
+func (s *Server) ScanRegions(ctx context.Context, request *pdextpb.ScanRegionsRequest) (*pdextpb.ScanRegionsResponse, error) {
+	if err := s.validateRequest(request.GetHeader()); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	cluster := s.GetRaftCluster()
+	if cluster == nil {
+		return &pdextpb.ScanRegionsResponse{Header: s.notBootstrappedHeader()}, nil
+	}
+	if request.Limit <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "limit should be positive, but got %d", request.Limit)
+	}
+	regions := cluster.ScanRegions(request.StartKey, request.EndKey, int(request.Limit))
+	resp := &pdextpb.ScanRegionsResponse{
+		Header:  s.header(),
+		Regions: make([]*metapb.Region, 0, len(regions)),
+		Leaders: make([]*metapb.Peer, 0, len(regions)),
+	}
+	for _, region := range regions {
+		leader := region.Leader
+		if leader == nil {
+			leader = &metapb.Peer{}
+		}
+		resp.Regions = append(resp.Regions, region.Region)
+		resp.Leaders = append(resp.Leaders, leader)
+	}
+	return resp, nil
+}
+
+// GetOperator implements gRPC pdextpb.PDServer.
+func (s *Server) GetOperator(ctx context.Context, request *pdextpb.GetOperatorRequest) (*pdextpb.GetOperatorResponse, error) {
+	if err := s.validateRequest(request.GetHeader()); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	cluster := s.GetRaftCluster()
+	if cluster == nil {
+		return &pdextpb.GetOperatorResponse{Header: s.notBootstrappedHeader()}, nil
+	}
+	resp := &pdextpb.GetOperatorResponse{Header: s.header()}
+	if op := cluster.coordinator.getOperator(request.GetRegionId()); op != nil {
+		resp.RegionId = op.RegionID()
+		resp.Desc = op.Desc()
+		resp.Status = op.Status()
+		resp.Kind = op.Kind().String()
+	}
+	return resp, nil
+}
+
 // AskSplit implements gRPC PDServer.
 func (s *Server) AskSplit(ctx context.Context, request *pdpb.AskSplitRequest) (*pdpb.AskSplitResponse, error) {
 	if err := s.validateRequest(request.GetHeader()); err != nil {
@@ -432,7 +500,7 @@ func (s *Server) AskSplit(ctx context.Context, request *pdpb.AskSplitRequest) (*
 	}
 	split, err := cluster.handleAskSplit(req)
 	if err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
 	return &pdpb.AskSplitResponse{
@@ -454,7 +522,7 @@ func (s *Server) ReportSplit(ctx context.Context, request *pdpb.ReportSplitReque
 	}
 	_, err := cluster.handleReportSplit(request)
 	if err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
 	return &pdpb.ReportSplitResponse{
@@ -490,7 +558,7 @@ func (s *Server) PutClusterConfig(ctx context.Context, request *pdpb.PutClusterC
 	}
 	conf := request.GetCluster()
 	if err := cluster.putConfig(conf); err != nil {
-		return nil, status.Errorf(codes.Unknown, err.Error())
+		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
 	log.Infof("put cluster config ok - %v", conf)
@@ -540,7 +608,7 @@ func (s *Server) GetGCSafePoint(ctx context.Context, request *pdpb.GetGCSafePoin
 		return &pdpb.GetGCSafePointResponse{Header: s.notBootstrappedHeader()}, nil
 	}
 
-	safePoint, err := s.kv.LoadGCSafePoint()
+	safePoint, err := s.getGCSafePoint()
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -562,24 +630,11 @@ func (s *Server) UpdateGCSafePoint(ctx context.Context, request *pdpb.UpdateGCSa
 		return &pdpb.UpdateGCSafePointResponse{Header: s.notBootstrappedHeader()}, nil
 	}
 
-	oldSafePoint, err := s.kv.LoadGCSafePoint()
+	newSafePoint, err := s.updateGCSafePoint(request.SafePoint)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	newSafePoint := request.SafePoint
-
-	// Only save the safe point if it's greater than the previous one
-	if newSafePoint > oldSafePoint {
-		if err := s.kv.SaveGCSafePoint(newSafePoint); err != nil {
-			return nil, errors.Trace(err)
-		}
-		log.Infof("updated gc safe point to %d", newSafePoint)
-	} else if newSafePoint < oldSafePoint {
-		log.Warn("trying to update gc safe point from %d to %d", oldSafePoint, newSafePoint)
-		newSafePoint = oldSafePoint
-	}
-
 	return &pdpb.UpdateGCSafePointResponse{
 		Header:       s.header(),
 		NewSafePoint: newSafePoint,
@@ -15,6 +15,7 @@ package server
 
 import (
 	"bytes"
+	"crypto/x509"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +29,8 @@ import (
 )
 
 var (
+	// ErrNotLeader is error info for the server is not leader
+	ErrNotLeader = errors.New("server is not leader")
 	// ErrNotBootstrapped is error info for cluster not bootstrapped
 	ErrNotBootstrapped = errors.New("TiKV cluster not bootstrapped, please start TiKV first")
 	// ErrOperatorNotFound is error info for operator not found
@@ -48,6 +51,35 @@ var (
 	ErrRegionIsStale = func(region *metapb.Region, origin *metapb.Region) error {
 		return errors.Errorf("region is stale: region %v origin %v", region, origin)
 	}
+	// ErrInvalidServiceID is error info for invalid service ID of GC safe point
+	ErrInvalidServiceID = errors.New("invalid service id, only letters, digits, '_' and '-' are allowed")
+	// ErrServiceSafePointRollback is error info for service safe point less than GC safe point
+	ErrServiceSafePointRollback = errors.New("service safe point is less than the gc safe point")
+	// ErrInvalidRole is error info for invalid role of the HTTP API callers
+	ErrInvalidRole = errors.New("invalid role, only read-only, operator and admin are allowed")
+	// ErrInvalidAuthName is error info for invalid name of auth token or identity
+	ErrInvalidAuthName = errors.New("invalid name, only letters, digits, '_', '.' and '-' are allowed in token names")
+	// ErrInvalidAuthToken is error info for unknown auth token
+	ErrInvalidAuthToken = errors.New("invalid auth token")
+	// ErrOperatorBatchTooLarge is error info for too many operators in a batch
+	ErrOperatorBatchTooLarge = errors.Errorf("too many operators in a batch, at most %d are allowed", maxOperatorBatchSize)
+	// ErrOperatorBatchConflict is error info for more than one operator of a region in a batch
+	ErrOperatorBatchConflict = errors.New("more than one operator of the region in a batch")
+	// ErrOperatorBatchRejected is error info for an operator in a batch which
+	// can't be added to the current regions and stores
+	ErrOperatorBatchRejected = errors.New("operator batch is rejected")
+	// ErrOperatorBatchNotFound is error info for operator batch not found
+	ErrOperatorBatchNotFound = errors.New("operator batch not found")
+	// ErrHeatmapDisabled is error info for the heatmap sampling is disabled
+	ErrHeatmapDisabled = errors.New("heatmap is disabled")
+	// ErrConfigRevisionMismatch is error info for the config is changed by others
+	ErrConfigRevisionMismatch = errors.New("config revision mismatch")
+	// ErrConfigRevisionNotFound is error info for config revision not found in the history
+	ErrConfigRevisionNotFound = errors.New("config revision not found")
+	// ErrInvalidAlertSilence is error info for invalid alert silence
+	ErrInvalidAlertSilence = errors.New("invalid alert silence, the matchers are required and it must end in the future after it starts")
+	// ErrAlertSilenceNotFound is error info for alert silence not found
+	ErrAlertSilenceNotFound = errors.New("alert silence not found")
 )
 
 // Handler is a helper to export methods to handle API/RPC requests.
@@ -300,42 +332,109 @@ func (h *Handler) GetHistory(start time.Time) ([]schedule.OperatorHistory, error
 	return c.getHistory(start), nil
 }
 
-var errAddOperator = errors.New("failed to add operator, maybe already have one")
+// addOperators adds the operators created by newOps atomically.
+func (h *Handler) addOperators(newOps func(c *coordinator) ([]*schedule.Operator, error)) error {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ops, err := newOps(c)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if ok := c.addOperator(ops...); !ok {
+		return errors.Trace(ErrAddOperator)
+	}
+	return nil
+}
 
-// AddTransferLeaderOperator adds an operator to transfer leader to the store.
-func (h *Handler) AddTransferLeaderOperator(regionID uint64, storeID uint64) error {
+// SubmitOperatorBatch adds the operators in the batch all or nothing, and
+// returns the id of the batch.
+func (h *Handler) SubmitOperatorBatch(b *OperatorBatch) (uint64, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if c != b.c {
+		return 0, errors.Trace(ErrAddOperator)
+	}
+	id, err := c.addOperatorBatch(b.ops)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return id, nil
+}
+
+// GetOperatorBatches returns the status of the recent operator batches.
+func (h *Handler) GetOperatorBatches() ([]*OperatorBatchStatus, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return c.getOperatorBatches(), nil
+}
+
+// GetOperatorBatch returns the status of an operator batch.
+func (h *Handler) GetOperatorBatch(id uint64) (*OperatorBatchStatus, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	status := c.getOperatorBatch(id)
+	if status == nil {
+		return nil, errors.Trace(ErrOperatorBatchNotFound)
+	}
+	return status, nil
+}
+
+// CancelOperatorBatch removes the running operators of an operator batch.
+func (h *Handler) CancelOperatorBatch(id uint64) error {
 	c, err := h.getCoordinator()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if !c.cancelOperatorBatch(id) {
+		return errors.Trace(ErrOperatorBatchNotFound)
+	}
+	return nil
+}
 
+// AddTransferLeaderOperator adds an operator to transfer leader to the store.
+func (h *Handler) AddTransferLeaderOperator(regionID uint64, storeID uint64) error {
+	return h.addOperators(func(c *coordinator) ([]*schedule.Operator, error) {
+		return h.newTransferLeaderOperator(c, regionID, storeID)
+	})
+}
+
+func (h *Handler) newTransferLeaderOperator(c *coordinator, regionID uint64, storeID uint64) ([]*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 	newLeader := region.GetStoreVoter(storeID)
 	if newLeader == nil {
-		return errors.Errorf("region has no voter in store %v", storeID)
+		return nil, errors.Errorf("region has no voter in store %v", storeID)
 	}
 
 	step := schedule.TransferLeader{FromStore: region.Leader.GetStoreId(), ToStore: newLeader.GetStoreId()}
 	op := schedule.NewOperator("adminTransferLeader", regionID, region.GetRegionEpoch(), schedule.OpAdmin|schedule.OpLeader, step)
-	if ok := c.addOperator(op); !ok {
-		return errors.Trace(errAddOperator)
-	}
-	return nil
+	return []*schedule.Operator{op}, nil
 }
 
 // AddTransferRegionOperator adds an operator to transfer region to the stores.
 func (h *Handler) AddTransferRegionOperator(regionID uint64, storeIDs map[uint64]struct{}) error {
-	c, err := h.getCoordinator()
-	if err != nil {
-		return errors.Trace(err)
-	}
+	return h.addOperators(func(c *coordinator) ([]*schedule.Operator, error) {
+		return h.newTransferRegionOperator(c, regionID, storeIDs)
+	})
+}
 
+func (h *Handler) newTransferRegionOperator(c *coordinator, regionID uint64, storeIDs map[uint64]struct{}) ([]*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	var steps []schedule.OperatorStep
@@ -343,14 +442,14 @@ func (h *Handler) AddTransferRegionOperator(regionID uint64, storeIDs map[uint64
 	// Add missing peers.
 	for id := range storeIDs {
 		if c.cluster.GetStore(id) == nil {
-			return core.NewStoreNotFoundErr(id)
+			return nil, core.NewStoreNotFoundErr(id)
 		}
 		if region.GetStorePeer(id) != nil {
 			continue
 		}
 		peer, err := c.cluster.AllocPeer(id)
 		if err != nil {
-			return errors.Trace(err)
+			return nil, errors.Trace(err)
 		}
 		if c.cluster.IsRaftLearnerEnabled() {
 			steps = append(steps,
@@ -371,66 +470,62 @@ func (h *Handler) AddTransferRegionOperator(regionID uint64, storeIDs map[uint64
 	}
 
 	op := schedule.NewOperator("adminMoveRegion", regionID, region.GetRegionEpoch(), schedule.OpAdmin|schedule.OpRegion, steps...)
-	if ok := c.addOperator(op); !ok {
-		return errors.Trace(errAddOperator)
-	}
-	return nil
+	return []*schedule.Operator{op}, nil
 }
 
 // AddTransferPeerOperator adds an operator to transfer peer.
 func (h *Handler) AddTransferPeerOperator(regionID uint64, fromStoreID, toStoreID uint64) error {
-	c, err := h.getCoordinator()
-	if err != nil {
-		return errors.Trace(err)
-	}
+	return h.addOperators(func(c *coordinator) ([]*schedule.Operator, error) {
+		return h.newTransferPeerOperator(c, regionID, fromStoreID, toStoreID)
+	})
+}
 
+func (h *Handler) newTransferPeerOperator(c *coordinator, regionID uint64, fromStoreID, toStoreID uint64) ([]*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	oldPeer := region.GetStorePeer(fromStoreID)
 	if oldPeer == nil {
-		return errors.Errorf("region has no peer in store %v", fromStoreID)
+		return nil, errors.Errorf("region has no peer in store %v", fromStoreID)
 	}
 
 	if c.cluster.GetStore(toStoreID) == nil {
-		return core.NewStoreNotFoundErr(toStoreID)
+		return nil, core.NewStoreNotFoundErr(toStoreID)
 	}
 	newPeer, err := c.cluster.AllocPeer(toStoreID)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
 
 	op := schedule.CreateMovePeerOperator("adminMovePeer", c.cluster, region, schedule.OpAdmin, fromStoreID, toStoreID, newPeer.GetId())
-	if ok := c.addOperator(op); !ok {
-		return errors.Trace(errAddOperator)
-	}
-	return nil
+	return []*schedule.Operator{op}, nil
 }
 
 // AddAddPeerOperator adds an operator to add peer.
 func (h *Handler) AddAddPeerOperator(regionID uint64, toStoreID uint64) error {
-	c, err := h.getCoordinator()
-	if err != nil {
-		return errors.Trace(err)
-	}
+	return h.addOperators(func(c *coordinator) ([]*schedule.Operator, error) {
+		return h.newAddPeerOperator(c, regionID, toStoreID)
+	})
+}
 
+func (h *Handler) newAddPeerOperator(c *coordinator, regionID uint64, toStoreID uint64) ([]*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	if region.GetStorePeer(toStoreID) != nil {
-		return errors.Errorf("region already has peer in store %v", toStoreID)
+		return nil, errors.Errorf("region already has peer in store %v", toStoreID)
 	}
 
 	if c.cluster.GetStore(toStoreID) == nil {
-		return core.NewStoreNotFoundErr(toStoreID)
+		return nil, core.NewStoreNotFoundErr(toStoreID)
 	}
 	newPeer, err := c.cluster.AllocPeer(toStoreID)
 	if err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
 
 	var steps []schedule.OperatorStep
@@ -445,88 +540,82 @@ func (h *Handler) AddAddPeerOperator(regionID uint64, toStoreID uint64) error {
 		}
 	}
 	op := schedule.NewOperator("adminAddPeer", regionID, region.GetRegionEpoch(), schedule.OpAdmin|schedule.OpRegion, steps...)
-	if ok := c.addOperator(op); !ok {
-		return errors.Trace(errAddOperator)
-	}
-	return nil
+	return []*schedule.Operator{op}, nil
 }
 
 // AddRemovePeerOperator adds an operator to remove peer.
 func (h *Handler) AddRemovePeerOperator(regionID uint64, fromStoreID uint64) error {
-	c, err := h.getCoordinator()
-	if err != nil {
-		return errors.Trace(err)
-	}
+	return h.addOperators(func(c *coordinator) ([]*schedule.Operator, error) {
+		return h.newRemovePeerOperator(c, regionID, fromStoreID)
+	})
+}
 
+func (h *Handler) newRemovePeerOperator(c *coordinator, regionID uint64, fromStoreID uint64) ([]*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	if region.GetStorePeer(fromStoreID) == nil {
-		return errors.Errorf("region has no peer in store %v", fromStoreID)
+		return nil, errors.Errorf("region has no peer in store %v", fromStoreID)
 	}
 
 	op := schedule.CreateRemovePeerOperator("adminRemovePeer", c.cluster, schedule.OpAdmin, region, fromStoreID)
-	if ok := c.addOperator(op); !ok {
-		return errors.Trace(errAddOperator)
-	}
-	return nil
+	return []*schedule.Operator{op}, nil
 }
 
 // AddMergeRegionOperator adds an operator to merge region.
 func (h *Handler) AddMergeRegionOperator(regionID uint64, targetID uint64) error {
-	c, err := h.getCoordinator()
-	if err != nil {
-		return errors.Trace(err)
-	}
+	return h.addOperators(func(c *coordinator) ([]*schedule.Operator, error) {
+		return h.newMergeRegionOperator(c, regionID, targetID)
+	})
+}
 
+func (h *Handler) newMergeRegionOperator(c *coordinator, regionID uint64, targetID uint64) ([]*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	target := c.cluster.GetRegion(targetID)
 	if target == nil {
-		return ErrRegionNotFound(targetID)
+		return nil, ErrRegionNotFound(targetID)
 	}
 
 	if len(region.DownPeers) > 0 || len(region.PendingPeers) > 0 || len(region.Learners) > 0 ||
 		len(region.Region.GetPeers()) != c.cluster.GetMaxReplicas() {
-		return ErrRegionAbnormalPeer(regionID)
+		return nil, ErrRegionAbnormalPeer(regionID)
 	}
 
 	if len(target.DownPeers) > 0 || len(target.PendingPeers) > 0 || len(target.Learners) > 0 ||
 		len(target.Region.GetPeers()) != c.cluster.GetMaxReplicas() {
-		return ErrRegionAbnormalPeer(targetID)
+		return nil, ErrRegionAbnormalPeer(targetID)
 	}
 
 	// for the case first region (start key is nil) with the last region (end key is nil) but not adjacent
 	if (bytes.Equal(region.StartKey, target.EndKey) || len(region.StartKey) == 0) &&
 		(bytes.Equal(region.EndKey, target.StartKey) || len(region.EndKey) == 0) {
-		return ErrRegionNotAdjacent
+		return nil, ErrRegionNotAdjacent
 	}
 
 	op1, op2, err := schedule.CreateMergeRegionOperator("adminMergeRegion", c.cluster, region, target, schedule.OpAdmin)
 	if err != nil {
-		return errors.Trace(err)
-	}
-	if ok := c.addOperator(op1, op2); !ok {
-		return errors.Trace(ErrAddOperator)
+		return nil, errors.Trace(err)
 	}
-	return nil
+	return []*schedule.Operator{op1, op2}, nil
 }
 
 // AddSplitRegionOperator adds an operator to split a region.
 func (h *Handler) AddSplitRegionOperator(regionID uint64, policy string) error {
-	c, err := h.getCoordinator()
-	if err != nil {
-		return errors.Trace(err)
-	}
+	return h.addOperators(func(c *coordinator) ([]*schedule.Operator, error) {
+		return h.newSplitRegionOperator(c, regionID, policy)
+	})
+}
 
+func (h *Handler) newSplitRegionOperator(c *coordinator, regionID uint64, policy string) ([]*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	step := schedule.SplitRegion{
@@ -535,32 +624,27 @@ func (h *Handler) AddSplitRegionOperator(regionID uint64, policy string) error {
 		Policy:   pdpb.CheckPolicy(pdpb.CheckPolicy_value[strings.ToUpper(policy)]),
 	}
 	op := schedule.NewOperator("adminSplitRegion", regionID, region.GetRegionEpoch(), schedule.OpAdmin, step)
-	if ok := c.addOperator(op); !ok {
-		return errors.Trace(errAddOperator)
-	}
-	return nil
+	return []*schedule.Operator{op}, nil
 }
 
 // AddScatterRegionOperator adds an operator to scatter a region.
 func (h *Handler) AddScatterRegionOperator(regionID uint64) error {
-	c, err := h.getCoordinator()
-	if err != nil {
-		return errors.Trace(err)
-	}
+	return h.addOperators(func(c *coordinator) ([]*schedule.Operator, error) {
+		return h.newScatterRegionOperator(c, regionID)
+	})
+}
 
+func (h *Handler) newScatterRegionOperator(c *coordinator, regionID uint64) ([]*schedule.Operator, error) {
 	region := c.cluster.GetRegion(regionID)
 	if region == nil {
-		return ErrRegionNotFound(regionID)
+		return nil, ErrRegionNotFound(regionID)
 	}
 
 	op := c.regionScatterer.Scatter(region)
 	if op == nil {
-		return nil
-	}
-	if ok := c.addOperator(op); !ok {
-		return errors.Trace(errAddOperator)
+		return nil, nil
 	}
-	return nil
+	return []*schedule.Operator{op}, nil
 }
 
 // GetDownPeerRegions gets the region with down peer.
@@ -590,6 +674,27 @@ func (h *Handler) GetMissPeerRegions() ([]*core.RegionInfo, error) {
 	return c.cachedCluster.GetRegionStatsByType(missPeer), nil
 }
 
+// GetNoHeartbeatRegions gets the regions whose leader has not reported
+// heartbeat within the threshold.
+func (h *Handler) GetNoHeartbeatRegions(threshold time.Duration) ([]*core.RegionInfo, error) {
+	c := h.s.GetRaftCluster()
+	if c == nil {
+		return nil, ErrNotBootstrapped
+	}
+	return c.cachedCluster.getNoHeartbeatRegions(threshold), nil
+}
+
+// CheckRegionKeyRange gets the key ranges not covered by any region and the
+// pairs of regions overlapped with each other.
+func (h *Handler) CheckRegionKeyRange() ([]core.KeyRange, [][2]*core.RegionInfo, error) {
+	c := h.s.GetRaftCluster()
+	if c == nil {
+		return nil, nil, ErrNotBootstrapped
+	}
+	holes, overlaps := c.cachedCluster.checkKeyRange()
+	return holes, overlaps, nil
+}
+
 // GetPendingPeerRegions gets the region with pending peer.
 func (h *Handler) GetPendingPeerRegions() ([]*core.RegionInfo, error) {
 	c := h.s.GetRaftCluster()
@@ -607,3 +712,163 @@ func (h *Handler) GetIncorrectNamespaceRegions() ([]*core.RegionInfo, error) {
 	}
 	return c.cachedCluster.GetRegionStatsByType(incorrectNamespace), nil
 }
+
+// GetGCSafePoint gets the GC safe point.
+func (h *Handler) GetGCSafePoint() (uint64, error) {
+	return h.s.getGCSafePoint()
+}
+
+// GetServiceGCSafePoints gets the safe points of the services which have not
+// expired.
+func (h *Handler) GetServiceGCSafePoints() ([]*core.ServiceSafePoint, error) {
+	return h.s.getServiceGCSafePoints()
+}
+
+// UpdateServiceGCSafePoint sets the safe point of a service, the GC safe point
+// will not exceed it in the ttl. A non-positive ttl removes the safe point.
+func (h *Handler) UpdateServiceGCSafePoint(serviceID string, safePoint uint64, ttl time.Duration) error {
+	return h.s.updateServiceGCSafePoint(serviceID, safePoint, ttl)
+}
+
+// RemoveServiceGCSafePoint removes the safe point of a service.
+func (h *Handler) RemoveServiceGCSafePoint(serviceID string) error {
+	return h.s.removeServiceGCSafePoint(serviceID)
+}
+
+// IsAuthEnabled returns true if the callers of the HTTP API need to be
+// authenticated.
+func (h *Handler) IsAuthEnabled() bool {
+	return h.s.cfg.Security.EnableAuth
+}
+
+// Authenticate returns the caller with the bearer token or the client
+// certificate, it returns nil if the caller is anonymous.
+func (h *Handler) Authenticate(token string, cert *x509.Certificate) (*Principal, error) {
+	return h.s.authenticate(token, cert)
+}
+
+// AuthenticateMember returns true if the client certificate belongs to the
+// member with the name.
+func (h *Handler) AuthenticateMember(name string, cert *x509.Certificate) (bool, error) {
+	return h.s.authenticateMember(name, cert)
+}
+
+// GetAuthTokens gets all the auth tokens.
+func (h *Handler) GetAuthTokens() ([]*core.AuthToken, error) {
+	return h.s.kv.LoadAuthTokens()
+}
+
+// CreateAuthToken creates or regenerates a token with the role, and returns
+// the token.
+func (h *Handler) CreateAuthToken(name string, role Role) (string, error) {
+	return h.s.createAuthToken(name, role)
+}
+
+// DeleteAuthToken deletes a token by name.
+func (h *Handler) DeleteAuthToken(name string) error {
+	return h.s.deleteAuthToken(name)
+}
+
+// GetAuthIdentities gets the roles bound to the client certificates.
+func (h *Handler) GetAuthIdentities() ([]*core.AuthIdentity, error) {
+	return h.s.kv.LoadAuthIdentities()
+}
+
+// SetAuthIdentity binds the role to the common name of client certificates.
+func (h *Handler) SetAuthIdentity(name string, role Role) error {
+	return h.s.setAuthIdentity(name, role)
+}
+
+// DeleteAuthIdentity deletes the role binding of a common name.
+func (h *Handler) DeleteAuthIdentity(name string) error {
+	return h.s.deleteAuthIdentity(name)
+}
+
+// IsAuditEnabled returns true if the mutating calls of the HTTP API are
+// recorded.
+func (h *Handler) IsAuditEnabled() bool {
+	return !h.s.cfg.Audit.Disable
+}
+
+// RecordAudit appends an entry to the audit log.
+func (h *Handler) RecordAudit(entry *core.AuditEntry) error {
+	return h.s.appendAuditEntry(entry)
+}
+
+// GetAuditEntries returns at most limit audit entries received in
+// [start, end). A non-positive limit means no limit.
+func (h *Handler) GetAuditEntries(start, end time.Time, limit int) ([]*core.AuditEntry, error) {
+	return h.s.getAuditEntries(start, end, limit)
+}
+
+// GetHeatmap returns the heatmap of the key range [startKey, endKey) sampled
+// in [start, end). The number of the key ranges is limited by the config if
+// maxBuckets is non-positive or larger.
+func (h *Handler) GetHeatmap(start, end time.Time, startKey, endKey []byte, maxBuckets int) (*Heatmap, error) {
+	cluster := h.s.GetRaftCluster()
+	if cluster == nil {
+		return nil, errors.Trace(ErrNotBootstrapped)
+	}
+	if limit := int(h.s.cfg.Heatmap.MaxBuckets); maxBuckets <= 0 || maxBuckets > limit {
+		maxBuckets = limit
+	}
+	heatmap := cluster.GetHeatmap(start, end, startKey, endKey, maxBuckets)
+	if heatmap == nil {
+		return nil, errors.Trace(ErrHeatmapDisabled)
+	}
+	return heatmap, nil
+}
+
+// GetAlerts returns the firing alerts found by the last evaluation.
+func (h *Handler) GetAlerts() ([]*AlertStatus, error) {
+	cluster := h.s.GetRaftCluster()
+	if cluster == nil {
+		return nil, errors.Trace(ErrNotBootstrapped)
+	}
+	return cluster.GetAlerts(), nil
+}
+
+// GetAlertSilences returns the alert silences which are not expired.
+func (h *Handler) GetAlertSilences() ([]*core.AlertSilence, error) {
+	cluster := h.s.GetRaftCluster()
+	if cluster == nil {
+		return nil, errors.Trace(ErrNotBootstrapped)
+	}
+	return cluster.GetAlertSilences(), nil
+}
+
+// AddAlertSilence adds an alert silence, the id is allocated.
+func (h *Handler) AddAlertSilence(silence *core.AlertSilence) error {
+	cluster := h.s.GetRaftCluster()
+	if cluster == nil {
+		return errors.Trace(ErrNotBootstrapped)
+	}
+	return errors.Trace(cluster.AddAlertSilence(silence))
+}
+
+// RemoveAlertSilence removes an alert silence.
+func (h *Handler) RemoveAlertSilence(id uint64) error {
+	cluster := h.s.GetRaftCluster()
+	if cluster == nil {
+		return errors.Trace(ErrNotBootstrapped)
+	}
+	return errors.Trace(cluster.RemoveAlertSilence(id))
+}
+
+// GetTS allocates count consecutive timestamps and returns the last one.
+func (h *Handler) GetTS(count uint32) (pdpb.Timestamp, error) {
+	if !h.s.IsLeader() {
+		return pdpb.Timestamp{}, ErrNotLeader
+	}
+	ts, err := h.s.getRespTS(count)
+	return ts, errors.Trace(err)
+}
+
+// AllocID allocates a unique ID.
+func (h *Handler) AllocID() (uint64, error) {
+	if !h.s.IsLeader() {
+		return 0, ErrNotLeader
+	}
+	id, err := h.s.idAlloc.Alloc()
+	return id, errors.Trace(err)
+}
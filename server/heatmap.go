@@ -0,0 +1,221 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/pd/pkg/logutil"
+	"github.com/pingcap/pd/server/core"
+)
+
+// heatmapBucket is the stats of the adjacent regions in a sample.
+type heatmapBucket struct {
+	writtenBytes uint64
+	readBytes    uint64
+	size         uint64
+	keys         uint64
+}
+
+// heatmapColumn is a sample of all the regions, the bucket i covers the key
+// range [keys[i], keys[i+1]).
+type heatmapColumn struct {
+	time    time.Time
+	keys    [][]byte
+	buckets []heatmapBucket
+}
+
+// keyHeatmap keeps the samples in a bounded time window.
+type keyHeatmap struct {
+	sync.RWMutex
+	columns    []*heatmapColumn
+	maxColumns int
+}
+
+func newKeyHeatmap(maxColumns int) *keyHeatmap {
+	if maxColumns < 1 {
+		maxColumns = 1
+	}
+	return &keyHeatmap{maxColumns: maxColumns}
+}
+
+// newHeatmapColumn compacts the regions which are sorted by key into at most
+// maxBuckets buckets, each bucket contains about the same number of regions.
+func newHeatmapColumn(t time.Time, regions []*core.RegionInfo, maxBuckets int) *heatmapColumn {
+	col := &heatmapColumn{time: t}
+	if len(regions) == 0 {
+		return col
+	}
+	n := maxBuckets
+	if n <= 0 || n > len(regions) {
+		n = len(regions)
+	}
+	col.keys = make([][]byte, 0, n+1)
+	col.buckets = make([]heatmapBucket, n)
+	col.keys = append(col.keys, regions[0].GetStartKey())
+	for i := 0; i < n; i++ {
+		from, to := i*len(regions)/n, (i+1)*len(regions)/n
+		b := &col.buckets[i]
+		for _, region := range regions[from:to] {
+			b.writtenBytes += region.WrittenBytes
+			b.readBytes += region.ReadBytes
+			b.size += uint64(region.ApproximateSize)
+			b.keys += uint64(region.ApproximateKeys)
+		}
+		col.keys = append(col.keys, regions[to-1].GetEndKey())
+	}
+	return col
+}
+
+func (h *keyHeatmap) append(col *heatmapColumn) {
+	h.Lock()
+	defer h.Unlock()
+	h.columns = append(h.columns, col)
+	if len(h.columns) > h.maxColumns {
+		h.columns = append(h.columns[:0], h.columns[len(h.columns)-h.maxColumns:]...)
+	}
+}
+
+// Heatmap is the flow and size of the key ranges over time. The key range i
+// is [Keys[i], Keys[i+1]), the values are indexed by the time and then the
+// key range.
+type Heatmap struct {
+	Times        []time.Time
+	Keys         [][]byte
+	WrittenBytes [][]uint64
+	ReadBytes    [][]uint64
+	Size         [][]uint64
+	KeyCount     [][]uint64
+}
+
+// endKeyGreater returns true if the end key is greater than the key, an empty
+// end key means the end of the key space.
+func endKeyGreater(end, key []byte) bool {
+	return len(end) == 0 || bytes.Compare(end, key) > 0
+}
+
+// get returns the samples in [start, end) projected onto at most maxBuckets
+// key ranges which cover [startKey, endKey). The boundaries of the samples
+// may be different as the regions split and merge, so the key ranges are
+// picked from all the boundaries evenly, and the value of a bucket is split
+// evenly into the key ranges it overlaps.
+func (h *keyHeatmap) get(start, end time.Time, startKey, endKey []byte, maxBuckets int) *Heatmap {
+	h.RLock()
+	defer h.RUnlock()
+
+	var cols []*heatmapColumn
+	for _, col := range h.columns {
+		if !col.time.Before(start) && col.time.Before(end) {
+			cols = append(cols, col)
+		}
+	}
+
+	// Collect the boundaries inside the key range.
+	var inner [][]byte
+	for _, col := range cols {
+		for _, key := range col.keys {
+			if bytes.Compare(key, startKey) > 0 && endKeyGreater(endKey, key) {
+				inner = append(inner, key)
+			}
+		}
+	}
+	sort.Slice(inner, func(i, j int) bool { return bytes.Compare(inner[i], inner[j]) < 0 })
+	uniq := inner[:0]
+	for _, key := range inner {
+		if len(uniq) == 0 || !bytes.Equal(uniq[len(uniq)-1], key) {
+			uniq = append(uniq, key)
+		}
+	}
+	if maxBuckets <= 0 {
+		maxBuckets = 1
+	}
+	keys := [][]byte{startKey}
+	if len(uniq) > maxBuckets-1 {
+		for i := 1; i < maxBuckets; i++ {
+			keys = append(keys, uniq[i*len(uniq)/maxBuckets])
+		}
+	} else {
+		keys = append(keys, uniq...)
+	}
+	keys = append(keys, endKey)
+
+	res := &Heatmap{Keys: keys}
+	n := len(keys) - 1
+	for _, col := range cols {
+		res.Times = append(res.Times, col.time)
+		writtenBytes, readBytes := make([]uint64, n), make([]uint64, n)
+		size, keyCount := make([]uint64, n), make([]uint64, n)
+		for i, b := range col.buckets {
+			from, to := col.keys[i], col.keys[i+1]
+			// The key ranges in [lo, hi) overlap with the bucket.
+			lo := sort.Search(n, func(j int) bool { return endKeyGreater(keys[j+1], from) })
+			hi := sort.Search(n, func(j int) bool { return !endKeyGreater(to, keys[j]) })
+			if lo >= hi {
+				continue
+			}
+			spread(writtenBytes[lo:hi], b.writtenBytes)
+			spread(readBytes[lo:hi], b.readBytes)
+			spread(size[lo:hi], b.size)
+			spread(keyCount[lo:hi], b.keys)
+		}
+		res.WrittenBytes = append(res.WrittenBytes, writtenBytes)
+		res.ReadBytes = append(res.ReadBytes, readBytes)
+		res.Size = append(res.Size, size)
+		res.KeyCount = append(res.KeyCount, keyCount)
+	}
+	return res
+}
+
+// spread adds the value to the slots evenly.
+func spread(slots []uint64, v uint64) {
+	n := uint64(len(slots))
+	for i := range slots {
+		slots[i] += v / n
+	}
+	slots[0] += v % n
+}
+
+func (c *RaftCluster) runHeatmapSampler(heatmap *keyHeatmap, interval time.Duration, maxBuckets int) {
+	defer logutil.LogPanic()
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.quit:
+			return
+		case now := <-ticker.C:
+			regions := c.cachedCluster.scanRegionsInRange(nil, nil, 0)
+			heatmap.append(newHeatmapColumn(now, regions, maxBuckets))
+		}
+	}
+}
+
+// GetHeatmap returns the samples in [start, end) which cover the key range
+// [startKey, endKey) with at most maxBuckets key ranges. It returns nil if the
+// sampling is disabled or the cluster is stopped.
+func (c *RaftCluster) GetHeatmap(start, end time.Time, startKey, endKey []byte, maxBuckets int) *Heatmap {
+	c.RLock()
+	heatmap := c.heatmap
+	c.RUnlock()
+	if heatmap == nil {
+		return nil
+	}
+	return heatmap.get(start, end, startKey, endKey, maxBuckets)
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testHeatmapSuite{})
+
+type testHeatmapSuite struct{}
+
+func newHeatmapTestRegions() []*core.RegionInfo {
+	keys := []string{"", "b", "d", "f", ""}
+	var regions []*core.RegionInfo
+	for i := 0; i < len(keys)-1; i++ {
+		region := core.NewRegionInfo(&metapb.Region{
+			Id:       uint64(i + 1),
+			StartKey: []byte(keys[i]),
+			EndKey:   []byte(keys[i+1]),
+		}, nil)
+		region.WrittenBytes = uint64(i+1) * 10
+		region.ApproximateSize = 1
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+func (s *testHeatmapSuite) TestHeatmap(c *C) {
+	t0 := time.Unix(100, 0)
+	t1 := t0.Add(time.Minute)
+	regions := newHeatmapTestRegions()
+
+	h := newKeyHeatmap(2)
+	col := newHeatmapColumn(t0, regions, 2)
+	c.Assert(col.keys, DeepEquals, [][]byte{{}, []byte("d"), {}})
+	c.Assert(col.buckets, HasLen, 2)
+	c.Assert(col.buckets[0].writtenBytes, Equals, uint64(30))
+	c.Assert(col.buckets[1].size, Equals, uint64(2))
+	h.append(col)
+	h.append(newHeatmapColumn(t1, regions, 8))
+
+	// The buckets of the first sample are split evenly.
+	res := h.get(t0, t1.Add(time.Second), nil, nil, 4)
+	c.Assert(res.Times, DeepEquals, []time.Time{t0, t1})
+	c.Assert(res.Keys, DeepEquals, [][]byte{nil, []byte("b"), []byte("d"), []byte("f"), nil})
+	c.Assert(res.WrittenBytes, DeepEquals, [][]uint64{{15, 15, 35, 35}, {10, 20, 30, 40}})
+	c.Assert(res.Size[0], DeepEquals, []uint64{1, 1, 1, 1})
+
+	// The boundaries are picked evenly.
+	res = h.get(t1, t1.Add(time.Second), nil, nil, 2)
+	c.Assert(res.Times, DeepEquals, []time.Time{t1})
+	c.Assert(res.Keys, DeepEquals, [][]byte{nil, []byte("d"), nil})
+	c.Assert(res.WrittenBytes, DeepEquals, [][]uint64{{30, 70}})
+
+	// Only the buckets overlapping with the key range are counted.
+	res = h.get(t1, t1.Add(time.Second), []byte("c"), []byte("e"), 4)
+	c.Assert(res.Keys, DeepEquals, [][]byte{[]byte("c"), []byte("d"), []byte("e")})
+	c.Assert(res.WrittenBytes, DeepEquals, [][]uint64{{20, 30}})
+
+	// The oldest sample is dropped.
+	h.append(newHeatmapColumn(t1.Add(time.Minute), regions, 8))
+	c.Assert(h.get(t0, t1, nil, nil, 4).Times, HasLen, 0)
+}
@@ -80,7 +80,9 @@ func (s *Server) leaderLoop() {
 				}
 			} else {
 				log.Infof("leader is %s, watch it", leader)
+				s.regionSyncer.StartSyncWithLeader(leader)
 				s.watchLeader()
+				s.regionSyncer.StopSyncWithLeader()
 				log.Info("leader changed, try to campaign leader")
 			}
 		}
@@ -163,6 +163,55 @@ var (
 			Help:      "Bucketed histogram of time spend(s) of patrol checks region.",
 			Buckets:   prometheus.ExponentialBuckets(1, 2, 15),
 		})
+
+	regionSyncDroppedCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "region_syncer",
+			Name:      "dropped_regions_total",
+			Help:      "Counter of region changes not sent to the region syncer.",
+		})
+
+	tsoSaveDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "tso_save_duration_seconds",
+			Help:      "Bucketed histogram of time spend(s) of saving timestamp.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 13),
+		})
+
+	clockOffsetGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "clock_offset_seconds",
+			Help:      "Offset of the clocks of members relative to the leader.",
+		}, []string{"member"})
+
+	clockOffsetExceededCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "clock_offset_exceeded_total",
+			Help:      "Counter of probes which find the clock offset of a member exceeds the threshold.",
+		}, []string{"member"})
+
+	certExpiryGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "certificate_expiry_timestamp_seconds",
+			Help:      "Unix time when the TLS certificates expire, the earliest one for the CA.",
+		}, []string{"type"})
+
+	alertNotificationCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "alert_notifications_total",
+			Help:      "Counter of the alert notifications sent to the webhooks.",
+		}, []string{"result"})
 )
 
 func init() {
@@ -183,4 +232,10 @@ func init() {
 	prometheus.MustRegister(metadataGauge)
 	prometheus.MustRegister(etcdStateGauge)
 	prometheus.MustRegister(patrolCheckRegionsHistogram)
+	prometheus.MustRegister(regionSyncDroppedCounter)
+	prometheus.MustRegister(tsoSaveDuration)
+	prometheus.MustRegister(clockOffsetGauge)
+	prometheus.MustRegister(clockOffsetExceededCounter)
+	prometheus.MustRegister(certExpiryGauge)
+	prometheus.MustRegister(alertNotificationCounter)
 }
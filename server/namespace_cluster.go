@@ -87,6 +87,12 @@ func (c *namespaceCluster) RandLeaderRegion(storeID uint64, opts ...core.RegionO
 	return nil
 }
 
+// RandRangeRegion returns a random region in the range which belongs to the
+// namespace.
+func (c *namespaceCluster) RandRangeRegion(startKey, endKey []byte, opts ...core.RegionOption) *core.RegionInfo {
+	return c.Cluster.RandRangeRegion(startKey, endKey, append(opts[:len(opts):len(opts)], c.checkRegion)...)
+}
+
 // GetAverageRegionSize returns the average region approximate size.
 func (c *namespaceCluster) GetAverageRegionSize() int64 {
 	var totalCount, totalSize int64
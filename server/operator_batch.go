@@ -0,0 +1,331 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/schedule"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxOperatorBatchSize is the max number of the operators in a batch.
+	maxOperatorBatchSize = 1024
+	// maxOperatorBatches is the max number of the batches kept by the
+	// coordinator, the oldest ones are forgotten.
+	maxOperatorBatches = 128
+)
+
+// The status of the operators in a batch.
+const (
+	OperatorRunning  = "running"
+	OperatorFinished = "finished"
+	OperatorTimeout  = "timeout"
+	// OperatorCanceled means the operator is removed before it finishes, by
+	// the cancellation of the batch or replaced by another operator.
+	OperatorCanceled = "canceled"
+)
+
+// OperatorBatch collects the operators which are added to the coordinator all
+// or nothing. Each operator is validated when it's added to the batch.
+type OperatorBatch struct {
+	h       *Handler
+	c       *coordinator
+	ops     []*schedule.Operator
+	regions map[uint64]struct{}
+	// epochs are the region epochs expected by the caller.
+	epochs map[uint64]*metapb.RegionEpoch
+}
+
+// NewOperatorBatch creates an empty batch.
+func (h *Handler) NewOperatorBatch() (*OperatorBatch, error) {
+	c, err := h.getCoordinator()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &OperatorBatch{
+		h:       h,
+		c:       c,
+		regions: make(map[uint64]struct{}),
+		epochs:  make(map[uint64]*metapb.RegionEpoch),
+	}, nil
+}
+
+// ExpectRegionEpoch makes the operators of the region added later fail if the
+// region epoch is not the expected one, so the operators are not created on a
+// region which has changed since the caller looked at it.
+func (b *OperatorBatch) ExpectRegionEpoch(regionID uint64, epoch *metapb.RegionEpoch) {
+	b.epochs[regionID] = epoch
+}
+
+// Len returns the number of the operators in the batch.
+func (b *OperatorBatch) Len() int {
+	return len(b.ops)
+}
+
+func (b *OperatorBatch) add(ops []*schedule.Operator, err error) error {
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(b.ops)+len(ops) > maxOperatorBatchSize {
+		return errors.Trace(ErrOperatorBatchTooLarge)
+	}
+	for _, op := range ops {
+		if _, ok := b.regions[op.RegionID()]; ok {
+			return errors.Annotatef(ErrOperatorBatchConflict, "region %v", op.RegionID())
+		}
+		expected, got := b.epochs[op.RegionID()], op.RegionEpoch()
+		if expected != nil && (expected.GetVersion() != got.GetVersion() || expected.GetConfVer() != got.GetConfVer()) {
+			return errors.Annotatef(ErrOperatorBatchRejected, "[region %v] region epoch %v does not match %v", op.RegionID(), got, expected)
+		}
+	}
+	for _, op := range ops {
+		b.regions[op.RegionID()] = struct{}{}
+	}
+	b.ops = append(b.ops, ops...)
+	return nil
+}
+
+// AddTransferLeaderOperator adds an operator to transfer leader to the store.
+func (b *OperatorBatch) AddTransferLeaderOperator(regionID uint64, storeID uint64) error {
+	return b.add(b.h.newTransferLeaderOperator(b.c, regionID, storeID))
+}
+
+// AddTransferRegionOperator adds an operator to transfer region to the stores.
+func (b *OperatorBatch) AddTransferRegionOperator(regionID uint64, storeIDs map[uint64]struct{}) error {
+	return b.add(b.h.newTransferRegionOperator(b.c, regionID, storeIDs))
+}
+
+// AddTransferPeerOperator adds an operator to transfer peer.
+func (b *OperatorBatch) AddTransferPeerOperator(regionID uint64, fromStoreID, toStoreID uint64) error {
+	return b.add(b.h.newTransferPeerOperator(b.c, regionID, fromStoreID, toStoreID))
+}
+
+// AddAddPeerOperator adds an operator to add peer.
+func (b *OperatorBatch) AddAddPeerOperator(regionID uint64, toStoreID uint64) error {
+	return b.add(b.h.newAddPeerOperator(b.c, regionID, toStoreID))
+}
+
+// AddRemovePeerOperator adds an operator to remove peer.
+func (b *OperatorBatch) AddRemovePeerOperator(regionID uint64, fromStoreID uint64) error {
+	return b.add(b.h.newRemovePeerOperator(b.c, regionID, fromStoreID))
+}
+
+// AddMergeRegionOperator adds an operator to merge region.
+func (b *OperatorBatch) AddMergeRegionOperator(regionID uint64, targetID uint64) error {
+	return b.add(b.h.newMergeRegionOperator(b.c, regionID, targetID))
+}
+
+// AddSplitRegionOperator adds an operator to split a region.
+func (b *OperatorBatch) AddSplitRegionOperator(regionID uint64, policy string) error {
+	return b.add(b.h.newSplitRegionOperator(b.c, regionID, policy))
+}
+
+// AddScatterRegionOperator adds an operator to scatter a region.
+func (b *OperatorBatch) AddScatterRegionOperator(regionID uint64) error {
+	return b.add(b.h.newScatterRegionOperator(b.c, regionID))
+}
+
+// operatorBatch is a submitted batch tracked by the coordinator.
+type operatorBatch struct {
+	id         uint64
+	createTime time.Time
+	ops        []*schedule.Operator
+}
+
+// OperatorBatchItem is the status of an operator in a batch.
+type OperatorBatchItem struct {
+	RegionID uint64 `json:"region_id"`
+	Desc     string `json:"desc"`
+	Status   string `json:"status"`
+}
+
+// OperatorBatchStatus is the progress of a batch.
+type OperatorBatchStatus struct {
+	ID         uint64               `json:"id"`
+	CreateTime time.Time            `json:"create_time"`
+	Total      int                  `json:"total"`
+	Running    int                  `json:"running"`
+	Finished   int                  `json:"finished"`
+	Timeout    int                  `json:"timeout"`
+	Canceled   int                  `json:"canceled"`
+	Operators  []*OperatorBatchItem `json:"operators"`
+}
+
+// checkOperatorBatchLocked checks the operators against the regions and the
+// stores, it returns the error of the first operator which can not be added.
+// Like the other operators added by the API, they are not limited by the
+// schedule limits, which are for the schedulers.
+func (c *coordinator) checkOperatorBatchLocked(ops []*schedule.Operator) error {
+	for _, op := range ops {
+		if !c.checkAddOperator(op) {
+			return errors.Annotatef(ErrOperatorBatchRejected, "[region %v] operator %s: the region is changed or has another operator", op.RegionID(), op.Desc())
+		}
+		if err := c.checkOperatorStores(op); err != nil {
+			return errors.Annotatef(ErrOperatorBatchRejected, "[region %v] operator %s: %v", op.RegionID(), op.Desc(), err)
+		}
+	}
+	return nil
+}
+
+// checkOperatorStores checks if the stores which the operator adds peers or
+// transfers leader to are available.
+func (c *coordinator) checkOperatorStores(op *schedule.Operator) error {
+	leaderFilters := []schedule.Filter{
+		schedule.NewStateFilter(),
+		schedule.NewHealthFilter(),
+		schedule.NewDisconnectFilter(),
+		schedule.NewRejectLeaderFilter(),
+	}
+	peerFilters := []schedule.Filter{
+		schedule.NewStateFilter(),
+		schedule.NewHealthFilter(),
+		schedule.NewDisconnectFilter(),
+		schedule.NewPendingPeerCountFilter(),
+		schedule.NewSnapshotCountFilter(),
+	}
+	for i := 0; i < op.Len(); i++ {
+		var (
+			storeID uint64
+			filters []schedule.Filter
+		)
+		switch step := op.Step(i).(type) {
+		case schedule.TransferLeader:
+			storeID, filters = step.ToStore, leaderFilters
+		case schedule.AddPeer:
+			storeID, filters = step.ToStore, peerFilters
+		case schedule.AddLearner:
+			storeID, filters = step.ToStore, peerFilters
+		default:
+			continue
+		}
+		store := c.cluster.GetStore(storeID)
+		if store == nil {
+			return core.NewStoreNotFoundErr(storeID)
+		}
+		for _, f := range filters {
+			if f.FilterTarget(c.cluster, store) {
+				return errors.Errorf("store %v is rejected by %s", storeID, f.Type())
+			}
+		}
+	}
+	return nil
+}
+
+// addOperatorBatch adds the operators all or nothing, and tracks them as a
+// batch.
+func (c *coordinator) addOperatorBatch(ops []*schedule.Operator) (uint64, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	if err := c.checkOperatorBatchLocked(ops); err != nil {
+		for _, op := range ops {
+			operatorCounter.WithLabelValues(op.Desc(), "canceled").Inc()
+		}
+		return 0, err
+	}
+	c.addOperatorsLocked(ops...)
+	batch := &operatorBatch{
+		id:         c.nextBatchID,
+		createTime: time.Now(),
+		ops:        ops,
+	}
+	c.nextBatchID++
+	c.batches = append(c.batches, batch)
+	if len(c.batches) > maxOperatorBatches {
+		c.batches = c.batches[len(c.batches)-maxOperatorBatches:]
+	}
+	log.Infof("add operator batch %v with %v operators", batch.id, len(ops))
+	return batch.id, nil
+}
+
+func (c *coordinator) getOperatorBatchLocked(id uint64) *operatorBatch {
+	for _, batch := range c.batches {
+		if batch.id == id {
+			return batch
+		}
+	}
+	return nil
+}
+
+func (c *coordinator) operatorBatchStatusLocked(batch *operatorBatch) *OperatorBatchStatus {
+	status := &OperatorBatchStatus{
+		ID:         batch.id,
+		CreateTime: batch.createTime,
+		Total:      len(batch.ops),
+		Operators:  make([]*OperatorBatchItem, 0, len(batch.ops)),
+	}
+	for _, op := range batch.ops {
+		item := &OperatorBatchItem{RegionID: op.RegionID(), Desc: op.Desc()}
+		switch {
+		case op.IsFinish():
+			item.Status = OperatorFinished
+			status.Finished++
+		case op.IsTimeout():
+			item.Status = OperatorTimeout
+			status.Timeout++
+		case c.operators[op.RegionID()] == op:
+			item.Status = OperatorRunning
+			status.Running++
+		default:
+			item.Status = OperatorCanceled
+			status.Canceled++
+		}
+		status.Operators = append(status.Operators, item)
+	}
+	return status
+}
+
+// getOperatorBatches returns the status of all the batches.
+func (c *coordinator) getOperatorBatches() []*OperatorBatchStatus {
+	c.RLock()
+	defer c.RUnlock()
+	res := make([]*OperatorBatchStatus, 0, len(c.batches))
+	for _, batch := range c.batches {
+		res = append(res, c.operatorBatchStatusLocked(batch))
+	}
+	return res
+}
+
+// getOperatorBatch returns the status of a batch, it returns nil if the batch
+// is not found.
+func (c *coordinator) getOperatorBatch(id uint64) *OperatorBatchStatus {
+	c.RLock()
+	defer c.RUnlock()
+	if batch := c.getOperatorBatchLocked(id); batch != nil {
+		return c.operatorBatchStatusLocked(batch)
+	}
+	return nil
+}
+
+// cancelOperatorBatch removes the running operators of a batch.
+func (c *coordinator) cancelOperatorBatch(id uint64) bool {
+	c.Lock()
+	defer c.Unlock()
+	batch := c.getOperatorBatchLocked(id)
+	if batch == nil {
+		return false
+	}
+	for _, op := range batch.ops {
+		if c.operators[op.RegionID()] == op && !op.IsFinish() {
+			c.removeOperatorLocked(op)
+		}
+	}
+	log.Infof("cancel operator batch %v", id)
+	return true
+}
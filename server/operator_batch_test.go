@@ -0,0 +1,117 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/namespace"
+	"github.com/pingcap/pd/server/schedule"
+)
+
+var _ = Suite(&testOperatorBatchSuite{})
+
+type testOperatorBatchSuite struct{}
+
+func newTestBatchOperator(regionID uint64, regionEpoch *metapb.RegionEpoch) *schedule.Operator {
+	step := schedule.TransferLeader{FromStore: 1, ToStore: 2}
+	return schedule.NewOperator("test", regionID, regionEpoch, schedule.OpAdmin|schedule.OpLeader, step)
+}
+
+func (s *testOperatorBatchSuite) TestOperatorBatch(c *C) {
+	_, opt := newTestScheduleConfig()
+	tc := newTestClusterInfo(opt)
+	hbStreams := newHeartbeatStreams(tc.clusterInfo.getClusterID())
+	defer hbStreams.Close()
+
+	co := newCoordinator(tc.clusterInfo, hbStreams, namespace.DefaultClassifier)
+	tc.addRegionStore(1, 2)
+	tc.addRegionStore(2, 0)
+	tc.addLeaderRegion(1, 1)
+	tc.addLeaderRegion(2, 1)
+
+	// Nothing is added if any operator is stale.
+	op1 := newTestBatchOperator(1, tc.GetRegion(1).GetRegionEpoch())
+	stale := newTestBatchOperator(2, &metapb.RegionEpoch{})
+	_, err := co.addOperatorBatch([]*schedule.Operator{op1, stale})
+	c.Assert(err, NotNil)
+	c.Assert(co.getOperator(1), IsNil)
+	c.Assert(co.getOperatorBatches(), HasLen, 0)
+
+	op2 := newTestBatchOperator(2, tc.GetRegion(2).GetRegionEpoch())
+	id, err := co.addOperatorBatch([]*schedule.Operator{op1, op2})
+	c.Assert(err, IsNil)
+	status := co.getOperatorBatch(id)
+	c.Assert(status.Total, Equals, 2)
+	c.Assert(status.Running, Equals, 2)
+
+	// The operator replaced by another one is canceled.
+	op3 := newTestBatchOperator(2, tc.GetRegion(2).GetRegionEpoch())
+	op3.SetPriorityLevel(core.HighPriority)
+	c.Assert(co.addOperator(op3), IsTrue)
+	status = co.getOperatorBatch(id)
+	c.Assert(status.Running, Equals, 1)
+	c.Assert(status.Canceled, Equals, 1)
+	c.Assert(status.Operators[1].Status, Equals, OperatorCanceled)
+
+	// Canceling the batch keeps the operators of the others.
+	c.Assert(co.cancelOperatorBatch(id), IsTrue)
+	c.Assert(co.getOperator(1), IsNil)
+	c.Assert(co.getOperator(2), Equals, op3)
+	c.Assert(co.getOperatorBatch(id).Canceled, Equals, 2)
+
+	c.Assert(co.getOperatorBatch(id+1), IsNil)
+	c.Assert(co.cancelOperatorBatch(id+1), IsFalse)
+}
+
+func (s *testOperatorBatchSuite) TestCheckOperatorBatch(c *C) {
+	cfg, opt := newTestScheduleConfig()
+	cfg.LeaderScheduleLimit = 1
+	tc := newTestClusterInfo(opt)
+	hbStreams := newHeartbeatStreams(tc.clusterInfo.getClusterID())
+	defer hbStreams.Close()
+
+	co := newCoordinator(tc.clusterInfo, hbStreams, namespace.DefaultClassifier)
+	tc.addRegionStore(1, 3)
+	tc.addRegionStore(2, 0)
+	for id := uint64(1); id <= 3; id++ {
+		tc.addLeaderRegion(id, 1)
+	}
+	newOp := func(regionID uint64, toStore uint64) *schedule.Operator {
+		step := schedule.TransferLeader{FromStore: 1, ToStore: toStore}
+		return schedule.NewOperator("test", regionID, tc.GetRegion(regionID).GetRegionEpoch(), schedule.OpAdmin|schedule.OpLeader, step)
+	}
+
+	// The error tells which operator fails.
+	_, err := co.addOperatorBatch([]*schedule.Operator{newOp(1, 2), newOp(2, 3)})
+	c.Assert(err, ErrorMatches, `\[region 2\] operator test: .*store 3 not found.*: operator batch is rejected`)
+	tc.addRegionStore(3, 0)
+	tc.setStoreOffline(3)
+	_, err = co.addOperatorBatch([]*schedule.Operator{newOp(1, 2), newOp(2, 3)})
+	c.Assert(err, ErrorMatches, `\[region 2\] operator test: store 3 is rejected by state-filter: operator batch is rejected`)
+
+	// The batch is not limited by the schedule limits.
+	c.Assert(co.addOperator(newOp(1, 2)), IsTrue)
+	_, err = co.addOperatorBatch([]*schedule.Operator{newOp(2, 2), newOp(3, 2)})
+	c.Assert(err, IsNil)
+	// The running operator is replaced only by a higher priority one.
+	_, err = co.addOperatorBatch([]*schedule.Operator{newOp(1, 2)})
+	c.Assert(errors.Cause(err), Equals, ErrOperatorBatchRejected)
+	op := newOp(1, 2)
+	op.SetPriorityLevel(core.HighPriority)
+	_, err = co.addOperatorBatch([]*schedule.Operator{op})
+	c.Assert(err, IsNil)
+}
@@ -15,6 +15,7 @@ package server
 
 import (
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -32,6 +33,11 @@ type scheduleOption struct {
 	ns             map[string]*namespaceOption
 	labelProperty  atomic.Value
 	clusterVersion atomic.Value
+
+	// persistLock serializes the persisting so that the revisions are
+	// saved in order.
+	persistLock sync.Mutex
+	revision    uint64
 }
 
 func newScheduleOption(cfg *Config) *scheduleOption {
@@ -256,7 +262,16 @@ func (o *scheduleOption) loadClusterVersion() semver.Version {
 	return o.clusterVersion.Load().(semver.Version)
 }
 
+func (o *scheduleOption) loadRevision() uint64 {
+	return atomic.LoadUint64(&o.revision)
+}
+
+// persist saves the config with a new revision, and keeps the revision in
+// the history.
 func (o *scheduleOption) persist(kv *core.KV) error {
+	o.persistLock.Lock()
+	defer o.persistLock.Unlock()
+
 	namespaces := make(map[string]NamespaceConfig)
 	for name, ns := range o.ns {
 		namespaces[name] = *ns.load()
@@ -267,9 +282,13 @@ func (o *scheduleOption) persist(kv *core.KV) error {
 		Namespace:      namespaces,
 		LabelProperty:  o.loadLabelPropertyConfig(),
 		ClusterVersion: o.loadClusterVersion(),
+		ConfigRevision: o.loadRevision() + 1,
+	}
+	if err := kv.SaveConfig(cfg); err != nil {
+		return errors.Trace(err)
 	}
-	err := kv.SaveConfig(cfg)
-	return errors.Trace(err)
+	atomic.StoreUint64(&o.revision, cfg.ConfigRevision)
+	return errors.Trace(saveConfigRevision(kv, cfg))
 }
 
 func (o *scheduleOption) reload(kv *core.KV) error {
@@ -298,6 +317,7 @@ func (o *scheduleOption) reload(kv *core.KV) error {
 		}
 		o.labelProperty.Store(cfg.LabelProperty)
 		o.clusterVersion.Store(cfg.ClusterVersion)
+		atomic.StoreUint64(&o.revision, cfg.ConfigRevision)
 	}
 	return nil
 }
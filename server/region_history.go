@@ -0,0 +1,213 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/server/cache"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/schedule"
+)
+
+const (
+	// maxHistoryRegions is the max count of regions whose history is kept,
+	// the least recently changed regions are evicted first.
+	maxHistoryRegions = 10000
+	// maxHistoryChangesPerRegion is the max count of changes kept for a region.
+	maxHistoryChangesPerRegion = 32
+)
+
+// RegionChangeKind is the kind of a region change.
+type RegionChangeKind string
+
+// Region change kinds.
+const (
+	RegionChangeCreate  RegionChangeKind = "create"
+	RegionChangeVersion RegionChangeKind = "version-change"
+	RegionChangeConfVer RegionChangeKind = "conf-ver-change"
+	RegionChangeLeader  RegionChangeKind = "leader-change"
+	RegionChangeSplit   RegionChangeKind = "split"
+	RegionChangeMerge   RegionChangeKind = "merge"
+)
+
+// RegionChange records a change of a region.
+type RegionChange struct {
+	Time       time.Time           `json:"time"`
+	Kind       RegionChangeKind    `json:"kind"`
+	FromEpoch  *metapb.RegionEpoch `json:"from_epoch,omitempty"`
+	ToEpoch    *metapb.RegionEpoch `json:"to_epoch,omitempty"`
+	FromLeader *metapb.Peer        `json:"from_leader,omitempty"`
+	ToLeader   *metapb.Peer        `json:"to_leader,omitempty"`
+	// Related is the parent region of a split child, the children of a split
+	// parent, the source regions of a merge target or the target of a merged
+	// source region.
+	Related  []uint64 `json:"related,omitempty"`
+	Operator string   `json:"operator,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// regionHistory keeps a bounded history of the changes of regions.
+type regionHistory struct {
+	sync.Mutex
+	changes cache.Cache
+	// getOperator returns the running operator of a region, which is
+	// regarded as the cause of the changes.
+	getOperator func(regionID uint64) *schedule.Operator
+	now         func() time.Time
+}
+
+func newRegionHistory(getOperator func(regionID uint64) *schedule.Operator) *regionHistory {
+	return &regionHistory{
+		changes:     cache.NewCache(maxHistoryRegions, cache.LRUCache),
+		getOperator: getOperator,
+		now:         time.Now,
+	}
+}
+
+// observe records the changes between the origin region and the region
+// reported by heartbeat. overlaps are the regions replaced by the region.
+func (h *regionHistory) observe(origin, region *core.RegionInfo, overlaps []*metapb.Region) {
+	now := h.now()
+	operator := h.operatorDesc(region.GetId())
+	overlapIDs := make([]uint64, 0, len(overlaps))
+	for _, item := range overlaps {
+		overlapIDs = append(overlapIDs, item.GetId())
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	if origin == nil {
+		h.appendLocked(region.GetId(), &RegionChange{
+			Time:     now,
+			Kind:     RegionChangeCreate,
+			ToEpoch:  region.GetRegionEpoch(),
+			ToLeader: region.Leader,
+			Related:  overlapIDs,
+			Operator: operator,
+			Detail:   fmt.Sprintf("StartKey:%q, EndKey:%q", region.GetStartKey(), region.GetEndKey()),
+		})
+		return
+	}
+
+	r, o := region.GetRegionEpoch(), origin.GetRegionEpoch()
+	if r.GetVersion() > o.GetVersion() {
+		change := &RegionChange{
+			Time:      now,
+			Kind:      RegionChangeVersion,
+			FromEpoch: o,
+			ToEpoch:   r,
+			Operator:  operator,
+			Detail:    core.DiffRegionKeyInfo(origin, region),
+		}
+		if len(overlapIDs) > 0 {
+			change.Kind = RegionChangeMerge
+			change.Related = overlapIDs
+			for _, id := range overlapIDs {
+				h.appendLocked(id, &RegionChange{
+					Time:     now,
+					Kind:     RegionChangeMerge,
+					Related:  []uint64{region.GetId()},
+					Operator: h.operatorDesc(id),
+					Detail:   fmt.Sprintf("merged into region %d", region.GetId()),
+				})
+			}
+		}
+		h.appendLocked(region.GetId(), change)
+	}
+	if r.GetConfVer() > o.GetConfVer() {
+		h.appendLocked(region.GetId(), &RegionChange{
+			Time:      now,
+			Kind:      RegionChangeConfVer,
+			FromEpoch: o,
+			ToEpoch:   r,
+			Operator:  operator,
+			Detail:    core.DiffRegionPeersInfo(origin, region),
+		})
+	}
+	if region.Leader.GetId() != origin.Leader.GetId() {
+		h.appendLocked(region.GetId(), &RegionChange{
+			Time:       now,
+			Kind:       RegionChangeLeader,
+			FromLeader: origin.Leader,
+			ToLeader:   region.Leader,
+			Operator:   operator,
+		})
+	}
+}
+
+// observeSplit records the split reported by TiKV, the left region is the
+// newly generated one and the right region keeps the origin region ID.
+func (h *regionHistory) observeSplit(left, right *metapb.Region) {
+	now := h.now()
+	operator := h.operatorDesc(right.GetId())
+
+	h.Lock()
+	defer h.Unlock()
+	h.appendLocked(right.GetId(), &RegionChange{
+		Time:     now,
+		Kind:     RegionChangeSplit,
+		ToEpoch:  right.GetRegionEpoch(),
+		Related:  []uint64{left.GetId()},
+		Operator: operator,
+		Detail:   fmt.Sprintf("split at %q", right.GetStartKey()),
+	})
+	h.appendLocked(left.GetId(), &RegionChange{
+		Time:     now,
+		Kind:     RegionChangeSplit,
+		ToEpoch:  left.GetRegionEpoch(),
+		Related:  []uint64{right.GetId()},
+		Operator: operator,
+		Detail:   fmt.Sprintf("split from region %d", right.GetId()),
+	})
+}
+
+// getHistory returns the changes of a region from the oldest to the latest.
+func (h *regionHistory) getHistory(regionID uint64) []*RegionChange {
+	h.Lock()
+	defer h.Unlock()
+	v, ok := h.changes.Peek(regionID)
+	if !ok {
+		return nil
+	}
+	changes := v.([]*RegionChange)
+	return append(changes[:0:0], changes...)
+}
+
+func (h *regionHistory) appendLocked(regionID uint64, change *RegionChange) {
+	var changes []*RegionChange
+	if v, ok := h.changes.Get(regionID); ok {
+		changes = v.([]*RegionChange)
+	}
+	if len(changes) >= maxHistoryChangesPerRegion {
+		changes = changes[len(changes)-maxHistoryChangesPerRegion+1:]
+	}
+	// Always copy to avoid racing with the slices returned by getHistory.
+	changes = append(changes[:len(changes):len(changes)], change)
+	h.changes.Put(regionID, changes)
+}
+
+func (h *regionHistory) operatorDesc(regionID uint64) string {
+	if h.getOperator == nil {
+		return ""
+	}
+	op := h.getOperator(regionID)
+	if op == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (kind:%s)", op.Desc(), op.Kind())
+}
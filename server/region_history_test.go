@@ -0,0 +1,126 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"github.com/gogo/protobuf/proto"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/server/core"
+	"github.com/pingcap/pd/server/schedule"
+)
+
+var _ = Suite(&testRegionHistorySuite{})
+
+type testRegionHistorySuite struct{}
+
+func newHistoryTestRegion(id uint64, start, end string, version, confVer uint64, leaderStore uint64) *core.RegionInfo {
+	peers := []*metapb.Peer{
+		{Id: id*10 + 1, StoreId: 1},
+		{Id: id*10 + 2, StoreId: 2},
+		{Id: id*10 + 3, StoreId: 3},
+	}
+	return core.NewRegionInfo(&metapb.Region{
+		Id:          id,
+		StartKey:    []byte(start),
+		EndKey:      []byte(end),
+		RegionEpoch: &metapb.RegionEpoch{Version: version, ConfVer: confVer},
+		Peers:       peers,
+	}, peers[leaderStore-1])
+}
+
+func (s *testRegionHistorySuite) TestObserve(c *C) {
+	ops := make(map[uint64]*schedule.Operator)
+	h := newRegionHistory(func(regionID uint64) *schedule.Operator { return ops[regionID] })
+
+	r1 := newHistoryTestRegion(1, "a", "b", 1, 1, 1)
+	h.observe(nil, r1, nil)
+	changes := h.getHistory(1)
+	c.Assert(changes, HasLen, 1)
+	c.Assert(changes[0].Kind, Equals, RegionChangeCreate)
+	c.Assert(changes[0].Operator, Equals, "")
+
+	// Transfer leader with an operator.
+	ops[1] = schedule.NewOperator("transfer-leader", 1, r1.GetRegionEpoch(), schedule.OpLeader)
+	r2 := newHistoryTestRegion(1, "a", "b", 1, 1, 2)
+	h.observe(r1, r2, nil)
+	changes = h.getHistory(1)
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[1].Kind, Equals, RegionChangeLeader)
+	c.Assert(changes[1].FromLeader.GetStoreId(), Equals, uint64(1))
+	c.Assert(changes[1].ToLeader.GetStoreId(), Equals, uint64(2))
+	c.Assert(changes[1].Operator, Equals, "transfer-leader (kind:leader)")
+	delete(ops, 1)
+
+	// Both version and conf version are changed.
+	r3 := newHistoryTestRegion(1, "a", "c", 2, 2, 2)
+	r4 := newHistoryTestRegion(2, "b", "c", 1, 1, 1)
+	h.observe(nil, r4, nil)
+	h.observe(r2, r3, []*metapb.Region{r4.Region})
+	changes = h.getHistory(1)
+	c.Assert(changes, HasLen, 4)
+	c.Assert(changes[2].Kind, Equals, RegionChangeMerge)
+	c.Assert(changes[2].Related, DeepEquals, []uint64{2})
+	c.Assert(changes[2].FromEpoch.GetVersion(), Equals, uint64(1))
+	c.Assert(changes[2].ToEpoch.GetVersion(), Equals, uint64(2))
+	c.Assert(changes[3].Kind, Equals, RegionChangeConfVer)
+	changes = h.getHistory(2)
+	c.Assert(changes, HasLen, 2)
+	c.Assert(changes[1].Kind, Equals, RegionChangeMerge)
+	c.Assert(changes[1].Related, DeepEquals, []uint64{1})
+
+	// Split region 1 into 3 and 1.
+	left := proto.Clone(r3.Region).(*metapb.Region)
+	left.Id, left.EndKey = 3, []byte("b")
+	right := proto.Clone(r3.Region).(*metapb.Region)
+	right.StartKey = []byte("b")
+	right.RegionEpoch.Version = 3
+	h.observeSplit(left, right)
+	changes = h.getHistory(1)
+	c.Assert(changes, HasLen, 5)
+	c.Assert(changes[4].Kind, Equals, RegionChangeSplit)
+	c.Assert(changes[4].Related, DeepEquals, []uint64{3})
+	changes = h.getHistory(3)
+	c.Assert(changes, HasLen, 1)
+	c.Assert(changes[0].Kind, Equals, RegionChangeSplit)
+	c.Assert(changes[0].Related, DeepEquals, []uint64{1})
+
+	// The heartbeat of the split region only updates the version.
+	r5 := core.NewRegionInfo(right, r3.Leader)
+	h.observe(r3, r5, nil)
+	changes = h.getHistory(1)
+	c.Assert(changes, HasLen, 6)
+	c.Assert(changes[5].Kind, Equals, RegionChangeVersion)
+
+	c.Assert(h.getHistory(4), HasLen, 0)
+}
+
+func (s *testRegionHistorySuite) TestLimit(c *C) {
+	h := newRegionHistory(nil)
+	origin := newHistoryTestRegion(1, "a", "b", 1, 1, 1)
+	h.observe(nil, origin, nil)
+	for i := 0; i < maxHistoryChangesPerRegion*2; i++ {
+		region := newHistoryTestRegion(1, "a", "b", 1, 1, uint64((i+1)%2+1))
+		h.observe(origin, region, nil)
+		origin = region
+	}
+	changes := h.getHistory(1)
+	c.Assert(len(changes), Equals, maxHistoryChangesPerRegion)
+	c.Assert(changes[0].Kind, Equals, RegionChangeLeader)
+
+	// The returned history is not affected by new changes.
+	h.observe(origin, newHistoryTestRegion(1, "a", "b", 2, 1, 1), nil)
+	c.Assert(changes[len(changes)-1].Kind, Equals, RegionChangeLeader)
+	c.Assert(h.getHistory(1)[maxHistoryChangesPerRegion-1].Kind, Equals, RegionChangeVersion)
+}
@@ -15,6 +15,7 @@ package server
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/pingcap/pd/server/core"
 	"github.com/pingcap/pd/server/namespace"
@@ -33,6 +34,7 @@ const (
 )
 
 type regionStatistics struct {
+	sync.RWMutex
 	opt        *scheduleOption
 	classifier namespace.Classifier
 	stats      map[regionStatisticType]map[uint64]*core.RegionInfo
@@ -57,6 +59,8 @@ func newRegionStatistics(opt *scheduleOption, classifier namespace.Classifier) *
 }
 
 func (r *regionStatistics) getRegionStatsByType(typ regionStatisticType) []*core.RegionInfo {
+	r.RLock()
+	defer r.RUnlock()
 	res := make([]*core.RegionInfo, 0, len(r.stats[typ]))
 	for _, r := range r.stats[typ] {
 		res = append(res, r.Clone())
@@ -80,6 +84,8 @@ func (r *regionStatistics) Observe(region *core.RegionInfo, stores []*core.Store
 		peerTypeIndex regionStatisticType
 		deleteIndex   regionStatisticType
 	)
+	r.Lock()
+	defer r.Unlock()
 	if len(region.Peers) < r.opt.GetMaxReplicas(namespace) {
 		r.stats[missPeer][regionID] = region
 		peerTypeIndex |= missPeer
@@ -128,12 +134,16 @@ func (r *regionStatistics) Observe(region *core.RegionInfo, stores []*core.Store
 }
 
 func (r *regionStatistics) clearDefunctRegion(regionID uint64) {
+	r.Lock()
+	defer r.Unlock()
 	if oldIndex, ok := r.index[regionID]; ok {
 		r.deleteEntry(oldIndex, regionID)
 	}
 }
 
 func (r *regionStatistics) Collect() {
+	r.RLock()
+	defer r.RUnlock()
 	regionStatusGauge.WithLabelValues("miss_peer_region_count").Set(float64(len(r.stats[missPeer])))
 	regionStatusGauge.WithLabelValues("extra_peer_region_count").Set(float64(len(r.stats[extraPeer])))
 	regionStatusGauge.WithLabelValues("down_peer_region_count").Set(float64(len(r.stats[downPeer])))
@@ -144,6 +154,7 @@ func (r *regionStatistics) Collect() {
 }
 
 type labelLevelStatistics struct {
+	sync.Mutex
 	regionLabelLevelStats map[uint64]int
 	labelLevelCounter     map[int]int
 }
@@ -158,6 +169,8 @@ func newLabelLevelStatistics() *labelLevelStatistics {
 func (l *labelLevelStatistics) Observe(region *core.RegionInfo, stores []*core.StoreInfo, labels []string) {
 	regionID := region.GetId()
 	regionLabelLevel := getRegionLabelIsolationLevel(stores, labels)
+	l.Lock()
+	defer l.Unlock()
 	if level, ok := l.regionLabelLevelStats[regionID]; ok {
 		if level == regionLabelLevel {
 			return
@@ -169,6 +182,8 @@ func (l *labelLevelStatistics) Observe(region *core.RegionInfo, stores []*core.S
 }
 
 func (l *labelLevelStatistics) Collect() {
+	l.Lock()
+	defer l.Unlock()
 	for level, count := range l.labelLevelCounter {
 		typ := fmt.Sprintf("level_%d", level)
 		regionLabelLevelGauge.WithLabelValues(typ).Set(float64(count))
@@ -176,6 +191,8 @@ func (l *labelLevelStatistics) Collect() {
 }
 
 func (l *labelLevelStatistics) clearDefunctRegion(regionID uint64) {
+	l.Lock()
+	defer l.Unlock()
 	if level, ok := l.regionLabelLevelStats[regionID]; ok {
 		l.labelLevelCounter[level]--
 		delete(l.regionLabelLevelStats, regionID)
@@ -0,0 +1,172 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncer
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/logutil"
+	"github.com/pingcap/pd/pkg/pdinternalpb"
+	"github.com/pingcap/pd/server/core"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// StartSyncWithLeader starts to sync regions from the leader in background.
+func (s *RegionSyncer) StartSyncWithLeader(leader *pdpb.Member) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	if s.mu.leaderID != leader.GetMemberId() {
+		// History indexes are only meaningful for the same leader.
+		s.mu.leaderID = leader.GetMemberId()
+		s.mu.nextIndex = 0
+	}
+	s.mu.cancel = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer logutil.LogPanic()
+		defer s.wg.Done()
+		for {
+			err := s.syncWithLeader(ctx, leader)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Warnf("region syncer failed to sync with leader %s: %v", leader.GetName(), err)
+				syncEventCounter.WithLabelValues("error").Inc()
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(syncerRetryTimeout):
+			}
+		}
+	}()
+}
+
+// StopSyncWithLeader stops syncing regions from the leader.
+func (s *RegionSyncer) StopSyncWithLeader() {
+	s.mu.Lock()
+	if s.mu.cancel != nil {
+		s.mu.cancel()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *RegionSyncer) syncWithLeader(ctx context.Context, leader *pdpb.Member) error {
+	if len(leader.GetClientUrls()) == 0 {
+		return errors.Errorf("leader %s has no client url", leader.GetName())
+	}
+	u, err := url.Parse(leader.GetClientUrls()[0])
+	if err != nil {
+		return errors.Trace(err)
+	}
+	opt := grpc.WithInsecure()
+	if s.creds != nil {
+		opt = grpc.WithTransportCredentials(s.creds)
+	}
+	cc, err := grpc.DialContext(ctx, u.Host, opt)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer cc.Close()
+
+	s.mu.RLock()
+	startIndex := s.mu.nextIndex
+	s.mu.RUnlock()
+	stream, err := pdinternalpb.NewRegionSyncClient(cc).SyncRegions(ctx, &pdinternalpb.SyncRegionRequest{
+		Header:     &pdpb.RequestHeader{ClusterId: s.server.ClusterID()},
+		Member:     &pdpb.Member{Name: s.server.Name(), MemberId: s.server.ID()},
+		StartIndex: startIndex,
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	log.Infof("region syncer starts to sync with leader %s from index %d", leader.GetName(), startIndex)
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		s.apply(resp)
+	}
+}
+
+func (s *RegionSyncer) apply(resp *pdinternalpb.SyncRegionResponse) {
+	var kv *core.KV
+	if regionKV := s.server.GetStorage().GetRegionKV(); regionKV != nil {
+		kv = core.NewKV(regionKV)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, r := range resp.Regions {
+		var leader *metapb.Peer
+		if i < len(resp.Leaders) && resp.Leaders[i].GetId() != 0 {
+			leader = resp.Leaders[i]
+		}
+		overlaps := s.mu.regions.SetRegion(core.NewRegionInfo(r, leader))
+		if kv == nil {
+			continue
+		}
+		if err := kv.SaveRegion(r); err != nil {
+			log.Errorf("region syncer failed to save region %d: %v", r.GetId(), err)
+		}
+		for _, item := range overlaps {
+			if err := kv.DeleteRegion(item); err != nil {
+				log.Errorf("region syncer failed to delete region %d: %v", item.GetId(), err)
+			}
+		}
+	}
+	if resp.NextIndex > s.mu.nextIndex {
+		s.mu.nextIndex = resp.NextIndex
+	}
+	if s.mu.nextIndex >= resp.LeaderIndex {
+		s.mu.lastCaughtUp = time.Now()
+	}
+	syncIndexGauge.WithLabelValues("follower").Set(float64(s.mu.nextIndex))
+	if resp.LeaderIndex > s.mu.nextIndex {
+		syncIndexGauge.WithLabelValues("lag").Set(float64(resp.LeaderIndex - s.mu.nextIndex))
+	} else {
+		syncIndexGauge.WithLabelValues("lag").Set(0)
+	}
+	syncLagGauge.Set(time.Since(s.mu.lastCaughtUp).Seconds())
+}
+
+// GetRegionCount returns the count of synced regions.
+func (s *RegionSyncer) GetRegionCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.mu.regions.GetRegionCount()
+}
+
+// TakeRegions returns the synced regions and resets the syncer, it is used
+// when the server becomes leader.
+func (s *RegionSyncer) TakeRegions() []*core.RegionInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	regions := s.mu.regions.GetRegions()
+	s.mu.regions = core.NewRegionsInfo()
+	s.mu.leaderID = 0
+	s.mu.nextIndex = 0
+	return regions
+}
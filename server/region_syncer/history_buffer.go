@@ -0,0 +1,71 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncer
+
+import (
+	"sync"
+
+	"github.com/pingcap/pd/server/core"
+)
+
+// historyBuffer keeps the latest region changes in a ring, each change is
+// identified by a monotonically increasing index starting from 1.
+type historyBuffer struct {
+	sync.RWMutex
+	records   []*core.RegionInfo
+	nextIndex uint64
+}
+
+func newHistoryBuffer(size int) *historyBuffer {
+	return &historyBuffer{
+		records:   make([]*core.RegionInfo, size),
+		nextIndex: 1,
+	}
+}
+
+// firstIndexLocked returns the index of the oldest record kept.
+func (h *historyBuffer) firstIndexLocked() uint64 {
+	if size := uint64(len(h.records)); h.nextIndex > size {
+		return h.nextIndex - size
+	}
+	return 1
+}
+
+func (h *historyBuffer) record(r *core.RegionInfo) {
+	h.Lock()
+	defer h.Unlock()
+	h.records[h.nextIndex%uint64(len(h.records))] = r
+	h.nextIndex++
+}
+
+func (h *historyBuffer) getNextIndex() uint64 {
+	h.RLock()
+	defer h.RUnlock()
+	return h.nextIndex
+}
+
+// recordsFrom returns the records since index. The second return value is
+// false if the records have been discarded.
+func (h *historyBuffer) recordsFrom(index uint64) ([]*core.RegionInfo, bool) {
+	h.RLock()
+	defer h.RUnlock()
+	if index < h.firstIndexLocked() || index > h.nextIndex {
+		return nil, false
+	}
+	records := make([]*core.RegionInfo, 0, h.nextIndex-index)
+	for i := index; i < h.nextIndex; i++ {
+		records = append(records, h.records[i%uint64(len(h.records))])
+	}
+	return records, true
+}
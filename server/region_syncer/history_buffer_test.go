@@ -0,0 +1,69 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncer
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/server/core"
+)
+
+func TestSyncer(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testHistoryBufferSuite{})
+
+type testHistoryBufferSuite struct{}
+
+func (s *testHistoryBufferSuite) TestBufferSize(c *C) {
+	var regions []*core.RegionInfo
+	for i := 0; i < 100; i++ {
+		regions = append(regions, core.NewRegionInfo(&metapb.Region{Id: uint64(i)}, nil))
+	}
+
+	h := newHistoryBuffer(10)
+	c.Assert(h.getNextIndex(), Equals, uint64(1))
+	records, ok := h.recordsFrom(1)
+	c.Assert(ok, IsTrue)
+	c.Assert(records, HasLen, 0)
+	_, ok = h.recordsFrom(2)
+	c.Assert(ok, IsFalse)
+
+	for i := 0; i < 5; i++ {
+		h.record(regions[i])
+	}
+	records, ok = h.recordsFrom(1)
+	c.Assert(ok, IsTrue)
+	c.Assert(records, DeepEquals, regions[:5])
+	records, ok = h.recordsFrom(4)
+	c.Assert(ok, IsTrue)
+	c.Assert(records, DeepEquals, regions[3:5])
+
+	// The oldest records are discarded.
+	for i := 5; i < 100; i++ {
+		h.record(regions[i])
+	}
+	c.Assert(h.getNextIndex(), Equals, uint64(101))
+	_, ok = h.recordsFrom(90)
+	c.Assert(ok, IsFalse)
+	records, ok = h.recordsFrom(91)
+	c.Assert(ok, IsTrue)
+	c.Assert(records, DeepEquals, regions[90:])
+	records, ok = h.recordsFrom(101)
+	c.Assert(ok, IsTrue)
+	c.Assert(records, HasLen, 0)
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncer
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	syncIndexGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "region_syncer",
+			Name:      "index",
+			Help:      "Indexes of the region syncer.",
+		}, []string{"type"})
+
+	syncLagGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "region_syncer",
+			Name:      "lag_seconds",
+			Help:      "Seconds since the follower last caught up with the leader.",
+		})
+
+	syncEventCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "region_syncer",
+			Name:      "event_count",
+			Help:      "Counter of region syncer events.",
+		}, []string{"type"})
+)
+
+func init() {
+	prometheus.MustRegister(syncIndexGauge)
+	prometheus.MustRegister(syncLagGauge)
+	prometheus.MustRegister(syncEventCounter)
+}
@@ -0,0 +1,264 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/pdinternalpb"
+	"github.com/pingcap/pd/server/core"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	historyBufferSize  = 10000
+	maxSyncBatchSize   = 100
+	fullSyncBatchSize  = 1000
+	syncerRetryTimeout = time.Second
+	// streamBufferSize is the max number of the batches queued for a
+	// follower, the follower is disconnected if it falls further behind and
+	// catches up from the history after reconnecting.
+	streamBufferSize = 1024
+)
+
+// Server is the PD server used by the region syncer.
+type Server interface {
+	ClusterID() uint64
+	ID() uint64
+	Name() string
+	IsLeader() bool
+	GetStorage() *core.KV
+	GetRegions() []*core.RegionInfo
+}
+
+// syncStream queues the batches for a follower, they are sent by the
+// goroutine serving the stream so a slow follower doesn't block the others.
+type syncStream struct {
+	stream pdinternalpb.RegionSync_SyncRegionsServer
+	ch     chan *pdinternalpb.SyncRegionResponse
+	done   chan struct{}
+}
+
+// RegionSyncer syncs the region changes from the leader to followers. The
+// leader streams every change it receives to the followers, a follower keeps
+// a copy of the regions so it can serve them as soon as it becomes leader.
+type RegionSyncer struct {
+	sync.Mutex
+	streams map[string]*syncStream
+	server  Server
+	history *historyBuffer
+	creds   credentials.TransportCredentials
+
+	// Follower side states.
+	mu struct {
+		sync.RWMutex
+		regions      *core.RegionsInfo
+		leaderID     uint64
+		nextIndex    uint64
+		lastCaughtUp time.Time
+		cancel       context.CancelFunc
+	}
+	wg sync.WaitGroup
+}
+
+// NewRegionSyncer creates a RegionSyncer. The creds are used to connect to
+// the leader, nil means insecure.
+func NewRegionSyncer(s Server, creds credentials.TransportCredentials) *RegionSyncer {
+	syncer := &RegionSyncer{
+		streams: make(map[string]*syncStream),
+		server:  s,
+		history: newHistoryBuffer(historyBufferSize),
+		creds:   creds,
+	}
+	syncer.mu.regions = core.NewRegionsInfo()
+	return syncer
+}
+
+// RunServer broadcasts the regions received from regionNotifier to the
+// followers until quit is closed.
+func (s *RegionSyncer) RunServer(regionNotifier <-chan *core.RegionInfo, quit <-chan struct{}) {
+	defer s.closeStreams()
+	batch := make([]*core.RegionInfo, 0, maxSyncBatchSize)
+	for {
+		select {
+		case <-quit:
+			log.Info("region syncer has been stopped")
+			return
+		case first := <-regionNotifier:
+			batch = append(batch[:0], first)
+		collect:
+			for len(batch) < maxSyncBatchSize {
+				select {
+				case r := <-regionNotifier:
+					batch = append(batch, r)
+				default:
+					break collect
+				}
+			}
+			s.broadcast(batch)
+		}
+	}
+}
+
+func (s *RegionSyncer) broadcast(regions []*core.RegionInfo) {
+	s.Lock()
+	defer s.Unlock()
+	for _, r := range regions {
+		s.history.record(r)
+	}
+	nextIndex := s.history.getNextIndex()
+	syncIndexGauge.WithLabelValues("leader").Set(float64(nextIndex))
+	resp := s.newResponse(regions, nextIndex, nextIndex)
+	for name, ss := range s.streams {
+		select {
+		case ss.ch <- resp:
+		default:
+			log.Warnf("region syncer closes the stream of %s which falls behind", name)
+			delete(s.streams, name)
+			close(ss.done)
+			syncEventCounter.WithLabelValues("slow_follower").Inc()
+		}
+	}
+}
+
+func (s *RegionSyncer) removeStream(name string, ss *syncStream) {
+	s.Lock()
+	defer s.Unlock()
+	if s.streams[name] == ss {
+		delete(s.streams, name)
+	}
+}
+
+func (s *RegionSyncer) closeStreams() {
+	s.Lock()
+	defer s.Unlock()
+	for name, ss := range s.streams {
+		delete(s.streams, name)
+		close(ss.done)
+	}
+}
+
+func (s *RegionSyncer) newResponse(regions []*core.RegionInfo, nextIndex, leaderIndex uint64) *pdinternalpb.SyncRegionResponse {
+	metas := make([]*metapb.Region, 0, len(regions))
+	leaders := make([]*metapb.Peer, 0, len(regions))
+	for _, r := range regions {
+		leader := r.Leader
+		if leader == nil {
+			leader = &metapb.Peer{}
+		}
+		metas = append(metas, r.Region)
+		leaders = append(leaders, leader)
+	}
+	return &pdinternalpb.SyncRegionResponse{
+		Header:      &pdpb.ResponseHeader{ClusterId: s.server.ClusterID()},
+		Regions:     metas,
+		Leaders:     leaders,
+		NextIndex:   nextIndex,
+		LeaderIndex: leaderIndex,
+	}
+}
+
+// SyncRegions implements RegionSyncServer. It first catches the follower up,
+// then keeps the stream open for the broadcasts.
+func (s *RegionSyncer) SyncRegions(req *pdinternalpb.SyncRegionRequest, stream pdinternalpb.RegionSync_SyncRegionsServer) error {
+	if !s.server.IsLeader() {
+		return status.Errorf(codes.Unavailable, "not leader")
+	}
+	if clusterID := req.Header.GetClusterId(); clusterID != s.server.ClusterID() {
+		return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.server.ClusterID(), clusterID)
+	}
+	name := req.Member.GetName()
+	index := req.StartIndex
+	if _, ok := s.history.recordsFrom(index); index == 0 || !ok {
+		index = s.history.getNextIndex()
+		log.Infof("region syncer starts full sync with %s from index %d", name, index)
+		if err := s.syncAll(stream, index); err != nil {
+			return errors.Trace(err)
+		}
+		syncEventCounter.WithLabelValues("full_sync").Inc()
+	}
+
+	// The stream is registered with the records taken at the same time, so
+	// the later broadcasts are queued after them without gaps.
+	s.Lock()
+	records, ok := s.history.recordsFrom(index)
+	if !ok {
+		s.Unlock()
+		return status.Errorf(codes.Aborted, "history of index %d has been discarded", index)
+	}
+	leaderIndex := s.history.getNextIndex()
+	if old, ok := s.streams[name]; ok {
+		close(old.done)
+	}
+	ss := &syncStream{
+		stream: stream,
+		ch:     make(chan *pdinternalpb.SyncRegionResponse, streamBufferSize),
+		done:   make(chan struct{}),
+	}
+	s.streams[name] = ss
+	s.Unlock()
+
+	for len(records) > 0 {
+		n := len(records)
+		if n > maxSyncBatchSize {
+			n = maxSyncBatchSize
+		}
+		index += uint64(n)
+		if err := stream.Send(s.newResponse(records[:n], index, leaderIndex)); err != nil {
+			s.removeStream(name, ss)
+			return errors.Trace(err)
+		}
+		records = records[n:]
+	}
+	log.Infof("region syncer is serving %s from index %d", name, index)
+
+	for {
+		select {
+		case resp := <-ss.ch:
+			if err := stream.Send(resp); err != nil {
+				log.Errorf("region syncer send data to %s error: %v", name, err)
+				s.removeStream(name, ss)
+				return errors.Trace(err)
+			}
+		case <-ss.done:
+			return nil
+		case <-stream.Context().Done():
+			s.removeStream(name, ss)
+			return nil
+		}
+	}
+}
+
+func (s *RegionSyncer) syncAll(stream pdinternalpb.RegionSync_SyncRegionsServer, index uint64) error {
+	regions := s.server.GetRegions()
+	for len(regions) > 0 {
+		n := len(regions)
+		if n > fullSyncBatchSize {
+			n = fullSyncBatchSize
+		}
+		if err := stream.Send(s.newResponse(regions[:n], index, s.history.getNextIndex())); err != nil {
+			return errors.Trace(err)
+		}
+		regions = regions[n:]
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syncer
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/pdinternalpb"
+	"github.com/pingcap/pd/pkg/testutil"
+	"github.com/pingcap/pd/server/core"
+	"google.golang.org/grpc"
+)
+
+var _ = Suite(&testServerSuite{})
+
+type testServerSuite struct{}
+
+type mockServer struct{}
+
+func (s *mockServer) ClusterID() uint64              { return 1 }
+func (s *mockServer) ID() uint64                     { return 1 }
+func (s *mockServer) Name() string                   { return "leader" }
+func (s *mockServer) IsLeader() bool                 { return true }
+func (s *mockServer) GetStorage() *core.KV           { return nil }
+func (s *mockServer) GetRegions() []*core.RegionInfo { return nil }
+
+type mockStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	// block blocks Send until it is closed if not nil.
+	block chan struct{}
+
+	mu      sync.Mutex
+	regions int
+}
+
+func (s *mockStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *mockStream) Send(resp *pdinternalpb.SyncRegionResponse) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.regions += len(resp.Regions)
+	return nil
+}
+
+func (s *mockStream) getRegions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.regions
+}
+
+func (s *testServerSuite) TestSlowFollower(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	syncer := NewRegionSyncer(&mockServer{}, nil)
+	fast := &mockStream{ctx: ctx}
+	slow := &mockStream{ctx: ctx, block: make(chan struct{})}
+	errs := make(chan error, 2)
+	for name, stream := range map[string]*mockStream{"fast": fast, "slow": slow} {
+		req := &pdinternalpb.SyncRegionRequest{
+			Header: &pdpb.RequestHeader{ClusterId: 1},
+			Member: &pdpb.Member{Name: name},
+		}
+		go func(stream *mockStream) {
+			errs <- syncer.SyncRegions(req, stream)
+		}(stream)
+	}
+	streamCount := func() int {
+		syncer.Lock()
+		defer syncer.Unlock()
+		return len(syncer.streams)
+	}
+	testutil.WaitUntil(c, func(c *C) bool { return streamCount() == 2 })
+
+	// The broadcasts are not blocked by the slow follower, which is
+	// disconnected once its queue is full.
+	n := streamBufferSize + 2
+	for i := 0; i < n; i++ {
+		syncer.broadcast([]*core.RegionInfo{core.NewRegionInfo(&metapb.Region{Id: uint64(i)}, nil)})
+		for fast.getRegions() <= i {
+			runtime.Gosched()
+		}
+	}
+	c.Assert(streamCount(), Equals, 1)
+	_, ok := syncer.streams["fast"]
+	c.Assert(ok, IsTrue)
+	close(slow.block)
+	c.Assert(<-errs, IsNil)
+
+	cancel()
+	c.Assert(<-errs, IsNil)
+	c.Assert(streamCount(), Equals, 0)
+}
@@ -0,0 +1,99 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/pkg/testutil"
+	"github.com/pingcap/pd/server/core"
+)
+
+var _ = Suite(&testRegionSyncerSuite{})
+
+type testRegionSyncerSuite struct {
+	testClusterBaseSuite
+}
+
+func (s *testRegionSyncerSuite) TestRegionSyncer(c *C) {
+	cfgs := NewTestMultiConfig(3)
+	svrs, cleanup := newTestServersWithCfgs(c, cfgs)
+	defer cleanup()
+
+	s.svr = mustWaitLeader(c, svrs)
+	s.grpcPDClient = mustNewGrpcClient(c, s.svr.GetAddr())
+	s.bootstrapCluster(c, s.svr.clusterID, "127.0.0.1:0")
+	cluster := s.svr.GetRaftCluster()
+	c.Assert(cluster, NotNil)
+
+	stores := make([]*metapb.Store, 0, 3)
+	for i := 0; i < 3; i++ {
+		store := s.newStore(c, 0, fmt.Sprintf("127.0.0.1:%d", i+1))
+		c.Assert(cluster.putStore(store), IsNil)
+		stores = append(stores, store)
+	}
+	n := 10
+	regions := make([]*core.RegionInfo, 0, n)
+	for i := 0; i < n; i++ {
+		peers := []*metapb.Peer{
+			s.newPeer(c, stores[0].GetId(), 0),
+			s.newPeer(c, stores[1].GetId(), 0),
+			s.newPeer(c, stores[2].GetId(), 0),
+		}
+		startKey, endKey := []byte(fmt.Sprintf("%03d", i)), []byte(fmt.Sprintf("%03d", i+1))
+		region := s.newRegion(c, 0, startKey, endKey, peers, nil)
+		regions = append(regions, core.NewRegionInfo(region, peers[i%3]))
+		c.Assert(cluster.cachedCluster.handleRegionHeartbeat(regions[i]), IsNil)
+	}
+	regionCount := cluster.cachedCluster.getRegionCount()
+
+	// Followers sync all regions from the leader.
+	var followers []*Server
+	for _, svr := range svrs {
+		if svr != s.svr {
+			followers = append(followers, svr)
+		}
+	}
+	testutil.WaitUntil(c, func(c *C) bool {
+		for _, svr := range followers {
+			if svr.regionSyncer.GetRegionCount() != regionCount {
+				return false
+			}
+		}
+		return true
+	})
+
+	// The new leader serves the regions without waiting for heartbeats.
+	c.Assert(s.svr.ResignLeader(""), IsNil)
+	var leader *Server
+	testutil.WaitUntil(c, func(c *C) bool {
+		leader = mustWaitLeader(c, followers)
+		return leader.GetRaftCluster() != nil
+	})
+	newCluster := leader.GetRaftCluster()
+	c.Assert(newCluster.cachedCluster.getRegionCount(), Equals, regionCount)
+	region := newCluster.GetRegionInfoByKey([]byte("005"))
+	c.Assert(region, NotNil)
+	c.Assert(region.Leader.GetStoreId(), Equals, stores[5%3].GetId())
+
+	// The synced regions are not counted until they report heartbeat, so
+	// the scheduling waits for the latest information.
+	c.Assert(newCluster.cachedCluster.isPrepared(), IsFalse)
+	for _, region := range regions {
+		c.Assert(newCluster.cachedCluster.handleRegionHeartbeat(region), IsNil)
+	}
+	c.Assert(newCluster.cachedCluster.isPrepared(), IsTrue)
+}
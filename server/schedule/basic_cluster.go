@@ -159,6 +159,18 @@ func (bc *BasicCluster) GetAdjacentRegions(region *core.RegionInfo) (*core.Regio
 	return bc.Regions.GetAdjacentRegions(region)
 }
 
+// GetRangeRegionStats returns the statistics of regions whose start key is in
+// range [startKey, endKey).
+func (bc *BasicCluster) GetRangeRegionStats(startKey, endKey []byte) *core.RegionStats {
+	return bc.Regions.GetRangeRegionStats(startKey, endKey)
+}
+
+// RandRangeRegion returns a random region whose start key is in range
+// [startKey, endKey).
+func (bc *BasicCluster) RandRangeRegion(startKey, endKey []byte, opts ...core.RegionOption) *core.RegionInfo {
+	return bc.Regions.RandRangeRegion(startKey, endKey, opts...)
+}
+
 // BlockStore stops balancer from selecting the store.
 func (bc *BasicCluster) BlockStore(storeID uint64) error {
 	return errors.Trace(bc.Stores.BlockStore(storeID))
@@ -369,6 +369,18 @@ func (o *Operator) IsTimeout() bool {
 	return time.Since(o.createTime) > LeaderOperatorWaitTime
 }
 
+// Status returns the status of the operator, which is one of "running",
+// "timeout" and "finished".
+func (o *Operator) Status() string {
+	if o.IsFinish() {
+		return "finished"
+	}
+	if o.IsTimeout() {
+		return "timeout"
+	}
+	return "running"
+}
+
 // Influence calculates the store difference which unfinished operator steps make
 func (o *Operator) Influence(opInfluence OpInfluence, region *core.RegionInfo) {
 	for step := atomic.LoadInt32(&o.currentStep); int(step) < len(o.steps); step++ {
@@ -14,47 +14,27 @@
 package schedule
 
 import (
-	"bytes"
-
 	"github.com/pingcap/pd/server/core"
 )
 
 // RangeCluster isolates the cluster by range.
 type RangeCluster struct {
 	Cluster
-	regions           *core.RegionsInfo
+	startKey          []byte
+	endKey            []byte
+	stats             *core.RegionStats
 	tolerantSizeRatio float64
 }
 
-const scanLimit = 128
-
 // GenRangeCluster gets a range cluster by specifying start key and end key.
+// The statistics of the range are taken from the aggregated region tree, so
+// it does not need to scan the regions.
 func GenRangeCluster(cluster Cluster, startKey, endKey []byte) *RangeCluster {
-	regions := core.NewRegionsInfo()
-	scanKey := startKey
-	loopEnd := false
-	for !loopEnd {
-		collect := cluster.ScanRegions(scanKey, scanLimit)
-		if len(collect) == 0 {
-			break
-		}
-		for _, r := range collect {
-			if bytes.Compare(r.StartKey, endKey) < 0 {
-				regions.SetRegion(r)
-			} else {
-				loopEnd = true
-				break
-			}
-			if string(r.EndKey) == "" {
-				loopEnd = true
-				break
-			}
-			scanKey = r.EndKey
-		}
-	}
 	return &RangeCluster{
-		Cluster: cluster,
-		regions: regions,
+		Cluster:  cluster,
+		startKey: startKey,
+		endKey:   endKey,
+		stats:    cluster.GetRangeRegionStats(startKey, endKey),
 	}
 }
 
@@ -66,11 +46,11 @@ func (r *RangeCluster) updateStoreInfo(s *core.StoreInfo) {
 		return
 	}
 	amplification := float64(s.RegionSize) / used
-	s.LeaderCount = r.regions.GetStoreLeaderCount(id)
-	s.LeaderSize = r.regions.GetStoreLeaderRegionSize(id)
-	s.RegionCount = r.regions.GetStoreRegionCount(id)
-	s.RegionSize = r.regions.GetStoreRegionSize(id)
-	s.PendingPeerCount = r.regions.GetStorePendingPeerCount(id)
+	s.LeaderCount = r.stats.StoreLeaderCount[id]
+	s.LeaderSize = r.stats.StoreLeaderSize[id]
+	s.RegionCount = r.stats.StorePeerCount[id]
+	s.RegionSize = r.stats.StorePeerSize[id]
+	s.PendingPeerCount = r.stats.StorePendingPeerCount[id]
 	s.Stats.UsedSize = uint64(float64(s.RegionSize)/amplification) * (1 << 20)
 	s.Stats.Available = s.Stats.GetCapacity() - s.Stats.GetUsedSize()
 }
@@ -106,17 +86,20 @@ func (r *RangeCluster) GetTolerantSizeRatio() float64 {
 
 // RandFollowerRegion returns a random region that has a follower on the store.
 func (r *RangeCluster) RandFollowerRegion(storeID uint64, opts ...core.RegionOption) *core.RegionInfo {
-	return r.regions.RandFollowerRegion(storeID, opts...)
+	return r.Cluster.RandRangeRegion(r.startKey, r.endKey, append(opts[:len(opts):len(opts)], core.FollowerInStore(storeID))...)
 }
 
 // RandLeaderRegion returns a random region that has leader on the store.
 func (r *RangeCluster) RandLeaderRegion(storeID uint64, opts ...core.RegionOption) *core.RegionInfo {
-	return r.regions.RandLeaderRegion(storeID, opts...)
+	return r.Cluster.RandRangeRegion(r.startKey, r.endKey, append(opts[:len(opts):len(opts)], core.LeaderInStore(storeID))...)
 }
 
 // GetAverageRegionSize returns the average region approximate size.
 func (r *RangeCluster) GetAverageRegionSize() int64 {
-	return r.regions.GetAverageRegionSize()
+	if r.stats.Count == 0 {
+		return 0
+	}
+	return r.stats.StorageSize / int64(r.stats.Count)
 }
 
 // GetRegionStores returns all stores that contains the region's peer.
@@ -37,6 +37,8 @@ type Cluster interface {
 	GetLeaderStore(region *core.RegionInfo) *core.StoreInfo
 	GetAdjacentRegions(region *core.RegionInfo) (*core.RegionInfo, *core.RegionInfo)
 	ScanRegions(startKey []byte, limit int) []*core.RegionInfo
+	GetRangeRegionStats(startKey, endKey []byte) *core.RegionStats
+	RandRangeRegion(startKey, endKey []byte, opts ...core.RegionOption) *core.RegionInfo
 
 	BlockStore(id uint64) error
 	UnblockStore(id uint64)
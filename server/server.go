@@ -19,6 +19,7 @@ import (
 	"math/rand"
 	"net/http"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -32,12 +33,17 @@ import (
 	"github.com/juju/errors"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/pkg/certutil"
 	"github.com/pingcap/pd/pkg/etcdutil"
 	"github.com/pingcap/pd/pkg/logutil"
+	"github.com/pingcap/pd/pkg/pdextpb"
+	"github.com/pingcap/pd/pkg/pdinternalpb"
 	"github.com/pingcap/pd/server/core"
 	"github.com/pingcap/pd/server/namespace"
+	syncer "github.com/pingcap/pd/server/region_syncer"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -47,6 +53,8 @@ const (
 	pdRootPath      = "/pd"
 	pdAPIPrefix     = "/pd/"
 	pdClusterIDPath = "/pd/cluster_id"
+	// regionStorageDir is the directory under data-dir for region storage.
+	regionStorageDir = "region-meta"
 )
 
 // EnableZap enable the zap logger in embed etcd.
@@ -63,6 +71,9 @@ type Server struct {
 	etcdCfg     *embed.Config
 	scheduleOpt *scheduleOption
 	handler     *Handler
+	// certReloader keeps the certificates used to connect to the members up
+	// to date with the files, it is nil if TLS is disabled.
+	certReloader *certutil.Reloader
 
 	serverLoopCtx    context.Context
 	serverLoopCancel func()
@@ -90,8 +101,31 @@ type Server struct {
 	// For tso, set after pd becomes leader.
 	ts            atomic.Value
 	lastSavedTime time.Time
+	tsoHealth     *tsoHealthMonitor
+	// For the clocks of members, probed by the leader.
+	clocksMu struct {
+		sync.RWMutex
+		clocks map[uint64]*MemberClock
+	}
 	// For async region heartbeat.
 	hbStreams *heartbeatStreams
+	// For syncing regions between leader and followers.
+	regionSyncer *syncer.RegionSyncer
+	// gcSafePointMu serializes the updates of GC safe point and the safe
+	// points of services.
+	gcSafePointMu sync.Mutex
+
+	// configLock serializes the config updates of UpdateConfig.
+	configLock sync.Mutex
+	// fileCfg is the config loaded from the config file last time.
+	fileCfg *Config
+	// cfgMu protects the items of cfg which are reloaded online.
+	cfgMu sync.RWMutex
+
+	// For the authentication of the HTTP API.
+	authCache authCache
+
+	auditLog auditLog
 }
 
 // CreateServer creates the UNINITIALIZED pd server with given configuration.
@@ -102,8 +136,20 @@ func CreateServer(cfg *Config, apiRegister func(*Server) http.Handler) (*Server,
 	s := &Server{
 		cfg:         cfg,
 		scheduleOpt: newScheduleOption(cfg),
+		fileCfg:     cfg.clone(),
 	}
 	s.handler = newHandler(s)
+	s.tsoHealth = newTSOHealthMonitor()
+	var creds credentials.TransportCredentials
+	if cfg.Security.tlsEnabled() {
+		reloader, err := certutil.NewReloader(cfg.Security.certPaths())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		s.certReloader = reloader
+		creds = reloader.TransportCredentials()
+	}
+	s.regionSyncer = syncer.NewRegionSyncer(s, creds)
 
 	// Adjust etcd config.
 	etcdCfg, err := s.cfg.genEmbedEtcdConfig()
@@ -115,7 +161,11 @@ func CreateServer(cfg *Config, apiRegister func(*Server) http.Handler) (*Server,
 			pdAPIPrefix: apiRegister(s),
 		}
 	}
-	etcdCfg.ServiceRegister = func(gs *grpc.Server) { pdpb.RegisterPDServer(gs, s) }
+	etcdCfg.ServiceRegister = func(gs *grpc.Server) {
+		pdpb.RegisterPDServer(gs, s)
+		pdextpb.RegisterPDServer(gs, s)
+		pdinternalpb.RegisterRegionSyncServer(gs, s.regionSyncer)
+	}
 	s.etcdCfg = etcdCfg
 	if EnableZap {
 		// The etcd master version has removed embed.Config.SetupLogging.
@@ -157,11 +207,16 @@ func (s *Server) startEtcd(ctx context.Context) error {
 	endpoints := []string{s.etcdCfg.ACUrls[0].String()}
 	log.Infof("create etcd v3 client with endpoints %v", endpoints)
 
-	client, err := clientv3.New(clientv3.Config{
+	etcdClientCfg := clientv3.Config{
 		Endpoints:   endpoints,
 		DialTimeout: etcdTimeout,
 		TLS:         tlsConfig,
-	})
+	}
+	if s.certReloader != nil {
+		// Overrides the credentials built from the static TLS config.
+		etcdClientCfg.DialOptions = []grpc.DialOption{grpc.WithTransportCredentials(s.certReloader.TransportCredentials())}
+	}
+	client, err := clientv3.New(etcdClientCfg)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -205,6 +260,14 @@ func (s *Server) startServer() error {
 	s.idAlloc = &idAllocator{s: s}
 	kvBase := newEtcdKVBase(s)
 	s.kv = core.NewKV(kvBase)
+	if s.cfg.UseRegionStorage {
+		var regionKVBase *core.BoltKV
+		regionKVBase, err = core.NewBoltKV(filepath.Join(s.cfg.DataDir, regionStorageDir))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		s.kv.SetRegionKV(core.NewRegionKV(regionKVBase))
+	}
 	s.cluster = newRaftCluster(s, s.clusterID)
 	s.hbStreams = newHeartbeatStreams(s.clusterID)
 	if s.classifier, err = namespace.CreateClassifier(s.cfg.NamespaceClassifier, s.kv, s.idAlloc); err != nil {
@@ -255,6 +318,14 @@ func (s *Server) Close() {
 		s.hbStreams.Close()
 	}
 
+	if s.kv != nil {
+		if err := s.kv.Close(); err != nil {
+			log.Errorf("close kv meet error: %v", err)
+		}
+	}
+
+	s.closeAuditLog()
+
 	log.Info("close server")
 }
 
@@ -289,10 +360,11 @@ func (s *Server) Run(ctx context.Context) error {
 
 func (s *Server) startServerLoop() {
 	s.serverLoopCtx, s.serverLoopCancel = context.WithCancel(context.Background())
-	s.serverLoopWg.Add(3)
+	s.serverLoopWg.Add(4)
 	go s.leaderLoop()
 	go s.etcdLeaderLoop()
 	go s.serverMetricsLoop()
+	go s.clockMonitorLoop()
 }
 
 func (s *Server) stopServerLoop() {
@@ -310,6 +382,7 @@ func (s *Server) serverMetricsLoop() {
 		select {
 		case <-time.After(serverMetricsInterval):
 			s.collectEtcdStateMetrics()
+			s.checkCertificates()
 		case <-ctx.Done():
 			log.Info("server is closed, exit metrics loop")
 			return
@@ -317,6 +390,27 @@ func (s *Server) serverMetricsLoop() {
 	}
 }
 
+// checkCertificates reloads the certificates if the files change, and
+// updates the expiry metrics.
+func (s *Server) checkCertificates() {
+	if s.certReloader == nil {
+		return
+	}
+	reloaded, err := s.certReloader.Reload()
+	if err != nil {
+		log.Errorf("failed to reload certificates, keep the previous ones: %v", err)
+	} else if reloaded {
+		log.Info("certificates are reloaded")
+	}
+	ca, cert := s.certReloader.Expiry()
+	if !ca.IsZero() {
+		certExpiryGauge.WithLabelValues("ca").Set(float64(ca.Unix()))
+	}
+	if !cert.IsZero() {
+		certExpiryGauge.WithLabelValues("cert").Set(float64(cert.Unix()))
+	}
+}
+
 func (s *Server) collectEtcdStateMetrics() {
 	etcdStateGauge.WithLabelValues("term").Set(float64(s.etcd.Server.Term()))
 	etcdStateGauge.WithLabelValues("appliedIndex").Set(float64(s.etcd.Server.AppliedIndex()))
@@ -419,6 +513,20 @@ func (s *Server) GetEndpoints() []string {
 	return s.client.Endpoints()
 }
 
+// GetStorage returns the backend storage of the server.
+func (s *Server) GetStorage() *core.KV {
+	return s.kv
+}
+
+// GetRegions returns all regions of the running raft cluster.
+func (s *Server) GetRegions() []*core.RegionInfo {
+	cluster := s.GetRaftCluster()
+	if cluster == nil {
+		return nil
+	}
+	return cluster.GetRegions()
+}
+
 // GetClient returns builtin etcd client.
 func (s *Server) GetClient() *clientv3.Client {
 	return s.client
@@ -453,7 +561,9 @@ func (s *Server) leaderTxn(cs ...clientv3.Cmp) clientv3.Txn {
 
 // GetConfig gets the config information.
 func (s *Server) GetConfig() *Config {
+	s.cfgMu.RLock()
 	cfg := s.cfg.clone()
+	s.cfgMu.RUnlock()
 	cfg.Schedule = *s.scheduleOpt.load()
 	cfg.Replication = *s.scheduleOpt.rep.load()
 	namespaces := make(map[string]NamespaceConfig)
@@ -463,6 +573,7 @@ func (s *Server) GetConfig() *Config {
 	cfg.Namespace = namespaces
 	cfg.LabelProperty = s.scheduleOpt.loadLabelPropertyConfig().clone()
 	cfg.ClusterVersion = s.scheduleOpt.loadClusterVersion()
+	cfg.ConfigRevision = s.scheduleOpt.loadRevision()
 	return cfg
 }
 
@@ -473,18 +584,10 @@ func (s *Server) GetScheduleConfig() *ScheduleConfig {
 	return cfg
 }
 
-// SetScheduleConfig sets the balance config information.
-func (s *Server) SetScheduleConfig(cfg ScheduleConfig) error {
-	if err := cfg.validate(); err != nil {
-		return errors.Trace(err)
-	}
-	old := s.scheduleOpt.load()
-	s.scheduleOpt.store(&cfg)
-	if err := s.scheduleOpt.persist(s.kv); err != nil {
-		return errors.Trace(err)
-	}
-	log.Infof("schedule config is updated: %+v, old: %+v", cfg, old)
-	return nil
+// SetScheduleConfig sets the balance config information, and returns the
+// warnings.
+func (s *Server) SetScheduleConfig(cfg ScheduleConfig) (*ConfigCheckResult, error) {
+	return s.SetScheduleAndReplicationConfig(&cfg, nil)
 }
 
 // GetReplicationConfig get the replication config.
@@ -494,19 +597,46 @@ func (s *Server) GetReplicationConfig() *ReplicationConfig {
 	return cfg
 }
 
-// SetReplicationConfig sets the replication config.
-func (s *Server) SetReplicationConfig(cfg ReplicationConfig) error {
-	if err := cfg.validate(); err != nil {
-		return errors.Trace(err)
+// SetReplicationConfig sets the replication config, and returns the warnings.
+func (s *Server) SetReplicationConfig(cfg ReplicationConfig) (*ConfigCheckResult, error) {
+	return s.SetScheduleAndReplicationConfig(nil, &cfg)
+}
+
+// SetScheduleAndReplicationConfig sets the schedule config and the
+// replication config as one revision, the nil one is not changed. It returns
+// the warnings, including the ones against the stores.
+func (s *Server) SetScheduleAndReplicationConfig(schedule *ScheduleConfig, rep *ReplicationConfig) (*ConfigCheckResult, error) {
+	res := &ConfigCheckResult{}
+	if schedule != nil {
+		schedule.check(res)
+	}
+	if rep != nil {
+		rep.check(res)
+		if cluster := s.GetRaftCluster(); cluster != nil {
+			(&Config{Replication: *rep}).checkStores(cluster.GetStores(), res)
+		}
+	}
+	if err := res.Err(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	logConfigWarnings(res)
+	oldSchedule, oldRep := s.scheduleOpt.load(), s.scheduleOpt.rep.load()
+	if schedule != nil {
+		s.scheduleOpt.store(schedule)
+	}
+	if rep != nil {
+		s.scheduleOpt.rep.store(rep)
 	}
-	old := s.scheduleOpt.rep.load()
-	s.scheduleOpt.rep.store(&cfg)
-	s.scheduleOpt.persist(s.kv)
 	if err := s.scheduleOpt.persist(s.kv); err != nil {
-		return errors.Trace(err)
+		return nil, errors.Trace(err)
 	}
-	log.Infof("replication config is updated: %+v, old: %+v", cfg, old)
-	return nil
+	if schedule != nil {
+		log.Infof("schedule config is updated: %+v, old: %+v", *schedule, oldSchedule)
+	}
+	if rep != nil {
+		log.Infof("replication config is updated: %+v, old: %+v", *rep, oldRep)
+	}
+	return res, nil
 }
 
 // GetNamespaceConfig get the namespace config.
@@ -605,7 +735,16 @@ func (s *Server) GetClusterVersion() semver.Version {
 
 // GetSecurityConfig get the security config.
 func (s *Server) GetSecurityConfig() *SecurityConfig {
-	return &s.cfg.Security
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	cfg := s.cfg.Security
+	return &cfg
+}
+
+func (s *Server) getAlertConfig() *AlertConfig {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.cfg.Alert.clone()
 }
 
 // IsNamespaceExist returns whether the namespace exists.
@@ -694,6 +833,8 @@ func (s *Server) GetMemberLeaderPriority(id uint64) (int, error) {
 
 // SetLogLevel sets log level.
 func (s *Server) SetLogLevel(level string) {
+	s.cfgMu.Lock()
+	defer s.cfgMu.Unlock()
 	s.cfg.Log.Level = level
 }
 
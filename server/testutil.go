@@ -78,6 +78,7 @@ func NewTestSingleConfig() *Config {
 	cfg.TickInterval = typeutil.NewDuration(100 * time.Millisecond)
 	cfg.ElectionInterval = typeutil.NewDuration(3000 * time.Millisecond)
 	cfg.leaderPriorityCheckInterval = typeutil.NewDuration(100 * time.Millisecond)
+	cfg.clockProbeInterval = typeutil.NewDuration(200 * time.Millisecond)
 
 	cfg.adjust(nil)
 
@@ -61,7 +61,10 @@ func (s *Server) saveTimestamp(now time.Time) error {
 	data := uint64ToBytes(uint64(now.UnixNano()))
 	key := s.getTimestampPath()
 
+	start := time.Now()
 	resp, err := s.leaderTxn().Then(clientv3.OpPut(key, string(data))).Commit()
+	tsoSaveDuration.Observe(time.Since(start).Seconds())
+	s.tsoHealth.observeSave(time.Since(start))
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -118,6 +121,11 @@ func (s *Server) updateTimestamp() error {
 	tsoCounter.WithLabelValues("save").Inc()
 
 	since := subTimeByWallClock(now, prev)
+	if since < 0 {
+		log.Errorf("clock fallback: %v, prev: %v, now: %v", since, prev, now)
+		tsoCounter.WithLabelValues("clock_fallback").Inc()
+		s.tsoHealth.record(tsoEventClockFallback)
+	}
 	if since > 3*updateTimestampStep {
 		log.Warnf("clock offset: %v, prev: %v, now: %v", since, prev, now)
 		tsoCounter.WithLabelValues("slow_save").Inc()
@@ -164,6 +172,8 @@ func (s *Server) getRespTS(count uint32) (pdpb.Timestamp, error) {
 		resp.Physical = current.physical.UnixNano() / int64(time.Millisecond)
 		resp.Logical = atomic.AddInt64(&current.logical, int64(count))
 		if resp.Logical >= maxLogical {
+			tsoCounter.WithLabelValues("logical_exhausted").Inc()
+			s.tsoHealth.record(tsoEventLogicalExhausted)
 			log.Errorf("logical part outside of max logical interval %v, please check ntp time, retry count %d", resp, i)
 			time.Sleep(updateTimestampStep)
 			continue
@@ -0,0 +1,130 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// tsoHealthWindow is the window in which the TSO events are counted.
+	tsoHealthWindow = time.Minute
+	// maxTSOEvents is the max count of events kept for each kind of event.
+	maxTSOEvents = 1024
+)
+
+type tsoEvent int
+
+const (
+	// tsoEventLogicalExhausted means the logical counter was exhausted and the
+	// request had to wait for the next physical time.
+	tsoEventLogicalExhausted tsoEvent = iota
+	// tsoEventSlowSave means saving timestamp to etcd was slow.
+	tsoEventSlowSave
+	// tsoEventClockFallback means the local clock went backwards.
+	tsoEventClockFallback
+	tsoEventCount
+)
+
+// TSOHealth is the health status of the TSO service of the leader. The
+// counts are of the events in the recent window.
+type TSOHealth struct {
+	Healthy          bool          `json:"healthy"`
+	Window           time.Duration `json:"window"`
+	LogicalExhausted int           `json:"logical_exhausted"`
+	SlowSaves        int           `json:"slow_saves"`
+	ClockFallbacks   int           `json:"clock_fallbacks"`
+	LastSaveLatency  time.Duration `json:"last_save_latency"`
+	Problems         []string      `json:"problems,omitempty"`
+}
+
+// tsoHealthMonitor records the abnormal events of TSO.
+type tsoHealthMonitor struct {
+	sync.Mutex
+	events          [tsoEventCount][]time.Time
+	lastSaveLatency time.Duration
+	now             func() time.Time
+}
+
+func newTSOHealthMonitor() *tsoHealthMonitor {
+	return &tsoHealthMonitor{now: time.Now}
+}
+
+// GetTSOHealth returns the health status of the TSO service, it is only
+// meaningful on the leader.
+func (s *Server) GetTSOHealth() *TSOHealth {
+	return s.tsoHealth.health()
+}
+
+func (m *tsoHealthMonitor) record(event tsoEvent) {
+	m.Lock()
+	defer m.Unlock()
+	m.recordLocked(event, m.now())
+}
+
+// observeSave records the latency of saving timestamp.
+func (m *tsoHealthMonitor) observeSave(latency time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	m.lastSaveLatency = latency
+	if latency > slowRequestTime {
+		m.recordLocked(tsoEventSlowSave, m.now())
+	}
+}
+
+func (m *tsoHealthMonitor) health() *TSOHealth {
+	m.Lock()
+	defer m.Unlock()
+	now := m.now()
+	h := &TSOHealth{
+		Window:           tsoHealthWindow,
+		LogicalExhausted: m.countLocked(tsoEventLogicalExhausted, now),
+		SlowSaves:        m.countLocked(tsoEventSlowSave, now),
+		ClockFallbacks:   m.countLocked(tsoEventClockFallback, now),
+		LastSaveLatency:  m.lastSaveLatency,
+	}
+	if h.LogicalExhausted > 0 {
+		h.Problems = append(h.Problems, fmt.Sprintf("logical counter exhausted %d times, the physical time may be updated too slowly", h.LogicalExhausted))
+	}
+	if h.SlowSaves > 0 {
+		h.Problems = append(h.Problems, fmt.Sprintf("saving timestamp was slower than %v for %d times", slowRequestTime, h.SlowSaves))
+	}
+	if h.ClockFallbacks > 0 {
+		h.Problems = append(h.Problems, fmt.Sprintf("local clock went backwards %d times", h.ClockFallbacks))
+	}
+	h.Healthy = len(h.Problems) == 0
+	return h
+}
+
+func (m *tsoHealthMonitor) recordLocked(event tsoEvent, now time.Time) {
+	events := m.events[event]
+	if len(events) >= maxTSOEvents {
+		events = events[1:]
+	}
+	m.events[event] = append(events, now)
+}
+
+// countLocked returns the count of the events in the window, the expired
+// events are removed.
+func (m *tsoHealthMonitor) countLocked(event tsoEvent, now time.Time) int {
+	events := m.events[event]
+	i := 0
+	for i < len(events) && now.Sub(events[i]) > tsoHealthWindow {
+		i++
+	}
+	m.events[event] = events[i:]
+	return len(events) - i
+}
@@ -250,17 +250,14 @@ func subTimeByWallClock(after time.Time, before time.Time) time.Duration {
 	return time.Duration(after.UnixNano() - before.UnixNano())
 }
 
-// InitHTTPClient initials a http client.
+// InitHTTPClient initials a http client. The certificates are reloaded
+// once the files change.
 func InitHTTPClient(svr *Server) error {
-	tlsConfig, err := svr.GetSecurityConfig().ToTLSConfig()
-	if err != nil {
-		return errors.Trace(err)
+	transport := &http.Transport{DisableKeepAlives: true}
+	if svr.certReloader != nil {
+		transport.DialTLS = svr.certReloader.DialTLS
 	}
-
-	DialClient = &http.Client{Transport: &http.Transport{
-		TLSClientConfig:   tlsConfig,
-		DisableKeepAlives: true,
-	}}
+	DialClient = &http.Client{Transport: transport}
 	return nil
 }
 
@@ -11,10 +11,12 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package api
+package server
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/juju/errors"
@@ -26,8 +28,8 @@ const (
 	etcdPeerStatsAPI = "/v2/stats/self"
 )
 
-// PeerStats is the etcd peers' stats.
-type PeerStats struct {
+// peerStats is the etcd peers' stats.
+type peerStats struct {
 	Name       string    `json:"name"`
 	ID         string    `json:"id"`
 	State      string    `json:"state"`
@@ -41,14 +43,17 @@ type PeerStats struct {
 	SendAppendRequestCnt int `json:"sendAppendRequestCnt"`
 }
 
-func getEtcdPeerStats(etcdClientURL string) (*PeerStats, error) {
-	ps := &PeerStats{}
-	resp, err := doGet(fmt.Sprintf("%s%s", etcdClientURL, etcdPeerStatsAPI))
+func getEtcdPeerStats(etcdClientURL string) (*peerStats, error) {
+	ps := &peerStats{}
+	resp, err := DialClient.Get(fmt.Sprintf("%s%s", etcdClientURL, etcdPeerStatsAPI))
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	defer resp.Body.Close()
-	if err := readJSON(resp.Body, ps); err != nil {
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("http get etcd peer stats from %s return code %d", etcdClientURL, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(ps); err != nil {
 		return nil, errors.Trace(err)
 	}
 	return ps, nil
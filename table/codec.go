@@ -16,6 +16,7 @@ package table
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 
 	"github.com/juju/errors"
 )
@@ -23,6 +24,9 @@ import (
 var (
 	tablePrefix = []byte{'t'}
 	metaPrefix  = []byte{'m'}
+
+	recordPrefixSep = []byte("_r")
+	indexPrefixSep  = []byte("_i")
 )
 
 const (
@@ -61,6 +65,35 @@ func (k Key) IsMeta() bool {
 	return bytes.HasPrefix(key, metaPrefix)
 }
 
+// Describe returns where the key is in the table data, like "table 45 index 2",
+// it returns an empty string if the key is not encoded from the table data.
+func (k Key) Describe() string {
+	_, key, err := decodeBytes(k)
+	if err != nil {
+		return ""
+	}
+	if bytes.HasPrefix(key, metaPrefix) {
+		return "meta"
+	}
+	if !bytes.HasPrefix(key, tablePrefix) {
+		return ""
+	}
+	key, tableID, err := DecodeInt(key[len(tablePrefix):])
+	if err != nil {
+		return ""
+	}
+	desc := fmt.Sprintf("table %d", tableID)
+	switch {
+	case bytes.HasPrefix(key, recordPrefixSep):
+		return desc + " record"
+	case bytes.HasPrefix(key, indexPrefixSep):
+		if _, indexID, err := DecodeInt(key[len(indexPrefixSep):]); err == nil {
+			return fmt.Sprintf("%s index %d", desc, indexID)
+		}
+	}
+	return desc
+}
+
 // DecodeInt decodes value encoded by EncodeInt before.
 // It returns the leftover un-decoded slice, decoded value if no error.
 func DecodeInt(b []byte) ([]byte, int64, error) {
@@ -77,3 +77,22 @@ func (s *testCodecSuite) TestTableID(c *C) {
 	key = encodeBytes([]byte("t\x80\x00\x00\x00\x00\x00\xff"))
 	c.Assert(Key(key).TableID(), Equals, int64(0))
 }
+
+func (s *testCodecSuite) TestDescribe(c *C) {
+	testCases := []struct {
+		key  Key
+		desc string
+	}{
+		{encodeBytes([]byte("t\x80\x00\x00\x00\x00\x00\x00\xff")), "table 255"},
+		{encodeBytes([]byte("t\x80\x00\x00\x00\x00\x00\x00\xff_r\x80\x00\x00\x00\x00\x00\x00\x01")), "table 255 record"},
+		{encodeBytes([]byte("t\x80\x00\x00\x00\x00\x00\x00\xff_i\x80\x00\x00\x00\x00\x00\x00\x02\x01")), "table 255 index 2"},
+		{encodeBytes([]byte("t\x80\x00\x00\x00\x00\x00\x00\xff_i\x01")), "table 255"},
+		{encodeBytes([]byte("mDB:1")), "meta"},
+		{encodeBytes([]byte("t\x80\x00")), ""},
+		{Key("t\x80\x00\x00\x00\x00\x00\x00\xff"), ""},
+		{Key(""), ""},
+	}
+	for _, t := range testCases {
+		c.Assert(t.key.Describe(), Equals, t.desc)
+	}
+}